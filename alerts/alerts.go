@@ -0,0 +1,335 @@
+// alerts package contains the evaluation of price alerts, such as a pair's price crossing a level, its spread
+// widening, or a session opening. Alerts are stored within the database and evaluated periodically by a worker
+// against the latest quotes and the session engine, triggering when their condition becomes met, no more often than
+// their cooldown, until they expire. Triggers are logged, and notified through pluggable channels
+package alerts
+
+import (
+	// Standard lib
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/quotes"
+	"github.com/deezone/forex-clock/sessions"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Alert types
+	TypePriceAbove   = "price-above"   // The pair's mid price rises above the threshold
+	TypePriceBelow   = "price-below"   // The pair's mid price falls below the threshold
+	TypePriceCross   = "price-cross"   // The pair's mid price crosses the threshold, in either direction
+	TypeSpreadAbove  = "spread-above"  // The pair's spread widens above the threshold, in pips
+	TypeSessionOpen  = "session-open"  // The session opens
+	TypeSessionClose = "session-close" // The session closes
+
+	// Longest error message kept for a trigger
+	maxErrorsLength = 2048
+)
+
+var (
+	// All alert types
+	Types = []string{TypePriceAbove, TypePriceBelow, TypePriceCross, TypeSpreadAbove, TypeSessionOpen, TypeSessionClose}
+)
+
+type (
+	// Evaluator is a struct representing the evaluation of stored alerts against the latest quotes and market events
+	Evaluator struct {
+		store     db.AlertStore
+		quotes    *quotes.Store
+		channels  map[string]Channel
+		mutex     sync.Mutex
+		armed     map[string]bool    // Whether the condition of level alerts was unmet when last evaluated, by alert
+		last      map[string]float64 // Mid price of cross alerts when last evaluated, by alert
+		evaluated time.Time          // When alerts were last evaluated
+		stop      chan struct{}      // Closed to stop the worker, nil when not running
+		done      chan struct{}      // Closed once the worker stops
+	}
+)
+
+// NewEvaluator creates and returns a new instance of an evaluator of stored alerts, notifying triggers through a set
+// of channels
+func NewEvaluator(store db.AlertStore, q *quotes.Store, channels ...Channel) *Evaluator {
+	e := &Evaluator{
+		store:    store,
+		quotes:   q,
+		channels: map[string]Channel{},
+		armed:    map[string]bool{},
+		last:     map[string]float64{},
+	}
+	for _, c := range channels {
+		e.channels[c.Name()] = c
+	}
+
+	return e
+}
+
+// QuoteType returns a boolean indicating if alerts of a type are evaluated against the quotes of a pair
+func QuoteType(t string) bool {
+	return t == TypePriceAbove || t == TypePriceBelow || t == TypePriceCross || t == TypeSpreadAbove
+}
+
+// SessionType returns a boolean indicating if alerts of a type are evaluated against the events of a session
+func SessionType(t string) bool { return t == TypeSessionOpen || t == TypeSessionClose }
+
+// Start starts evaluating alerts periodically
+func (e *Evaluator) Start() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.stop != nil {
+		return
+	}
+
+	e.stop, e.done = make(chan struct{}), make(chan struct{})
+	go e.run(e.stop, e.done)
+}
+
+// Close stops the worker, waiting for notifications in progress to be sent
+func (e *Evaluator) Close() {
+	e.mutex.Lock()
+	stop, done := e.stop, e.done
+	e.stop = nil
+	e.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Evaluate evaluates every active alert at a point in time, expiring those past their expiry and triggering those
+// whose condition became met, then waits for their notifications to be sent
+// NOTE: Session alerts match the market events since alerts were last evaluated, so none match the first time
+func (e *Evaluator) Evaluate(now time.Time) {
+	list, err := e.store.ListAll()
+	if err != nil {
+		log.WithError(err).Error("Error listing alerts")
+		return
+	}
+
+	e.mutex.Lock()
+	from := e.evaluated
+	e.evaluated = now
+	e.mutex.Unlock()
+
+	var events []*sessions.Event
+	if !from.IsZero() {
+		events = sessions.GetInstance().Events(from.Add(time.Nanosecond), now.Add(time.Nanosecond))
+	}
+
+	var wg sync.WaitGroup
+	active := map[string]bool{}
+	for _, a := range list {
+		if a.Status != db.AlertActive {
+			continue
+		}
+		if a.ExpiresAt != nil && !now.Before(*a.ExpiresAt) {
+			e.expire(a, now)
+			continue
+		}
+		active[a.ID] = true
+
+		value, message, ok := e.check(a, events)
+		if !ok {
+			continue
+		}
+
+		// NOTE: Triggers within the cooldown are skipped
+		if a.LastTriggeredAt != nil && now.Sub(*a.LastTriggeredAt) < time.Duration(a.Cooldown)*time.Second {
+			log.WithField("alert", a.ID).Debug("Alert trigger skipped during its cooldown")
+			continue
+		}
+
+		ev := e.trigger(a, value, message, now)
+		if ev == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(a *db.Alert, ev *db.AlertEvent) {
+			defer wg.Done()
+			e.notify(a, ev)
+		}(a, ev)
+	}
+	wg.Wait()
+
+	// Forget the state of alerts that are no longer active
+	e.mutex.Lock()
+	for id := range e.armed {
+		if !active[id] {
+			delete(e.armed, id)
+		}
+	}
+	for id := range e.last {
+		if !active[id] {
+			delete(e.last, id)
+		}
+	}
+	e.mutex.Unlock()
+}
+
+// check returns whether an alert's condition became met, the value that met it, and a message describing it
+// NOTE: Level conditions (ex: above a price) are met once until they're unmet again, and stale quotes are ignored
+func (e *Evaluator) check(a *db.Alert, events []*sessions.Event) (float64, string, bool) {
+	if SessionType(a.Type) {
+		for _, ev := range events {
+			if ev.Type == a.Type && ev.Session == a.Session {
+				verb := "opened"
+				if a.Type == TypeSessionClose {
+					verb = "closed"
+				}
+				return 0, fmt.Sprintf("%s session %s", sessionName(a.Session), verb), true
+			}
+		}
+		return 0, "", false
+	}
+
+	q := e.quotes.Cross(a.Pair)
+	if q == nil || q.Stale {
+		return 0, "", false
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if a.Type == TypePriceCross {
+		prev, ok := e.last[a.ID]
+		e.last[a.ID] = q.Mid
+		if !ok || !((prev < a.Threshold && q.Mid >= a.Threshold) || (prev > a.Threshold && q.Mid <= a.Threshold)) {
+			return 0, "", false
+		}
+		return q.Mid, fmt.Sprintf("%s crossed %s at %s", q.Pair, format(a.Threshold), format(q.Mid)), true
+	}
+
+	value, met, message := q.Mid, false, ""
+	switch a.Type {
+	case TypePriceAbove:
+		met, message = q.Mid > a.Threshold, "%s rose above %s at %s"
+	case TypePriceBelow:
+		met, message = q.Mid < a.Threshold, "%s fell below %s at %s"
+	case TypeSpreadAbove:
+		value = q.Spread
+		met, message = q.Spread > a.Threshold, "%s spread widened above %s pips to %s"
+	}
+
+	// NOTE: Alerts are armed until first evaluated, so conditions already met when created trigger
+	armed, ok := e.armed[a.ID]
+	e.armed[a.ID] = !met
+	if !met || (ok && !armed) {
+		return 0, "", false
+	}
+
+	return value, fmt.Sprintf(message, q.Pair, format(a.Threshold), format(value)), true
+}
+
+// trigger records a trigger of an alert, returning its log, or nil if it was triggered by another replica
+func (e *Evaluator) trigger(a *db.Alert, value float64, message string, now time.Time) *db.AlertEvent {
+	if err := e.store.Trigger(a, now); err != nil {
+		if err != db.ErrNotFound {
+			log.WithError(err).WithField("alert", a.ID).Error("Error triggering alert")
+		}
+		return nil
+	}
+
+	ev := &db.AlertEvent{
+		ID:        db.NewID(),
+		AlertID:   a.ID,
+		Owner:     a.Owner,
+		Type:      a.Type,
+		Pair:      a.Pair,
+		Session:   a.Session,
+		Threshold: a.Threshold,
+		Value:     value,
+		Message:   message,
+		Notified:  db.StringList{},
+		CreatedAt: now.UTC().Truncate(time.Second),
+	}
+	if err := e.store.CreateEvent(ev); err != nil {
+		log.WithError(err).WithField("alert", a.ID).Error("Error logging alert trigger")
+	}
+
+	log.WithFields(log.Fields{"alert": a.ID, "type": a.Type, "message": message}).Info("Alert triggered")
+
+	return ev
+}
+
+// notify sends a trigger of an alert through each of its channels, logging the results
+func (e *Evaluator) notify(a *db.Alert, ev *db.AlertEvent) {
+	errs := []string{}
+	for _, name := range a.Channels {
+		c, ok := e.channels[name]
+		if !ok {
+			errs = append(errs, name+": Unknown channel")
+			continue
+		}
+		if err := c.Notify(a, ev); err != nil {
+			errs = append(errs, name+": "+err.Error())
+			continue
+		}
+		ev.Notified = append(ev.Notified, name)
+	}
+
+	ev.Errors = strings.Join(errs, "; ")
+	if len(ev.Errors) > maxErrorsLength {
+		ev.Errors = ev.Errors[:maxErrorsLength]
+	}
+	if ev.Errors != "" {
+		log.WithFields(log.Fields{"alert": a.ID, "errors": ev.Errors}).Warn("Error notifying alert trigger")
+	}
+
+	if err := e.store.UpdateEvent(ev); err != nil {
+		log.WithError(err).WithField("alert", a.ID).Error("Error logging alert notifications")
+	}
+}
+
+// expire moves an alert past its expiry to the expired status
+func (e *Evaluator) expire(a *db.Alert, now time.Time) {
+	if err := e.store.Expire(a, now); err != nil {
+		if err != db.ErrNotFound {
+			log.WithError(err).WithField("alert", a.ID).Error("Error expiring alert")
+		}
+		return
+	}
+
+	log.WithField("alert", a.ID).Info("Alert expired")
+}
+
+// run evaluates alerts periodically, until stopped
+func (e *Evaluator) run(stop, done chan struct{}) {
+	defer close(done)
+
+	t := time.NewTicker(time.Duration(config.GetInstance().Alerts.Interval) * time.Second)
+	defer t.Stop()
+
+	e.Evaluate(time.Now())
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-t.C:
+			e.Evaluate(now)
+		}
+	}
+}
+
+// sessionName returns the name of a session, or its ID if it isn't known
+func sessionName(id string) string {
+	if s := sessions.GetInstance().Session(id); s != nil {
+		return s.Name
+	}
+
+	return id
+}
+
+// format returns the shortest representation of a number
+func format(n float64) string { return strconv.FormatFloat(n, 'f', -1, 64) }
@@ -0,0 +1,27 @@
+// Test suite setup for the alerts package
+package alerts
+
+import (
+	// Standard lib
+	"io/ioutil"
+	"testing"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests the alerts package
+func TestAlerts(t *testing.T) {
+	// Register gomega fail handler
+	RegisterFailHandler(Fail)
+
+	// Have go's testing package run package specs
+	RunSpecs(t, "Alerts Suite")
+}
+
+func init() {
+	// Set logger output so as not to log during tests
+	log.SetOutput(ioutil.Discard)
+}
@@ -0,0 +1,280 @@
+// Tests the alerts.go file
+package alerts
+
+import (
+	// Standard lib
+	"errors"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/db/dbtest"
+	"github.com/deezone/forex-clock/provider"
+	"github.com/deezone/forex-clock/quotes"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing a channel recording the triggers it's notified of
+	recordingChannel struct {
+		mutex    sync.Mutex
+		name     string
+		err      error // Error returned when notified, if set
+		triggers []*db.AlertEvent
+	}
+)
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Notify(a *db.Alert, ev *db.AlertEvent) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.triggers = append(c.triggers, ev)
+	return c.err
+}
+
+// notified returns the triggers the channel was notified of
+func (c *recordingChannel) notified() []*db.AlertEvent {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]*db.AlertEvent{}, c.triggers...)
+}
+
+var _ = Describe("alerts.go", func() {
+	var (
+		conn  db.DB
+		store db.AlertStore
+		q     *quotes.Store
+		log   *recordingChannel
+		e     *Evaluator
+	)
+
+	// put stores a fresh EURUSD quote with a 1 pip spread around a mid price
+	put := func(mid float64) {
+		q.Put(&provider.Tick{Pair: "EURUSD", Bid: mid - 0.00005, Ask: mid + 0.00005, Time: time.Now()})
+	}
+
+	// alert stores and returns an active EURUSD alert
+	alert := func(t string, threshold float64) *db.Alert {
+		a := &db.Alert{
+			ID:        db.NewID(),
+			Owner:     "api-key:k1",
+			Type:      t,
+			Pair:      "EURUSD",
+			Threshold: threshold,
+			Channels:  db.StringList{ChannelLog},
+			Status:    db.AlertActive,
+		}
+		store.Create(a)
+		return a
+	}
+
+	// evaluate evaluates alerts, returning the recorded alert afterwards
+	evaluate := func(a *db.Alert) *db.Alert {
+		e.Evaluate(time.Now())
+		a, _ = store.Get(a.Owner, a.ID)
+		return a
+	}
+
+	BeforeEach(func() {
+		conn = dbtest.New()
+		store = db.NewAlertStore(conn)
+		q = quotes.NewStore(nil)
+		log = &recordingChannel{name: ChannelLog}
+		e = NewEvaluator(store, q, log)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+	})
+
+	It("Triggers level alerts once their condition becomes met", func() {
+		a := alert(TypePriceAbove, 1.1)
+		a.Repeat = true
+		store.Update(a)
+
+		put(1.0995)
+		Expect(evaluate(a).Triggers).To(Equal(0))
+
+		put(1.1005)
+		a = evaluate(a)
+		Expect(a.Triggers).To(Equal(1))
+		Expect(a.Status).To(Equal(db.AlertActive))
+		Expect(a.LastTriggeredAt).NotTo(BeNil())
+
+		// NOTE: The condition staying met doesn't trigger again, until it's unmet and met again
+		put(1.101)
+		Expect(evaluate(a).Triggers).To(Equal(1))
+		put(1.099)
+		Expect(evaluate(a).Triggers).To(Equal(1))
+		put(1.102)
+		Expect(evaluate(a).Triggers).To(Equal(2))
+
+		// Verify output
+		triggers := log.notified()
+		Expect(triggers).To(HaveLen(2))
+		Expect(triggers[0].AlertID).To(Equal(a.ID))
+		Expect(triggers[0].Value).To(Equal(1.1005))
+		Expect(triggers[0].Message).To(Equal("EURUSD rose above 1.1 at 1.1005"))
+
+		events, _ := store.ListEvents(db.AlertEventFilter{Owner: a.Owner, AlertID: a.ID, Limit: 10})
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Notified).To(Equal(db.StringList{ChannelLog}))
+		Expect(events[0].Errors).To(BeEmpty())
+	})
+
+	It("Triggers alerts whose condition is met when created", func() {
+		a := alert(TypePriceBelow, 1.1)
+
+		put(1.09)
+		a = evaluate(a)
+
+		// Verify output
+		Expect(a.Triggers).To(Equal(1))
+		Expect(a.Status).To(Equal(db.AlertTriggered))
+
+		// NOTE: Alerts that don't repeat are no longer evaluated once triggered
+		put(1.11)
+		evaluate(a)
+		put(1.08)
+		Expect(evaluate(a).Triggers).To(Equal(1))
+		Expect(log.notified()).To(HaveLen(1))
+	})
+
+	It("Triggers cross alerts when the price crosses the threshold in either direction", func() {
+		a := alert(TypePriceCross, 1.1)
+		a.Repeat = true
+		store.Update(a)
+
+		// NOTE: The first evaluation only records the price, whichever side of the threshold it's on
+		put(1.1005)
+		Expect(evaluate(a).Triggers).To(Equal(0))
+		put(1.101)
+		Expect(evaluate(a).Triggers).To(Equal(0))
+
+		put(1.0995)
+		Expect(evaluate(a).Triggers).To(Equal(1))
+		put(1.1)
+		Expect(evaluate(a).Triggers).To(Equal(2))
+
+		// Verify output
+		Expect(log.notified()[0].Message).To(Equal("EURUSD crossed 1.1 at 1.0995"))
+	})
+
+	It("Triggers spread alerts on the spread in pips", func() {
+		a := alert(TypeSpreadAbove, 2)
+
+		put(1.1)
+		Expect(evaluate(a).Triggers).To(Equal(0))
+
+		q.Put(&provider.Tick{Pair: "EURUSD", Bid: 1.0998, Ask: 1.1002, Time: time.Now()})
+		Expect(evaluate(a).Triggers).To(Equal(1))
+
+		// Verify output
+		Expect(log.notified()[0].Value).To(Equal(4.0))
+	})
+
+	It("Skips triggers within the alert's cooldown", func() {
+		a := alert(TypePriceAbove, 1.1)
+		a.Repeat, a.Cooldown = true, 3600
+		store.Update(a)
+
+		put(1.101)
+		Expect(evaluate(a).Triggers).To(Equal(1))
+		put(1.099)
+		evaluate(a)
+		put(1.101)
+
+		// Verify output
+		Expect(evaluate(a).Triggers).To(Equal(1))
+	})
+
+	It("Triggers alerts on indices", func() {
+		a := alert(TypePriceAbove, 30)
+		a.Pair = "VIX"
+		store.Update(a)
+
+		q.Put(&provider.Tick{Pair: "VIX", Bid: 29.9, Ask: 29.95, Time: time.Now()})
+		Expect(evaluate(a).Triggers).To(Equal(0))
+
+		q.Put(&provider.Tick{Pair: "VIX", Bid: 30.45, Ask: 30.55, Time: time.Now()})
+		Expect(evaluate(a).Triggers).To(Equal(1))
+
+		// Verify output
+		Expect(log.notified()[0].Message).To(Equal("VIX rose above 30 at 30.5"))
+	})
+
+	It("Ignores stale quotes", func() {
+		a := alert(TypePriceAbove, 1.1)
+
+		q.Put(&provider.Tick{Pair: "EURUSD", Bid: 1.11, Ask: 1.1101, Time: time.Now().Add(-time.Hour)})
+
+		// Verify output
+		Expect(evaluate(a).Triggers).To(Equal(0))
+	})
+
+	It("Expires alerts past their expiry", func() {
+		a := alert(TypePriceAbove, 1.1)
+		expires := time.Now().Add(-time.Minute)
+		a.ExpiresAt = &expires
+		store.Update(a)
+
+		put(1.11)
+		a = evaluate(a)
+
+		// Verify output
+		Expect(a.Status).To(Equal(db.AlertExpired))
+		Expect(a.Triggers).To(Equal(0))
+		Expect(log.notified()).To(BeEmpty())
+	})
+
+	It("Triggers session alerts on the session's events since last evaluated", func() {
+		a := alert(TypeSessionOpen, 0)
+		a.Pair, a.Session = "", "london"
+		store.Update(a)
+
+		// London opens at 08:00 BST, or 07:00 UTC, on Wednesday 5 June 2024
+		open := time.Date(2024, time.June, 5, 7, 0, 0, 0, time.UTC)
+		e.Evaluate(open.Add(-time.Minute))
+		a, _ = store.Get(a.Owner, a.ID)
+		Expect(a.Triggers).To(Equal(0))
+
+		e.Evaluate(open.Add(time.Minute))
+		a, _ = store.Get(a.Owner, a.ID)
+
+		// Verify output
+		Expect(a.Triggers).To(Equal(1))
+		Expect(log.notified()[0].Message).To(Equal("London session opened"))
+	})
+
+	It("Records the errors of channels that failed to notify a trigger", func() {
+		log.err = errors.New("Disk full")
+		a := alert(TypePriceAbove, 1.1)
+		a.Channels = db.StringList{ChannelLog, ChannelWebhook}
+		store.Update(a)
+
+		put(1.11)
+		evaluate(a)
+
+		// Verify output
+		events, _ := store.ListEvents(db.AlertEventFilter{Owner: a.Owner, AlertID: a.ID, Limit: 10})
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Notified).To(BeEmpty())
+		Expect(events[0].Errors).To(Equal("log: Disk full; webhook: Unknown channel"))
+	})
+
+	It("Doesn't trigger alerts already triggered by another replica", func() {
+		a := alert(TypePriceAbove, 1.1)
+		stale := *a
+
+		// Another replica triggers the alert after it's listed
+		store.Trigger(&stale, time.Now())
+		Expect(e.trigger(a, 1.11, "", time.Now())).To(BeNil())
+	})
+})
@@ -0,0 +1,242 @@
+// alerts package contains the evaluation of price alerts
+// channels contains the channels notifying triggers: the log, webhooks, and email
+package alerts
+
+import (
+	// Standard lib
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/webhooks"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Channel names
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+
+	// Type of event sent in the event header of webhook notifications
+	EventAlertTriggered = "alert-triggered"
+
+	// Largest response body read from webhook receivers
+	maxResponseBody = 64 * 1024
+)
+
+var (
+	// All channel names
+	Channels = []string{ChannelLog, ChannelWebhook, ChannelEmail}
+
+	// Errors of channels that can't be used
+	ErrUnknownChannel = errors.New("Unknown channel, expected one of: " + strings.Join(Channels, ", "))
+	ErrEmailDisabled  = errors.New("Email notifications require `alerts.smtp.address` to be set")
+)
+
+type (
+	// Channel is an interface that all channels notifying triggers of alerts must fulfill
+	Channel interface {
+		// Name returns the name alerts refer to the channel by (ex: "log")
+		Name() string
+		// Notify sends a trigger of an alert
+		Notify(a *db.Alert, ev *db.AlertEvent) error
+	}
+	// Notification is a struct representing the payload of webhook notifications
+	Notification struct {
+		Alert *db.Alert      `json:"alert"`
+		Event *db.AlertEvent `json:"event"`
+	}
+	// LogChannel is a struct representing a channel logging triggers
+	LogChannel struct{}
+	// WebhookChannel is a struct representing a channel posting signed triggers to the webhook URL of alerts
+	WebhookChannel struct {
+		client *http.Client
+	}
+	// EmailChannel is a struct representing a channel emailing triggers to the email address of alerts, through
+	// the SMTP server at `alerts.smtp.address`
+	EmailChannel struct{}
+)
+
+// ValidChannel returns an error if alerts can't be notified through a channel
+func ValidChannel(name string) error {
+	switch name {
+	case ChannelLog, ChannelWebhook:
+		return nil
+	case ChannelEmail:
+		if config.GetInstance().Alerts.SMTP.Address == "" {
+			return ErrEmailDisabled
+		}
+		return nil
+	}
+
+	return ErrUnknownChannel
+}
+
+// NewLogChannel creates and returns a new instance of a channel logging triggers
+func NewLogChannel() *LogChannel { return &LogChannel{} }
+
+// Name returns the name of the channel
+func (c *LogChannel) Name() string { return ChannelLog }
+
+// Notify logs a trigger of an alert
+func (c *LogChannel) Notify(a *db.Alert, ev *db.AlertEvent) error {
+	log.WithFields(log.Fields{
+		"alert":   a.ID,
+		"name":    a.Name,
+		"trigger": ev.ID,
+		"value":   ev.Value,
+	}).Warn("Alert: " + ev.Message)
+
+	return nil
+}
+
+// NewWebhookChannel creates and returns a new instance of a channel posting triggers to webhooks
+// NOTE: Like webhook deliveries, notifications are never sent to private, loopback, or link-local addresses, so
+// alerts can't reach internal services
+func NewWebhookChannel() *WebhookChannel { return &WebhookChannel{client: webhooks.NewClient()} }
+
+// Name returns the name of the channel
+func (c *WebhookChannel) Name() string { return ChannelWebhook }
+
+// Notify posts a trigger of an alert to its webhook URL, signed with the alert's secret like webhook deliveries
+// NOTE: Responses other than 2xx are errors
+func (c *WebhookChannel) Notify(a *db.Alert, ev *db.AlertEvent) error {
+	payload, err := json.Marshal(&Notification{Alert: a, Event: ev})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout())
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, a.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "forex-clock-alerts")
+	req.Header.Set(webhooks.EventHeader, EventAlertTriggered)
+	req.Header.Set(webhooks.DeliveryHeader, ev.ID)
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(a.Secret, time.Now(), payload))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Read (a limited amount of) the body, so the connection can be reused
+	ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.New("Receiver responded with " + resp.Status)
+	}
+
+	return nil
+}
+
+// NewEmailChannel creates and returns a new instance of a channel emailing triggers
+func NewEmailChannel() *EmailChannel { return &EmailChannel{} }
+
+// Name returns the name of the channel
+func (c *EmailChannel) Name() string { return ChannelEmail }
+
+// Notify emails a trigger of an alert to its email address, upgrading the connection with STARTTLS when the server
+// supports it, and authenticating when a username is set
+func (c *EmailChannel) Notify(a *db.Alert, ev *db.AlertEvent) error {
+	sc := config.GetInstance().Alerts.SMTP
+	if sc.Address == "" {
+		return ErrEmailDisabled
+	}
+
+	host, _, err := net.SplitHostPort(sc.Address)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", sc.Address, timeout())
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(timeout()))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if sc.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", sc.Username, sc.Password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(sc.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(a.Email); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message(sc.From, a, ev)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// message returns the email message of a trigger of an alert
+func message(from string, a *db.Alert, ev *db.AlertEvent) []byte {
+	// NOTE: Line breaks are removed from header values, so they can't add headers
+	header := strings.NewReplacer("\r", " ", "\n", " ")
+	subject := ev.Message
+	if a.Name != "" {
+		subject = a.Name + ": " + ev.Message
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "From: %s\r\n", header.Replace(from))
+	fmt.Fprintf(b, "To: %s\r\n", header.Replace(a.Email))
+	fmt.Fprintf(b, "Subject: [forex-clock] %s\r\n", header.Replace(subject))
+	fmt.Fprintf(b, "Date: %s\r\n", ev.CreatedAt.Format(time.RFC1123Z))
+	fmt.Fprintf(b, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(b, "%s\r\n\r\n", ev.Message)
+	fmt.Fprintf(b, "Alert: %s (%s)\r\n", a.ID, a.Type)
+	fmt.Fprintf(b, "Triggered at: %s\r\n", ev.CreatedAt.Format(time.RFC3339))
+
+	return b.Bytes()
+}
+
+// timeout returns the time allowed for a notification to be sent
+func timeout() time.Duration { return time.Duration(config.GetInstance().Alerts.Timeout) * time.Second }
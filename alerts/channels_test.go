@@ -0,0 +1,198 @@
+// Tests the channels.go file
+package alerts
+
+import (
+	// Standard lib
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/webhooks"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// serveSMTP accepts a single connection on a listener, replying to a plain SMTP session and returning the messages
+// received through a channel
+func serveSMTP(l net.Listener) <-chan string {
+	messages := make(chan string, 1)
+	go func() {
+		defer close(messages)
+
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("220 localhost ESMTP\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+			case "EHLO", "HELO":
+				conn.Write([]byte("250 localhost\r\n"))
+			case "DATA":
+				conn.Write([]byte("354 Go ahead\r\n"))
+				data := ""
+				for !strings.HasSuffix(data, "\r\n.\r\n") {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					data += line
+				}
+				messages <- data
+				conn.Write([]byte("250 OK\r\n"))
+			case "QUIT":
+				conn.Write([]byte("221 Bye\r\n"))
+				return
+			default:
+				conn.Write([]byte("250 OK\r\n"))
+			}
+		}
+	}()
+
+	return messages
+}
+
+var _ = Describe("channels.go", func() {
+	var (
+		// Test alert and trigger
+		a  *db.Alert
+		ev *db.AlertEvent
+	)
+
+	BeforeEach(func() {
+		a = &db.Alert{
+			ID:        "a1",
+			Name:      "Euro\r\nBcc: everyone@example.com",
+			Type:      TypePriceAbove,
+			Pair:      "EURUSD",
+			Threshold: 1.1,
+			Channels:  db.StringList{ChannelWebhook},
+			Secret:    "whsec_a1",
+			Email:     "trader@example.com",
+			Status:    db.AlertActive,
+		}
+		ev = &db.AlertEvent{
+			ID:        "e1",
+			AlertID:   "a1",
+			Type:      TypePriceAbove,
+			Pair:      "EURUSD",
+			Threshold: 1.1,
+			Value:     1.1005,
+			Message:   "EURUSD rose above 1.1 at 1.1005",
+			CreatedAt: time.Date(2024, time.June, 5, 7, 0, 0, 0, time.UTC),
+		}
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Alerts.SMTP.Address = ""
+		config.GetInstance().Webhooks.PrivateAddresses = false
+	})
+
+	It("Validates channels", func() {
+		Expect(ValidChannel(ChannelLog)).To(Succeed())
+		Expect(ValidChannel(ChannelWebhook)).To(Succeed())
+		Expect(ValidChannel("sms")).To(Equal(ErrUnknownChannel))
+		Expect(ValidChannel(ChannelEmail)).To(Equal(ErrEmailDisabled))
+
+		config.GetInstance().Alerts.SMTP.Address = "localhost:25"
+		Expect(ValidChannel(ChannelEmail)).To(Succeed())
+	})
+
+	It("Posts signed triggers to the alert's webhook", func() {
+		var (
+			header, event string
+			payload       []byte
+		)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			header, event = req.Header.Get(webhooks.SignatureHeader), req.Header.Get(webhooks.EventHeader)
+			payload, _ = ioutil.ReadAll(req.Body)
+		}))
+		defer receiver.Close()
+
+		// NOTE: Test receivers listen on a loopback address
+		config.GetInstance().Webhooks.PrivateAddresses = true
+		a.WebhookURL = receiver.URL
+		Expect(NewWebhookChannel().Notify(a, ev)).To(Succeed())
+
+		// Verify output
+		Expect(event).To(Equal(EventAlertTriggered))
+		Expect(webhooks.Verify("whsec_a1", header, payload, time.Minute, time.Now())).To(Succeed())
+
+		n := &Notification{}
+		Expect(json.Unmarshal(payload, n)).To(Succeed())
+		Expect(n.Alert.ID).To(Equal("a1"))
+		Expect(n.Alert.Secret).To(BeEmpty())
+		Expect(n.Event.Value).To(Equal(1.1005))
+	})
+
+	It("Fails webhook notifications the receiver doesn't accept", func() {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer receiver.Close()
+
+		config.GetInstance().Webhooks.PrivateAddresses = true
+		a.WebhookURL = receiver.URL
+
+		// Verify output
+		Expect(NewWebhookChannel().Notify(a, ev)).To(MatchError("Receiver responded with 410 Gone"))
+	})
+
+	It("Refuses to post triggers to private addresses", func() {
+		received := false
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			received = true
+		}))
+		defer receiver.Close()
+
+		// NOTE: Hostnames are checked once resolved
+		for _, target := range []string{receiver.URL, strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)} {
+			a.WebhookURL = target
+			err := NewWebhookChannel().Notify(a, ev)
+
+			// Verify output
+			Expect(err).To(MatchError(ContainSubstring(webhooks.ErrPrivateAddress.Error())), target)
+		}
+		Expect(received).To(BeFalse())
+	})
+
+	It("Emails triggers through the SMTP server", func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+
+		messages := serveSMTP(l)
+		config.GetInstance().Alerts.SMTP.Address = l.Addr().String()
+		Expect(NewEmailChannel().Notify(a, ev)).To(Succeed())
+
+		// Verify output
+		var message string
+		Eventually(messages).Should(Receive(&message))
+		Expect(message).To(ContainSubstring("To: trader@example.com\r\n"))
+		Expect(message).To(ContainSubstring("Subject: [forex-clock] Euro  Bcc: everyone@example.com: EURUSD rose"))
+		Expect(message).NotTo(ContainSubstring("\r\nBcc:"))
+		Expect(message).To(ContainSubstring("Alert: a1 (price-above)"))
+	})
+
+	It("Fails email notifications without an SMTP server", func() {
+		Expect(NewEmailChannel().Notify(a, ev)).To(Equal(ErrEmailDisabled))
+	})
+})
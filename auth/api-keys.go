@@ -0,0 +1,92 @@
+// auth package contains authentication and authorization of API requests
+// api-keys contains authentication with API keys, which are stored as hashes
+package auth
+
+import (
+	// Standard lib
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+)
+
+const (
+	// Prefix of all generated API keys, making them easy to identify (ex: in secret scanners)
+	APIKeyPrefix = "fc_"
+
+	// Number of random bytes within a generated API key
+	apiKeyBytes = 32
+
+	// ID of the principal authenticated with the configured bootstrap admin key
+	BootstrapKeyID = "bootstrap"
+)
+
+type (
+	// APIKeyAuthenticator is a struct representing an authenticator of API keys, read from the
+	// `X-API-Key` header or a bearer token
+	APIKeyAuthenticator struct {
+		store db.APIKeyStore
+	}
+)
+
+// NewAPIKeyAuthenticator creates and returns a new instance of an API key authenticator
+func NewAPIKeyAuthenticator(store db.APIKeyStore) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{store: store}
+}
+
+// Authenticate returns the principal of the API key within a request
+func (a *APIKeyAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	key := req.Header.Get(APIKeyHeader)
+	if key == "" {
+		// NOTE: Bearer tokens that aren't API keys are left for other authenticators (ex: JWTs)
+		if token := BearerToken(req); strings.HasPrefix(token, APIKeyPrefix) {
+			key = token
+		}
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	// Check the bootstrap admin key, used to create the first stored keys
+	if admin := config.GetInstance().Auth.AdminKey; admin != "" && subtle.ConstantTimeCompare([]byte(key), []byte(admin)) == 1 {
+		return &Principal{ID: BootstrapKeyID, Type: PrincipalTypeAPIKey, Scopes: []string{ScopeAdmin}}, nil
+	}
+
+	k, err := a.store.GetByHash(HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if k == nil || k.Revoked() {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{ID: k.ID, Type: PrincipalTypeAPIKey, Scopes: k.Scopes}, nil
+}
+
+// GenerateAPIKey returns a new random API key and its hash
+// NOTE: Only the hash should be stored, the key itself is shown to the client once
+func GenerateAPIKey() (string, string, error) {
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hash of an API key, as stored within the database
+// NOTE: Keys are long and random, so a fast hash is sufficient
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
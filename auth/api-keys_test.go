@@ -0,0 +1,105 @@
+// Tests the api-keys.go file
+package auth
+
+import (
+	// Standard lib
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("api-keys.go", func() {
+	var (
+		// Key and authenticator to test
+		key string
+		a   *APIKeyAuthenticator
+		// Stored key
+		stored *db.APIKey
+	)
+
+	BeforeEach(func() {
+		var hash string
+		var err error
+		key, hash, err = GenerateAPIKey()
+		Expect(err).To(Not(HaveOccurred()))
+
+		stored = &db.APIKey{ID: "key-1", Name: "test", Hash: hash, Scopes: db.StringList{ScopeSessionsRead}, CreatedAt: time.Now()}
+		a = NewAPIKeyAuthenticator(newMemoryAPIKeyStore(stored))
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Auth.AdminKey = ""
+	})
+
+	Describe("`GenerateAPIKey` method", func() {
+		It("Returns a prefixed, unique key and its hash", func() {
+			other, _, _ := GenerateAPIKey()
+
+			Expect(strings.HasPrefix(key, APIKeyPrefix)).To(BeTrue())
+			Expect(key).To(Not(Equal(other)))
+			Expect(stored.Hash).To(Equal(HashAPIKey(key)))
+			Expect(stored.Hash).To(Not(ContainSubstring(key)))
+		})
+	})
+
+	Describe("`Authenticate` method", func() {
+		It("Returns `ErrNoCredentials` when the request has no API key", func() {
+			req := httptest.NewRequest("GET", "/sessions", nil)
+			_, err := a.Authenticate(req)
+			Expect(err).To(Equal(ErrNoCredentials))
+
+			// Bearer tokens that aren't API keys are ignored
+			req.Header.Set(AuthorizationHeader, "Bearer eyJhbGciOi")
+			_, err = a.Authenticate(req)
+			Expect(err).To(Equal(ErrNoCredentials))
+		})
+
+		It("Authenticates keys from the API key header and bearer tokens", func() {
+			req := httptest.NewRequest("GET", "/sessions", nil)
+			req.Header.Set(APIKeyHeader, key)
+			p, err := a.Authenticate(req)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(p.ID).To(Equal("key-1"))
+			Expect(p.HasScope(ScopeSessionsRead)).To(BeTrue())
+			Expect(p.HasScope(ScopeAdmin)).To(BeFalse())
+
+			req = httptest.NewRequest("GET", "/sessions", nil)
+			req.Header.Set(AuthorizationHeader, "Bearer "+key)
+			p, err = a.Authenticate(req)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(p.ID).To(Equal("key-1"))
+		})
+
+		It("Rejects unknown and revoked keys", func() {
+			req := httptest.NewRequest("GET", "/sessions", nil)
+			req.Header.Set(APIKeyHeader, APIKeyPrefix+"unknown")
+			_, err := a.Authenticate(req)
+			Expect(err).To(Equal(ErrInvalidCredentials))
+
+			now := time.Now()
+			stored.RevokedAt = &now
+			req.Header.Set(APIKeyHeader, key)
+			_, err = a.Authenticate(req)
+			Expect(err).To(Equal(ErrInvalidCredentials))
+		})
+
+		It("Authenticates the bootstrap admin key", func() {
+			config.GetInstance().Auth.AdminKey = "bootstrap-secret"
+
+			req := httptest.NewRequest("GET", "/admin/api-keys", nil)
+			req.Header.Set(APIKeyHeader, "bootstrap-secret")
+			p, err := a.Authenticate(req)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(p.ID).To(Equal(BootstrapKeyID))
+			Expect(p.HasScope(ScopeQuotesRead)).To(BeTrue())
+		})
+	})
+})
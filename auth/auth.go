@@ -0,0 +1,121 @@
+// auth package contains authentication and authorization of API requests. Requests are authenticated by
+// one of several authenticators (ex: API keys), resulting in a principal whose scopes authorize access to routes
+package auth
+
+import (
+	// Standard lib
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/helpers"
+)
+
+const (
+	// Scopes
+	ScopeSessionsRead = "sessions:read"
+	ScopeQuotesRead   = "quotes:read"
+	ScopeAlerts       = "alerts"
+	ScopeAdmin        = "admin"
+
+	// Principal types
+	PrincipalTypeAPIKey = "api-key"
+
+	// Headers credentials are read from
+	APIKeyHeader        = helpers.APIKeyHeader
+	AuthorizationHeader = helpers.AuthorizationHeader
+	BearerPrefix        = "Bearer "
+)
+
+type (
+	// Authenticator is an interface that all request authenticators must fulfill
+	Authenticator interface {
+		// Authenticate returns the principal making a request, `ErrNoCredentials` if the request doesn't
+		// contain credentials the authenticator handles, or another error if the credentials are invalid
+		Authenticate(req *http.Request) (*Principal, error)
+	}
+	// Principal is a struct representing an authenticated client
+	Principal struct {
+		ID     string   `json:"id"`     // Unique identifier of the client (ex: an API key ID)
+		Type   string   `json:"type"`   // How the client was authenticated (ex: "api-key")
+		Scopes []string `json:"scopes"` // Scopes granted to the client
+	}
+	// contextKey is a type used for request context keys, avoiding collisions with other packages
+	contextKey int
+)
+
+var (
+	// All known scopes
+	Scopes = []string{ScopeSessionsRead, ScopeQuotesRead, ScopeAlerts, ScopeAdmin}
+
+	// Common errors
+	ErrNoCredentials      = errors.New("No credentials provided")
+	ErrInvalidCredentials = errors.New("Invalid credentials")
+
+	// Context key of the request principal
+	principalKey contextKey
+)
+
+// HasScope returns a boolean indicating if a principal has been granted a scope
+// NOTE: The admin scope grants all scopes
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewContext returns a copy of a context containing a principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// FromContext returns the principal within a context, or nil if the request is anonymous
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+
+	return p
+}
+
+// InvalidCredentials returns a boolean indicating if an authentication error means a request's credentials are
+// invalid, rather than that they couldn't be checked (ex: as the database or key set is unavailable)
+func InvalidCredentials(err error) bool {
+	switch err {
+	case ErrInvalidCredentials, ErrMalformedToken, ErrInvalidAlgorithm, ErrInvalidSignature, ErrTokenExpired,
+		ErrInvalidIssuer, ErrInvalidAudience, ErrUnknownKey:
+		return true
+	}
+
+	return false
+}
+
+// ValidScope returns a boolean indicating if a scope is known
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasCredentials returns a boolean indicating if a request contains credentials of any kind
+func HasCredentials(req *http.Request) bool {
+	return req.Header.Get(APIKeyHeader) != "" || BearerToken(req) != ""
+}
+
+// BearerToken returns the bearer token within a request's `Authorization` header, if any
+func BearerToken(req *http.Request) string {
+	h := req.Header.Get(AuthorizationHeader)
+	if len(h) < len(BearerPrefix) || !strings.EqualFold(h[:len(BearerPrefix)], BearerPrefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(h[len(BearerPrefix):])
+}
@@ -0,0 +1,91 @@
+// Test suite setup for the auth package
+package auth
+
+import (
+	// Standard lib
+	"io/ioutil"
+	"testing"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	// Struct representing an in-memory API key store used during tests
+	memoryAPIKeyStore struct {
+		keys map[string]*db.APIKey
+	}
+)
+
+// newMemoryAPIKeyStore creates and returns a new in-memory API key store containing keys
+func newMemoryAPIKeyStore(keys ...*db.APIKey) *memoryAPIKeyStore {
+	s := &memoryAPIKeyStore{keys: map[string]*db.APIKey{}}
+	for _, k := range keys {
+		s.keys[k.ID] = k
+	}
+
+	return s
+}
+
+func (s *memoryAPIKeyStore) Create(k *db.APIKey) error { s.keys[k.ID] = k; return nil }
+
+func (s *memoryAPIKeyStore) Get(id string) (*db.APIKey, error) { return s.keys[id], nil }
+
+func (s *memoryAPIKeyStore) GetByHash(hash string) (*db.APIKey, error) {
+	for _, k := range s.keys {
+		if k.Hash == hash {
+			return k, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *memoryAPIKeyStore) List() ([]*db.APIKey, error) {
+	keys := []*db.APIKey{}
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+func (s *memoryAPIKeyStore) Rotate(id, prefix, hash string) error {
+	k, ok := s.keys[id]
+	if !ok {
+		return db.ErrNotFound
+	}
+	k.Prefix, k.Hash = prefix, hash
+
+	return nil
+}
+
+func (s *memoryAPIKeyStore) Revoke(id string) error {
+	k, ok := s.keys[id]
+	if !ok {
+		return db.ErrNotFound
+	}
+	t := k.CreatedAt
+	k.RevokedAt = &t
+
+	return nil
+}
+
+// Tests the auth package
+func TestAuth(t *testing.T) {
+	// Register gomega fail handler
+	RegisterFailHandler(Fail)
+
+	// Have go's testing package run package specs
+	RunSpecs(t, "Auth Suite")
+}
+
+func init() {
+	// Set logger output so as not to log during tests
+	log.SetOutput(ioutil.Discard)
+}
@@ -0,0 +1,252 @@
+// auth package contains authentication and authorization of API requests
+// jwks contains fetching and caching of JSON Web Key Sets, used to verify JWT signatures
+package auth
+
+import (
+	// Standard lib
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Minimum time between fetches, limiting fetches caused by unknown key IDs or an unavailable key set
+	jwksMinRefresh = 30 * time.Second
+
+	// The max time to wait for key set requests to complete
+	jwksTimeout = 5 * time.Second
+)
+
+type (
+	// JWKS is a struct representing a cached JSON Web Key Set, read from a URL or a local file
+	// Keys are fetched again once the cache expires, or when a token is signed with an unknown key
+	// (ex: after the provider rotates its keys)
+	JWKS struct {
+		url        string                      // URL of the key set
+		file       string                      // Path of the key set file, used when no URL is set
+		ttl        time.Duration               // How long to cache keys
+		minRefresh time.Duration               // Minimum time between fetches
+		client     *http.Client                // HTTP client used for requests
+		mutex      sync.RWMutex                // Mutex guarding the cached keys
+		keys       map[string]crypto.PublicKey // Cached keys, by key ID
+		fetched    time.Time                   // When the keys were last fetched
+		attempted  time.Time                   // When a fetch was last attempted
+		err        error                       // Error of the last fetch attempt, nil if it succeeded
+		fetching   chan struct{}               // Closed once the fetch in progress completes, nil if none is
+	}
+	// jwk is a struct representing the relevant parts of a single RSA or EC JSON Web Key
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+)
+
+var (
+	// Common errors
+	ErrUnknownKey = errors.New("Unknown signing key")
+)
+
+// NewJWKS creates and returns a new key set read from a URL, or a local file if no URL is set
+func NewJWKS(url, file string, ttl time.Duration) *JWKS {
+	return &JWKS{
+		url:        url,
+		file:       file,
+		ttl:        ttl,
+		minRefresh: jwksMinRefresh,
+		client:     &http.Client{Timeout: jwksTimeout},
+		keys:       map[string]crypto.PublicKey{},
+	}
+}
+
+// Key returns the public key with an ID, fetching the key set if the cache has expired or the key is unknown
+func (s *JWKS) Key(kid string) (crypto.PublicKey, error) {
+	s.mutex.RLock()
+	key, ok := s.keys[kid]
+	expired := time.Since(s.fetched) >= s.ttl
+	s.mutex.RUnlock()
+
+	if ok && !expired {
+		return key, nil
+	}
+
+	if err := s.refresh(); err != nil {
+		// Keep using cached keys if the key set is temporarily unavailable
+		log.WithError(err).Warn("Error fetching JSON Web Key Set")
+	}
+
+	s.mutex.RLock()
+	key, ok = s.keys[kid]
+	err := s.err
+	s.mutex.RUnlock()
+
+	// NOTE: Keys that can't be found as the key set is unavailable aren't unknown, the token may well be valid
+	if !ok && err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// refresh fetches the key set, replacing all cached keys
+// NOTE: Fetches are attempted at most once per minimum refresh interval, so unknown keys and an
+// unavailable key set don't cause a fetch for every request. The key set is fetched without holding the
+// mutex, so checks of cached keys aren't held up by a slow provider, and requests that need the keys while
+// they're being fetched wait for that fetch rather than starting another
+func (s *JWKS) refresh() error {
+	s.mutex.Lock()
+	if done := s.fetching; done != nil {
+		s.mutex.Unlock()
+		<-done
+		return nil
+	}
+	if !s.attempted.IsZero() && time.Since(s.attempted) < s.minRefresh {
+		s.mutex.Unlock()
+		return nil
+	}
+	done := make(chan struct{})
+	s.attempted, s.fetching = time.Now(), done
+	s.mutex.Unlock()
+
+	keys, err := s.fetch()
+
+	s.mutex.Lock()
+	if s.err = err; err == nil {
+		s.keys = keys
+		s.fetched = time.Now()
+	}
+	s.fetching = nil
+	s.mutex.Unlock()
+	close(done)
+
+	return err
+}
+
+// fetch reads and parses the key set
+func (s *JWKS) fetch() (map[string]crypto.PublicKey, error) {
+	b, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(b)
+}
+
+// read returns the raw key set from the URL or file
+func (s *JWKS) read() ([]byte, error) {
+	if s.url == "" {
+		return ioutil.ReadFile(s.file)
+	}
+
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected status code from key set: %d", resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// ParseJWKS parses a JSON Web Key Set, returning its RSA and EC signing keys by key ID
+// NOTE: Keys of other types or uses are skipped
+func ParseJWKS(b []byte) (map[string]crypto.PublicKey, error) {
+	set := struct {
+		Keys []*jwk `json:"keys"`
+	}{}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("Invalid key %q: %s", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+// publicKey returns the public key of a JSON Web Key, or nil if the key type isn't supported
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("Point is not on curve")
+		}
+
+		return key, nil
+	}
+
+	return nil, nil
+}
+
+// decodeInt decodes a base64url-encoded, big-endian unsigned integer
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("Empty value")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
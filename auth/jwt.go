@@ -0,0 +1,252 @@
+// auth package contains authentication and authorization of API requests
+// jwt contains authentication with RS256 / ES256 JWT bearer tokens issued by an OIDC provider
+package auth
+
+import (
+	// Standard lib
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+)
+
+const (
+	// Principal types
+	PrincipalTypeJWT = "jwt"
+
+	// Supported signing algorithms
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+type (
+	// JWTAuthenticator is a struct representing an authenticator of JWT bearer tokens, verified
+	// against a JSON Web Key Set
+	JWTAuthenticator struct {
+		keys       *JWKS             // Keys used to verify token signatures
+		issuer     string            // Required `iss` claim
+		audience   string            // Required `aud` claim
+		scopeClaim string            // Claim containing the client's scopes
+		scopeMap   map[string]string // Mapping of claim values to scopes
+		leeway     time.Duration     // Allowed clock skew when checking expiry
+	}
+	// jwtHeader is a struct representing the relevant parts of a JWT header
+	jwtHeader struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	// jwtClaims is a struct representing the registered claims checked for every JWT
+	jwtClaims struct {
+		Issuer    string      `json:"iss"`
+		Subject   string      `json:"sub"`
+		Audience  interface{} `json:"aud"` // A single string or an array of strings
+		ExpiresAt *int64      `json:"exp"`
+		NotBefore *int64      `json:"nbf"`
+	}
+)
+
+var (
+	// Common errors
+	ErrMalformedToken   = errors.New("Malformed token")
+	ErrInvalidAlgorithm = errors.New("Unsupported token signing algorithm")
+	ErrInvalidSignature = errors.New("Invalid token signature")
+	ErrTokenExpired     = errors.New("Token is expired or not yet valid")
+	ErrInvalidIssuer    = errors.New("Invalid token issuer")
+	ErrInvalidAudience  = errors.New("Invalid token audience")
+)
+
+// NewJWTAuthenticator creates and returns a new instance of a JWT authenticator from configuration settings
+func NewJWTAuthenticator(c config.AuthJWT) *JWTAuthenticator {
+	scopeMap := map[string]string{}
+	for _, m := range config.SplitList(c.ScopeMapping) {
+		if parts := strings.SplitN(m, "=", 2); len(parts) == 2 {
+			scopeMap[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	return &JWTAuthenticator{
+		keys:       NewJWKS(c.JWKSURL, c.JWKSFile, time.Duration(c.JWKSCacheTTL)*time.Second),
+		issuer:     c.Issuer,
+		audience:   c.Audience,
+		scopeClaim: c.ScopeClaim,
+		scopeMap:   scopeMap,
+		leeway:     time.Duration(c.Leeway) * time.Second,
+	}
+}
+
+// JWTEnabled returns a boolean indicating if JWT authentication is configured
+func JWTEnabled(c config.AuthJWT) bool { return c.JWKSURL != "" || c.JWKSFile != "" }
+
+// Authenticate returns the principal of the JWT bearer token within a request
+func (a *JWTAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	token := BearerToken(req)
+	if token == "" || strings.HasPrefix(token, APIKeyPrefix) {
+		return nil, ErrNoCredentials
+	}
+
+	payload, err := a.verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode registered claims for validation, and all claims to read scopes from
+	std := &jwtClaims{}
+	claims := map[string]interface{}{}
+	if decodeSegment(payload, std) != nil || decodeSegment(payload, &claims) != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := a.validate(std, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &Principal{ID: std.Subject, Type: PrincipalTypeJWT, Scopes: a.scopes(claims[a.scopeClaim])}, nil
+}
+
+// verify checks the signature of a token, returning its encoded payload
+func (a *JWTAuthenticator) verify(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrMalformedToken
+	}
+
+	header := &jwtHeader{}
+	if err := decodeSegment(parts[0], header); err != nil {
+		return "", ErrMalformedToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformedToken
+	}
+
+	key, err := a.keys.Key(header.Kid)
+	if err != nil {
+		return "", err
+	}
+
+	// NOTE: The key type must match the algorithm, so a token can't choose how its signature is checked
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(header.Alg, key, digest[:], sig); err != nil {
+		return "", err
+	}
+
+	return parts[1], nil
+}
+
+// validate checks the issuer, audience, and validity period of a token
+func (a *JWTAuthenticator) validate(c *jwtClaims, now time.Time) error {
+	// Expiry is required, tokens that never expire aren't accepted
+	if c.ExpiresAt == nil || now.After(time.Unix(*c.ExpiresAt, 0).Add(a.leeway)) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != nil && now.Add(a.leeway).Before(time.Unix(*c.NotBefore, 0)) {
+		return ErrTokenExpired
+	}
+
+	// NOTE: Tokens are never accepted without an issuer to check, as any provider could have signed them
+	if a.issuer == "" || c.Issuer != a.issuer {
+		return ErrInvalidIssuer
+	}
+
+	if a.audience != "" && !containsClaim(c.Audience, a.audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// scopes maps the values of a scope claim onto known scopes
+// NOTE: Unknown values are ignored, so a token never grants more than the known scopes
+func (a *JWTAuthenticator) scopes(claim interface{}) []string {
+	scopes := make([]string, 0)
+	add := func(v string) {
+		if mapped, ok := a.scopeMap[v]; ok {
+			v = mapped
+		}
+		if ValidScope(v) {
+			scopes = append(scopes, v)
+		}
+	}
+
+	switch v := claim.(type) {
+	case string:
+		for _, s := range strings.Fields(v) {
+			add(s)
+		}
+	case []interface{}:
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				add(str)
+			}
+		}
+	}
+
+	return scopes
+}
+
+// verifySignature checks a signature of a SHA-256 digest with a public key
+func verifySignature(alg string, key crypto.PublicKey, digest, sig []byte) error {
+	switch alg {
+	case AlgorithmRS256:
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidAlgorithm
+		}
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, sig) != nil {
+			return ErrInvalidSignature
+		}
+	case AlgorithmES256:
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrInvalidAlgorithm
+		}
+		// ES256 signatures are the 32-byte R and S values concatenated
+		if len(sig) != 64 {
+			return ErrInvalidSignature
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrInvalidAlgorithm
+	}
+
+	return nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON token segment
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// containsClaim returns a boolean indicating if a string or array claim contains a value
+func containsClaim(claim interface{}, value string) bool {
+	switch v := claim.(type) {
+	case string:
+		return v == value
+	case []interface{}:
+		for _, s := range v {
+			if s == value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,315 @@
+// Tests the jwt.go and jwks.go files
+package auth
+
+import (
+	// Standard lib
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing an in-process JSON Web Key Set server, standing in for an OIDC provider
+	jwksServer struct {
+		*httptest.Server
+		mutex    sync.Mutex
+		keys     []map[string]string
+		requests int
+	}
+)
+
+// newJWKSServer creates and starts a new key set server
+func newJWKSServer() *jwksServer {
+	s := &jwksServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		s.requests++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": s.keys})
+	}))
+
+	return s
+}
+
+// setKeys replaces the keys served, simulating key rotation
+func (s *jwksServer) setKeys(keys ...map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.keys = keys
+}
+
+// rsaJWK returns the JSON Web Key of an RSA public key
+func rsaJWK(kid string, k *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+	}
+}
+
+// ecJWK returns the JSON Web Key of a P-256 public key
+func ecJWK(kid string, k *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// signJWT returns a signed token with claims
+func signJWT(alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, _ = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, _ := ecdsa.Sign(rand.Reader, k, digest[:])
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+var _ = Describe("jwt.go", func() {
+	var (
+		// Signing keys
+		rsaKey *rsa.PrivateKey
+		ecKey  *ecdsa.PrivateKey
+		// Key set server and authenticator to test
+		server *jwksServer
+		a      *JWTAuthenticator
+		// Claims of a valid token
+		claims map[string]interface{}
+	)
+
+	// authenticate authenticates a request with a bearer token
+	authenticate := func(token string) (*Principal, error) {
+		req := httptest.NewRequest("GET", "/sessions", nil)
+		req.Header.Set(AuthorizationHeader, "Bearer "+token)
+
+		return a.Authenticate(req)
+	}
+
+	BeforeEach(func() {
+		rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+		ecKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		server = newJWKSServer()
+		server.setKeys(rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey))
+
+		a = NewJWTAuthenticator(config.AuthJWT{
+			JWKSURL:      server.URL,
+			JWKSCacheTTL: 3600,
+			Issuer:       "https://sso.example.com",
+			Audience:     "forex-clock",
+			ScopeClaim:   "scope",
+			ScopeMapping: "fx-admins=admin",
+			Leeway:       0,
+		})
+
+		claims = map[string]interface{}{
+			"iss":   "https://sso.example.com",
+			"sub":   "user-1",
+			"aud":   []string{"forex-clock", "other"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "sessions:read unknown:scope",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("`Authenticate` method", func() {
+		It("Ignores requests without a JWT bearer token", func() {
+			req := httptest.NewRequest("GET", "/sessions", nil)
+			_, err := a.Authenticate(req)
+			Expect(err).To(Equal(ErrNoCredentials))
+
+			_, err = authenticate(APIKeyPrefix + "abc")
+			Expect(err).To(Equal(ErrNoCredentials))
+		})
+
+		It("Authenticates RS256 and ES256 tokens, mapping claims onto scopes", func() {
+			p, err := authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, claims))
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(p.ID).To(Equal("user-1"))
+			Expect(p.Type).To(Equal(PrincipalTypeJWT))
+			Expect(p.Scopes).To(Equal([]string{ScopeSessionsRead}))
+
+			claims["scope"] = []string{"fx-admins"}
+			p, err = authenticate(signJWT(AlgorithmES256, "ec-1", ecKey, claims))
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(p.Scopes).To(Equal([]string{ScopeAdmin}))
+		})
+
+		It("Caches keys between requests", func() {
+			for i := 0; i < 3; i++ {
+				_, err := authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, claims))
+				Expect(err).To(Not(HaveOccurred()))
+			}
+
+			Expect(server.requests).To(Equal(1))
+		})
+
+		It("Fetches keys again when signed with an unknown key", func() {
+			_, err := authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, claims))
+			Expect(err).To(Not(HaveOccurred()))
+
+			// Rotate keys
+			rotated, _ := rsa.GenerateKey(rand.Reader, 2048)
+			server.setKeys(rsaJWK("rsa-2", &rotated.PublicKey))
+			a.keys.minRefresh = 0
+
+			_, err = authenticate(signJWT(AlgorithmRS256, "rsa-2", rotated, claims))
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(server.requests).To(Equal(2))
+
+			// Keys removed from the set are no longer accepted
+			_, err = authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, claims))
+			Expect(err).To(Equal(ErrUnknownKey))
+		})
+
+		It("Rejects tokens with invalid signatures or algorithms", func() {
+			other, _ := rsa.GenerateKey(rand.Reader, 2048)
+			_, err := authenticate(signJWT(AlgorithmRS256, "rsa-1", other, claims))
+			Expect(err).To(Equal(ErrInvalidSignature))
+
+			// An RSA key can't verify an ES256 token
+			_, err = authenticate(signJWT(AlgorithmES256, "rsa-1", ecKey, claims))
+			Expect(err).To(Equal(ErrInvalidAlgorithm))
+
+			token := signJWT(AlgorithmRS256, "rsa-1", rsaKey, claims)
+			_, err = authenticate(token[:len(token)-4])
+			Expect(err).To(HaveOccurred())
+
+			_, err = authenticate("not-a-token")
+			Expect(err).To(Equal(ErrMalformedToken))
+		})
+
+		It("Rejects expired tokens and tokens for other issuers or audiences", func() {
+			expired := map[string]interface{}{}
+			for k, v := range claims {
+				expired[k] = v
+			}
+			expired["exp"] = time.Now().Add(-time.Minute).Unix()
+			_, err := authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, expired))
+			Expect(err).To(Equal(ErrTokenExpired))
+
+			delete(expired, "exp")
+			_, err = authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, expired))
+			Expect(err).To(Equal(ErrTokenExpired))
+
+			claims["iss"] = "https://evil.example.com"
+			_, err = authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, claims))
+			Expect(err).To(Equal(ErrInvalidIssuer))
+
+			claims["iss"] = "https://sso.example.com"
+			claims["aud"] = "other"
+			_, err = authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, claims))
+			Expect(err).To(Equal(ErrInvalidAudience))
+		})
+
+		It("Rejects tokens when no issuer is configured", func() {
+			a.issuer = ""
+
+			for _, iss := range []interface{}{"https://sso.example.com", ""} {
+				claims["iss"] = iss
+				_, err := authenticate(signJWT(AlgorithmRS256, "rsa-1", rsaKey, claims))
+
+				// Verify output
+				Expect(err).To(Equal(ErrInvalidIssuer))
+			}
+		})
+	})
+
+	Describe("`JWKS` struct", func() {
+		It("Reads keys from a local file", func() {
+			dir, _ := ioutil.TempDir("", "jwks")
+			defer os.RemoveAll(dir)
+
+			b, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{ecJWK("ec-1", &ecKey.PublicKey)}})
+			path := filepath.Join(dir, "jwks.json")
+			ioutil.WriteFile(path, b, 0600)
+
+			key, err := NewJWKS("", path, time.Hour).Key("ec-1")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(key.(*ecdsa.PublicKey).X).To(Equal(ecKey.PublicKey.X))
+		})
+
+		It("Doesn't hold up checks of cached keys while fetching the key set", func() {
+			release := make(chan struct{})
+			slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				<-release
+				json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{}})
+			}))
+			defer slow.Close()
+			defer close(release)
+
+			s := NewJWKS(slow.URL, "", time.Hour)
+			s.keys, s.fetched = map[string]crypto.PublicKey{"ec-1": &ecKey.PublicKey}, time.Now()
+			go s.Key("unknown")
+			Eventually(func() bool {
+				s.mutex.RLock()
+				defer s.mutex.RUnlock()
+				return s.fetching != nil
+			}).Should(BeTrue())
+
+			// Call method
+			key, err := s.Key("ec-1")
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(key).To(Equal(&ecKey.PublicKey))
+		})
+
+		It("Doesn't report keys of an unavailable key set as unknown", func() {
+			s := NewJWKS("", filepath.Join(os.TempDir(), "missing-jwks.json"), time.Hour)
+
+			// Call method
+			_, err := s.Key("ec-1")
+
+			// Verify output
+			Expect(err).To(HaveOccurred())
+			Expect(err).NotTo(Equal(ErrUnknownKey))
+			Expect(InvalidCredentials(err)).To(BeFalse())
+		})
+
+		It("Skips unsupported keys", func() {
+			keys, err := ParseJWKS([]byte(`{"keys": [{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"}, {"kty": "RSA", "kid": "enc", "use": "enc"}]}`))
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(keys).To(BeEmpty())
+		})
+	})
+})
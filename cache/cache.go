@@ -0,0 +1,219 @@
+// cache package contains an in-process caching layer for provider and database reads, with time to live and least
+// recently used eviction. Concurrent reads of a missing value are coalesced into a single load, and stale values may
+// be served while they're refreshed. Values may instead be shared between replicas within a Redis-compatible server
+package cache
+
+import (
+	// Standard lib
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Supported backends
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+type (
+	// Backend is an interface that all cached value stores must fulfill
+	Backend interface {
+		// Get returns the entry of a key, nil if it doesn't exist or has expired
+		Get(key string, now time.Time) (*Entry, error)
+		// Set stores the entry of a key until it expires
+		Set(key string, e *Entry, now time.Time) error
+		// Delete removes the entry of a key
+		Delete(key string) error
+	}
+	// Entry is a struct representing a single cached value
+	Entry struct {
+		Value   []byte    // JSON-encoded value
+		Fresh   time.Time // When the value becomes stale and is refreshed
+		Expires time.Time // When the value may no longer be used, even while it's refreshed
+	}
+	// Policy is a struct representing how long values are cached
+	Policy struct {
+		TTL                  time.Duration // How long a value is fresh
+		StaleWhileRevalidate time.Duration // How long a stale value may be used while it's refreshed
+		SkipNil              bool          // Whether nil values are returned without being cached
+	}
+	// Loader is a function returning a value to cache, called when a value is missing or stale
+	Loader func() (interface{}, error)
+	// Stats is a struct representing the use of a cache
+	Stats struct {
+		Name       string `json:"name"`
+		Hits       int64  `json:"hits"`
+		StaleHits  int64  `json:"stale-hits"`
+		Misses     int64  `json:"misses"`
+		LoadErrors int64  `json:"load-errors"`
+		Entries    *int   `json:"entries,omitempty"`   // Number of stored entries, if known by the backend
+		Evictions  *int64 `json:"evictions,omitempty"` // Number of evicted entries, if known by the backend
+	}
+	// Cache is a struct representing a named set of cached values within a backend
+	Cache struct {
+		name       string
+		backend    Backend
+		group      group // Loads in progress
+		hits       int64
+		staleHits  int64
+		misses     int64
+		loadErrors int64
+	}
+	// Interface of backends that can report their size
+	sizer interface {
+		Len() int
+		Evictions() int64
+	}
+)
+
+var (
+	// Registry of created caches, by name
+	registry      = map[string]*Cache{}
+	registryMutex = &sync.Mutex{}
+)
+
+// NewBackend creates and returns the backend configured within configuration settings
+func NewBackend(c config.Cache) (Backend, error) {
+	switch c.Backend {
+	case BackendMemory:
+		return NewMemoryBackend(c.MaxEntries), nil
+	case BackendRedis:
+		return NewRedisBackend(c.Redis), nil
+	}
+
+	return nil, fmt.Errorf("Unknown cache backend: %s", c.Backend)
+}
+
+// New creates and returns a new instance of a named cache within a backend, replacing any cache of the same name
+// within the stats registry
+// NOTE: Keys are prefixed with the name, so caches may share a backend
+func New(name string, backend Backend) *Cache {
+	c := &Cache{name: name, backend: backend}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registry[name] = c
+
+	return c
+}
+
+// All returns every created cache, ordered by name
+func All() []*Cache {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	caches := make([]*Cache, 0, len(registry))
+	for _, c := range registry {
+		caches = append(caches, c)
+	}
+	sort.Slice(caches, func(a, b int) bool { return caches[a].name < caches[b].name })
+
+	return caches
+}
+
+// Name returns the name of the cache
+func (c *Cache) Name() string { return c.name }
+
+// Fetch decodes the value of a key into `dst`, loading and storing it when missing. Concurrent fetches of a missing
+// key share a single load, and stale values are returned while a single background load refreshes them
+// NOTE: Backend errors are logged and treated as misses, so an unavailable backend doesn't cause an outage
+func (c *Cache) Fetch(key string, p Policy, dst interface{}, load Loader) error {
+	if !config.GetInstance().Cache.Enabled || p.TTL <= 0 {
+		b, err := encode(load)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(b, dst)
+	}
+
+	key = c.name + ":" + key
+	now := time.Now()
+
+	e, err := c.backend.Get(key, now)
+	if err != nil {
+		log.WithError(err).WithField("cache", c.name).Error("Error reading cached value")
+	}
+
+	switch {
+	case e != nil && now.Before(e.Fresh):
+		atomic.AddInt64(&c.hits, 1)
+	case e != nil:
+		atomic.AddInt64(&c.staleHits, 1)
+		c.group.Go(key, func() ([]byte, error) { return c.load(key, p, load) })
+	default:
+		atomic.AddInt64(&c.misses, 1)
+		b, err := c.group.Do(key, func() ([]byte, error) { return c.load(key, p, load) })
+		if err != nil {
+			return err
+		}
+		e = &Entry{Value: b}
+	}
+
+	return json.Unmarshal(e.Value, dst)
+}
+
+// Delete removes the value of a key, so it's loaded again when next fetched
+func (c *Cache) Delete(key string) error { return c.backend.Delete(c.name + ":" + key) }
+
+// Stats returns the use of the cache since it was created
+func (c *Cache) Stats() *Stats {
+	s := &Stats{
+		Name:       c.name,
+		Hits:       atomic.LoadInt64(&c.hits),
+		StaleHits:  atomic.LoadInt64(&c.staleHits),
+		Misses:     atomic.LoadInt64(&c.misses),
+		LoadErrors: atomic.LoadInt64(&c.loadErrors),
+	}
+
+	// NOTE: Backend sizes include the entries of every cache sharing the backend
+	if b, ok := c.backend.(sizer); ok {
+		entries, evictions := b.Len(), b.Evictions()
+		s.Entries, s.Evictions = &entries, &evictions
+	}
+
+	return s
+}
+
+// load loads and encodes a value, storing it within the backend
+func (c *Cache) load(key string, p Policy, load Loader) ([]byte, error) {
+	b, err := encode(load)
+	if err != nil {
+		atomic.AddInt64(&c.loadErrors, 1)
+		return nil, err
+	}
+
+	// NOTE: Skipping nil values stops lookups of keys that don't exist from evicting values that do
+	if p.SkipNil && string(b) == "null" {
+		return b, nil
+	}
+
+	now := time.Now()
+	e := &Entry{Value: b, Fresh: now.Add(p.TTL), Expires: now.Add(p.TTL + p.StaleWhileRevalidate)}
+	if err := c.backend.Set(key, e, now); err != nil {
+		log.WithError(err).WithField("cache", c.name).Error("Error storing cached value")
+	}
+
+	return b, nil
+}
+
+// encode calls a loader, returning its JSON-encoded value
+// NOTE: Values are always encoded, so callers never share a cached value
+func encode(load Loader) ([]byte, error) {
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,27 @@
+// Test suite setup for the cache package
+package cache
+
+import (
+	// Standard lib
+	"io/ioutil"
+	"testing"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests the cache package
+func TestCache(t *testing.T) {
+	// Register gomega fail handler
+	RegisterFailHandler(Fail)
+
+	// Have go's testing package run package specs
+	RunSpecs(t, "Cache Suite")
+}
+
+func init() {
+	// Set logger output so as not to log during tests
+	log.SetOutput(ioutil.Discard)
+}
@@ -0,0 +1,308 @@
+// Tests the cache package
+package cache
+
+import (
+	// Standard lib
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	"github.com/alicebob/miniredis"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// backendBehavior describes the behavior every backend must have
+func backendBehavior(newBackend func() Backend) {
+	var (
+		// Backend to test
+		b Backend
+		// Start time of tests
+		now = time.Date(2020, time.March, 2, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		b = newBackend()
+	})
+
+	It("Stores entries until they're deleted", func() {
+		e := &Entry{Value: []byte(`{"a":1}`), Fresh: now.Add(time.Minute), Expires: now.Add(time.Hour)}
+		Expect(b.Set("key", e, now)).To(Succeed())
+
+		// Verify stored entry
+		stored, err := b.Get("key", now)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(stored.Value).To(Equal(e.Value))
+		Expect(stored.Fresh.Equal(e.Fresh)).To(BeTrue())
+
+		// Verify deleted entry
+		Expect(b.Delete("key")).To(Succeed())
+		stored, err = b.Get("key", now)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(stored).To(BeNil())
+	})
+
+	It("Returns nil for missing keys", func() {
+		e, err := b.Get("missing", now)
+
+		// Verify result
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(e).To(BeNil())
+	})
+}
+
+var _ = Describe("cache.go", func() {
+	var (
+		// Cache to test
+		c *Cache
+		// Backend of the cache
+		b *MemoryBackend
+		// Number of loader calls, a new counter for each spec
+		// NOTE: Loaders count calls with the counter of the spec that created them, so background refreshes
+		// finishing after a spec never count against the next one
+		loads *int64
+		// Loader counting its calls
+		loader = func(v interface{}) Loader {
+			counter := loads
+			return func() (interface{}, error) {
+				atomic.AddInt64(counter, 1)
+				return v, nil
+			}
+		}
+	)
+
+	BeforeEach(func() {
+		config.GetInstance().Cache.Enabled = true
+		b = NewMemoryBackend(100)
+		c = New("test", b)
+		loads = new(int64)
+	})
+
+	It("Loads missing values, then returns cached values", func() {
+		for i := 0; i < 3; i++ {
+			var v map[string]int
+			Expect(c.Fetch("key", Policy{TTL: time.Minute}, &v, loader(map[string]int{"a": 1}))).To(Succeed())
+
+			// Verify value
+			Expect(v).To(Equal(map[string]int{"a": 1}))
+		}
+
+		// Verify loads and stats
+		Expect(atomic.LoadInt64(loads)).To(Equal(int64(1)))
+		s := c.Stats()
+		Expect(s.Name).To(Equal("test"))
+		Expect(s.Misses).To(Equal(int64(1)))
+		Expect(s.Hits).To(Equal(int64(2)))
+		Expect(*s.Entries).To(Equal(1))
+	})
+
+	It("Coalesces concurrent loads of the same key", func() {
+		release := make(chan struct{})
+		counter := loads
+		slow := func() (interface{}, error) {
+			atomic.AddInt64(counter, 1)
+			<-release
+			return "value", nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				var v string
+				Expect(c.Fetch("key", Policy{TTL: time.Minute}, &v, slow)).To(Succeed())
+				Expect(v).To(Equal("value"))
+			}()
+		}
+
+		// Let every fetch start waiting, then finish the load
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		// Verify a single load was made
+		Expect(atomic.LoadInt64(loads)).To(Equal(int64(1)))
+	})
+
+	It("Returns stale values while refreshing them in the background", func() {
+		var v int
+		p := Policy{TTL: time.Millisecond, StaleWhileRevalidate: time.Minute}
+		Expect(c.Fetch("key", p, &v, loader(1))).To(Succeed())
+		time.Sleep(5 * time.Millisecond)
+
+		// Verify the stale value is returned
+		Expect(c.Fetch("key", p, &v, loader(2))).To(Succeed())
+		Expect(v).To(Equal(1))
+		Expect(c.Stats().StaleHits).To(Equal(int64(1)))
+
+		// Verify the value is refreshed
+		Eventually(func() int {
+			var refreshed int
+			c.Fetch("key", Policy{TTL: time.Minute}, &refreshed, loader(3))
+			return refreshed
+		}).Should(Equal(2))
+	})
+
+	It("Returns load errors without caching them", func() {
+		var v int
+		err := c.Fetch("key", Policy{TTL: time.Minute}, &v, func() (interface{}, error) {
+			return nil, errors.New("Upstream unavailable")
+		})
+
+		// Verify error and stats
+		Expect(err).To(MatchError("Upstream unavailable"))
+		Expect(c.Stats().LoadErrors).To(Equal(int64(1)))
+		Expect(b.Len()).To(BeZero())
+
+		// Verify the next fetch loads again
+		Expect(c.Fetch("key", Policy{TTL: time.Minute}, &v, loader(1))).To(Succeed())
+		Expect(v).To(Equal(1))
+	})
+
+	It("Skips caching nil values when the policy does", func() {
+		for i := 0; i < 2; i++ {
+			var v *int
+			Expect(c.Fetch("missing", Policy{TTL: time.Minute, SkipNil: true}, &v, loader(nil))).To(Succeed())
+
+			// Verify value
+			Expect(v).To(BeNil())
+		}
+
+		// Verify loads and stats
+		Expect(atomic.LoadInt64(loads)).To(Equal(int64(2)))
+		Expect(c.Stats().Misses).To(Equal(int64(2)))
+		Expect(b.Len()).To(BeZero())
+	})
+
+	It("Loads every value when disabled", func() {
+		config.GetInstance().Cache.Enabled = false
+		defer func() { config.GetInstance().Cache.Enabled = true }()
+
+		for i := 0; i < 3; i++ {
+			var v int
+			Expect(c.Fetch("key", Policy{TTL: time.Minute}, &v, loader(1))).To(Succeed())
+		}
+
+		// Verify loads
+		Expect(atomic.LoadInt64(loads)).To(Equal(int64(3)))
+		Expect(b.Len()).To(BeZero())
+	})
+
+	It("Deletes values, and lists caches by name", func() {
+		var v int
+		Expect(c.Fetch("key", Policy{TTL: time.Minute}, &v, loader(1))).To(Succeed())
+		Expect(c.Delete("key")).To(Succeed())
+		Expect(c.Fetch("key", Policy{TTL: time.Minute}, &v, loader(2))).To(Succeed())
+
+		// Verify reload
+		Expect(v).To(Equal(2))
+
+		// Verify registry
+		New("another", b)
+		names := []string{}
+		for _, cache := range All() {
+			names = append(names, cache.Name())
+		}
+		Expect(names).To(ContainElement("another"))
+		Expect(names).To(ContainElement("test"))
+	})
+})
+
+var _ = Describe("memory-backend.go", func() {
+	backendBehavior(func() Backend { return NewMemoryBackend(10) })
+
+	It("Evicts the least recently used entries", func() {
+		b := NewMemoryBackend(2)
+		now := time.Now()
+		e := &Entry{Value: []byte("1"), Fresh: now.Add(time.Minute), Expires: now.Add(time.Minute)}
+
+		b.Set("a", e, now)
+		b.Set("b", e, now)
+		b.Get("a", now)
+		b.Set("c", e, now)
+
+		// Verify "b" was evicted, as "a" was used more recently
+		Expect(b.Get("a", now)).ToNot(BeNil())
+		Expect(b.Get("b", now)).To(BeNil())
+		Expect(b.Get("c", now)).ToNot(BeNil())
+		Expect(b.Len()).To(Equal(2))
+		Expect(b.Evictions()).To(Equal(int64(1)))
+	})
+
+	It("Expires entries", func() {
+		b := NewMemoryBackend(2)
+		now := time.Now()
+		b.Set("a", &Entry{Value: []byte("1"), Fresh: now, Expires: now.Add(time.Second)}, now)
+
+		// Verify expiry
+		Expect(b.Get("a", now)).ToNot(BeNil())
+		Expect(b.Get("a", now.Add(time.Second))).To(BeNil())
+		Expect(b.Len()).To(BeZero())
+	})
+})
+
+var _ = Describe("redis-backend.go", func() {
+	var (
+		// In-process Redis-compatible server
+		m *miniredis.Miniredis
+	)
+
+	BeforeEach(func() {
+		var err error
+		m, err = miniredis.Run()
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	AfterEach(func() {
+		m.Close()
+	})
+
+	backendBehavior(func() Backend {
+		return NewRedisBackend(config.CacheRedis{Address: m.Addr(), Prefix: "test:"})
+	})
+
+	It("Prefixes and expires keys", func() {
+		b := NewRedisBackend(config.CacheRedis{Address: m.Addr(), Prefix: "test:"})
+		now := time.Now()
+		Expect(b.Set("key", &Entry{Value: []byte("1"), Fresh: now, Expires: now.Add(10 * time.Second)}, now)).To(Succeed())
+
+		// Verify key and expiry
+		Expect(m.Exists("test:key")).To(BeTrue())
+		Expect(m.TTL("test:key")).To(Equal(10 * time.Second))
+		m.FastForward(10 * time.Second)
+		Expect(m.Exists("test:key")).To(BeFalse())
+	})
+
+	It("Serves a cache shared between instances", func() {
+		b := NewRedisBackend(config.CacheRedis{Address: m.Addr(), Prefix: "test:"})
+		config.GetInstance().Cache.Enabled = true
+
+		var v string
+		Expect(New("first", b).Fetch("key", Policy{TTL: time.Minute}, &v, func() (interface{}, error) {
+			return "shared", nil
+		})).To(Succeed())
+
+		// Verify a second cache of the same name reads the value
+		Expect(New("first", NewRedisBackend(config.CacheRedis{Address: m.Addr(), Prefix: "test:"})).Fetch("key", Policy{TTL: time.Minute}, &v, func() (interface{}, error) {
+			return "not shared", nil
+		})).To(Succeed())
+		Expect(v).To(Equal("shared"))
+	})
+
+	It("Returns errors of unavailable servers", func() {
+		b := NewRedisBackend(config.CacheRedis{Address: m.Addr()})
+		m.Close()
+
+		// Verify error
+		_, err := b.Get("key", time.Now())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,110 @@
+// cache package contains an in-process caching layer for provider and database reads
+// memory-backend contains a backend keeping values within the process, evicting the least recently used
+package cache
+
+import (
+	// Standard lib
+	"container/list"
+	"sync"
+	"time"
+)
+
+type (
+	// MemoryBackend is a struct representing a size-bounded backend keeping values within the process
+	MemoryBackend struct {
+		mutex      sync.Mutex
+		maxEntries int                      // Number of entries kept before evicting
+		order      *list.List               // Entries, most recently used first
+		items      map[string]*list.Element // Elements of the order list, by key
+		evictions  int64                    // Number of entries evicted to make room
+	}
+	// Struct representing an entry within the order list
+	memoryItem struct {
+		key   string
+		entry *Entry
+	}
+)
+
+// NewMemoryBackend creates and returns a new instance of an in-memory backend holding at most a number of entries
+func NewMemoryBackend(maxEntries int) *MemoryBackend {
+	return &MemoryBackend{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+// Get returns the entry of a key, nil if it doesn't exist or has expired
+func (b *MemoryBackend) Get(key string, now time.Time) (*Entry, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	el, ok := b.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	item := el.Value.(*memoryItem)
+	if !now.Before(item.entry.Expires) {
+		b.remove(el)
+		return nil, nil
+	}
+	b.order.MoveToFront(el)
+
+	return item.entry, nil
+}
+
+// Set stores the entry of a key until it expires, evicting the least recently used entries if full
+func (b *MemoryBackend) Set(key string, e *Entry, now time.Time) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if el, ok := b.items[key]; ok {
+		el.Value.(*memoryItem).entry = e
+		b.order.MoveToFront(el)
+		return nil
+	}
+
+	b.items[key] = b.order.PushFront(&memoryItem{key: key, entry: e})
+	for b.order.Len() > b.maxEntries {
+		b.remove(b.order.Back())
+		b.evictions++
+	}
+
+	return nil
+}
+
+// Delete removes the entry of a key
+func (b *MemoryBackend) Delete(key string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if el, ok := b.items[key]; ok {
+		b.remove(el)
+	}
+
+	return nil
+}
+
+// Len returns the number of stored entries, including expired entries not yet removed
+func (b *MemoryBackend) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.order.Len()
+}
+
+// Evictions returns the number of entries evicted to make room for others
+func (b *MemoryBackend) Evictions() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.evictions
+}
+
+// remove removes an element from the order list and index
+// NOTE: Must be called while holding the mutex
+func (b *MemoryBackend) remove(el *list.Element) {
+	b.order.Remove(el)
+	delete(b.items, el.Value.(*memoryItem).key)
+}
@@ -0,0 +1,113 @@
+// cache package contains an in-process caching layer for provider and database reads
+// redis-backend contains a backend keeping values within a Redis-compatible server, sharing them between replicas
+package cache
+
+import (
+	// Standard lib
+	"bytes"
+	"errors"
+	"strconv"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// Connection pool settings
+	redisMaxIdle     = 10
+	redisIdleTimeout = 5 * time.Minute
+	redisTimeout     = 2 * time.Second
+)
+
+type (
+	// RedisBackend is a struct representing a backend keeping values within a Redis-compatible server
+	// NOTE: Entries are stored as their fresh time (in ms), a separator, and their value, expiring with the entry
+	RedisBackend struct {
+		pool   *redis.Pool
+		prefix string // Prefix of all keys
+	}
+)
+
+// NewRedisBackend creates and returns a new instance of a Redis-backed backend
+// NOTE: Connections are made when needed, so an unavailable server doesn't prevent start up
+func NewRedisBackend(c config.CacheRedis) *RedisBackend {
+	return &RedisBackend{
+		pool: &redis.Pool{
+			MaxIdle:     redisMaxIdle,
+			IdleTimeout: redisIdleTimeout,
+			Dial: func() (redis.Conn, error) {
+				return redis.Dial("tcp", c.Address,
+					redis.DialPassword(c.Password),
+					redis.DialDatabase(c.DB),
+					redis.DialConnectTimeout(redisTimeout),
+					redis.DialReadTimeout(redisTimeout),
+					redis.DialWriteTimeout(redisTimeout),
+				)
+			},
+		},
+		prefix: c.Prefix,
+	}
+}
+
+// Get returns the entry of a key, nil if it doesn't exist or has expired
+func (b *RedisBackend) Get(key string, now time.Time) (*Entry, error) {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	value, err := redis.Bytes(conn.Do("GET", b.prefix+key))
+	if err == redis.ErrNil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	i := bytes.IndexByte(value, '|')
+	if i < 0 {
+		return nil, errors.New("Malformed cache entry: " + key)
+	}
+
+	fresh, err := strconv.ParseInt(string(value[:i]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: The server removes expired entries, so the expiry time isn't needed
+	return &Entry{Value: value[i+1:], Fresh: time.Unix(0, fresh*int64(time.Millisecond))}, nil
+}
+
+// Set stores the entry of a key until it expires
+func (b *RedisBackend) Set(key string, e *Entry, now time.Time) error {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	// NOTE: Expiry is relative to `now`, so entries don't depend on the server's clock
+	ttl := millis(e.Expires) - millis(now)
+	if ttl < 1 {
+		return nil
+	}
+
+	value := append([]byte(strconv.FormatInt(millis(e.Fresh), 10)+"|"), e.Value...)
+	_, err := conn.Do("SET", b.prefix+key, value, "PX", ttl)
+
+	return err
+}
+
+// Delete removes the entry of a key
+func (b *RedisBackend) Delete(key string) error {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", b.prefix+key)
+
+	return err
+}
+
+// Close closes all connections to the server
+func (b *RedisBackend) Close() error { return b.pool.Close() }
+
+// millis returns a time as milliseconds since the Unix epoch
+func millis(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
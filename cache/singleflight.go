@@ -0,0 +1,73 @@
+// cache package contains an in-process caching layer for provider and database reads
+// singleflight contains coalescing of concurrent loads of the same key into a single call
+package cache
+
+import (
+	// Standard lib
+	"sync"
+)
+
+type (
+	// Struct representing a load in progress or completed
+	call struct {
+		wg    sync.WaitGroup
+		value []byte
+		err   error
+	}
+	// Struct representing a group of loads, at most one in progress per key
+	group struct {
+		mutex sync.Mutex
+		calls map[string]*call
+	}
+)
+
+// Do calls a function, unless a call for the same key is in progress, in which case its result is waited for
+func (g *group) Do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	c, started := g.start(key)
+	if started {
+		g.run(key, c, fn)
+	} else {
+		c.wg.Wait()
+	}
+
+	return c.value, c.err
+}
+
+// Go calls a function in the background, unless a call for the same key is in progress
+func (g *group) Go(key string, fn func() ([]byte, error)) {
+	if c, started := g.start(key); started {
+		go g.run(key, c, fn)
+	}
+}
+
+// start returns the call in progress for a key, starting one if there isn't one
+func (g *group) start(key string) (*call, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	if c, ok := g.calls[key]; ok {
+		return c, false
+	}
+
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+
+	return c, true
+}
+
+// run calls a function, recording its result and ending the call
+func (g *group) run(key string, c *call, fn func() ([]byte, error)) {
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+
+		c.wg.Done()
+	}()
+
+	c.value, c.err = fn()
+}
@@ -0,0 +1,188 @@
+// candles package contains the persistence of ingested ticks and their aggregation into OHLC candles
+// aggregator contains the worker building candles from saved ticks
+package candles
+
+import (
+	// Standard lib
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// How often ticks older than the retention period are deleted
+	purgeInterval = time.Hour
+)
+
+type (
+	// Aggregator is a struct representing the building of candles from ticks saved since they were last built
+	Aggregator struct {
+		ticks     db.TickStore
+		candles   db.CandleStore
+		mutex     sync.Mutex
+		dirty     map[string]*span // Range of ticks saved since candles were last built, by pair
+		lastPurge time.Time
+		stop      chan struct{} // Closed to stop the worker, nil when not running
+		done      chan struct{} // Closed once the worker stops
+	}
+	// Struct representing a range of time, inclusive of both ends
+	span struct {
+		from time.Time
+		to   time.Time
+	}
+)
+
+// NewAggregator creates and returns a new instance of an aggregator building candles from saved ticks
+func NewAggregator(ticks db.TickStore, candles db.CandleStore) *Aggregator {
+	return &Aggregator{ticks: ticks, candles: candles, dirty: map[string]*span{}}
+}
+
+// Start starts building candles periodically
+func (a *Aggregator) Start() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stop != nil {
+		return
+	}
+
+	a.stop, a.done = make(chan struct{}), make(chan struct{})
+	go a.run(a.stop, a.done)
+}
+
+// Close stops the worker, building candles of ticks saved since they were last built
+// NOTE: Must be called after the recorder is closed, so its last batch is included
+func (a *Aggregator) Close() {
+	a.mutex.Lock()
+	stop, done := a.stop, a.done
+	a.stop = nil
+	a.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+
+	a.Aggregate()
+}
+
+// Mark records that ticks of a pair quoted within a range have been saved, so their candles are built
+func (a *Aggregator) Mark(pair string, from, to time.Time) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	s, ok := a.dirty[pair]
+	if !ok {
+		a.dirty[pair] = &span{from: from, to: to}
+		return
+	}
+	if from.Before(s.from) {
+		s.from = from
+	}
+	if to.After(s.to) {
+		s.to = to
+	}
+}
+
+// Aggregate builds candles of every interval including ticks saved since candles were last built
+// NOTE: Ranges that fail are marked again, so they're retried the next time candles are built
+func (a *Aggregator) Aggregate() {
+	a.mutex.Lock()
+	dirty := a.dirty
+	a.dirty = map[string]*span{}
+	a.mutex.Unlock()
+
+	for pair, s := range dirty {
+		if err := a.aggregate(pair, s); err != nil {
+			log.WithError(err).WithField("pair", pair).Error("Error building candles")
+			a.Mark(pair, s.from, s.to)
+		}
+	}
+}
+
+// Purge deletes ticks older than the retention period, if any
+func (a *Aggregator) Purge(now time.Time) {
+	days := config.GetInstance().Candles.TickRetention
+	if days == 0 {
+		return
+	}
+
+	n, err := a.ticks.DeleteTicks(now.AddDate(0, 0, -days))
+	if err != nil {
+		log.WithError(err).Error("Error deleting expired ticks")
+		return
+	}
+	if n > 0 {
+		log.WithField("ticks", n).Info("Deleted expired ticks")
+	}
+}
+
+// aggregate builds candles of every interval including a range of a pair's ticks
+func (a *Aggregator) aggregate(pair string, s *span) error {
+	// Rebuild the shortest candles including the range from ticks
+	shortest := Intervals[0]
+	from, _ := shortest.Bucket(s.from)
+	_, to := shortest.Bucket(s.to)
+
+	ticks, err := a.ticks.ListTicks(pair, from, to)
+	if err != nil {
+		return err
+	}
+	if err := a.save(FromTicks(shortest, ticks)); err != nil {
+		return err
+	}
+
+	// Rebuild longer candles including the range from the shortest candles
+	for _, iv := range Intervals[1:] {
+		from, _ := iv.Bucket(s.from)
+		_, to := iv.Bucket(s.to)
+
+		list, err := a.candles.List(db.CandleFilter{Pair: pair, Interval: shortest.Name, From: from, To: to})
+		if err != nil {
+			return err
+		}
+		if err := a.save(Merge(iv, list)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// save stores candles, stamping when they were built
+func (a *Aggregator) save(candles []*db.Candle) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, c := range candles {
+		c.UpdatedAt = now
+	}
+
+	return a.candles.Save(candles)
+}
+
+// run builds candles and deletes expired ticks periodically, until stopped
+func (a *Aggregator) run(stop, done chan struct{}) {
+	defer close(done)
+
+	t := time.NewTicker(time.Duration(config.GetInstance().Candles.AggregateInterval) * time.Second)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-t.C:
+			a.Aggregate()
+			if now.Sub(a.lastPurge) >= purgeInterval {
+				a.Purge(now)
+				a.lastPurge = now
+			}
+		}
+	}
+}
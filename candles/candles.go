@@ -0,0 +1,150 @@
+// candles package contains the persistence of ingested ticks and their aggregation into OHLC candles. A recorder
+// saves ticks in batches, and an aggregator builds candles of every interval from the ticks saved. Intervals are
+// aligned to the FX trading day, which closes at 17:00 New York time rather than midnight UTC
+package candles
+
+import (
+	// Standard lib
+	"sort"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/tz"
+)
+
+const (
+	// Time zone and hour of the daily FX close
+	DayCloseTimeZone = "America/New_York"
+	DayCloseHour     = 17
+)
+
+type (
+	// Interval is a struct representing the period of time candles span
+	Interval struct {
+		Name     string        // Name of the interval (ex: "1h")
+		Duration time.Duration // Length of the interval, a whole trading day for daily intervals
+	}
+)
+
+var (
+	// Intervals candles are aggregated at, from shortest to longest
+	// NOTE: The shortest interval is built from ticks, and every other interval from it
+	Intervals = []*Interval{
+		{Name: "1m", Duration: time.Minute},
+		{Name: "5m", Duration: 5 * time.Minute},
+		{Name: "15m", Duration: 15 * time.Minute},
+		{Name: "1h", Duration: time.Hour},
+		{Name: "4h", Duration: 4 * time.Hour},
+		{Name: "1d", Duration: 24 * time.Hour},
+	}
+
+	// Time zone of the daily FX close
+	dayCloseLocation = mustLoad(DayCloseTimeZone)
+)
+
+// LookupInterval returns an interval by name, or nil if it doesn't exist
+func LookupInterval(name string) *Interval {
+	for _, iv := range Intervals {
+		if iv.Name == name {
+			return iv
+		}
+	}
+
+	return nil
+}
+
+// DayStart returns the start of the FX trading day including a point in time, which is the most recent daily close
+func DayStart(t time.Time) time.Time {
+	local := t.In(dayCloseLocation)
+	start := time.Date(local.Year(), local.Month(), local.Day(), DayCloseHour, 0, 0, 0, dayCloseLocation)
+	if start.After(t) {
+		start = time.Date(local.Year(), local.Month(), local.Day()-1, DayCloseHour, 0, 0, 0, dayCloseLocation)
+	}
+
+	return start.UTC()
+}
+
+// Bucket returns the start and end (exclusive) of the interval including a point in time
+// NOTE: Intervals are counted from the start of the trading day, so the last interval of a day shortened or
+// lengthened by a clock change ends at the daily close
+func (iv *Interval) Bucket(t time.Time) (time.Time, time.Time) {
+	day := DayStart(t)
+	next := DayStart(day.Add(36 * time.Hour))
+	if iv.Duration >= 24*time.Hour {
+		return day, next
+	}
+
+	start := day.Add(t.Sub(day) / iv.Duration * iv.Duration)
+	end := start.Add(iv.Duration)
+	if end.After(next) {
+		end = next
+	}
+
+	return start, end
+}
+
+// FromTicks builds candles of an interval from the mid prices of a pair's ticks
+func FromTicks(iv *Interval, ticks []*db.Tick) []*db.Candle {
+	sorted := append([]*db.Tick{}, ticks...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].QuotedAt.Before(sorted[b].QuotedAt) })
+
+	candles := []*db.Candle{}
+	var c *db.Candle
+	for _, t := range sorted {
+		mid := (t.Bid + t.Ask) / 2
+		if c == nil || !t.QuotedAt.Before(c.CloseTime) {
+			start, end := iv.Bucket(t.QuotedAt)
+			c = &db.Candle{Pair: t.Pair, Interval: iv.Name, OpenTime: start, CloseTime: end, Open: mid, High: mid, Low: mid}
+			candles = append(candles, c)
+		}
+
+		if mid > c.High {
+			c.High = mid
+		}
+		if mid < c.Low {
+			c.Low = mid
+		}
+		c.Close = mid
+		c.Ticks++
+	}
+
+	return candles
+}
+
+// Merge builds candles of an interval from a pair's candles of a shorter interval
+func Merge(iv *Interval, candles []*db.Candle) []*db.Candle {
+	sorted := append([]*db.Candle{}, candles...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].OpenTime.Before(sorted[b].OpenTime) })
+
+	merged := []*db.Candle{}
+	var m *db.Candle
+	for _, c := range sorted {
+		if m == nil || !c.OpenTime.Before(m.CloseTime) {
+			start, end := iv.Bucket(c.OpenTime)
+			m = &db.Candle{Pair: c.Pair, Interval: iv.Name, OpenTime: start, CloseTime: end, Open: c.Open, High: c.High, Low: c.Low}
+			merged = append(merged, m)
+		}
+
+		if c.High > m.High {
+			m.High = c.High
+		}
+		if c.Low < m.Low {
+			m.Low = c.Low
+		}
+		m.Close = c.Close
+		m.Ticks += c.Ticks
+	}
+
+	return merged
+}
+
+// mustLoad loads a time zone from the embedded database, which includes every zone used
+func mustLoad(name string) *time.Location {
+	loc, err := tz.Load(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return loc
+}
@@ -0,0 +1,27 @@
+// Test suite setup for the candles package
+package candles
+
+import (
+	// Standard lib
+	"io/ioutil"
+	"testing"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests the candles package
+func TestCandles(t *testing.T) {
+	// Register gomega fail handler
+	RegisterFailHandler(Fail)
+
+	// Have go's testing package run package specs
+	RunSpecs(t, "Candles Suite")
+}
+
+func init() {
+	// Set logger output so as not to log during tests
+	log.SetOutput(ioutil.Discard)
+}
@@ -0,0 +1,346 @@
+// Tests the candles.go, aggregator.go, and recorder.go files
+package candles
+
+import (
+	// Standard lib
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/quotes"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing in-memory tick storage, used throughout tests
+	memoryTickStore struct {
+		mutex sync.Mutex
+		ticks []*db.Tick
+		err   error // Error returned by every method, if set
+	}
+	// Struct representing in-memory candle storage, used throughout tests
+	memoryCandleStore struct {
+		mutex   sync.Mutex
+		candles map[string]*db.Candle
+	}
+)
+
+func (s *memoryTickStore) SaveTicks(ticks []*db.Tick) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.ticks = append(s.ticks, ticks...)
+	return nil
+}
+
+func (s *memoryTickStore) ListTicks(pair string, from, to time.Time) ([]*db.Tick, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := []*db.Tick{}
+	for _, t := range s.ticks {
+		if t.Pair == pair && !t.QuotedAt.Before(from) && t.QuotedAt.Before(to) {
+			list = append(list, t)
+		}
+	}
+	return list, s.err
+}
+
+func (s *memoryTickStore) DeleteTicks(before time.Time) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	kept := []*db.Tick{}
+	for _, t := range s.ticks {
+		if !t.QuotedAt.Before(before) {
+			kept = append(kept, t)
+		}
+	}
+	n := len(s.ticks) - len(kept)
+	s.ticks = kept
+	return int64(n), s.err
+}
+
+func (s *memoryCandleStore) Save(candles []*db.Candle) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, c := range candles {
+		s.candles[c.Pair+c.Interval+c.OpenTime.String()] = c
+	}
+	return nil
+}
+
+func (s *memoryCandleStore) List(f db.CandleFilter) ([]*db.Candle, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := []*db.Candle{}
+	for _, c := range s.candles {
+		if c.Pair == f.Pair && c.Interval == f.Interval && !c.OpenTime.Before(f.From) && (f.To.IsZero() || c.OpenTime.Before(f.To)) {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].OpenTime.Before(list[b].OpenTime) })
+	return list, nil
+}
+
+// tick returns a tick of a pair with a mid price, quoted at a point in time
+func tick(pair string, mid float64, t time.Time) *db.Tick {
+	return &db.Tick{Pair: pair, QuotedAt: t, Bid: mid - 0.00005, Ask: mid + 0.00005}
+}
+
+var _ = Describe("candles.go", func() {
+	It("Looks up intervals by name", func() {
+		Expect(LookupInterval("4h").Duration).To(Equal(4 * time.Hour))
+		Expect(LookupInterval("2h")).To(BeNil())
+	})
+
+	It("Starts trading days at the 17:00 New York close", func() {
+		// EST (UTC-5)
+		Expect(DayStart(time.Date(2024, 1, 10, 21, 59, 0, 0, time.UTC))).To(Equal(time.Date(2024, 1, 9, 22, 0, 0, 0, time.UTC)))
+		Expect(DayStart(time.Date(2024, 1, 10, 22, 0, 0, 0, time.UTC))).To(Equal(time.Date(2024, 1, 10, 22, 0, 0, 0, time.UTC)))
+
+		// EDT (UTC-4)
+		Expect(DayStart(time.Date(2024, 7, 10, 21, 30, 0, 0, time.UTC))).To(Equal(time.Date(2024, 7, 10, 21, 0, 0, 0, time.UTC)))
+	})
+
+	It("Aligns intervals to the start of the trading day", func() {
+		t := time.Date(2024, 1, 10, 3, 17, 42, 0, time.UTC)
+
+		start, end := LookupInterval("1m").Bucket(t)
+		Expect(start).To(Equal(time.Date(2024, 1, 10, 3, 17, 0, 0, time.UTC)))
+		Expect(end).To(Equal(time.Date(2024, 1, 10, 3, 18, 0, 0, time.UTC)))
+
+		// NOTE: Four-hour intervals start at 22:00 UTC, rather than midnight, during EST
+		start, end = LookupInterval("4h").Bucket(t)
+		Expect(start).To(Equal(time.Date(2024, 1, 10, 2, 0, 0, 0, time.UTC)))
+		Expect(end).To(Equal(time.Date(2024, 1, 10, 6, 0, 0, 0, time.UTC)))
+
+		start, end = LookupInterval("1d").Bucket(t)
+		Expect(start).To(Equal(time.Date(2024, 1, 9, 22, 0, 0, 0, time.UTC)))
+		Expect(end).To(Equal(time.Date(2024, 1, 10, 22, 0, 0, 0, time.UTC)))
+	})
+
+	It("Shortens the last interval of trading days shortened by a clock change", func() {
+		// NOTE: Clocks go forward on Mar 10 2024, so the trading day starting Mar 9 17:00 EST lasts 23 hours
+		t := time.Date(2024, 3, 10, 19, 30, 0, 0, time.UTC)
+
+		start, end := LookupInterval("4h").Bucket(t)
+		Expect(start).To(Equal(time.Date(2024, 3, 10, 18, 0, 0, 0, time.UTC)))
+		Expect(end).To(Equal(time.Date(2024, 3, 10, 21, 0, 0, 0, time.UTC)))
+
+		start, end = LookupInterval("1d").Bucket(t)
+		Expect(end.Sub(start)).To(Equal(23 * time.Hour))
+	})
+
+	It("Builds candles from the mid prices of ticks", func() {
+		base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+		candles := FromTicks(LookupInterval("1m"), []*db.Tick{
+			tick("EURUSD", 1.1002, base.Add(10*time.Second)),
+			tick("EURUSD", 1.1000, base.Add(5*time.Second)),
+			tick("EURUSD", 1.1005, base.Add(30*time.Second)),
+			tick("EURUSD", 1.0998, base.Add(50*time.Second)),
+			tick("EURUSD", 1.1001, base.Add(70*time.Second)),
+		})
+
+		// Verify output
+		Expect(candles).To(HaveLen(2))
+		c := candles[0]
+		Expect(c.Interval).To(Equal("1m"))
+		Expect(c.OpenTime).To(Equal(base))
+		Expect(c.CloseTime).To(Equal(base.Add(time.Minute)))
+		Expect(c.Open).To(BeNumerically("~", 1.1000, 1e-9))
+		Expect(c.High).To(BeNumerically("~", 1.1005, 1e-9))
+		Expect(c.Low).To(BeNumerically("~", 1.0998, 1e-9))
+		Expect(c.Close).To(BeNumerically("~", 1.0998, 1e-9))
+		Expect(c.Ticks).To(Equal(4))
+		Expect(candles[1].Ticks).To(Equal(1))
+
+		Expect(FromTicks(LookupInterval("1m"), nil)).To(BeEmpty())
+	})
+
+	It("Merges candles into longer intervals", func() {
+		base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+		iv := LookupInterval("1m")
+		minutes := []*db.Candle{}
+		for i, p := range [][4]float64{{1.1, 1.2, 1.0, 1.15}, {1.15, 1.3, 1.1, 1.2}, {1.2, 1.25, 0.9, 1.0}} {
+			start, end := iv.Bucket(base.Add(time.Duration(i) * 4 * time.Minute))
+			minutes = append(minutes, &db.Candle{Pair: "EURUSD", Interval: "1m", OpenTime: start, CloseTime: end, Open: p[0], High: p[1], Low: p[2], Close: p[3], Ticks: 2})
+		}
+
+		merged := Merge(LookupInterval("5m"), minutes)
+
+		// Verify output
+		Expect(merged).To(HaveLen(2))
+		Expect(merged[0].Ticks).To(Equal(4))
+		Expect(merged[1].OpenTime).To(Equal(base.Add(5 * time.Minute)))
+
+		merged = Merge(LookupInterval("15m"), minutes)
+		Expect(merged).To(HaveLen(1))
+		Expect(*merged[0]).To(Equal(db.Candle{
+			Pair: "EURUSD", Interval: "15m", OpenTime: base, CloseTime: base.Add(15 * time.Minute),
+			Open: 1.1, High: 1.3, Low: 0.9, Close: 1.0, Ticks: 6,
+		}))
+	})
+})
+
+var _ = Describe("aggregator.go", func() {
+	var (
+		ticks   *memoryTickStore
+		candles *memoryCandleStore
+		a       *Aggregator
+		base    time.Time
+	)
+
+	BeforeEach(func() {
+		ticks = &memoryTickStore{}
+		candles = &memoryCandleStore{candles: map[string]*db.Candle{}}
+		a = NewAggregator(ticks, candles)
+		base = time.Date(2024, 1, 10, 21, 58, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Candles.TickRetention = 7
+	})
+
+	It("Builds candles of every interval from marked ticks", func() {
+		ticks.SaveTicks([]*db.Tick{
+			tick("EURUSD", 1.1, base),
+			tick("EURUSD", 1.2, base.Add(90*time.Second)),
+			tick("EURUSD", 1.3, base.Add(3*time.Minute)),
+		})
+		a.Mark("EURUSD", base, base.Add(3*time.Minute))
+		a.Aggregate()
+
+		// Verify output
+		list, _ := candles.List(db.CandleFilter{Pair: "EURUSD", Interval: "1m"})
+		Expect(list).To(HaveLen(3))
+
+		// NOTE: The last tick falls within the next trading day
+		list, _ = candles.List(db.CandleFilter{Pair: "EURUSD", Interval: "1d"})
+		Expect(list).To(HaveLen(2))
+		Expect(list[0].Close).To(BeNumerically("~", 1.2, 1e-9))
+		Expect(list[0].Ticks).To(Equal(2))
+		Expect(list[1].OpenTime).To(Equal(time.Date(2024, 1, 10, 22, 0, 0, 0, time.UTC)))
+		Expect(list[1].UpdatedAt).To(Not(BeZero()))
+
+		// Verify candles are rebuilt including ticks saved afterwards
+		ticks.SaveTicks([]*db.Tick{tick("EURUSD", 1.4, base.Add(3*time.Minute+time.Second))})
+		a.Mark("EURUSD", base.Add(3*time.Minute+time.Second), base.Add(3*time.Minute+time.Second))
+		a.Aggregate()
+
+		list, _ = candles.List(db.CandleFilter{Pair: "EURUSD", Interval: "1d"})
+		Expect(list[1].High).To(BeNumerically("~", 1.4, 1e-9))
+		Expect(list[1].Ticks).To(Equal(2))
+		list, _ = candles.List(db.CandleFilter{Pair: "EURUSD", Interval: "1d", From: base.Add(time.Hour)})
+		Expect(list).To(BeEmpty())
+	})
+
+	It("Retries ranges that fail", func() {
+		ticks.SaveTicks([]*db.Tick{tick("EURUSD", 1.1, base)})
+		ticks.err = errors.New("Unreachable")
+		a.Mark("EURUSD", base, base)
+		a.Aggregate()
+
+		// Verify output
+		list, _ := candles.List(db.CandleFilter{Pair: "EURUSD", Interval: "1m"})
+		Expect(list).To(BeEmpty())
+
+		ticks.err = nil
+		a.Aggregate()
+		list, _ = candles.List(db.CandleFilter{Pair: "EURUSD", Interval: "1m"})
+		Expect(list).To(HaveLen(1))
+	})
+
+	It("Deletes ticks older than the retention period", func() {
+		ticks.SaveTicks([]*db.Tick{tick("EURUSD", 1.1, base.AddDate(0, 0, -8)), tick("EURUSD", 1.1, base)})
+		a.Purge(base)
+
+		// Verify output
+		Expect(ticks.ticks).To(HaveLen(1))
+
+		config.GetInstance().Candles.TickRetention = 0
+		a.Purge(base.AddDate(1, 0, 0))
+		Expect(ticks.ticks).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("recorder.go", func() {
+	var (
+		ticks *memoryTickStore
+		a     *Aggregator
+		r     *Recorder
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		ticks = &memoryTickStore{}
+		a = NewAggregator(ticks, &memoryCandleStore{candles: map[string]*db.Candle{}})
+		r = NewRecorder(ticks, a)
+		now = time.Now().UTC()
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Candles.BatchSize = 500
+	})
+
+	It("Saves buffered ticks, marking them for aggregation", func() {
+		r.Save(&quotes.Quote{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now})
+		r.Save(&quotes.Quote{Pair: "USDJPY", Bid: 150.1, Ask: 150.12, Time: now.Add(time.Second)})
+		r.Save(&quotes.Quote{Pair: "EURUSD", Bid: 1.07, Ask: 1.0701, Time: now.Add(-time.Second)})
+		Expect(ticks.ticks).To(BeEmpty())
+
+		// Verify output
+		Expect(r.Flush()).To(Succeed())
+		Expect(ticks.ticks).To(HaveLen(3))
+		Expect(a.dirty).To(HaveLen(2))
+		Expect(*a.dirty["EURUSD"]).To(Equal(span{from: now.Add(-time.Second), to: now}))
+	})
+
+	It("Keeps ticks not saved for the next flush", func() {
+		config.GetInstance().Candles.BatchSize = 1
+		ticks.err = errors.New("Unreachable")
+		for i := 0; i < 12; i++ {
+			r.Save(&quotes.Quote{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now.Add(time.Duration(i) * time.Second)})
+		}
+
+		// Verify output
+		Expect(r.Flush()).To(HaveOccurred())
+		Expect(r.pending).To(HaveLen(maxPendingBatches))
+		Expect(r.pending[0].QuotedAt).To(Equal(now.Add(2 * time.Second)))
+
+		ticks.err = nil
+		Expect(r.Flush()).To(Succeed())
+		Expect(ticks.ticks).To(HaveLen(maxPendingBatches))
+		Expect(r.pending).To(BeEmpty())
+	})
+
+	It("Saves ticks once a batch is full, and when closed", func() {
+		config.GetInstance().Candles.BatchSize = 2
+		r.Start()
+		r.Save(&quotes.Quote{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now})
+		r.Save(&quotes.Quote{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now.Add(time.Second)})
+
+		// Verify output
+		Eventually(func() int {
+			ticks.mutex.Lock()
+			defer ticks.mutex.Unlock()
+			return len(ticks.ticks)
+		}).Should(Equal(2))
+
+		r.Save(&quotes.Quote{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now.Add(2 * time.Second)})
+		r.Close()
+		Expect(ticks.ticks).To(HaveLen(3))
+	})
+})
@@ -0,0 +1,178 @@
+// candles package contains the persistence of ingested ticks and their aggregation into OHLC candles
+// recorder contains the quote hook saving ticks in batches
+package candles
+
+import (
+	// Standard lib
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/quotes"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Number of batches kept while saving fails, after which the oldest ticks are dropped
+	maxPendingBatches = 10
+)
+
+type (
+	// Recorder is a struct representing a quote hook buffering ticks, saving them in batches
+	Recorder struct {
+		store      db.TickStore
+		aggregator *Aggregator // Aggregator told of the ticks saved, if any
+		mutex      sync.Mutex
+		pending    []*db.Tick
+		flushing   sync.Mutex    // Held while saving, so batches are saved in order
+		wake       chan struct{} // Signals a full batch is pending
+		stop       chan struct{} // Closed to stop the worker, nil when not running
+		done       chan struct{} // Closed once the worker stops
+	}
+)
+
+// NewRecorder creates and returns a new instance of a recorder saving ticks to a store, telling an aggregator
+// of the ticks saved
+func NewRecorder(store db.TickStore, aggregator *Aggregator) *Recorder {
+	return &Recorder{store: store, aggregator: aggregator, wake: make(chan struct{}, 1)}
+}
+
+// Save buffers a quote's tick, to be saved with the next batch
+func (r *Recorder) Save(q *quotes.Quote) error {
+	r.mutex.Lock()
+	r.pending = append(r.pending, &db.Tick{Pair: q.Pair, QuotedAt: q.Time.UTC(), Bid: q.Bid, Ask: q.Ask, Source: q.Source})
+	full := len(r.pending) >= config.GetInstance().Candles.BatchSize
+	r.mutex.Unlock()
+
+	if full {
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Start starts saving buffered ticks periodically, or once a batch is full
+func (r *Recorder) Start() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stop != nil {
+		return
+	}
+
+	r.stop, r.done = make(chan struct{}), make(chan struct{})
+	go r.run(r.stop, r.done)
+}
+
+// Close stops the worker, saving buffered ticks
+// NOTE: Must be called after the quote store is closed, so no ticks are buffered afterwards
+func (r *Recorder) Close() {
+	r.mutex.Lock()
+	stop, done := r.stop, r.done
+	r.stop = nil
+	r.mutex.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	if err := r.Flush(); err != nil {
+		log.WithError(err).Error("Error saving ticks")
+	}
+}
+
+// Flush saves buffered ticks in batches, keeping those not saved for the next flush
+func (r *Recorder) Flush() error {
+	r.flushing.Lock()
+	defer r.flushing.Unlock()
+
+	size := config.GetInstance().Candles.BatchSize
+
+	r.mutex.Lock()
+	ticks := r.pending
+	r.pending = nil
+	r.mutex.Unlock()
+
+	for len(ticks) > 0 {
+		n := size
+		if n > len(ticks) {
+			n = len(ticks)
+		}
+
+		if err := r.store.SaveTicks(ticks[:n]); err != nil {
+			r.requeue(ticks, size)
+			return err
+		}
+		r.mark(ticks[:n])
+		ticks = ticks[n:]
+	}
+
+	return nil
+}
+
+// requeue returns ticks not saved to the front of the buffer, dropping the oldest beyond the pending limit
+func (r *Recorder) requeue(ticks []*db.Tick, size int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.pending = append(ticks, r.pending...)
+	if max := size * maxPendingBatches; len(r.pending) > max {
+		log.WithField("ticks", len(r.pending)-max).Warn("Dropping ticks not saved")
+		r.pending = r.pending[len(r.pending)-max:]
+	}
+}
+
+// mark tells the aggregator of the range of each pair's ticks saved
+func (r *Recorder) mark(ticks []*db.Tick) {
+	if r.aggregator == nil {
+		return
+	}
+
+	spans := map[string]*span{}
+	for _, t := range ticks {
+		s, ok := spans[t.Pair]
+		if !ok {
+			spans[t.Pair] = &span{from: t.QuotedAt, to: t.QuotedAt}
+			continue
+		}
+		if t.QuotedAt.Before(s.from) {
+			s.from = t.QuotedAt
+		}
+		if t.QuotedAt.After(s.to) {
+			s.to = t.QuotedAt
+		}
+	}
+
+	for pair, s := range spans {
+		r.aggregator.Mark(pair, s.from, s.to)
+	}
+}
+
+// run saves buffered ticks periodically, or once a batch is full, until stopped
+func (r *Recorder) run(stop, done chan struct{}) {
+	defer close(done)
+
+	t := time.NewTicker(time.Duration(config.GetInstance().Candles.FlushInterval) * time.Second)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		case <-r.wake:
+		}
+
+		if err := r.Flush(); err != nil {
+			log.WithError(err).Error("Error saving ticks")
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// cli package contains the application's command-line interface. Each subcommand lives within its own file
+// and is registered within the `commands` map
+package cli
+
+import (
+	// Standard lib
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+)
+
+const (
+	// Exit codes
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+
+	// Command run when none is provided
+	DefaultCommand = "serve"
+)
+
+type (
+	// Command is a struct representing a single subcommand
+	Command struct {
+		Usage       string                  // Usage of the command's arguments
+		Description string                  // Short description of the command
+		Run         func(args []string) int // Function running the command, returning an exit code
+	}
+)
+
+var (
+	// Streams to write to for standard and error output
+	// NOTE: Pattern used to allow for test-based overrides of stdout / stderr
+	Output      io.Writer = os.Stdout
+	ErrorOutput io.Writer = os.Stderr
+
+	// All available commands
+	commands = map[string]*Command{}
+
+	// Global flag values
+	configPath string
+	envName    string
+)
+
+// Run parses command-line arguments and runs the matching command, returning the exit code to use
+// Usage: forex-clock [--config <path>] [--env <name>] <command> [arguments]
+func Run(args []string) int {
+	fs := newFlagSet("forex-clock")
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	// Default to serving when no command is provided
+	rest := fs.Args()
+	if len(rest) == 0 {
+		rest = []string{DefaultCommand}
+	}
+
+	cmd, ok := commands[rest[0]]
+	if !ok {
+		fmt.Fprintf(ErrorOutput, "Unknown command: %s\n\n", rest[0])
+		usage()
+		return ExitUsage
+	}
+
+	return cmd.Run(rest[1:])
+}
+
+// newFlagSet creates a flag set that includes the global `--config` and `--env` flags
+// NOTE: Global flags are accepted both before and after a command
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ErrorOutput)
+	fs.StringVar(&configPath, "config", configPath, "Path to a JSON configuration file, overriding "+strings.ToUpper(config.ConfigLocation))
+	fs.StringVar(&envName, "env", envName, "Environment to run in (ex: dev, test, prod), overriding "+strings.ToUpper(config.EnvPrefix)+"ENVIRONMENT")
+
+	return fs
+}
+
+// initConfig applies global flags and initializes configuration, returning any validation errors
+func initConfig() error {
+	if configPath != "" {
+		if _, err := os.Stat(configPath); err != nil {
+			return fmt.Errorf("Unable to read configuration file. Error was: %s", err)
+		}
+		config.SetConfigFile(configPath)
+	}
+	if envName != "" {
+		config.SetEnv("ENVIRONMENT", envName)
+	}
+
+	config.Init()
+
+	return config.GetInstance().Validate()
+}
+
+// usage outputs the available commands
+func usage() {
+	fmt.Fprintln(ErrorOutput, "Usage: forex-clock [--config <path>] [--env <name>] <command> [arguments]")
+	fmt.Fprintln(ErrorOutput, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		c := commands[name]
+		fmt.Fprintf(ErrorOutput, "  %-30s %s\n", strings.TrimSpace(name+" "+c.Usage), c.Description)
+	}
+}
+
+// printJSON outputs a value as indented JSON
+func printJSON(v interface{}) int {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintln(ErrorOutput, "Error encoding output. Error was: "+err.Error())
+		return ExitError
+	}
+
+	fmt.Fprintln(Output, string(out))
+
+	return ExitOK
+}
+
+// subcommand dispatches to a named function from a set of sub-subcommands (ex: `migrate up`)
+func subcommand(name string, args []string, subs map[string]func([]string) int) int {
+	if len(args) == 0 {
+		fmt.Fprintf(ErrorOutput, "Missing %s subcommand\n", name)
+		return ExitUsage
+	}
+
+	fn, ok := subs[args[0]]
+	if !ok {
+		fmt.Fprintf(ErrorOutput, "Unknown %s subcommand: %s\n", name, args[0])
+		return ExitUsage
+	}
+
+	return fn(args[1:])
+}
@@ -0,0 +1,111 @@
+// Tests the cli.go file
+package cli
+
+import (
+	// Standard lib
+	"bytes"
+	"os"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// run runs the command-line interface with a set of arguments, returning the exit code and the standard
+// and error output, then resets global flags and configuration
+func run(args ...string) (int, string, string) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	Output, ErrorOutput = out, errOut
+	defer func() {
+		Output, ErrorOutput = os.Stdout, os.Stderr
+		configPath, envName = "", ""
+		config.SetConfigFile("")
+		os.Unsetenv(strings.ToUpper(config.EnvPrefix + "ENVIRONMENT"))
+		os.Unsetenv(config.EnvPrefix + "ENVIRONMENT")
+		config.Init()
+	}()
+
+	code := Run(args)
+
+	return code, out.String(), errOut.String()
+}
+
+var _ = Describe("cli.go", func() {
+	Describe("`Run` method", func() {
+		Context("When the command is unknown", func() {
+			It("Outputs usage and returns the usage exit code", func() {
+				// Call method
+				code, _, errOut := run("launch")
+
+				// Verify output
+				Expect(code).To(Equal(ExitUsage))
+				Expect(errOut).To(ContainSubstring("Unknown command: launch"))
+				Expect(errOut).To(ContainSubstring("Usage: forex-clock"))
+				Expect(errOut).To(ContainSubstring("migrate up|down|status"))
+			})
+		})
+
+		Context("When a flag is unknown", func() {
+			It("Returns the usage exit code", func() {
+				// Call method
+				code, _, errOut := run("--verbose", "version")
+
+				// Verify output
+				Expect(code).To(Equal(ExitUsage))
+				Expect(errOut).To(ContainSubstring("flag provided but not defined: -verbose"))
+			})
+		})
+
+		Context("When a subcommand is missing or unknown", func() {
+			It("Returns the usage exit code", func() {
+				// Call method
+				code, _, errOut := run("migrate")
+				Expect(code).To(Equal(ExitUsage))
+				Expect(errOut).To(ContainSubstring("Missing migrate subcommand"))
+
+				code, _, errOut = run("sessions", "later")
+				Expect(code).To(Equal(ExitUsage))
+				Expect(errOut).To(ContainSubstring("Unknown sessions subcommand: later"))
+			})
+		})
+
+		Context("When global flags are given", func() {
+			It("Accepts them before and after the command", func() {
+				// Call method
+				code, out, _ := run("--env", "test", "version")
+				Expect(code).To(Equal(ExitOK))
+				Expect(out).To(HavePrefix(config.GetInstance().Name))
+
+				code, _, errOut := run("version", "--config", "/does/not/exist.json")
+
+				// Verify output
+				// NOTE: The version is still output when configuration can't be read
+				Expect(code).To(Equal(ExitOK))
+				Expect(errOut).To(BeEmpty())
+			})
+
+			It("Applies the environment to configuration", func() {
+				var env string
+				commands["test-env"] = &Command{Run: func(args []string) int {
+					fs := newFlagSet("test-env")
+					fs.Parse(args)
+					initConfig()
+					env = config.GetInstance().Environment
+					return ExitOK
+				}}
+				defer delete(commands, "test-env")
+
+				// Call method
+				code, _, _ := run("--env", "prod", "test-env")
+
+				// Verify output
+				Expect(code).To(Equal(ExitOK))
+				Expect(env).To(Equal("prod"))
+			})
+		})
+	})
+})
@@ -0,0 +1,63 @@
+// cli package contains the application's command-line interface
+// config contains the `config` commands, used to validate and output configuration
+package cli
+
+import (
+	// Standard lib
+	"fmt"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+)
+
+// configCommand dispatches `config` subcommands
+func configCommand(args []string) int {
+	return subcommand("config", args, map[string]func([]string) int{
+		"check": configCheck,
+		"print": configPrint,
+	})
+}
+
+// configCheck validates a configuration file plus environment and outputs the effective configuration
+func configCheck(args []string) int {
+	fs := newFlagSet("config check")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	// Initialize configuration
+	// NOTE: Validation errors are output by the check itself
+	if err := initConfig(); err != nil {
+		if _, ok := err.(config.ValidationErrors); !ok {
+			fmt.Fprintln(ErrorOutput, err.Error())
+			return ExitError
+		}
+	}
+
+	if err := config.Check(Output); err != nil {
+		return ExitError
+	}
+
+	return ExitOK
+}
+
+// configPrint outputs the effective configuration, with secrets redacted, without validating it
+func configPrint(args []string) int {
+	fs := newFlagSet("config print")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	if err := initConfig(); err != nil {
+		if _, ok := err.(config.ValidationErrors); !ok {
+			fmt.Fprintln(ErrorOutput, err.Error())
+			return ExitError
+		}
+	}
+
+	return printJSON(config.GetInstance().Redacted())
+}
+
+func init() {
+	commands["config"] = &Command{Usage: "check|print", Description: "Validate or output the effective configuration", Run: configCommand}
+}
@@ -0,0 +1,91 @@
+// Tests the config.go file
+package cli
+
+import (
+	// Standard lib
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("config.go", func() {
+	var (
+		// Temporary directory holding a configuration file
+		dir string
+		// Path to the configuration file
+		path string
+	)
+
+	BeforeEach(func() {
+		// Create temporary configuration file location
+		var err error
+		dir, err = ioutil.TempDir("", "forex-clock-cli")
+		Expect(err).To(Not(HaveOccurred()))
+		path = filepath.Join(dir, "config.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("`config check` command", func() {
+		Context("When configuration is valid", func() {
+			It("Outputs the redacted configuration and exits successfully", func() {
+				Expect(ioutil.WriteFile(path, []byte(`{"db":{"password":"hunter2"}}`), 0644)).To(Succeed())
+
+				// Call method
+				code, out, _ := run("--config", path, "config", "check")
+
+				// Verify output
+				Expect(code).To(Equal(ExitOK))
+				Expect(out).To(ContainSubstring("Configuration is valid"))
+				Expect(out).NotTo(ContainSubstring("hunter2"))
+			})
+		})
+
+		Context("When configuration is invalid", func() {
+			It("Outputs the errors and exits with an error", func() {
+				Expect(ioutil.WriteFile(path, []byte(`{"log":{"level":"loud"},"server":{"port":0}}`), 0644)).To(Succeed())
+
+				// Call method
+				code, out, _ := run("config", "check", "--config", path)
+
+				// Verify output
+				Expect(code).To(Equal(ExitError))
+				Expect(out).To(ContainSubstring("log.level"))
+				Expect(out).To(ContainSubstring("server.port"))
+				Expect(out).NotTo(ContainSubstring("Configuration is valid"))
+			})
+		})
+
+		Context("When the configuration file doesn't exist", func() {
+			It("Exits with an error", func() {
+				// Call method
+				code, out, errOut := run("--config", filepath.Join(dir, "missing.json"), "config", "check")
+
+				// Verify output
+				Expect(code).To(Equal(ExitError))
+				Expect(out).To(BeEmpty())
+				Expect(errOut).To(ContainSubstring("Unable to read configuration file"))
+			})
+		})
+	})
+
+	Describe("`config print` command", func() {
+		It("Outputs the redacted configuration without validating it", func() {
+			Expect(ioutil.WriteFile(path, []byte(`{"log":{"level":"loud"},"db":{"password":"hunter2"}}`), 0644)).To(Succeed())
+
+			// Call method
+			code, out, _ := run("--config", path, "config", "print")
+
+			// Verify output
+			Expect(code).To(Equal(ExitOK))
+			Expect(out).To(ContainSubstring(`"level": "loud"`))
+			Expect(out).NotTo(ContainSubstring("hunter2"))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+// cli package contains the application's command-line interface
+// migrate contains the `migrate` commands, used to apply and roll back database migrations
+package cli
+
+import (
+	// Standard lib
+	"fmt"
+	"text/tabwriter"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+)
+
+// migrateCommand dispatches `migrate` subcommands
+func migrateCommand(args []string) int {
+	return subcommand("migrate", args, map[string]func([]string) int{
+		"up":     migrateUp,
+		"down":   migrateDown,
+		"status": migrateStatus,
+	})
+}
+
+// migrateUp applies all pending migrations
+func migrateUp(args []string) int {
+	fs := newFlagSet("migrate up")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	d, code := openDB()
+	if d == nil {
+		return code
+	}
+	defer d.Close()
+
+	done, err := db.MigrateUp(d)
+	printMigrations("Applied", done)
+	if err != nil {
+		fmt.Fprintln(ErrorOutput, "Error applying migrations. Error was: "+err.Error())
+		return ExitError
+	}
+
+	return ExitOK
+}
+
+// migrateDown rolls back applied migrations
+func migrateDown(args []string) int {
+	fs := newFlagSet("migrate down")
+	steps := fs.Int("steps", 1, "Number of migrations to roll back")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	d, code := openDB()
+	if d == nil {
+		return code
+	}
+	defer d.Close()
+
+	done, err := db.MigrateDown(d, *steps)
+	printMigrations("Rolled back", done)
+	if err != nil {
+		fmt.Fprintln(ErrorOutput, "Error rolling back migrations. Error was: "+err.Error())
+		return ExitError
+	}
+
+	return ExitOK
+}
+
+// migrateStatus outputs every known migration along with whether it has been applied
+func migrateStatus(args []string) int {
+	fs := newFlagSet("migrate status")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	d, code := openDB()
+	if d == nil {
+		return code
+	}
+	defer d.Close()
+
+	statuses, err := db.MigrateStatus(d)
+	if err != nil {
+		fmt.Fprintln(ErrorOutput, "Error reading migration status. Error was: "+err.Error())
+		return ExitError
+	}
+
+	w := tabwriter.NewWriter(Output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSTATUS")
+	for _, s := range statuses {
+		status := "pending"
+		if s.Applied {
+			status = "applied"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.ID, s.Name, status)
+	}
+	w.Flush()
+
+	return ExitOK
+}
+
+// openDB initializes configuration and opens the database, returning nil and an exit code on failure
+func openDB() (db.DB, int) {
+	if err := initConfig(); err != nil {
+		fmt.Fprintln(ErrorOutput, err.Error())
+		return nil, ExitError
+	}
+
+	d := db.NewFCDB()
+	if err := d.Ready(); err != nil {
+		fmt.Fprintln(ErrorOutput, "Unable to connect to database. Error was: "+err.Error())
+		return nil, ExitError
+	}
+
+	return d, ExitOK
+}
+
+// printMigrations outputs a list of migrations acted upon
+func printMigrations(action string, migrations []*db.Migration) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(Output, "%s 0 migrations\n", action)
+		return
+	}
+
+	for _, m := range migrations {
+		fmt.Fprintf(Output, "%s %d %s\n", action, m.ID, m.Name)
+	}
+}
+
+func init() {
+	commands["migrate"] = &Command{Usage: "up|down|status", Description: "Apply, roll back, or list database migrations", Run: migrateCommand}
+}
@@ -0,0 +1,84 @@
+// cli package contains the application's command-line interface
+// mock-provider contains the `mock-provider` command, which serves replayed ticks over HTTP for the HTTP polling
+// provider to point at during local development
+package cli
+
+import (
+	// Standard lib
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/provider"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Port the mock provider listens on by default, matching the default `provider.http.url`
+	DefaultMockProviderPort = 6011
+)
+
+// mockProvider plays a tick file and serves the latest ticks at `/quotes` until the application is asked to exit
+func mockProvider(args []string) int {
+	fs := newFlagSet("mock-provider")
+	file := fs.String("file", "", "Path of a CSV or JSON Lines file of ticks (default: provider.replay.file)")
+	port := fs.Int("port", DefaultMockProviderPort, "Port to listen on")
+	speed := fs.Int("speed", -1, "Playback speed, as a multiple of the recorded pace (default: provider.replay.speed)")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	if err := initConfig(); err != nil {
+		fmt.Fprintln(ErrorOutput, err.Error())
+		return ExitError
+	}
+
+	// Play every pair, looping and timestamping ticks as they're played so quotes never go stale
+	c := config.GetInstance().Provider
+	c.Pairs = ""
+	c.Replay.Loop, c.Replay.Rebase = true, true
+	if *file != "" {
+		c.Replay.File = *file
+	}
+	if *speed >= 0 {
+		c.Replay.Speed = *speed
+	}
+
+	r := provider.NewReplay(c)
+	if err := r.Start(); err != nil {
+		fmt.Fprintln(ErrorOutput, "Error starting replay. Error was: "+err.Error())
+		return ExitError
+	}
+	defer r.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/quotes", provider.SnapshotHandler(r))
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", *port), Handler: mux}
+
+	errs := make(chan error, 1)
+	go func() { errs <- srv.ListenAndServe() }()
+	log.Infof("Serving replayed ticks of %s at http://127.0.0.1:%d/quotes", c.Replay.File, *port)
+
+	// Listen for and exit on SIGINT
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+
+	select {
+	case err := <-errs:
+		fmt.Fprintln(ErrorOutput, "Error serving ticks. Error was: "+err.Error())
+		return ExitError
+	case <-stop:
+		srv.Close()
+	}
+
+	return ExitOK
+}
+
+func init() {
+	commands["mock-provider"] = &Command{Usage: "[--file <path>]", Description: "Serve replayed FX quotes for the HTTP provider", Run: mockProvider}
+}
@@ -0,0 +1,91 @@
+// cli package contains the application's command-line interface
+// serve contains the `serve` command, which runs the HTTP server
+package cli
+
+import (
+	// Standard lib
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/server"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+// serve starts the HTTP server and blocks until the application is asked to exit
+func serve(args []string) int {
+	fs := newFlagSet("serve")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	m := "Starting forex-clock application..."
+	log.Info(m)
+
+	// Initialize configuration, refusing to start with invalid configuration
+	if err := initConfig(); err != nil {
+		log.Error("Invalid configuration, refusing to start. Error was: " + err.Error())
+		return ExitError
+	}
+
+	m = "Configuration loaded..."
+	log.Info(m)
+
+	// Create new server
+	s := server.NewServer()
+
+	m = "Creating new server..."
+	log.Info(m)
+
+	// Start server
+	m = "Starting server..."
+	log.Info(m)
+	if err := s.Start(); err != nil {
+		fmt.Fprintln(ErrorOutput, "Error starting application. Error was: "+err.Error())
+		return ExitError
+	}
+
+	// Reload runtime-safe configuration when the configuration file changes
+	done := make(chan struct{})
+	go func() {
+		if err := config.Watch(done); err != nil {
+			log.WithError(err).Warn("Unable to watch configuration file for changes")
+		}
+	}()
+
+	// Periodically re-read secrets so rotated values are picked up
+	go config.RefreshSecrets(done)
+
+	// Listen for and exit the application on SIGKILL or SIGINT,
+	// reloading configuration on SIGHUP
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, os.Kill, syscall.SIGHUP)
+
+	for sig := range stop {
+		if sig == syscall.SIGHUP {
+			log.Info("Received SIGHUP, reloading configuration...")
+			config.Reload()
+			continue
+		}
+
+		// Attempt to stop the server
+		close(done)
+		s.Stop()
+
+		// Log shut down
+		m = "Server is shutting down..."
+		log.Info(m)
+		break
+	}
+
+	return ExitOK
+}
+
+func init() {
+	commands["serve"] = &Command{Description: "Run the HTTP server (default)", Run: serve}
+}
@@ -0,0 +1,195 @@
+// cli package contains the application's command-line interface
+// sessions contains the `sessions` commands, used to query the session engine offline
+package cli
+
+import (
+	// Standard lib
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/sessions"
+
+	// Third-party
+	"github.com/marksost/go-utils"
+)
+
+const (
+	// Format of dates accepted by session commands
+	DateFormat = "2006-01-02"
+)
+
+// sessionsCommand dispatches `sessions` subcommands
+func sessionsCommand(args []string) int {
+	return subcommand("sessions", args, map[string]func([]string) int{
+		"now":      sessionsNow,
+		"next":     sessionsNext,
+		"calendar": sessionsCalendar,
+	})
+}
+
+// sessionsNow outputs the state of the market and every session
+func sessionsNow(args []string) int {
+	fs := newFlagSet("sessions now")
+	at := fs.String("at", "", "Point in time to check, as RFC 3339 (default: now)")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	t, code := parseTime(*at)
+	if code != ExitOK {
+		return code
+	}
+
+	e, code := sessionEngine()
+	if e == nil {
+		return code
+	}
+
+	status := e.Status(t)
+	if *asJSON {
+		return printJSON(status)
+	}
+
+	market := "closed"
+	if status.Open {
+		market = "open"
+	}
+	fmt.Fprintf(Output, "Market is %s at %s\n\n", market, t.UTC().Format(time.RFC3339))
+
+	w := tabwriter.NewWriter(Output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tSTATUS\tLOCAL TIME\tNEXT OPEN\tNEXT CLOSE")
+	for _, s := range status.Sessions {
+		state := "closed"
+		if s.Open {
+			state = "open"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Name, state, s.LocalTime, formatTime(s.NextOpen), formatTime(s.NextClose))
+	}
+	w.Flush()
+
+	return ExitOK
+}
+
+// sessionsNext outputs the next market events
+func sessionsNext(args []string) int {
+	fs := newFlagSet("sessions next")
+	at := fs.String("at", "", "Point in time to start from, as RFC 3339 (default: now)")
+	count := fs.Int("count", 5, "Number of events to output")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	t, code := parseTime(*at)
+	if code != ExitOK {
+		return code
+	}
+
+	e, code := sessionEngine()
+	if e == nil {
+		return code
+	}
+
+	return printEvents(e.Next(t, *count), *asJSON)
+}
+
+// sessionsCalendar outputs all market events within a date range
+func sessionsCalendar(args []string) int {
+	fs := newFlagSet("sessions calendar")
+	from := fs.String("from", "", "First date to include, as YYYY-MM-DD in UTC (default: today)")
+	days := fs.Int("days", 7, "Number of days to include")
+	session := fs.String("session", "", "Only include events for a session ID (ex: london)")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	if *from != "" {
+		var err error
+		if start, err = time.Parse(DateFormat, *from); err != nil {
+			fmt.Fprintln(ErrorOutput, "Invalid --from date. Error was: "+err.Error())
+			return ExitUsage
+		}
+	}
+
+	e, code := sessionEngine()
+	if e == nil {
+		return code
+	}
+
+	if *session != "" && e.Session(*session) == nil {
+		fmt.Fprintln(ErrorOutput, "Unknown session: "+*session)
+		return ExitUsage
+	}
+
+	events := make([]*sessions.Event, 0)
+	for _, ev := range e.Events(start, start.AddDate(0, 0, *days)) {
+		if *session == "" || ev.Session == *session || goutils.SliceContains(*session, ev.Sessions) {
+			events = append(events, ev)
+		}
+	}
+
+	return printEvents(events, *asJSON)
+}
+
+// sessionEngine initializes configuration and returns the session engine, or nil and an exit code on failure
+func sessionEngine() (*sessions.Engine, int) {
+	if err := initConfig(); err != nil {
+		fmt.Fprintln(ErrorOutput, err.Error())
+		return nil, ExitError
+	}
+
+	return sessions.GetInstance(), ExitOK
+}
+
+// printEvents outputs a list of market events
+func printEvents(events []*sessions.Event, asJSON bool) int {
+	if asJSON {
+		return printJSON(events)
+	}
+
+	w := tabwriter.NewWriter(Output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME (UTC)\tEVENT\tSESSION")
+	for _, ev := range events {
+		subject := ev.Session
+		if len(ev.Sessions) > 0 {
+			subject = fmt.Sprintf("%v", ev.Sessions)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", ev.Time.Format(time.RFC3339), ev.Type, subject)
+	}
+	w.Flush()
+
+	return ExitOK
+}
+
+// parseTime parses an RFC 3339 time, defaulting to now
+func parseTime(s string) (time.Time, int) {
+	if s == "" {
+		return time.Now(), ExitOK
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		fmt.Fprintln(ErrorOutput, "Invalid time. Error was: "+err.Error())
+		return t, ExitUsage
+	}
+
+	return t, ExitOK
+}
+
+// formatTime formats an optional time in UTC
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func init() {
+	commands["sessions"] = &Command{Usage: "now|next|calendar", Description: "Query trading sessions offline", Run: sessionsCommand}
+}
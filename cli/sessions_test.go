@@ -0,0 +1,78 @@
+// Tests the sessions.go file
+package cli
+
+import (
+	// Standard lib
+	"encoding/json"
+
+	// Internal
+	"github.com/deezone/forex-clock/sessions"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sessions.go", func() {
+	Describe("`sessions now` command", func() {
+		It("Outputs the state of the market and every session", func() {
+			// Call method
+			code, out, _ := run("sessions", "now", "--at", "2024-06-05T10:00:00Z")
+
+			// Verify output
+			Expect(code).To(Equal(ExitOK))
+			Expect(out).To(HavePrefix("Market is open at 2024-06-05T10:00:00Z\n"))
+			Expect(out).To(MatchRegexp(`SESSION\s+STATUS\s+LOCAL TIME\s+NEXT OPEN\s+NEXT CLOSE`))
+			Expect(out).To(MatchRegexp(`London\s+open\s+2024-06-05T11:00:00\+01:00\s+2024-06-06T07:00:00Z\s+2024-06-05T16:00:00Z`))
+			Expect(out).To(MatchRegexp(`New York\s+closed\s+2024-06-05T06:00:00-04:00\s+2024-06-05T12:00:00Z`))
+		})
+
+		It("Outputs JSON", func() {
+			// Call method
+			code, out, _ := run("sessions", "now", "--at", "2024-06-08T12:00:00Z", "--json")
+
+			// Verify output
+			status := &sessions.MarketStatus{}
+			Expect(code).To(Equal(ExitOK))
+			Expect(json.Unmarshal([]byte(out), status)).To(Succeed())
+			Expect(status.Open).To(BeFalse())
+			Expect(status.Sessions).To(HaveLen(4))
+		})
+
+		It("Rejects invalid times", func() {
+			// Call method
+			code, out, errOut := run("sessions", "now", "--at", "tomorrow")
+
+			// Verify output
+			Expect(code).To(Equal(ExitUsage))
+			Expect(out).To(BeEmpty())
+			Expect(errOut).To(ContainSubstring("Invalid time"))
+		})
+	})
+
+	Describe("`sessions next` command", func() {
+		It("Outputs the next market events", func() {
+			// Call method
+			code, out, _ := run("sessions", "next", "--at", "2024-06-05T10:00:00Z", "--count", "3")
+
+			// Verify output
+			Expect(code).To(Equal(ExitOK))
+			Expect(out).To(MatchRegexp(`^TIME \(UTC\)\s+EVENT\s+SESSION\n` +
+				`2024-06-05T12:00:00Z\s+overlap-start\s+\[london new-york\]\n` +
+				`2024-06-05T12:00:00Z\s+session-open\s+new-york\n` +
+				`2024-06-05T16:00:00Z\s+overlap-end\s+\[london new-york\]\n$`))
+		})
+
+		It("Outputs JSON", func() {
+			// Call method
+			code, out, _ := run("sessions", "next", "--at", "2024-06-05T10:00:00Z", "--count", "1", "--json")
+
+			// Verify output
+			events := []*sessions.Event{}
+			Expect(code).To(Equal(ExitOK))
+			Expect(json.Unmarshal([]byte(out), &events)).To(Succeed())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Type).To(Equal(sessions.EventOverlapStart))
+		})
+	})
+})
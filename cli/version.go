@@ -0,0 +1,37 @@
+// cli package contains the application's command-line interface
+// version contains the `version` command
+package cli
+
+import (
+	// Standard lib
+	"fmt"
+	"runtime"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+)
+
+// version outputs the release version of the application
+func version(args []string) int {
+	fs := newFlagSet("version")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	// NOTE: Validation errors are ignored, the version is still useful
+	initConfig()
+	c := config.GetInstance()
+
+	v := c.ReleaseVersion
+	if v == "" {
+		v = "unknown"
+	}
+
+	fmt.Fprintf(Output, "%s %s (%s)\n", c.Name, v, runtime.Version())
+
+	return ExitOK
+}
+
+func init() {
+	commands["version"] = &Command{Description: "Output the release version", Run: version}
+}
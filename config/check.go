@@ -0,0 +1,33 @@
+// config package handles setting up, parsing, and storing all configuration settings for the application.
+// check contains the `config check` routine used to validate configuration outside of a running server
+package config
+
+import (
+	// Standard lib
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Check outputs the effective configuration, with secrets redacted, to the provided writer
+// and validates it, returning any validation errors
+func Check(w io.Writer) error {
+	c := GetInstance()
+
+	// Output effective configuration
+	out, err := json.MarshalIndent(c.Redacted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(out))
+
+	// Validate configuration
+	if err := c.Validate(); err != nil {
+		fmt.Fprintln(w, err.Error())
+		return err
+	}
+
+	fmt.Fprintln(w, "Configuration is valid")
+
+	return nil
+}
@@ -5,6 +5,9 @@ package config
 
 import (
 	// Standard lib
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	// Third-party
@@ -32,6 +35,9 @@ const (
 var (
 	config *Config
 
+	// Mutex guarding the config instance, which may be swapped during a reload
+	configMutex sync.RWMutex
+
 	// Supported API versions
 	SupportedVersions = []string{Version10}
 )
@@ -39,50 +45,378 @@ var (
 type (
 	// Component-specific configuration
 
+	// Struct containing configuration settings for price alerts
+	Alerts struct {
+		// Whether stored alerts are evaluated
+		Enabled bool `json:"enabled" env:"ALERTS_ENABLED" default:"true"`
+		// How often (in seconds) alerts are evaluated against the latest quotes and market events
+		Interval int `json:"interval" env:"ALERTS_INTERVAL" default:"5" validate:"min=1,max=300"`
+		// Shortest time (in seconds) between triggers of an alert, unless the alert sets its own
+		Cooldown int `json:"cooldown" env:"ALERTS_COOLDOWN" default:"300" validate:"min=0" reload:"true"`
+		// Timeout (in seconds) allowed for a notification to be sent
+		Timeout int `json:"timeout" env:"ALERTS_TIMEOUT" default:"10" validate:"min=1,max=60" reload:"true"`
+		// Settings for email notifications
+		SMTP AlertsSMTP `json:"smtp"`
+	}
+	// Struct containing configuration settings for sending alert notifications by email
+	// NOTE: Email notifications are only available when an address is set
+	AlertsSMTP struct {
+		// Address of the SMTP server (ex: "smtp.example.com:587")
+		Address string `json:"address" env:"ALERTS_SMTP_ADDRESS" default:"" reload:"true"`
+		// Username used to authenticate, no authentication is attempted when empty
+		Username string `json:"username" env:"ALERTS_SMTP_USERNAME" default:"" reload:"true"`
+		// Password used to authenticate
+		Password string `json:"password" env:"ALERTS_SMTP_PASSWORD" default:"" secret:"true" reload:"true"`
+		// Sender address of notifications
+		From string `json:"from" env:"ALERTS_SMTP_FROM" default:"forex-clock@localhost" reload:"true"`
+	}
+
+	// Struct containing configuration settings for authentication
+	Auth struct {
+		// Whether data routes require authentication, admin routes always do
+		Required bool `json:"required" env:"AUTH_REQUIRED" default:"false"`
+		// A static API key granted the admin scope, used to create the first stored API keys
+		AdminKey string `json:"admin-key" env:"AUTH_ADMIN_KEY" default:"" secret:"true"`
+		// Settings for JWT bearer tokens
+		JWT AuthJWT `json:"jwt"`
+	}
+
+	// Struct containing configuration settings for JWT bearer tokens issued by an OIDC provider
+	// NOTE: JWTs are only accepted when a JWKS URL or file is set
+	AuthJWT struct {
+		// URL of the JSON Web Key Set used to verify tokens (ex: an OIDC provider's `jwks_uri`)
+		JWKSURL string `json:"jwks-url" env:"AUTH_JWT_JWKS_URL" default:""`
+		// Path of a local JSON Web Key Set file, used when no URL is set
+		JWKSFile string `json:"jwks-file" env:"AUTH_JWT_JWKS_FILE" default:""`
+		// How long (in seconds) to cache the key set before fetching it again
+		JWKSCacheTTL int `json:"jwks-cache-ttl" env:"AUTH_JWT_JWKS_CACHE_TTL" default:"3600" validate:"min=60"`
+		// Required `iss` claim of tokens, which must be set when a JWKS URL or file is
+		Issuer string `json:"issuer" env:"AUTH_JWT_ISSUER" default:""`
+		// Required `aud` claim of tokens
+		Audience string `json:"audience" env:"AUTH_JWT_AUDIENCE" default:"forex-clock"`
+		// Claim containing the client's scopes, as a space-separated string or an array
+		ScopeClaim string `json:"scope-claim" env:"AUTH_JWT_SCOPE_CLAIM" default:"scope" validate:"required"`
+		// Comma-separated mapping of claim values to scopes (ex: "fx-admins=admin,fx-readers=sessions:read")
+		// NOTE: Claim values that are already known scopes are always granted
+		ScopeMapping string `json:"scope-mapping" env:"AUTH_JWT_SCOPE_MAPPING" default:""`
+		// Allowed clock skew (in seconds) when checking expiry
+		Leeway int `json:"leeway" env:"AUTH_JWT_LEEWAY" default:"60" validate:"min=0,max=300"`
+	}
+
+	// Struct containing configuration settings for the in-process cache
+	Cache struct {
+		// Whether provider and database reads are cached
+		Enabled bool `json:"enabled" env:"CACHE_ENABLED" default:"true" reload:"true"`
+		// Where cached values are stored, "redis" shares values between replicas
+		Backend string `json:"backend" env:"CACHE_BACKEND" default:"memory" validate:"oneof=memory redis"`
+		// Maximum number of values kept in memory, the least recently used are evicted first
+		MaxEntries int `json:"max-entries" env:"CACHE_MAX_ENTRIES" default:"10000" validate:"min=1"`
+		// How long (in seconds) API key lookups are cached
+		// NOTE: With the memory backend, revoked keys remain usable on other replicas for up to this long
+		APIKeyTTL int `json:"api-key-ttl" env:"CACHE_API_KEY_TTL" default:"30" validate:"min=0" reload:"true"`
+		// Settings for the Redis-compatible backend
+		Redis CacheRedis `json:"redis"`
+	}
+	// Struct containing configuration settings for a Redis-compatible cache backend
+	CacheRedis struct {
+		// Address of the server
+		Address string `json:"address" env:"CACHE_REDIS_ADDRESS" default:"127.0.0.1:6379"`
+		// Password used to authenticate
+		Password string `json:"password" env:"CACHE_REDIS_PASSWORD" default:"" secret:"true"`
+		// Database number to use
+		DB int `json:"db" env:"CACHE_REDIS_DB" default:"0" validate:"min=0"`
+		// Prefix of all keys
+		Prefix string `json:"prefix" env:"CACHE_REDIS_PREFIX" default:"forex-clock:cache:"`
+	}
+
+	// Struct containing configuration settings for tick persistence and OHLC candle aggregation
+	Candles struct {
+		// Whether ingested ticks are saved within the database and aggregated into candles
+		Enabled bool `json:"enabled" env:"CANDLES_ENABLED" default:"false"`
+		// Largest number of ticks saved at a time
+		BatchSize int `json:"batch-size" env:"CANDLES_BATCH_SIZE" default:"500" validate:"min=1,max=10000" reload:"true"`
+		// Longest time (in seconds) ticks are buffered before being saved
+		FlushInterval int `json:"flush-interval" env:"CANDLES_FLUSH_INTERVAL" default:"1" validate:"min=1,max=60"`
+		// How often (in seconds) candles are built from newly saved ticks
+		AggregateInterval int `json:"aggregate-interval" env:"CANDLES_AGGREGATE_INTERVAL" default:"5" validate:"min=1,max=3600"`
+		// Number of days ticks are kept once aggregated, 0 keeps them forever
+		TickRetention int `json:"tick-retention" env:"CANDLES_TICK_RETENTION" default:"7" validate:"min=0" reload:"true"`
+	}
+
+	// Struct containing configuration settings for response compression
+	Compression struct {
+		// Whether responses are compressed
+		Enabled bool `json:"enabled" env:"COMPRESSION_ENABLED" default:"true" reload:"true"`
+		// Minimum size (in bytes) of responses to compress, smaller responses aren't worth the overhead
+		MinSize int `json:"min-size" env:"COMPRESSION_MIN_SIZE" default:"1024" validate:"min=0" reload:"true"`
+		// Comma-separated content types to compress, "type/*" matches all subtypes
+		ContentTypes string `json:"content-types" env:"COMPRESSION_CONTENT_TYPES" default:"application/json,text/plain,text/csv,text/calendar,text/html" reload:"true"`
+		// Compression level of gzip responses, from 1 (fastest) to 9 (smallest)
+		GzipLevel int `json:"gzip-level" env:"COMPRESSION_GZIP_LEVEL" default:"6" validate:"min=1,max=9"`
+		// Compression quality of brotli responses, from 0 (fastest) to 11 (smallest)
+		BrotliQuality int `json:"brotli-quality" env:"COMPRESSION_BROTLI_QUALITY" default:"4" validate:"min=0,max=11"`
+	}
+
+	// Struct containing configuration settings for cross-origin resource sharing (CORS)
+	// NOTE: List values are comma-separated
+	CORS struct {
+		// Origins allowed to make cross-origin requests
+		AllowedOrigins string `json:"allowed-origins" env:"CORS_ALLOWED_ORIGINS" default:"*" reload:"true"`
+		// Methods allowed for cross-origin requests
+		AllowedMethods string `json:"allowed-methods" env:"CORS_ALLOWED_METHODS" default:"GET,HEAD,POST,PUT,PATCH,DELETE" reload:"true"`
+		// Non-simple headers allowed for cross-origin requests
+		AllowedHeaders string `json:"allowed-headers" env:"CORS_ALLOWED_HEADERS" default:"" reload:"true"`
+		// Whether requests can include user credentials
+		AllowCredentials bool `json:"allow-credentials" env:"CORS_ALLOW_CREDENTIALS" default:"false" reload:"true"`
+		// How long (in seconds) the results of a pre-flight request can be cached
+		MaxAge int `json:"max-age" env:"CORS_MAX_AGE" default:"0" validate:"min=0,max=86400" reload:"true"`
+	}
+
 	// Struct containing configuration settings for a database
 	DB struct {
 		// The username to use when connecting to a DB server
-		Username string `json:"username" env:"DB_USERNAME" default:""`
+		Username string `json:"username" env:"DB_USERNAME" default:"" reload:"true"`
 		// The password to use when connecting to a DB server
-		Password string `json:"password" env:"DB_PASSWORD" default:""`
+		Password string `json:"password" env:"DB_PASSWORD" default:"" secret:"true" reload:"true"`
 		// The name of the database to use
 		DatabaseName string `json:"database-name" env:"DB_DB_NAME" default:""`
 		// Struct containing information for TCP connections
 		TCP DBTCP `json:"tcp"`
 		// The max time (in seconds) to wait for operations to complete
-		Timeout int `json:"timeout" env:"DB_TIMEOUT" default:"5"`
+		Timeout int `json:"timeout" env:"DB_TIMEOUT" default:"5" validate:"min=1,max=300"`
 		// The database dialect to use
-		Dialect string `json:"dialect" env:"DB_DIALECT" default:"mysql"`
+		Dialect string `json:"dialect" env:"DB_DIALECT" default:"mysql" validate:"oneof=mysql postgres"`
 	}
 
 	// Struct containing configuration settings for a database TCP connection
 	DBTCP struct {
 		// The host of a DB server
-		Host string `json:"host" env:"DB_TCP_HOST" default:"localhost"`
+		Host string `json:"host" env:"DB_TCP_HOST" default:"localhost" validate:"required"`
 		// The port of a DB server
-		Port int `json:"port" env:"DB_TCP_PORT" default:"3306"`
+		Port int `json:"port" env:"DB_TCP_PORT" default:"3306" validate:"min=1,max=65535"`
+	}
+
+	// Struct containing configuration settings for the currency pair registry
+	Pairs struct {
+		// Comma-separated currency pairs added to the registry at start up, if missing
+		Seed string `json:"seed" env:"PAIRS_SEED" default:"EURUSD,USDJPY,GBPUSD,USDCHF,AUDUSD,USDCAD,NZDUSD,EURGBP,EURJPY,EURCHF,EURAUD,EURCAD,GBPJPY,GBPCHF,AUDJPY,AUDNZD,CADJPY,CHFJPY,USDSEK,USDNOK,USDDKK,USDSGD,USDHKD,USDMXN,USDZAR,USDTRY,USDPLN,EURPLN,USDCNY,XAUUSD"`
+	}
+
+	// Struct containing configuration settings for the source of FX quotes
+	Provider struct {
+		// Source of FX quotes, "none" disables market data
+		Type string `json:"type" env:"PROVIDER_TYPE" default:"none" validate:"oneof=none replay http"`
+		// Comma-separated currency pairs received (ex: "EURUSD,USDJPY"), empty for every pair the source has
+		Pairs string `json:"pairs" env:"PROVIDER_PAIRS" default:""`
+		// Settings for the replay provider
+		Replay ProviderReplay `json:"replay"`
+		// Settings for the HTTP polling provider
+		HTTP ProviderHTTP `json:"http"`
+	}
+	// Struct containing configuration settings for a provider playing historical ticks from a file
+	ProviderReplay struct {
+		// Path of a CSV (".csv") or JSON Lines (".jsonl") file of ticks, with `time`, `pair`, `bid`, and `ask` fields
+		File string `json:"file" env:"PROVIDER_REPLAY_FILE" default:""`
+		// Playback speed, as a multiple of the recorded pace (ex: 60 plays an hour of ticks per minute), 0 plays
+		// every tick at once
+		Speed int `json:"speed" env:"PROVIDER_REPLAY_SPEED" default:"1" validate:"min=0"`
+		// Whether playback restarts once finished
+		// NOTE: Ignored when playing every tick at once
+		Loop bool `json:"loop" env:"PROVIDER_REPLAY_LOOP" default:"true"`
+		// Whether ticks are timestamped when played, rather than when recorded
+		Rebase bool `json:"rebase" env:"PROVIDER_REPLAY_REBASE" default:"true"`
+	}
+	// Struct containing configuration settings for a provider polling an HTTP endpoint
+	ProviderHTTP struct {
+		// URL returning a JSON array of the latest ticks, requested with the `pairs` parameter
+		URL string `json:"url" env:"PROVIDER_HTTP_URL" default:"http://127.0.0.1:6011/quotes"`
+		// Bearer token sent with requests
+		Token string `json:"token" env:"PROVIDER_HTTP_TOKEN" default:"" secret:"true"`
+		// How often (in seconds) the endpoint is polled
+		Interval int `json:"interval" env:"PROVIDER_HTTP_INTERVAL" default:"1" validate:"min=1,max=3600"`
+		// Timeout (in seconds) allowed for the endpoint to respond
+		Timeout int `json:"timeout" env:"PROVIDER_HTTP_TIMEOUT" default:"5" validate:"min=1,max=60"`
+	}
+
+	// Struct containing configuration settings for the snapshot of the latest FX quotes
+	Quotes struct {
+		// Age (in seconds) after which a quote is stale
+		StaleAfter int `json:"stale-after" env:"QUOTES_STALE_AFTER" default:"30" validate:"min=1" reload:"true"`
+		// Comma-separated ages (in seconds) after which quotes of a pair are stale, overriding `stale-after`
+		// (ex: "USDTRY=300,XAUUSD=60")
+		StaleAfterPairs string `json:"stale-after-pairs" env:"QUOTES_STALE_AFTER_PAIRS" default:"" reload:"true"`
+		// Whether the latest quote of each pair is saved within the database, and restored at start up
+		Persist bool `json:"persist" env:"QUOTES_PERSIST" default:"false"`
+		// Comma-separated currencies quotes of pairs that aren't quoted may be derived through, in order of
+		// preference (ex: "USD,EUR"), empty disables cross rates
+		Pivots string `json:"pivots" env:"QUOTES_PIVOTS" default:"USD" reload:"true"`
+		// Comma-separated symbols of indices quoted by the provider alongside currency pairs (ex: "VIX,DXY")
+		Indices string `json:"indices" env:"QUOTES_INDICES" default:"VIX" reload:"true"`
+	}
+
+	// Struct containing configuration settings for per-client rate limiting and daily quotas
+	RateLimit struct {
+		// Whether requests are rate limited
+		Enabled bool `json:"enabled" env:"RATE_LIMIT_ENABLED" default:"true" reload:"true"`
+		// Where limiter state is stored, "db" or "redis" share limits between replicas
+		Store string `json:"store" env:"RATE_LIMIT_STORE" default:"memory" validate:"oneof=memory db redis"`
+		// What requests are limited by: the API key (or client IP for anonymous requests), the client IP,
+		// the route, or a combination
+		KeyBy string `json:"key-by" env:"RATE_LIMIT_KEY_BY" default:"key" validate:"oneof=key ip route key+route ip+route" reload:"true"`
+		// Sustained number of requests allowed per minute
+		Rate int `json:"rate" env:"RATE_LIMIT_RATE" default:"120" validate:"min=1" reload:"true"`
+		// Number of requests allowed in a burst, above the sustained rate
+		Burst int `json:"burst" env:"RATE_LIMIT_BURST" default:"20" validate:"min=1" reload:"true"`
+		// Number of requests allowed per API key per UTC day, 0 for no quota
+		DailyQuota int `json:"daily-quota" env:"RATE_LIMIT_DAILY_QUOTA" default:"0" validate:"min=0" reload:"true"`
+		// Number of failed authentications allowed per client IP per minute, 0 for no limit
+		// NOTE: Checked before authenticating, so guessing credentials is limited and doesn't cost a lookup per guess
+		AuthFailures int `json:"auth-failures" env:"RATE_LIMIT_AUTH_FAILURES" default:"10" validate:"min=0" reload:"true"`
+		// Whether to read the client IP from the `X-Forwarded-For` header, only enable behind a trusted proxy
+		TrustProxy bool `json:"trust-proxy" env:"RATE_LIMIT_TRUST_PROXY" default:"false" reload:"true"`
+		// Number of trusted proxies in front of the server, each appending to `X-Forwarded-For`
+		// NOTE: The client IP is this many entries from the end of the header, earlier entries can be forged
+		TrustedProxies int `json:"trusted-proxies" env:"RATE_LIMIT_TRUSTED_PROXIES" default:"1" validate:"min=1,max=10" reload:"true"`
+		// Settings for the Redis-compatible store
+		Redis RateLimitRedis `json:"redis"`
+	}
+
+	// Struct containing configuration settings for a Redis-compatible rate limit store
+	RateLimitRedis struct {
+		// Address of the server
+		Address string `json:"address" env:"RATE_LIMIT_REDIS_ADDRESS" default:"127.0.0.1:6379"`
+		// Password used to authenticate
+		Password string `json:"password" env:"RATE_LIMIT_REDIS_PASSWORD" default:"" secret:"true"`
+		// Database number to use
+		DB int `json:"db" env:"RATE_LIMIT_REDIS_DB" default:"0" validate:"min=0"`
+		// Prefix of all keys
+		Prefix string `json:"prefix" env:"RATE_LIMIT_REDIS_PREFIX" default:"forex-clock:"`
+	}
+
+	// Struct containing configuration settings for resolving secret values
+	Secrets struct {
+		// The secret provider to use
+		Provider string `json:"provider" env:"SECRETS_PROVIDER" default:"none" validate:"oneof=none file vault"`
+		// Directory containing secret files, used by the file provider
+		Dir string `json:"dir" env:"SECRETS_DIR" default:"/run/secrets"`
+		// How often (in seconds) to re-read secrets so rotated values are picked up, 0 to disable
+		RefreshInterval int `json:"refresh-interval" env:"SECRETS_REFRESH_INTERVAL" default:"0" validate:"min=0"`
+		// Settings for the Vault-compatible provider
+		Vault SecretsVault `json:"vault"`
+	}
+
+	// Struct containing configuration settings for a HashiCorp Vault-compatible secret provider
+	SecretsVault struct {
+		// Base address of the server
+		Address string `json:"address" env:"VAULT_ADDR" default:"http://127.0.0.1:8200"`
+		// Token used to authenticate
+		Token string `json:"token" env:"VAULT_TOKEN" default:"" secret:"true"`
+		// Mount path of the key/value (version 2) secrets engine
+		Mount string `json:"mount" env:"VAULT_MOUNT" default:"secret"`
+		// Path of the secret within the mount
+		Path string `json:"path" env:"VAULT_PATH" default:"forex-clock"`
+		// The max time (in seconds) to wait for requests to complete
+		Timeout int `json:"timeout" env:"VAULT_TIMEOUT" default:"5" validate:"min=1,max=60"`
 	}
 
 	// Struct containing configuration settings for application logging
 	Log struct {
 		// The formatter to use
-		Formatter string `json:"formatter" env:"LOG_FORMATTER" default:"text"`
+		Formatter string `json:"formatter" env:"LOG_FORMATTER" default:"text" validate:"oneof=text json" reload:"true"`
 		// The log level to use
-		Level string `json:"level" env:"LOG_LEVEL" default:"info"`
+		Level string `json:"level" env:"LOG_LEVEL" default:"info" validate:"oneof=debug info warn error fatal panic" reload:"true"`
+	}
+
+	// Struct containing configuration settings for trading sessions
+	Sessions struct {
+		// Trading session definitions, defaults are used when empty
+		// NOTE: Only settable via a JSON configuration file
+		Definitions []SessionDefinition `json:"definitions" reload:"true"`
+		// Comma-separated market holidays, as `MM-DD` (yearly) or `YYYY-MM-DD`, optionally
+		// prefixed with a session ID to close a single session (ex: "london:12-26")
+		Holidays string `json:"holidays" env:"SESSIONS_HOLIDAYS" default:"01-01,12-25" reload:"true"`
+		// Weekly market open, as a weekday and time within the weekly time zone
+		WeekOpen string `json:"week-open" env:"SESSIONS_WEEK_OPEN" default:"Sun 17:00" reload:"true"`
+		// Weekly market close, as a weekday and time within the weekly time zone
+		WeekClose string `json:"week-close" env:"SESSIONS_WEEK_CLOSE" default:"Fri 17:00" reload:"true"`
+		// IANA time zone of the weekly market open and close
+		TimeZone string `json:"time-zone" env:"SESSIONS_TIME_ZONE" default:"America/New_York" validate:"required" reload:"true"`
+	}
+
+	// Struct containing the definition of a single trading session
+	SessionDefinition struct {
+		// Unique identifier of the session (ex: "london")
+		ID string `json:"id"`
+		// Display name of the session (ex: "London")
+		Name string `json:"name"`
+		// IANA time zone of the session's city (ex: "Europe/London")
+		TimeZone string `json:"time-zone"`
+		// Local opening time of the session (ex: "08:00")
+		Open string `json:"open"`
+		// Local closing time of the session (ex: "17:00")
+		Close string `json:"close"`
+	}
+
+	// Struct containing configuration settings for per-session volatility and range statistics
+	Stats struct {
+		// Number of days statistics are computed over, unless requested otherwise
+		Lookback int `json:"lookback" env:"STATS_LOOKBACK" default:"30" validate:"min=1,max=365" reload:"true"`
+		// How long (in seconds) computed statistics are cached
+		CacheTTL int `json:"cache-ttl" env:"STATS_CACHE_TTL" default:"300" validate:"min=0" reload:"true"`
+	}
+
+	// Struct containing configuration settings for streams of market events
+	Stream struct {
+		// How often (in seconds) heartbeats are sent to idle clients
+		HeartbeatInterval int `json:"heartbeat-interval" env:"STREAM_HEARTBEAT_INTERVAL" default:"30" validate:"min=1,max=300" reload:"true"`
+		// Number of events buffered per client, clients falling further behind are disconnected
+		BufferSize int `json:"buffer-size" env:"STREAM_BUFFER_SIZE" default:"32" validate:"min=1,max=1024"`
+		// Number of recent events kept to resume server-sent event streams from their `Last-Event-ID`
+		ReplaySize int `json:"replay-size" env:"STREAM_REPLAY_SIZE" default:"100" validate:"min=0,max=1024" reload:"true"`
+		// Timeout (in seconds) allowed for writing a message to a client
+		WriteTimeout int `json:"write-timeout" env:"STREAM_WRITE_TIMEOUT" default:"10" validate:"min=1,max=300" reload:"true"`
+	}
+
+	// Struct containing configuration settings for the currency strength meter
+	Strength struct {
+		// How often (in seconds) strength is measured from the latest quotes
+		Interval int `json:"interval" env:"STRENGTH_INTERVAL" default:"10" validate:"min=1,max=300" reload:"true"`
+		// Length (in minutes) of the rolling intraday window
+		Intraday int `json:"intraday" env:"STRENGTH_INTRADAY" default:"240" validate:"min=5,max=1440" reload:"true"`
+	}
+
+	// Struct containing configuration settings for webhook deliveries
+	Webhooks struct {
+		// Whether market events are delivered to webhooks
+		Enabled bool `json:"enabled" env:"WEBHOOKS_ENABLED" default:"true"`
+		// Number of attempts before a delivery is moved to the dead-letter queue
+		MaxAttempts int `json:"max-attempts" env:"WEBHOOKS_MAX_ATTEMPTS" default:"8" validate:"min=1,max=50" reload:"true"`
+		// Delay (in seconds) before the first retry, doubled for every following retry
+		BackoffBase int `json:"backoff-base" env:"WEBHOOKS_BACKOFF_BASE" default:"10" validate:"min=1" reload:"true"`
+		// Longest delay (in seconds) between retries
+		BackoffMax int `json:"backoff-max" env:"WEBHOOKS_BACKOFF_MAX" default:"3600" validate:"min=1" reload:"true"`
+		// Timeout (in seconds) allowed for receivers to respond
+		Timeout int `json:"timeout" env:"WEBHOOKS_TIMEOUT" default:"10" validate:"min=1,max=60" reload:"true"`
+		// How often (in seconds) queued deliveries are checked for
+		PollInterval int `json:"poll-interval" env:"WEBHOOKS_POLL_INTERVAL" default:"5" validate:"min=1,max=300"`
+		// Number of deliveries attempted at a time
+		BatchSize int `json:"batch-size" env:"WEBHOOKS_BATCH_SIZE" default:"50" validate:"min=1,max=1000" reload:"true"`
+		// Whether webhook deliveries and alert notifications may be sent to private, loopback, and link-local addresses
+		PrivateAddresses bool `json:"private-addresses" env:"WEBHOOKS_PRIVATE_ADDRESSES" default:"false" reload:"true"`
 	}
 
 	// Struct containing configuration settings for the application server
 	Server struct {
 		// Port the server should listen on
-		Port int `json:"port" env:"SERVER_PORT" default:"6010"`
+		Port int `json:"port" env:"SERVER_PORT" default:"6010" validate:"min=1,max=65535"`
 		// Various timeouts for the server
 		Timeouts struct {
 			// Timeout (in seconds) allowed for server read operations
-			Read int `json:"read" env:"SERVER_READ_TIMEOUT" default:"30"`
+			Read int `json:"read" env:"SERVER_READ_TIMEOUT" default:"30" validate:"min=1,max=3600"`
 			// Timeout (in seconds) allowed for server write operations
-			Write int `json:"write" env:"SERVER_WRITE_TIMEOUT" default:"30"`
+			Write int `json:"write" env:"SERVER_WRITE_TIMEOUT" default:"30" validate:"min=1,max=3600"`
 			// Timeout (in seconds) allowed for server to shutdown
-			ShutDown int `json:"shutdown" env:"SERVER_SHUTDOWN_TIMEOUT" default:"5"`
+			ShutDown int `json:"shutdown" env:"SERVER_SHUTDOWN_TIMEOUT" default:"5" validate:"min=0,max=300"`
 		} `json:"timeouts"`
 	}
 
@@ -91,27 +425,75 @@ type (
 		/* Top-level configuration */
 
 		// The environment the application is running in
-		Environment string `json:"environment" env:"ENVIRONMENT" default:"dev"`
+		Environment string `json:"environment" env:"ENVIRONMENT" default:"dev" validate:"oneof=dev prod test"`
 
 		// Name of the application
-		Name string `json:"name" env:"NAME" default:"forex-clock"`
+		Name string `json:"name" env:"NAME" default:"forex-clock" validate:"required"`
 
 		// The current release version of the application
 		ReleaseVersion string `json:"release-version" env:"RELEASE_VERSION" default:""`
 
 		// Start time of the application
-		StartTime time.Time
+		StartTime time.Time `json:"-"`
 
 		/* Component-specific configuration */
 
+		// Settings for price alerts
+		Alerts Alerts `json:"alerts"`
+
+		// Settings for authentication
+		Auth Auth `json:"auth"`
+
+		// Settings for the in-process cache
+		Cache Cache `json:"cache"`
+
+		// Settings for tick persistence and candle aggregation
+		Candles Candles `json:"candles"`
+
+		// Settings for response compression
+		Compression Compression `json:"compression"`
+
+		// Settings for cross-origin resource sharing
+		CORS CORS `json:"cors"`
+
 		// Settings for the database
 		DB DB `json:"db"`
 
 		// Settings for the logger
 		Log Log `json:"log"`
 
+		// Settings for the currency pair registry
+		Pairs Pairs `json:"pairs"`
+
+		// Settings for the source of FX quotes
+		Provider Provider `json:"provider"`
+
+		// Settings for the snapshot of the latest FX quotes
+		Quotes Quotes `json:"quotes"`
+
+		// Settings for rate limiting
+		RateLimit RateLimit `json:"rate-limit"`
+
+		// Settings for resolving secret values
+		Secrets Secrets `json:"secrets"`
+
 		// Settings for the server
 		Server Server `json:"server"`
+
+		// Settings for trading sessions
+		Sessions Sessions `json:"sessions"`
+
+		// Settings for per-session statistics
+		Stats Stats `json:"stats"`
+
+		// Settings for streams of market events
+		Stream Stream `json:"stream"`
+
+		// Settings for the currency strength meter
+		Strength Strength `json:"strength"`
+
+		// Settings for webhook deliveries
+		Webhooks Webhooks `json:"webhooks"`
 	}
 )
 
@@ -119,6 +501,8 @@ type (
 func (c *Config) setLoggerSettings() {
 	// Set logging level based on config value
 	switch c.Log.Level {
+	case "debug":
+		log.SetLevel(log.DebugLevel)
 	case "error":
 		log.SetLevel(log.ErrorLevel)
 	case "fatal":
@@ -146,26 +530,84 @@ func (c *Config) setLoggerSettings() {
 // Init creates a new config instance and initializes it
 func Init() {
 	// Create new config instance
-	config = &Config{}
-
-	// Used configurator to set up config
-	configurator.InitializeConfig(config)
+	c := load()
 
 	// Set logging settings
-	config.setLoggerSettings()
+	c.setLoggerSettings()
 
 	// Set start time
-	config.StartTime = time.Now()
+	c.StartTime = time.Now()
+
+	configMutex.Lock()
+	config = c
+	configMutex.Unlock()
 }
 
 // GetInstance returns the initialized config instance
+// NOTE: The instance may be replaced during a reload, so callers should avoid
+// holding on to the returned pointer for long-lived settings
 func GetInstance() *Config {
+	configMutex.RLock()
+	c := config
+	configMutex.RUnlock()
+
 	// Check if config has been initialized yet
-	if config == nil {
+	if c == nil {
 		Init()
+		return GetInstance()
+	}
+
+	return c
+}
+
+// load creates a new config instance populated from all configurator sources,
+// value files, and the configured secret provider
+func load() *Config {
+	c := &Config{}
+
+	// Used configurator to set up config
+	configurator.InitializeConfig(c)
+
+	// Resolve secret values
+	resolveSecrets(c)
+
+	return c
+}
+
+// ConfigFile returns the path of the outside configuration file in use, if any
+func ConfigFile() string {
+	if path := os.Getenv(strings.ToUpper(ConfigLocation)); path != "" {
+		return path
+	}
+
+	return os.Getenv(ConfigLocation)
+}
+
+// SetConfigFile sets the path of the outside configuration file to use the next time configuration is loaded
+// NOTE: Sets both the upper-cased and as-declared environment variables to match how they may be read
+func SetConfigFile(path string) {
+	os.Setenv(strings.ToUpper(ConfigLocation), path)
+	os.Setenv(ConfigLocation, path)
+}
+
+// SetEnv sets a prefixed environment variable (ex: "ENVIRONMENT"), overriding the value
+// used the next time configuration is loaded
+// NOTE: Sets both the upper-cased and as-declared names to match how they may be read
+func SetEnv(name, value string) {
+	os.Setenv(strings.ToUpper(EnvPrefix+name), value)
+	os.Setenv(EnvPrefix+name, value)
+}
+
+// SplitList splits a comma-separated configuration value into its trimmed, non-empty parts
+func SplitList(s string) []string {
+	list := make([]string, 0)
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
 	}
 
-	return config
+	return list
 }
 
 func init() {
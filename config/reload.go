@@ -0,0 +1,207 @@
+// config package handles setting up, parsing, and storing all configuration settings for the application.
+// reload contains hot reloading of runtime-safe configuration values. Values that are safe to change while
+// the application is running are marked with a `reload:"true"` tag
+package config
+
+import (
+	// Standard lib
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+	fsnotify "gopkg.in/fsnotify.v1"
+)
+
+const (
+	// Struct tag marking a value as safe to change at runtime
+	TagReload = "reload"
+
+	// Time to wait for further file events before reloading
+	// NOTE: Editors commonly write a file in several operations
+	WatchDebounce = 250 * time.Millisecond
+)
+
+type (
+	// ReloadResult is a struct describing the outcome of a configuration reload
+	ReloadResult struct {
+		Applied  []string // Keys that changed and were applied
+		Rejected []string // Keys that changed but require a restart to take effect
+	}
+	// Subscriber is a function called after configuration has been reloaded
+	Subscriber func(prev, next *Config, changed []string)
+	// configField is a struct pairing a configuration struct field with its value
+	configField struct {
+		field reflect.StructField
+		value reflect.Value
+	}
+)
+
+var (
+	// Functions to notify after a reload
+	subscribers      = map[int]Subscriber{}
+	subscriberID     int
+	subscribersMutex sync.Mutex
+
+	// Mutex ensuring only one reload runs at a time
+	reloadMutex sync.Mutex
+)
+
+// Subscribe registers a function to be called after configuration has been reloaded
+// and returns a function that removes the subscription
+func Subscribe(fn Subscriber) func() {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+
+	subscriberID++
+	id := subscriberID
+	subscribers[id] = fn
+
+	return func() {
+		subscribersMutex.Lock()
+		delete(subscribers, id)
+		subscribersMutex.Unlock()
+	}
+}
+
+// Reload re-reads configuration from all sources and applies any changed values that are safe
+// to change at runtime. Changes to other values are rejected with a warning.
+// NOTE: If the new configuration is invalid, nothing is applied and an error is returned
+func Reload() (*ReloadResult, error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	// Load and validate new configuration
+	loaded := load()
+	if err := loaded.Validate(); err != nil {
+		log.WithError(err).Error("Configuration reload failed, keeping current configuration")
+		return nil, err
+	}
+
+	// Copy the current configuration, applying reloadable changes to the copy
+	old := GetInstance()
+	next := *old
+	result := &ReloadResult{Applied: []string{}, Rejected: []string{}}
+
+	oldFields, nextFields, loadedFields := fieldsOf(old), fieldsOf(&next), fieldsOf(loaded)
+	for _, key := range sortedKeys(loadedFields) {
+		lf, of := loadedFields[key], oldFields[key]
+		if reflect.DeepEqual(lf.value.Interface(), of.value.Interface()) {
+			continue
+		}
+
+		if lf.field.Tag.Get(TagReload) != "true" {
+			result.Rejected = append(result.Rejected, key)
+			log.WithField("key", key).Warn("Configuration change requires a restart and was not applied")
+			continue
+		}
+
+		nextFields[key].value.Set(lf.value)
+		result.Applied = append(result.Applied, key)
+	}
+
+	if len(result.Applied) == 0 {
+		log.Debug("Configuration reloaded, no runtime changes detected")
+		return result, nil
+	}
+
+	// Swap in the new configuration and apply logger settings
+	configMutex.Lock()
+	config = &next
+	configMutex.Unlock()
+	next.setLoggerSettings()
+
+	// Audit log of changed keys
+	// NOTE: Values are intentionally not logged, as they may contain secrets
+	log.WithField("keys", result.Applied).Info("Configuration reloaded")
+
+	notify(old, &next, result.Applied)
+
+	return result, nil
+}
+
+// Watch reloads configuration whenever the outside configuration file changes,
+// until the stop channel is closed
+// NOTE: Returns immediately if no configuration file is in use
+func Watch(stop <-chan struct{}) error {
+	path := ConfigFile()
+	if path == "" {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// Watch the directory rather than the file, as editors and secret mounts often replace files
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			return nil
+		case event := <-watcher.Events:
+			if filepath.Clean(event.Name) == filepath.Clean(path) {
+				debounce = time.After(WatchDebounce)
+			}
+		case err := <-watcher.Errors:
+			log.WithError(err).Warn("Error watching configuration file")
+		case <-debounce:
+			debounce = nil
+			log.WithField("file", path).Info("Configuration file changed, reloading")
+			Reload()
+		}
+	}
+}
+
+// String returns a single-line summary of a reload result
+func (r *ReloadResult) String() string {
+	return fmt.Sprintf("applied: %v, rejected: %v", r.Applied, r.Rejected)
+}
+
+// notify calls all subscribers with the old and new configuration
+func notify(prev, next *Config, changed []string) {
+	subscribersMutex.Lock()
+	fns := make([]Subscriber, 0, len(subscribers))
+	for _, fn := range subscribers {
+		fns = append(fns, fn)
+	}
+	subscribersMutex.Unlock()
+
+	for _, fn := range fns {
+		fn(prev, next, changed)
+	}
+}
+
+// fieldsOf returns all configuration values keyed by their dotted keys,
+// skipping values excluded from JSON (ex: start time)
+func fieldsOf(c *Config) map[string]configField {
+	fields := map[string]configField{}
+	walkFields(reflect.ValueOf(c).Elem(), "", func(key string, f reflect.StructField, v reflect.Value) {
+		if f.Tag.Get("json") != "-" {
+			fields[key] = configField{field: f, value: v}
+		}
+	})
+
+	return fields
+}
+
+// sortedKeys returns the keys of a field map in a stable order
+func sortedKeys(fields map[string]configField) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,101 @@
+// Tests the reload.go file
+package config
+
+import (
+	// Standard lib
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("reload.go", func() {
+	var (
+		// Temporary directory holding a configuration file
+		dir string
+		// Path to the configuration file
+		path string
+	)
+
+	BeforeEach(func() {
+		// Create temporary configuration file location
+		var err error
+		dir, err = ioutil.TempDir("", "forex-clock-config")
+		Expect(err).To(Not(HaveOccurred()))
+		path = filepath.Join(dir, "config.json")
+
+		// Point configurator at the file and start from a fresh config
+		SetConfigFile(path)
+		Expect(ioutil.WriteFile(path, []byte(`{}`), 0644)).To(Succeed())
+		Init()
+	})
+
+	AfterEach(func() {
+		// Reset configuration
+		SetConfigFile("")
+		os.RemoveAll(dir)
+		Init()
+	})
+
+	Describe("`Reload` method", func() {
+		Context("When runtime-safe values change", func() {
+			It("Applies them and notifies subscribers", func() {
+				// Subscribe to changes
+				var changed []string
+				unsubscribe := Subscribe(func(prev, next *Config, keys []string) {
+					changed = keys
+				})
+				defer unsubscribe()
+
+				// Change configuration file
+				Expect(ioutil.WriteFile(path, []byte(`{"log":{"level":"warn"},"cors":{"allowed-origins":"https://example.com"}}`), 0644)).To(Succeed())
+
+				// Call method
+				result, err := Reload()
+
+				// Verify return values
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(result.Applied).To(ConsistOf("cors.allowed-origins", "log.level"))
+				Expect(result.Rejected).To(BeEmpty())
+
+				// Verify new configuration and notifications
+				Expect(GetInstance().Log.Level).To(Equal("warn"))
+				Expect(GetInstance().CORS.AllowedOrigins).To(Equal("https://example.com"))
+				Expect(changed).To(Equal(result.Applied))
+			})
+		})
+
+		Context("When values requiring a restart change", func() {
+			It("Rejects them", func() {
+				// Change configuration file
+				Expect(ioutil.WriteFile(path, []byte(`{"server":{"port":7010}}`), 0644)).To(Succeed())
+
+				// Call method
+				result, err := Reload()
+
+				// Verify return values
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(result.Applied).To(BeEmpty())
+				Expect(result.Rejected).To(ConsistOf("server.port"))
+				Expect(GetInstance().Server.Port).To(Equal(6010))
+			})
+		})
+
+		Context("When the new configuration is invalid", func() {
+			It("Keeps the current configuration and returns an error", func() {
+				// Change configuration file
+				Expect(ioutil.WriteFile(path, []byte(`{"log":{"level":"verbose"}}`), 0644)).To(Succeed())
+
+				// Call method
+				_, err := Reload()
+
+				// Verify return value
+				Expect(err).To(HaveOccurred())
+				Expect(GetInstance().Log.Level).To(Equal("info"))
+			})
+		})
+	})
+})
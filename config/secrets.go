@@ -0,0 +1,246 @@
+// config package handles setting up, parsing, and storing all configuration settings for the application.
+// secrets contains resolution of secret configuration values from files and external secret providers
+package config
+
+import (
+	// Standard lib
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Suffix of environment variables containing the path to a file holding a value
+	// Ex: `forex-clock_DB_PASSWORD_FILE=/run/secrets/db-password`
+	FileEnvSuffix = "_FILE"
+
+	// Supported secret providers
+	SecretsProviderNone  = "none"
+	SecretsProviderFile  = "file"
+	SecretsProviderVault = "vault"
+
+	// Header used to authenticate with a Vault-compatible server
+	VaultTokenHeader = "X-Vault-Token"
+)
+
+type (
+	// SecretProvider is an interface that all secret providers must fulfill
+	// Secrets are looked up by their dotted configuration key (ex: "db.password")
+	SecretProvider interface {
+		// Get returns the value of a secret, and a boolean indicating if it was found
+		Get(key string) (string, bool, error)
+		// String returns the name of the provider - used in logging
+		String() string
+	}
+	// FileSecretProvider is a struct representing a provider that reads secrets from files
+	// within a directory, named after each secret's key (ex: "/run/secrets/db.password")
+	FileSecretProvider struct {
+		Dir string // Directory containing secret files
+	}
+	// VaultSecretProvider is a struct representing a provider that reads secrets from a single
+	// key/value (version 2) secret of a HashiCorp Vault-compatible HTTP API
+	VaultSecretProvider struct {
+		Address string       // Base address of the server (ex: "http://127.0.0.1:8200")
+		Token   string       // Token used to authenticate
+		Mount   string       // Mount path of the key/value secrets engine
+		Path    string       // Path of the secret within the mount
+		Client  *http.Client // HTTP client used for requests
+	}
+	// vaultResponse is a struct representing the relevant parts of a key/value (version 2) read response
+	vaultResponse struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+)
+
+// NewSecretProvider creates and returns the secret provider configured within a config instance
+// NOTE: Returns nil if no provider is configured
+func NewSecretProvider(c *Config) SecretProvider {
+	switch c.Secrets.Provider {
+	case SecretsProviderFile:
+		return &FileSecretProvider{Dir: c.Secrets.Dir}
+	case SecretsProviderVault:
+		return &VaultSecretProvider{
+			Address: c.Secrets.Vault.Address,
+			Token:   c.Secrets.Vault.Token,
+			Mount:   c.Secrets.Vault.Mount,
+			Path:    c.Secrets.Vault.Path,
+			Client:  &http.Client{Timeout: time.Duration(c.Secrets.Vault.Timeout) * time.Second},
+		}
+	}
+
+	return nil
+}
+
+// Get returns the contents of the file named after a secret's key
+func (p *FileSecretProvider) Get(key string) (string, bool, error) {
+	b, err := ioutil.ReadFile(filepath.Join(p.Dir, key))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return strings.TrimSpace(string(b)), true, nil
+}
+
+// String returns the name of the provider
+func (p *FileSecretProvider) String() string { return SecretsProviderFile }
+
+// Get returns the value of a secret's key within the configured key/value secret
+func (p *VaultSecretProvider) Get(key string) (string, bool, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(p.Address, "/"), p.Mount, p.Path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set(VaultTokenHeader, p.Token)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("Unexpected status code from secret provider: %d", resp.StatusCode)
+	}
+
+	body := &vaultResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(body); err != nil {
+		return "", false, err
+	}
+
+	value, ok := body.Data.Data[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", false, errors.New("Secret value is not a string: " + key)
+	}
+
+	return s, true, nil
+}
+
+// String returns the name of the provider
+func (p *VaultSecretProvider) String() string { return SecretsProviderVault }
+
+// applyFileEnv sets string values from files referenced by `_FILE`-suffixed environment variables,
+// which take priority over all other sources
+func applyFileEnv(c *Config) error {
+	errs := []string{}
+	walkFields(reflect.ValueOf(c).Elem(), "", func(key string, field reflect.StructField, v reflect.Value) {
+		env, ok := field.Tag.Lookup("env")
+		if !ok || v.Kind() != reflect.String {
+			return
+		}
+
+		path := lookupEnv(env + FileEnvSuffix)
+		if path == "" {
+			return
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, key+": "+err.Error())
+			return
+		}
+
+		v.SetString(strings.TrimSpace(string(b)))
+	})
+
+	if len(errs) > 0 {
+		return errors.New("Unable to read value file(s): " + strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// applySecrets sets secret values from a secret provider
+// NOTE: Values not found within the provider are left as-is. Settings of the provider itself are skipped
+func applySecrets(c *Config, p SecretProvider) error {
+	errs := []string{}
+	walkFields(reflect.ValueOf(c).Elem(), "", func(key string, field reflect.StructField, v reflect.Value) {
+		if field.Tag.Get(TagSecret) != "true" || v.Kind() != reflect.String || strings.HasPrefix(key, "secrets.") {
+			return
+		}
+
+		value, ok, err := p.Get(key)
+		if err != nil {
+			errs = append(errs, key+": "+err.Error())
+			return
+		}
+
+		if ok {
+			v.SetString(value)
+		}
+	})
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Unable to read secret(s) from %s provider: %s", p, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// resolveSecrets applies values from files and the configured secret provider to a config instance
+func resolveSecrets(c *Config) {
+	if err := applyFileEnv(c); err != nil {
+		log.WithError(err).Error("Error reading configuration values from files")
+	}
+
+	if p := NewSecretProvider(c); p != nil {
+		if err := applySecrets(c, p); err != nil {
+			log.WithError(err).Error("Error reading configuration values from secret provider")
+		}
+	}
+}
+
+// RefreshSecrets periodically reloads configuration so rotated secrets are picked up,
+// until the stop channel is closed
+// NOTE: Returns immediately if no refresh interval is configured
+func RefreshSecrets(stop <-chan struct{}) {
+	interval := GetInstance().Secrets.RefreshInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			Reload()
+		}
+	}
+}
+
+// lookupEnv returns the value of a prefixed environment variable
+// NOTE: Checks both the upper-cased and as-declared names to match how configurator may read them
+func lookupEnv(name string) string {
+	if v := os.Getenv(strings.ToUpper(EnvPrefix + name)); v != "" {
+		return v
+	}
+
+	return os.Getenv(EnvPrefix + name)
+}
@@ -0,0 +1,150 @@
+// Tests the secrets.go file
+package config
+
+import (
+	// Standard lib
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("secrets.go", func() {
+	var (
+		// Temporary directory holding secret files
+		dir string
+		// Config instance to test
+		c *Config
+	)
+
+	BeforeEach(func() {
+		// Create temporary directory
+		var err error
+		dir, err = ioutil.TempDir("", "forex-clock-secrets")
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Copy the default config so tests don't leak values
+		d := *GetInstance()
+		c = &d
+	})
+
+	AfterEach(func() {
+		// Remove temporary directory
+		os.RemoveAll(dir)
+	})
+
+	Describe("`applyFileEnv` method", func() {
+		var (
+			// Environment variable pointing at a value file
+			env string
+		)
+
+		BeforeEach(func() {
+			// Write value file and point environment variable at it
+			path := filepath.Join(dir, "db-password")
+			Expect(ioutil.WriteFile(path, []byte("from-file\n"), 0600)).To(Succeed())
+			env = EnvPrefix + "DB_PASSWORD" + FileEnvSuffix
+			os.Setenv(env, path)
+		})
+
+		AfterEach(func() {
+			// Reset environment
+			os.Unsetenv(env)
+		})
+
+		It("Sets values from files referenced by environment variables", func() {
+			// Call method
+			err := applyFileEnv(c)
+
+			// Verify results
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(c.DB.Password).To(Equal("from-file"))
+		})
+	})
+
+	Describe("`FileSecretProvider` struct", func() {
+		It("Reads secrets from files named after their keys", func() {
+			// Write secret file
+			Expect(ioutil.WriteFile(filepath.Join(dir, "db.password"), []byte("s3cret"), 0600)).To(Succeed())
+
+			// Call method
+			err := applySecrets(c, &FileSecretProvider{Dir: dir})
+
+			// Verify results
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(c.DB.Password).To(Equal("s3cret"))
+		})
+	})
+
+	Describe("`VaultSecretProvider` struct", func() {
+		var (
+			// Local stand-in for a Vault server
+			ts *httptest.Server
+			// Provider to test
+			p *VaultSecretProvider
+		)
+
+		BeforeEach(func() {
+			// Start local server
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Header.Get(VaultTokenHeader) != "root" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				if req.URL.Path != "/v1/secret/data/forex-clock" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(`{"data":{"data":{"db.password":"from-vault"}}}`))
+			}))
+
+			p = &VaultSecretProvider{Address: ts.URL, Token: "root", Mount: "secret", Path: "forex-clock", Client: ts.Client()}
+		})
+
+		AfterEach(func() {
+			// Stop local server
+			ts.Close()
+		})
+
+		Context("When the secret exists", func() {
+			It("Sets its value", func() {
+				// Call method
+				err := applySecrets(c, p)
+
+				// Verify results
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(c.DB.Password).To(Equal("from-vault"))
+			})
+		})
+
+		Context("When the token is invalid", func() {
+			It("Returns an error", func() {
+				// Set invalid token
+				p.Token = "invalid"
+
+				// Call method
+				_, _, err := p.Get("db.password")
+
+				// Verify return value
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("`String` method", func() {
+		It("Redacts secret values", func() {
+			// Set secret values
+			c.DB.Password = "hunter2"
+			c.Secrets.Vault.Token = "root-token"
+
+			// Verify return value
+			Expect(c.String()).To(Not(ContainSubstring("hunter2")))
+			Expect(c.String()).To(Not(ContainSubstring("root-token")))
+		})
+	})
+})
@@ -0,0 +1,211 @@
+// config package handles setting up, parsing, and storing all configuration settings for the application.
+// validate contains declarative validation and redaction of configuration values
+package config
+
+import (
+	// Standard lib
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Struct tags used for validation and redaction
+	// Ex: `validate:"min=1,max=65535"` or `secret:"true"`
+	TagValidate = "validate"
+	TagSecret   = "secret"
+
+	// Value used in place of secrets when configuration is output
+	RedactedValue = "[REDACTED]"
+)
+
+type (
+	// ValidationError is a struct representing a single invalid configuration value
+	ValidationError struct {
+		Key     string // Dotted path of the configuration key (ex: "server.timeouts.read")
+		Value   string // The offending value as a string
+		Message string // Human-readable description of the problem
+	}
+	// ValidationErrors is a slice of validation errors that together satisfy the error interface
+	ValidationErrors []*ValidationError
+)
+
+// Error returns a human-readable representation of a single validation error
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s (got %q)", e.Key, e.Message, e.Value)
+}
+
+// Error returns all validation errors, one per line
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+
+	return fmt.Sprintf("%d invalid configuration value(s):\n  %s", len(e), strings.Join(lines, "\n  "))
+}
+
+// Validate checks every configuration value against the rules declared in its `validate` tag
+// and returns all failures at once, or nil if the configuration is valid
+func (c *Config) Validate() error {
+	errs := ValidationErrors{}
+	walkFields(reflect.ValueOf(c).Elem(), "", func(key string, field reflect.StructField, v reflect.Value) {
+		if rules, ok := field.Tag.Lookup(TagValidate); ok {
+			errs = append(errs, validateValue(key, rules, v, field.Tag.Get(TagSecret) == "true")...)
+		}
+	})
+	errs = append(errs, c.validateDependent()...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// validateDependent checks values whose rules depend on other values, which can't be declared within tags
+func (c *Config) validateDependent() ValidationErrors {
+	errs := ValidationErrors{}
+	if (c.Auth.JWT.JWKSURL != "" || c.Auth.JWT.JWKSFile != "") && c.Auth.JWT.Issuer == "" {
+		errs = append(errs, &ValidationError{
+			Key:     "auth.jwt.issuer",
+			Value:   c.Auth.JWT.Issuer,
+			Message: "is required when auth.jwt.jwks-url or auth.jwt.jwks-file is set",
+		})
+	}
+
+	return errs
+}
+
+// Redacted returns a copy of the configuration with all secret values replaced,
+// making it safe to log or output
+func (c *Config) Redacted() *Config {
+	r := *c
+	walkFields(reflect.ValueOf(&r).Elem(), "", func(key string, field reflect.StructField, v reflect.Value) {
+		if field.Tag.Get(TagSecret) == "true" && v.Kind() == reflect.String && v.String() != "" {
+			v.SetString(RedactedValue)
+		}
+	})
+
+	return &r
+}
+
+// String returns the configuration as JSON with all secret values redacted, making
+// it safe to use within log fields
+func (c *Config) String() string {
+	out, _ := json.Marshal(c.Redacted())
+
+	return string(out)
+}
+
+// walkFields recursively visits every exported, non-struct field of a struct value,
+// passing along a dotted key formed from the fields' `json` tags
+func walkFields(v reflect.Value, prefix string, fn func(string, reflect.StructField, reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
+
+		key := keyName(prefix, field)
+
+		// Recurse into nested configuration structs
+		if field.Type.Kind() == reflect.Struct && field.Type.PkgPath() != "time" {
+			walkFields(v.Field(i), key, fn)
+			continue
+		}
+
+		fn(key, field, v.Field(i))
+	}
+}
+
+// keyName forms the dotted key of a field based on its `json` tag, falling back to the field name
+func keyName(prefix string, field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		name = field.Name
+	}
+
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+// validateValue applies a comma-separated list of rules to a single value
+// Supported rules: `required`, `min=<n>`, `max=<n>`, and `oneof=<a b c>`
+// NOTE: Secret values are redacted within any returned errors
+func validateValue(key, rules string, v reflect.Value, secret bool) ValidationErrors {
+	errs := ValidationErrors{}
+	value := fmt.Sprintf("%v", v.Interface())
+	shown := value
+	if secret {
+		shown = RedactedValue
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+
+		var msg string
+		switch name {
+		case "required":
+			if v.IsZero() {
+				msg = "is required"
+			}
+		case "min", "max":
+			limit, _ := strconv.ParseFloat(arg, 64)
+			n, ok := numeric(v)
+			switch {
+			case !ok:
+				msg = "is not a number"
+			case name == "min" && n < limit:
+				msg = "must be at least " + arg
+			case name == "max" && n > limit:
+				msg = "must be at most " + arg
+			}
+		case "oneof":
+			allowed := strings.Fields(arg)
+			found := false
+			for _, a := range allowed {
+				if a == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				msg = "must be one of: " + strings.Join(allowed, ", ")
+			}
+		default:
+			msg = "has unknown validation rule " + name
+		}
+
+		if msg != "" {
+			errs = append(errs, &ValidationError{Key: key, Value: shown, Message: msg})
+		}
+	}
+
+	return errs
+}
+
+// numeric returns the value of numeric kinds as a float, and a boolean indicating if the value was numeric
+func numeric(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
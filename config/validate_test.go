@@ -0,0 +1,122 @@
+// Tests the validate.go file
+package config
+
+import (
+	// Standard lib
+	"bytes"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("validate.go", func() {
+	var (
+		// Config instance to test
+		c *Config
+	)
+
+	BeforeEach(func() {
+		// Copy the default config so tests don't leak values
+		d := *GetInstance()
+		c = &d
+	})
+
+	Describe("`Validate` method", func() {
+		Context("When all values are valid", func() {
+			It("Returns nil", func() {
+				// Verify return value
+				Expect(c.Validate()).To(BeNil())
+			})
+		})
+
+		Context("When values are invalid", func() {
+			BeforeEach(func() {
+				// Set invalid values
+				c.Log.Level = "verbose"
+				c.DB.Dialect = "oracle"
+				c.Server.Port = 70000
+				c.Server.Timeouts.Read = 0
+				c.Name = ""
+			})
+
+			It("Returns all errors at once", func() {
+				// Call method
+				err := c.Validate()
+
+				// Verify return value
+				Expect(err).To(HaveOccurred())
+				errs, ok := err.(ValidationErrors)
+				Expect(ok).To(BeTrue())
+				Expect(errs).To(HaveLen(5))
+
+				// Verify error keys and messages
+				Expect(err.Error()).To(ContainSubstring("log.level: must be one of"))
+				Expect(err.Error()).To(ContainSubstring("db.dialect: must be one of: mysql, postgres"))
+				Expect(err.Error()).To(ContainSubstring("server.port: must be at most 65535"))
+				Expect(err.Error()).To(ContainSubstring("server.timeouts.read: must be at least 1"))
+				Expect(err.Error()).To(ContainSubstring("name: is required"))
+			})
+		})
+
+		Context("When a JWKS source is set without an issuer", func() {
+			It("Returns an error", func() {
+				for _, set := range []func(){
+					func() { c.Auth.JWT.JWKSURL = "https://sso.example.com/jwks" },
+					func() { c.Auth.JWT.JWKSFile = "/etc/forex-clock/jwks.json" },
+				} {
+					c.Auth.JWT.JWKSURL, c.Auth.JWT.JWKSFile, c.Auth.JWT.Issuer = "", "", ""
+					set()
+
+					// Call method
+					err := c.Validate()
+
+					// Verify return value
+					Expect(err).To(HaveOccurred())
+					Expect(err.(ValidationErrors)).To(HaveLen(1))
+					Expect(err.Error()).To(ContainSubstring("auth.jwt.issuer: is required when auth.jwt.jwks-url or auth.jwt.jwks-file is set"))
+
+					c.Auth.JWT.Issuer = "https://sso.example.com"
+					Expect(c.Validate()).To(BeNil())
+				}
+			})
+		})
+	})
+
+	Describe("`Redacted` method", func() {
+		BeforeEach(func() {
+			// Set secret value
+			c.DB.Password = "hunter2"
+		})
+
+		It("Returns a copy with secret values redacted", func() {
+			// Call method
+			r := c.Redacted()
+
+			// Verify return value
+			Expect(r.DB.Password).To(Equal(RedactedValue))
+			Expect(r.DB.Username).To(Equal(c.DB.Username))
+
+			// Verify original is untouched
+			Expect(c.DB.Password).To(Equal("hunter2"))
+		})
+	})
+
+	Describe("`Check` method", func() {
+		It("Outputs the effective configuration without secrets", func() {
+			// Set secret value
+			GetInstance().DB.Password = "hunter2"
+			defer func() { GetInstance().DB.Password = "" }()
+
+			// Call method
+			out := &bytes.Buffer{}
+			err := Check(out)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(out.String()).To(ContainSubstring(RedactedValue))
+			Expect(out.String()).To(Not(ContainSubstring("hunter2")))
+			Expect(out.String()).To(ContainSubstring("Configuration is valid"))
+		})
+	})
+})
@@ -0,0 +1,321 @@
+// database implementations
+// alerts contains storage of price alerts and the history of their triggers
+package db
+
+import (
+	// Standard lib
+	"database/sql"
+	"time"
+)
+
+const (
+	// Alert statuses
+	AlertActive    = "active"    // Evaluated, and triggered when its condition is met
+	AlertTriggered = "triggered" // Triggered, and not repeating
+	AlertExpired   = "expired"   // Reached its expiry without being triggered, or while repeating
+	AlertDisabled  = "disabled"  // Paused, until activated again
+
+	// Alert queries
+	selectAlertsQuery = "SELECT id, owner, name, alert_type, pair, session, threshold, channels, webhook_url, secret, email, cooldown, repeating, status, triggers, last_triggered_at, expires_at, created_at, updated_at FROM alerts"
+	selectAlertQuery  = selectAlertsQuery + " WHERE owner = ? AND id = ?"
+	insertAlertQuery  = "INSERT INTO alerts (id, owner, name, alert_type, pair, session, threshold, channels, webhook_url, secret, email, cooldown, repeating, status, triggers, last_triggered_at, expires_at, created_at, updated_at) VALUES (:id, :owner, :name, :alert_type, :pair, :session, :threshold, :channels, :webhook_url, :secret, :email, :cooldown, :repeating, :status, :triggers, :last_triggered_at, :expires_at, :created_at, :updated_at)"
+	updateAlertQuery  = "UPDATE alerts SET name = ?, alert_type = ?, pair = ?, session = ?, threshold = ?, channels = ?, webhook_url = ?, email = ?, cooldown = ?, repeating = ?, status = ?, expires_at = ?, updated_at = ? WHERE id = ? AND owner = ?"
+	deleteAlertQuery  = "DELETE FROM alerts WHERE id = ? AND owner = ?"
+	// NOTE: Only succeeds if the alert wasn't triggered or changed since it was read, so replicas don't trigger it twice
+	triggerAlertQuery = "UPDATE alerts SET status = ?, triggers = ?, last_triggered_at = ?, updated_at = ? WHERE id = ? AND triggers = ? AND updated_at = ?"
+	expireAlertQuery  = "UPDATE alerts SET status = ?, updated_at = ? WHERE id = ? AND status = ? AND updated_at = ?"
+
+	// Alert event queries
+	selectAlertEventsQuery = "SELECT id, alert_id, owner, alert_type, pair, session, threshold, value, message, notified, errors, created_at FROM alert_events"
+	insertAlertEventQuery  = "INSERT INTO alert_events (id, alert_id, owner, alert_type, pair, session, threshold, value, message, notified, errors, created_at) VALUES (:id, :alert_id, :owner, :alert_type, :pair, :session, :threshold, :value, :message, :notified, :errors, :created_at)"
+	updateAlertEventQuery  = "UPDATE alert_events SET notified = ?, errors = ? WHERE id = ?"
+	deleteAlertEventsQuery = "DELETE FROM alert_events WHERE alert_id = ? AND owner = ?"
+)
+
+type (
+	// AlertStore is an interface that all alert storage implementations must fulfill
+	// NOTE: Alerts belong to the client that created them, so methods used by clients are scoped to an owner
+	AlertStore interface {
+		// Create stores a new alert
+		Create(a *Alert) error
+		// Get returns an owner's alert by ID, or nil if it doesn't exist
+		Get(owner, id string) (*Alert, error)
+		// List returns all alerts of an owner
+		List(owner string) ([]*Alert, error)
+		// ListAll returns all alerts, of every owner
+		ListAll() ([]*Alert, error)
+		// Update replaces the settings and status of an alert, returning `ErrNotFound` if its owner has no such alert
+		Update(a *Alert) error
+		// Delete removes an owner's alert, along with the history of its triggers
+		Delete(owner, id string) error
+		// Trigger records that an alert was triggered, returning `ErrNotFound` if it was triggered or changed
+		// since it was read
+		Trigger(a *Alert, now time.Time) error
+		// Expire moves an active alert to the expired status, returning `ErrNotFound` if it was changed since it
+		// was read
+		Expire(a *Alert, now time.Time) error
+		// CreateEvent logs a trigger of an alert
+		CreateEvent(e *AlertEvent) error
+		// UpdateEvent replaces the notification results of a logged trigger
+		UpdateEvent(e *AlertEvent) error
+		// ListEvents returns the most recent triggers matching a filter
+		ListEvents(f AlertEventFilter) ([]*AlertEvent, error)
+	}
+	// Alert is a struct representing a single price alert
+	Alert struct {
+		ID              string     `db:"id" json:"id"`
+		Owner           string     `db:"owner" json:"-"` // Client that created the alert (ex: "api-key:<id>")
+		Name            string     `db:"name" json:"name"`
+		Type            string     `db:"alert_type" json:"type"`           // Condition of the alert (ex: "price-above")
+		Pair            string     `db:"pair" json:"pair,omitempty"`       // Pair, or index, of quote conditions
+		Session         string     `db:"session" json:"session,omitempty"` // Session of session conditions
+		Threshold       float64    `db:"threshold" json:"threshold"`       // Price, or spread in pips, of quote conditions
+		Channels        StringList `db:"channels" json:"channels"`         // Channels notified of triggers (ex: "log")
+		WebhookURL      string     `db:"webhook_url" json:"webhook-url,omitempty"`
+		Secret          string     `db:"secret" json:"-"` // Key signing webhook notifications
+		Email           string     `db:"email" json:"email,omitempty"`
+		Cooldown        int        `db:"cooldown" json:"cooldown"` // Shortest time (in seconds) between triggers
+		Repeat          bool       `db:"repeating" json:"repeat"`  // Whether the alert stays active once triggered
+		Status          string     `db:"status" json:"status"`     // "active", "triggered", "expired", or "disabled"
+		Triggers        int        `db:"triggers" json:"triggers"` // Number of times the alert was triggered
+		LastTriggeredAt *time.Time `db:"last_triggered_at" json:"last-triggered-at"`
+		ExpiresAt       *time.Time `db:"expires_at" json:"expires-at"` // When the alert stops being evaluated, nil for never
+		CreatedAt       time.Time  `db:"created_at" json:"created-at"`
+		UpdatedAt       time.Time  `db:"updated_at" json:"updated-at"`
+	}
+	// AlertEvent is a struct representing the log of a single trigger of an alert
+	AlertEvent struct {
+		ID        string     `db:"id" json:"id"`
+		AlertID   string     `db:"alert_id" json:"alert-id"`
+		Owner     string     `db:"owner" json:"-"` // Owner of the alert
+		Type      string     `db:"alert_type" json:"type"`
+		Pair      string     `db:"pair" json:"pair,omitempty"`
+		Session   string     `db:"session" json:"session,omitempty"`
+		Threshold float64    `db:"threshold" json:"threshold"`
+		Value     float64    `db:"value" json:"value"` // Price, or spread in pips, that triggered quote conditions
+		Message   string     `db:"message" json:"message"`
+		Notified  StringList `db:"notified" json:"notified"` // Channels notified successfully
+		Errors    string     `db:"errors" json:"errors"`     // Errors of channels that failed, empty if none did
+		CreatedAt time.Time  `db:"created_at" json:"created-at"`
+	}
+	// AlertEventFilter is a struct representing the triggers to list
+	AlertEventFilter struct {
+		Owner   string // Triggers of alerts of an owner
+		AlertID string // Triggers of an alert, empty for all of the owner's alerts
+		Limit   int    // Maximum number of triggers
+	}
+	// Struct representing alert storage within a database
+	alertStore struct {
+		db DB
+	}
+)
+
+// NewAlertStore creates and returns a new instance of alert storage backed by a database
+func NewAlertStore(db DB) AlertStore { return &alertStore{db: db} }
+
+// Create stores a new alert
+func (s *alertStore) Create(a *Alert) error { return s.namedExec(insertAlertQuery, a) }
+
+// Get returns an owner's alert by ID, or nil if it doesn't exist
+func (s *alertStore) Get(owner, id string) (*Alert, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Alert{}
+	if err := i.Get(a, i.Rebind(selectAlertQuery), owner, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// List returns all alerts of an owner
+func (s *alertStore) List(owner string) ([]*Alert, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := []*Alert{}
+	err = i.Select(&alerts, i.Rebind(selectAlertsQuery+" WHERE owner = ? ORDER BY created_at"), owner)
+
+	return alerts, err
+}
+
+// ListAll returns all alerts, of every owner
+func (s *alertStore) ListAll() ([]*Alert, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := []*Alert{}
+	err = i.Select(&alerts, selectAlertsQuery+" ORDER BY created_at")
+
+	return alerts, err
+}
+
+// Update replaces the settings and status of an alert, returning `ErrNotFound` if its owner has no such alert
+func (s *alertStore) Update(a *Alert) error {
+	return s.exec(updateAlertQuery, a.Name, a.Type, a.Pair, a.Session, a.Threshold, a.Channels, a.WebhookURL, a.Email,
+		a.Cooldown, a.Repeat, a.Status, a.ExpiresAt, a.UpdatedAt, a.ID, a.Owner)
+}
+
+// Delete removes an owner's alert, along with the history of its triggers
+func (s *alertStore) Delete(owner, id string) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := i.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(tx.Rebind(deleteAlertEventsQuery), id, owner); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(tx.Rebind(deleteAlertQuery), id, owner)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Trigger records that an alert was triggered, returning `ErrNotFound` if it was triggered or changed since it
+// was read
+// NOTE: Alerts that don't repeat move to the triggered status
+func (s *alertStore) Trigger(a *Alert, now time.Time) error {
+	now = now.UTC().Truncate(time.Second)
+	status := a.Status
+	if !a.Repeat {
+		status = AlertTriggered
+	}
+
+	if err := s.exec(triggerAlertQuery, status, a.Triggers+1, now, now, a.ID, a.Triggers, a.UpdatedAt); err != nil {
+		return err
+	}
+	a.Status, a.Triggers, a.LastTriggeredAt, a.UpdatedAt = status, a.Triggers+1, &now, now
+
+	return nil
+}
+
+// Expire moves an active alert to the expired status, returning `ErrNotFound` if it was changed since it was read
+func (s *alertStore) Expire(a *Alert, now time.Time) error {
+	now = now.UTC().Truncate(time.Second)
+	if err := s.exec(expireAlertQuery, AlertExpired, now, a.ID, AlertActive, a.UpdatedAt); err != nil {
+		return err
+	}
+	a.Status, a.UpdatedAt = AlertExpired, now
+
+	return nil
+}
+
+// CreateEvent logs a trigger of an alert
+func (s *alertStore) CreateEvent(e *AlertEvent) error { return s.namedExec(insertAlertEventQuery, e) }
+
+// UpdateEvent replaces the notification results of a logged trigger
+func (s *alertStore) UpdateEvent(e *AlertEvent) error {
+	return s.exec(updateAlertEventQuery, e.Notified, e.Errors, e.ID)
+}
+
+// ListEvents returns the most recent triggers matching a filter
+func (s *alertStore) ListEvents(f AlertEventFilter) ([]*AlertEvent, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := selectAlertEventsQuery+" WHERE owner = ?", []interface{}{f.Owner}
+	if f.AlertID != "" {
+		query, args = query+" AND alert_id = ?", append(args, f.AlertID)
+	}
+	query, args = query+" ORDER BY created_at DESC LIMIT ?", append(args, f.Limit)
+
+	events := []*AlertEvent{}
+	err = i.Select(&events, i.Rebind(query), args...)
+
+	return events, err
+}
+
+// namedExec runs an insert query with named parameters
+func (s *alertStore) namedExec(query string, arg interface{}) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.NamedExec(query, arg)
+
+	return err
+}
+
+// exec runs an update query, returning `ErrNotFound` if no rows were affected
+func (s *alertStore) exec(query string, args ...interface{}) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	return execAffecting(i, query, args...)
+}
+
+func init() {
+	RegisterMigration(&Migration{
+		ID:   8,
+		Name: "create alert tables",
+		Up: []string{
+			`CREATE TABLE alerts (
+				id VARCHAR(32) NOT NULL PRIMARY KEY,
+				owner VARCHAR(255) NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				alert_type VARCHAR(32) NOT NULL,
+				pair VARCHAR(16) NOT NULL,
+				session VARCHAR(64) NOT NULL,
+				threshold DOUBLE PRECISION NOT NULL,
+				channels VARCHAR(255) NOT NULL,
+				webhook_url VARCHAR(2048) NOT NULL,
+				secret VARCHAR(128) NOT NULL,
+				email VARCHAR(255) NOT NULL,
+				cooldown INTEGER NOT NULL,
+				repeating BOOLEAN NOT NULL,
+				status VARCHAR(16) NOT NULL,
+				triggers INTEGER NOT NULL,
+				last_triggered_at TIMESTAMP NULL,
+				expires_at TIMESTAMP NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE alert_events (
+				id VARCHAR(32) NOT NULL PRIMARY KEY,
+				alert_id VARCHAR(32) NOT NULL,
+				owner VARCHAR(255) NOT NULL,
+				alert_type VARCHAR(32) NOT NULL,
+				pair VARCHAR(16) NOT NULL,
+				session VARCHAR(64) NOT NULL,
+				threshold DOUBLE PRECISION NOT NULL,
+				value DOUBLE PRECISION NOT NULL,
+				message VARCHAR(1024) NOT NULL,
+				notified VARCHAR(255) NOT NULL,
+				errors VARCHAR(2048) NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			)`,
+			"CREATE INDEX alerts_owner ON alerts (owner, created_at)",
+			"CREATE INDEX alert_events_alert ON alert_events (alert_id, created_at)",
+			"CREATE INDEX alert_events_owner ON alert_events (owner, created_at)",
+		},
+		Down: []string{"DROP TABLE alert_events", "DROP TABLE alerts"},
+	})
+}
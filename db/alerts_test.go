@@ -0,0 +1,169 @@
+// Tests the alerts.go file
+package db
+
+import (
+	// Standard lib
+	"time"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("alerts.go", func() {
+	var (
+		db    DB
+		store AlertStore
+		now   = time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+	)
+
+	// newAlert stores and returns an active alert
+	newAlert := func(repeat bool) *Alert {
+		a := &Alert{
+			ID:        NewID(),
+			Owner:     "api-key:k1",
+			Type:      "price-above",
+			Pair:      "EURUSD",
+			Threshold: 1.1,
+			Channels:  StringList{"log"},
+			Repeat:    repeat,
+			Status:    AlertActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		Expect(store.Create(a)).To(Succeed())
+
+		return a
+	}
+
+	BeforeEach(func() {
+		db = newMigratedTestDB()
+		store = NewAlertStore(db)
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("Scopes alerts and their triggers to their owner", func() {
+		a, b := newAlert(false), newAlert(false)
+		b.Owner = "jwt:k1"
+		Expect(store.Update(b)).To(Equal(ErrNotFound))
+		other := &Alert{ID: NewID(), Owner: "jwt:k1", Type: "price-above", Channels: StringList{"log"}, CreatedAt: now, UpdatedAt: now}
+		Expect(store.Create(other)).To(Succeed())
+		for _, alert := range []*Alert{a, other} {
+			Expect(store.CreateEvent(&AlertEvent{ID: NewID(), AlertID: alert.ID, Owner: alert.Owner, Notified: StringList{}, CreatedAt: now})).To(Succeed())
+		}
+
+		// Verify output
+		list, err := store.List("api-key:k1")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(list).To(HaveLen(2))
+		Expect(list[0].Owner).To(Equal("api-key:k1"))
+		Expect(store.ListAll()).To(HaveLen(3))
+
+		Expect(store.Get("jwt:k1", a.ID)).To(BeNil())
+		Expect(store.Get("jwt:k1", other.ID)).NotTo(BeNil())
+
+		events, _ := store.ListEvents(AlertEventFilter{Owner: "jwt:k1", Limit: 10})
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].AlertID).To(Equal(other.ID))
+		Expect(store.ListEvents(AlertEventFilter{Owner: "jwt:k1", AlertID: a.ID, Limit: 10})).To(BeEmpty())
+
+		Expect(store.Delete("jwt:k1", a.ID)).To(Equal(ErrNotFound))
+		Expect(store.ListEvents(AlertEventFilter{Owner: "api-key:k1", Limit: 10})).To(HaveLen(1))
+		Expect(store.Delete("api-key:k1", a.ID)).To(Succeed())
+		Expect(store.ListEvents(AlertEventFilter{Owner: "api-key:k1", Limit: 10})).To(BeEmpty())
+	})
+
+	Describe("`Trigger` method", func() {
+		It("Records triggers, moving alerts that don't repeat to the triggered status", func() {
+			a := newAlert(false)
+			at := now.Add(time.Minute + 500*time.Millisecond)
+
+			// Call method
+			err := store.Trigger(a, at)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(a.Status).To(Equal(AlertTriggered))
+			Expect(a.Triggers).To(Equal(1))
+
+			stored, _ := store.Get(a.Owner, a.ID)
+			Expect(stored.Status).To(Equal(AlertTriggered))
+			Expect(stored.Triggers).To(Equal(1))
+			Expect(*stored.LastTriggeredAt).To(BeTemporally("==", now.Add(time.Minute)))
+			Expect(stored.UpdatedAt).To(BeTemporally("==", now.Add(time.Minute)))
+		})
+
+		It("Keeps repeating alerts active", func() {
+			a := newAlert(true)
+
+			// Call method
+			Expect(store.Trigger(a, now.Add(time.Minute))).To(Succeed())
+			Expect(store.Trigger(a, now.Add(2*time.Minute))).To(Succeed())
+
+			// Verify output
+			stored, _ := store.Get(a.Owner, a.ID)
+			Expect(stored.Status).To(Equal(AlertActive))
+			Expect(stored.Triggers).To(Equal(2))
+		})
+
+		It("Doesn't trigger alerts triggered or changed since they were read", func() {
+			a := newAlert(true)
+			triggered := *a
+
+			// Call method
+			Expect(store.Trigger(&triggered, now.Add(time.Minute))).To(Succeed())
+
+			// Verify output
+			Expect(store.Trigger(a, now.Add(time.Minute))).To(Equal(ErrNotFound))
+
+			// Verify changed alerts aren't triggered with settings they no longer have
+			b := newAlert(true)
+			updated := *b
+			updated.Threshold, updated.UpdatedAt = 1.2, now.Add(time.Second)
+			Expect(store.Update(&updated)).To(Succeed())
+			Expect(store.Trigger(b, now.Add(time.Minute))).To(Equal(ErrNotFound))
+
+			stored, _ := store.Get(b.Owner, b.ID)
+			Expect(stored.Triggers).To(Equal(0))
+		})
+	})
+
+	Describe("`Expire` method", func() {
+		It("Moves active alerts to the expired status", func() {
+			a := newAlert(false)
+
+			// Call method
+			err := store.Expire(a, now.Add(time.Hour))
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			stored, _ := store.Get(a.Owner, a.ID)
+			Expect(stored.Status).To(Equal(AlertExpired))
+			Expect(stored.UpdatedAt).To(BeTemporally("==", now.Add(time.Hour)))
+		})
+
+		It("Doesn't expire alerts which are no longer active or were changed since they were read", func() {
+			a := newAlert(false)
+			triggered := *a
+			Expect(store.Trigger(&triggered, now.Add(time.Minute))).To(Succeed())
+
+			// Call method
+			err := store.Expire(a, now.Add(time.Hour))
+
+			// Verify output
+			Expect(err).To(Equal(ErrNotFound))
+			stored, _ := store.Get(a.Owner, a.ID)
+			Expect(stored.Status).To(Equal(AlertTriggered))
+
+			// Verify alerts that are active again aren't expired from a stale read
+			stale := *stored
+			stored.Status, stored.UpdatedAt = AlertActive, now.Add(2*time.Minute)
+			Expect(store.Update(stored)).To(Succeed())
+			stale.Status = AlertActive
+			Expect(store.Expire(&stale, now.Add(time.Hour))).To(Equal(ErrNotFound))
+		})
+	})
+})
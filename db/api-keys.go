@@ -0,0 +1,152 @@
+// database implementations
+// api-keys contains storage of API keys, which are stored as hashes
+package db
+
+import (
+	// Standard lib
+	"database/sql"
+	"time"
+)
+
+const (
+	// Select queries
+	selectAPIKeysQuery       = "SELECT id, name, prefix, hash, scopes, created_at, rotated_at, revoked_at FROM api_keys"
+	selectAPIKeyByIDQuery    = selectAPIKeysQuery + " WHERE id = ?"
+	selectAPIKeyByHashQuery  = selectAPIKeysQuery + " WHERE hash = ?"
+	selectAPIKeysOrderClause = " ORDER BY created_at"
+
+	// Insert queries
+	insertAPIKeyQuery = "INSERT INTO api_keys (id, name, prefix, hash, scopes, created_at) VALUES (:id, :name, :prefix, :hash, :scopes, :created_at)"
+
+	// Update queries
+	rotateAPIKeyQuery = "UPDATE api_keys SET prefix = ?, hash = ?, rotated_at = ? WHERE id = ? AND revoked_at IS NULL"
+	revokeAPIKeyQuery = "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL"
+)
+
+type (
+	// APIKeyStore is an interface that all API key storage implementations must fulfill
+	APIKeyStore interface {
+		// Create stores a new API key
+		Create(k *APIKey) error
+		// Get returns an API key by ID, or nil if it doesn't exist
+		Get(id string) (*APIKey, error)
+		// GetByHash returns an API key by the hash of its value, or nil if it doesn't exist
+		GetByHash(hash string) (*APIKey, error)
+		// List returns all API keys, including revoked keys
+		List() ([]*APIKey, error)
+		// Rotate replaces the value of an active API key
+		Rotate(id, prefix, hash string) error
+		// Revoke permanently disables an API key
+		Revoke(id string) error
+	}
+	// APIKey is a struct representing a single stored API key
+	APIKey struct {
+		ID        string     `db:"id" json:"id"`
+		Name      string     `db:"name" json:"name"`
+		Prefix    string     `db:"prefix" json:"prefix"` // The first characters of the key, to help identify it
+		Hash      string     `db:"hash" json:"-"`
+		Scopes    StringList `db:"scopes" json:"scopes"`
+		CreatedAt time.Time  `db:"created_at" json:"created-at"`
+		RotatedAt *time.Time `db:"rotated_at" json:"rotated-at"`
+		RevokedAt *time.Time `db:"revoked_at" json:"revoked-at"`
+	}
+	// Struct representing API key storage within a database
+	apiKeyStore struct {
+		db DB
+	}
+)
+
+// NewAPIKeyStore creates and returns a new instance of API key storage backed by a database
+func NewAPIKeyStore(db DB) APIKeyStore { return &apiKeyStore{db: db} }
+
+// Revoked returns a boolean indicating if the key has been revoked
+func (k *APIKey) Revoked() bool { return k.RevokedAt != nil }
+
+// Create stores a new API key
+func (s *apiKeyStore) Create(k *APIKey) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.NamedExec(insertAPIKeyQuery, k)
+
+	return err
+}
+
+// Get returns an API key by ID, or nil if it doesn't exist
+func (s *apiKeyStore) Get(id string) (*APIKey, error) { return s.getOne(selectAPIKeyByIDQuery, id) }
+
+// GetByHash returns an API key by the hash of its value, or nil if it doesn't exist
+func (s *apiKeyStore) GetByHash(hash string) (*APIKey, error) {
+	return s.getOne(selectAPIKeyByHashQuery, hash)
+}
+
+// List returns all API keys, including revoked keys
+func (s *apiKeyStore) List() ([]*APIKey, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []*APIKey{}
+	err = i.Select(&keys, selectAPIKeysQuery+selectAPIKeysOrderClause)
+
+	return keys, err
+}
+
+// Rotate replaces the value of an active API key
+func (s *apiKeyStore) Rotate(id, prefix, hash string) error {
+	return s.exec(rotateAPIKeyQuery, prefix, hash, time.Now().UTC(), id)
+}
+
+// Revoke permanently disables an API key
+func (s *apiKeyStore) Revoke(id string) error { return s.exec(revokeAPIKeyQuery, time.Now().UTC(), id) }
+
+// getOne returns the single API key matching a query, or nil if none match
+func (s *apiKeyStore) getOne(query string, args ...interface{}) (*APIKey, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &APIKey{}
+	if err := i.Get(k, i.Rebind(query), args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// exec runs an update query, returning `ErrNotFound` if no rows were affected
+func (s *apiKeyStore) exec(query string, args ...interface{}) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	return execAffecting(i, query, args...)
+}
+
+func init() {
+	RegisterMigration(&Migration{
+		ID:   1,
+		Name: "create api_keys table",
+		Up: []string{
+			`CREATE TABLE api_keys (
+				id VARCHAR(32) NOT NULL PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				prefix VARCHAR(16) NOT NULL,
+				hash CHAR(64) NOT NULL UNIQUE,
+				scopes VARCHAR(1024) NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				rotated_at TIMESTAMP NULL,
+				revoked_at TIMESTAMP NULL
+			)`,
+		},
+		Down: []string{"DROP TABLE api_keys"},
+	})
+}
@@ -0,0 +1,79 @@
+// database implementations
+// cached-api-keys contains storage of API keys caching lookups by hash, the lookup made for every authenticated request
+package db
+
+import (
+	// Standard lib
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/cache"
+	"github.com/deezone/forex-clock/config"
+)
+
+const (
+	// Prefix of cached API key lookup keys
+	apiKeyHashCacheKey = "hash:"
+)
+
+type (
+	// Struct representing API key storage caching lookups by hash of another storage implementation
+	cachedAPIKeyStore struct {
+		APIKeyStore
+		cache *cache.Cache
+	}
+)
+
+// NewCachedAPIKeyStore creates and returns a new instance of API key storage caching lookups by hash
+// NOTE: Rotating or revoking a key removes its cached lookup, other replicas using the memory backend
+// may use the old value until it expires
+func NewCachedAPIKeyStore(store APIKeyStore, c *cache.Cache) APIKeyStore {
+	return &cachedAPIKeyStore{APIKeyStore: store, cache: c}
+}
+
+// GetByHash returns an API key by the hash of its value, or nil if it doesn't exist
+// NOTE: Keys that don't exist aren't cached, as every request with a made up key would otherwise add an entry
+func (s *cachedAPIKeyStore) GetByHash(hash string) (*APIKey, error) {
+	var k *APIKey
+	p := cache.Policy{TTL: time.Duration(config.GetInstance().Cache.APIKeyTTL) * time.Second, SkipNil: true}
+	err := s.cache.Fetch(apiKeyHashCacheKey+hash, p, &k, func() (interface{}, error) {
+		return s.APIKeyStore.GetByHash(hash)
+	})
+	if err != nil || k == nil {
+		return nil, err
+	}
+
+	// The hash isn't encoded, but is known
+	k.Hash = hash
+
+	return k, nil
+}
+
+// Rotate replaces the value of an active API key
+func (s *cachedAPIKeyStore) Rotate(id, prefix, hash string) error {
+	return s.forget(id, func() error { return s.APIKeyStore.Rotate(id, prefix, hash) })
+}
+
+// Revoke permanently disables an API key
+func (s *cachedAPIKeyStore) Revoke(id string) error {
+	return s.forget(id, func() error { return s.APIKeyStore.Revoke(id) })
+}
+
+// forget updates an API key, then removes its cached lookup
+// NOTE: The lookup is removed after updating, so a concurrent lookup can't cache the old value
+func (s *cachedAPIKeyStore) forget(id string, update func() error) error {
+	k, err := s.APIKeyStore.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+
+	if k == nil {
+		return nil
+	}
+
+	return s.cache.Delete(apiKeyHashCacheKey + k.Hash)
+}
@@ -0,0 +1,63 @@
+// Tests the cached-api-keys.go file
+package db
+
+import (
+	// Standard lib
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/cache"
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cached-api-keys.go", func() {
+	var (
+		// Database, cache backend, and store to test
+		db      DB
+		backend *cache.MemoryBackend
+		s       APIKeyStore
+	)
+
+	BeforeEach(func() {
+		config.GetInstance().Cache.Enabled = true
+		db = newMigratedTestDB()
+		backend = cache.NewMemoryBackend(100)
+		s = NewCachedAPIKeyStore(NewAPIKeyStore(db), cache.New("api-keys-test", backend))
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("`GetByHash` method", func() {
+		It("Caches keys that exist", func() {
+			k := &APIKey{ID: NewID(), Name: "test", Prefix: "fxk_abc", Hash: "hash-1", Scopes: StringList{"admin"}, CreatedAt: time.Now().UTC()}
+			Expect(s.Create(k)).To(Succeed())
+
+			// Call method
+			found, err := s.GetByHash("hash-1")
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(found.ID).To(Equal(k.ID))
+			Expect(found.Hash).To(Equal("hash-1"))
+			Expect(backend.Len()).To(Equal(1))
+		})
+
+		It("Doesn't cache keys that don't exist", func() {
+			for _, hash := range []string{"unknown-1", "unknown-2", "unknown-1"} {
+				// Call method
+				found, err := s.GetByHash(hash)
+
+				// Verify output
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(found).To(BeNil())
+			}
+			Expect(backend.Len()).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,136 @@
+// database implementations
+// candles contains storage of OHLC candles aggregated from ticks
+package db
+
+import (
+	// Standard lib
+	"time"
+)
+
+const (
+	// Candle queries
+	selectCandlesQuery = "SELECT pair, interval_name, open_time, close_time, open_price, high_price, low_price, close_price, tick_count, updated_at FROM candles WHERE pair = ? AND interval_name = ?"
+	insertCandleQuery  = "INSERT INTO candles (pair, interval_name, open_time, close_time, open_price, high_price, low_price, close_price, tick_count, updated_at) VALUES (:pair, :interval_name, :open_time, :close_time, :open_price, :high_price, :low_price, :close_price, :tick_count, :updated_at)"
+	updateCandleQuery  = "UPDATE candles SET close_time = ?, open_price = ?, high_price = ?, low_price = ?, close_price = ?, tick_count = ?, updated_at = ? WHERE pair = ? AND interval_name = ? AND open_time = ?"
+)
+
+type (
+	// CandleStore is an interface that all candle storage implementations must fulfill
+	CandleStore interface {
+		// Save replaces candles, inserting those that don't exist
+		Save(candles []*Candle) error
+		// List returns the candles matching a filter, ordered by open time
+		List(f CandleFilter) ([]*Candle, error)
+	}
+	// Candle is a struct representing the open, high, low, and close mid prices of a pair over an interval
+	Candle struct {
+		Pair      string    `db:"pair" json:"pair"`
+		Interval  string    `db:"interval_name" json:"interval"` // Name of the interval (ex: "1h")
+		OpenTime  time.Time `db:"open_time" json:"open-time"`    // Start of the interval
+		CloseTime time.Time `db:"close_time" json:"close-time"`  // End of the interval (exclusive)
+		Open      float64   `db:"open_price" json:"open"`
+		High      float64   `db:"high_price" json:"high"`
+		Low       float64   `db:"low_price" json:"low"`
+		Close     float64   `db:"close_price" json:"close"`
+		Ticks     int       `db:"tick_count" json:"ticks"` // Number of ticks aggregated
+		UpdatedAt time.Time `db:"updated_at" json:"updated-at"`
+	}
+	// CandleFilter is a struct representing the candles to list
+	CandleFilter struct {
+		Pair     string
+		Interval string
+		From     time.Time // Candles opening at or after a point in time, zero for all
+		To       time.Time // Candles opening before a point in time, zero for all
+		Limit    int       // Largest number of candles to list, 0 for all
+		Latest   bool      // Whether to list the latest candles when limited, rather than the earliest
+	}
+	// Struct representing candle storage within a database
+	candleStore struct {
+		db DB
+	}
+)
+
+// NewCandleStore creates and returns a new instance of candle storage backed by a database
+func NewCandleStore(db DB) CandleStore { return &candleStore{db: db} }
+
+// Save replaces candles, inserting those that don't exist
+// NOTE: Updates each candle's row, inserting it if it doesn't exist, as upserts differ between dialects
+func (s *candleStore) Save(candles []*Candle) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range candles {
+		err := execAffecting(i, updateCandleQuery, c.CloseTime, c.Open, c.High, c.Low, c.Close, c.Ticks, c.UpdatedAt, c.Pair, c.Interval, c.OpenTime)
+		if err == ErrNotFound {
+			_, err = i.NamedExec(insertCandleQuery, c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List returns the candles matching a filter, ordered by open time
+func (s *candleStore) List(f CandleFilter) ([]*Candle, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := selectCandlesQuery, []interface{}{f.Pair, f.Interval}
+	if !f.From.IsZero() {
+		query, args = query+" AND open_time >= ?", append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		query, args = query+" AND open_time < ?", append(args, f.To)
+	}
+	if f.Latest {
+		query += " ORDER BY open_time DESC"
+	} else {
+		query += " ORDER BY open_time"
+	}
+	if f.Limit > 0 {
+		query, args = query+" LIMIT ?", append(args, f.Limit)
+	}
+
+	candles := []*Candle{}
+	if err := i.Select(&candles, i.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	// Order the latest candles by open time
+	if f.Latest {
+		for a, b := 0, len(candles)-1; a < b; a, b = a+1, b-1 {
+			candles[a], candles[b] = candles[b], candles[a]
+		}
+	}
+
+	return candles, nil
+}
+
+func init() {
+	RegisterMigration(&Migration{
+		ID:   7,
+		Name: "create candles table",
+		Up: []string{
+			`CREATE TABLE candles (
+				pair VARCHAR(16) NOT NULL,
+				interval_name VARCHAR(8) NOT NULL,
+				open_time TIMESTAMP NOT NULL,
+				close_time TIMESTAMP NOT NULL,
+				open_price DOUBLE PRECISION NOT NULL,
+				high_price DOUBLE PRECISION NOT NULL,
+				low_price DOUBLE PRECISION NOT NULL,
+				close_price DOUBLE PRECISION NOT NULL,
+				tick_count INTEGER NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (pair, interval_name, open_time)
+			)`,
+		},
+		Down: []string{"DROP TABLE candles"},
+	})
+}
@@ -0,0 +1,83 @@
+// dbtest package contains databases used by the tests of packages storing data
+package dbtest
+
+import (
+	// Standard lib
+	"sync"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+
+	// Third-party
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	// Database "type" of test databases
+	DBTypeTest = "test-db"
+)
+
+type (
+	// Struct representing a database wrapping a connection opened by a test
+	testDB struct {
+		instance *sqlx.DB
+		mutex    sync.RWMutex
+	}
+)
+
+// New creates and returns a new in-memory SQLite database, with every registered migration applied
+// NOTE: A single connection is used, as each connection to `:memory:` opens a separate database
+func New() db.DB {
+	i := sqlx.MustOpen("sqlite3", ":memory:?_loc=UTC")
+	i.SetMaxOpenConns(1)
+
+	d := Wrap(i)
+	if _, err := db.MigrateUp(d); err != nil {
+		panic("Error migrating test database. Error was: " + err.Error())
+	}
+
+	return d
+}
+
+// Wrap creates and returns a new database wrapping a connection, such as one to a mocked driver
+// NOTE: The connection's driver name sets the dialect of queries (ex: `sqlx.NewDb(mock, "mysql")`)
+func Wrap(i *sqlx.DB) db.DB {
+	return &testDB{instance: i}
+}
+
+// Close is used to call a `Close` method of the underlying database driver
+func (d *testDB) Close() error {
+	return d.GetInstance().Close()
+}
+
+// GetInstance returns an internal sqlx.DB instance to allow
+// for direct access to the database
+func (d *testDB) GetInstance() *sqlx.DB {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	return d.instance
+}
+
+// SetInstance sets an internal sqlx.DB instance to allow
+// for direct access to the database
+func (d *testDB) SetInstance(i *sqlx.DB) db.DB {
+	d.mutex.Lock()
+	d.instance = i
+	d.mutex.Unlock()
+
+	return d
+}
+
+// String returns the "type" of database as a string - used in health checks
+func (d *testDB) String() string { return DBTypeTest }
+
+// Ready checks if the DB is "ready" - used in health checks
+func (d *testDB) Ready() error {
+	if d.GetInstance() == nil {
+		return db.ErrNilInstance
+	}
+
+	return d.GetInstance().Ping()
+}
@@ -4,14 +4,15 @@ package db
 
 import (
 	// Standard lib
-	"errors"
+	"sync"
 
 	// Internal
 	"github.com/deezone/forex-clock/config"
 
 	// Third-party
-	_ "github.com/lib/pq"
 	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -23,48 +24,56 @@ const (
 type (
 	// Struct representing a single SoulCycle DB instance
 	fcDB struct {
-		dbType   string
-		instance *sqlx.DB
+		dbType      string
+		instance    *sqlx.DB
+		mutex       sync.RWMutex // Mutex guarding the instance, which is replaced when credentials rotate
+		unsubscribe func()       // Function removing the configuration reload subscription
 	}
 )
 
 // NewSCDB creates and returns a new instance of a SoulCycle DB
 func NewFCDB() DB {
-	// Form configs
-	c := config.GetInstance()
-	dsnConfig := DSNConfig{
-		Username:     c.DB.Username,
-		Password:     c.DB.Password,
-		DatabaseName: c.DB.DatabaseName,
-		TCP:          DSNConfigTCP{Host: c.DB.TCP.Host, Port: c.DB.TCP.Port},
-		Timeout:      c.DB.Timeout,
-		Dialect:      c.DB.Dialect,
-	}
-
 	// Attempt to open DB, checking for errors
 	// NOTE: Ignoring error here. `sqlx.Connect` only errors if the driver isn't imported
 	// It's always imported, so no way for that to error
-	i, _ := sqlx.Connect(dsnConfig.Dialect, formDSN(dsnConfig))
+	i, _ := connect(config.GetInstance())
 
-	// Form and return new DB
-	return &fcDB{
+	// Form new DB
+	db := &fcDB{
 		dbType:   DBTypeFC,
 		instance: i,
 	}
+
+	// Reconnect when credentials are rotated
+	db.unsubscribe = config.Subscribe(db.rotate)
+
+	return db
 }
 
 // Close is used to call a `Close` method of the underlying database driver
-func (db *fcDB) Close() error { return db.instance.Close() }
+func (db *fcDB) Close() error {
+	db.unsubscribe()
+
+	return db.GetInstance().Close()
+}
 
 // GetInstance returns an internal sqlx.DB instance to allow
 // for direct access to the database
-func (db *fcDB) GetInstance() *sqlx.DB { return db.instance }
+func (db *fcDB) GetInstance() *sqlx.DB {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	return db.instance
+}
 
 // SetInstance sets an internal sqlx.DB instance to allow
 // for direct access to the database
 // NOTE: Returns the DB struct to allow for chaining of methods
 func (db *fcDB) SetInstance(i *sqlx.DB) DB {
+	db.mutex.Lock()
 	db.instance = i
+	db.mutex.Unlock()
+
 	return db
 }
 
@@ -75,9 +84,47 @@ func (db *fcDB) String() string { return db.dbType }
 func (db *fcDB) Ready() error {
 	// Ensure database instance is valid
 	if db.GetInstance() == nil {
-		return errors.New("Nil database instance detected")
+		return ErrNilInstance
 	}
 
 	// Ping database
 	return db.GetInstance().Ping()
 }
+
+// rotate reconnects to the database when credentials change during a configuration reload
+// NOTE: The current connection is kept if the new credentials can't be used
+func (db *fcDB) rotate(prev, next *config.Config, changed []string) {
+	if prev.DB.Username == next.DB.Username && prev.DB.Password == next.DB.Password {
+		return
+	}
+
+	i, err := connect(next)
+	if err != nil {
+		log.WithError(err).Error("Unable to connect with rotated database credentials, keeping current connection")
+		return
+	}
+
+	// Swap in the new connection, closing the old one
+	old := db.GetInstance()
+	db.SetInstance(i)
+	if old != nil {
+		old.Close()
+	}
+
+	log.Info("Database credentials rotated")
+}
+
+// connect opens a connection to the database described by a config instance
+func connect(c *config.Config) (*sqlx.DB, error) {
+	// Form configs
+	dsnConfig := DSNConfig{
+		Username:     c.DB.Username,
+		Password:     c.DB.Password,
+		DatabaseName: c.DB.DatabaseName,
+		TCP:          DSNConfigTCP{Host: c.DB.TCP.Host, Port: c.DB.TCP.Port},
+		Timeout:      c.DB.Timeout,
+		Dialect:      c.DB.Dialect,
+	}
+
+	return sqlx.Connect(dsnConfig.Dialect, formDSN(dsnConfig))
+}
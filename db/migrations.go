@@ -0,0 +1,165 @@
+// database implementations
+// migrations contains versioned schema changes and the routines to apply and roll them back
+package db
+
+import (
+	// Standard lib
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// Schema queries
+	createMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		id INTEGER NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`
+	selectMigrationsQuery = "SELECT id FROM schema_migrations"
+	insertMigrationQuery  = "INSERT INTO schema_migrations (id, name, applied_at) VALUES (?, ?, ?)"
+	deleteMigrationQuery  = "DELETE FROM schema_migrations WHERE id = ?"
+)
+
+type (
+	// Migration is a struct representing a single versioned schema change
+	// NOTE: Statements are run one at a time, as not all drivers support multiple statements per query
+	Migration struct {
+		ID   int      // Unique, increasing version of the migration
+		Name string   // Short description of the migration
+		Up   []string // Statements applying the migration
+		Down []string // Statements rolling back the migration
+	}
+	// MigrationStatus is a struct representing whether a migration has been applied
+	MigrationStatus struct {
+		*Migration
+		Applied bool
+	}
+)
+
+var (
+	// All migrations known to the application
+	// NOTE: Packages register their migrations with `RegisterMigration`
+	migrations = []*Migration{}
+)
+
+// RegisterMigration adds a migration to the set of known migrations
+func RegisterMigration(m *Migration) {
+	for _, existing := range migrations {
+		if existing.ID == m.ID {
+			panic(fmt.Sprintf("Duplicate migration ID: %d", m.ID))
+		}
+	}
+
+	migrations = append(migrations, m)
+	sort.Slice(migrations, func(a, b int) bool { return migrations[a].ID < migrations[b].ID })
+}
+
+// Migrations returns all known migrations, ordered by ID
+func Migrations() []*Migration { return migrations }
+
+// MigrateStatus returns every known migration along with whether it has been applied
+func MigrateStatus(db DB) ([]*MigrationStatus, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, &MigrationStatus{Migration: m, Applied: applied[m.ID]})
+	}
+
+	return statuses, nil
+}
+
+// MigrateUp applies all pending migrations in order, returning the migrations applied
+func MigrateUp(db DB) ([]*Migration, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]*Migration, 0)
+	for _, m := range migrations {
+		if applied[m.ID] {
+			continue
+		}
+
+		if err := runMigration(db, m, m.Up, insertMigrationQuery, m.ID, m.Name, time.Now().UTC()); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// MigrateDown rolls back a number of applied migrations, most recent first, returning the migrations rolled back
+func MigrateDown(db DB, steps int) ([]*Migration, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]*Migration, 0)
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		if !applied[m.ID] {
+			continue
+		}
+
+		if err := runMigration(db, m, m.Down, deleteMigrationQuery, m.ID); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// appliedMigrations returns the IDs of all applied migrations, creating the migrations table if needed
+func appliedMigrations(db DB) (map[int]bool, error) {
+	i, err := instance(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = i.Exec(createMigrationsTableQuery); err != nil {
+		return nil, err
+	}
+
+	ids := []int{}
+	if err := i.Select(&ids, selectMigrationsQuery); err != nil {
+		return nil, err
+	}
+
+	applied := map[int]bool{}
+	for _, id := range ids {
+		applied[id] = true
+	}
+
+	return applied, nil
+}
+
+// runMigration runs a migration's statements and records the change within a single transaction
+func runMigration(db DB, m *Migration, statements []string, record string, args ...interface{}) error {
+	tx, err := db.GetInstance().Beginx()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statements {
+		if _, err := tx.Exec(s); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Migration %d (%s) failed: %s", m.ID, m.Name, err)
+		}
+	}
+
+	if _, err := tx.Exec(tx.Rebind(record), args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
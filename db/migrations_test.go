@@ -0,0 +1,154 @@
+// Tests the migrations.go file
+package db
+
+import (
+	// Third-party
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// newTestDB returns a DB backed by a new in-memory SQLite database
+// NOTE: A single connection is used, as each connection to `:memory:` opens a separate database
+func newTestDB() DB {
+	i := sqlx.MustOpen("sqlite3", ":memory:?_loc=UTC")
+	i.SetMaxOpenConns(1)
+
+	return (&fcDB{dbType: DBTypeFC, unsubscribe: func() {}}).SetInstance(i)
+}
+
+// newMigratedTestDB returns a DB backed by a new in-memory SQLite database, with every migration applied
+func newMigratedTestDB() DB {
+	db := newTestDB()
+	_, err := MigrateUp(db)
+	Expect(err).To(Not(HaveOccurred()))
+
+	return db
+}
+
+// tableExists returns whether a table exists within a SQLite database
+func tableExists(db DB, name string) bool {
+	n := 0
+	db.GetInstance().Get(&n, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name)
+
+	return n == 1
+}
+
+// migrationIDs returns the IDs of a list of migrations
+func migrationIDs(list []*Migration) []int {
+	ids := make([]int, 0, len(list))
+	for _, m := range list {
+		ids = append(ids, m.ID)
+	}
+
+	return ids
+}
+
+var _ = Describe("migrations.go", func() {
+	var db DB
+
+	BeforeEach(func() {
+		db = newTestDB()
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("`MigrateUp` method", func() {
+		It("Applies every pending migration in order", func() {
+			// Call method
+			done, err := MigrateUp(db)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(migrationIDs(done)).To(Equal([]int{1, 3, 4, 5, 6, 7, 8}))
+			for _, table := range []string{"schema_migrations", "api_keys", "webhooks", "candles", "alerts", "alert_events"} {
+				Expect(tableExists(db, table)).To(BeTrue(), table)
+			}
+
+			// Verify migrations are only applied once
+			done, err = MigrateUp(db)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(done).To(BeEmpty())
+
+			statuses, err := MigrateStatus(db)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(statuses).To(HaveLen(len(Migrations())))
+			for _, s := range statuses {
+				Expect(s.Applied).To(BeTrue(), s.Name)
+			}
+		})
+
+		It("Rolls back and stops at a failing migration", func() {
+			defer func(prev []*Migration) { migrations = prev }(migrations)
+			migrations = append(migrations[:len(migrations):len(migrations)],
+				&Migration{ID: 100, Name: "broken", Up: []string{"CREATE TABLE broken (id INTEGER)", "CREATE TABL oops"}},
+				&Migration{ID: 101, Name: "after", Up: []string{"CREATE TABLE after (id INTEGER)"}},
+			)
+
+			// Call method
+			done, err := MigrateUp(db)
+
+			// Verify output
+			Expect(err).To(MatchError(HavePrefix("Migration 100 (broken) failed:")))
+			Expect(migrationIDs(done)).To(Equal([]int{1, 3, 4, 5, 6, 7, 8}))
+			Expect(tableExists(db, "broken")).To(BeFalse())
+			Expect(tableExists(db, "after")).To(BeFalse())
+
+			statuses, _ := MigrateStatus(db)
+			Expect(statuses[len(statuses)-2].Applied).To(BeFalse())
+		})
+	})
+
+	Describe("`MigrateDown` method", func() {
+		It("Rolls back applied migrations, most recent first", func() {
+			MigrateUp(db)
+
+			// Call method
+			done, err := MigrateDown(db, 2)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(migrationIDs(done)).To(Equal([]int{8, 7}))
+			Expect(tableExists(db, "alerts")).To(BeFalse())
+			Expect(tableExists(db, "alert_events")).To(BeFalse())
+			Expect(tableExists(db, "candles")).To(BeFalse())
+			Expect(tableExists(db, "ticks")).To(BeTrue())
+
+			statuses, _ := MigrateStatus(db)
+			applied := []int{}
+			for _, s := range statuses {
+				if s.Applied {
+					applied = append(applied, s.ID)
+				}
+			}
+			Expect(applied).To(Equal([]int{1, 3, 4, 5, 6}))
+
+			// Verify rolled back migrations are applied again
+			done, err = MigrateUp(db)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(migrationIDs(done)).To(Equal([]int{7, 8}))
+		})
+
+		It("Rolls back every migration", func() {
+			MigrateUp(db)
+
+			// Call method
+			done, err := MigrateDown(db, 100)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(migrationIDs(done)).To(Equal([]int{8, 7, 6, 5, 4, 3, 1}))
+			Expect(tableExists(db, "api_keys")).To(BeFalse())
+		})
+	})
+
+	It("Returns an error without a connection", func() {
+		_, err := MigrateUp(&fcDB{})
+
+		// Verify output
+		Expect(err).To(Equal(ErrNilInstance))
+	})
+})
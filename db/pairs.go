@@ -0,0 +1,126 @@
+// database implementations
+// pairs contains storage of currency pair reference data
+package db
+
+import (
+	// Standard lib
+	"database/sql"
+	"time"
+)
+
+const (
+	// Pair queries
+	selectPairsQuery = "SELECT symbol, base, quote, pip_size, price_precision, class, sessions, created_at, updated_at FROM pairs"
+	selectPairQuery  = selectPairsQuery + " WHERE symbol = ?"
+	insertPairQuery  = "INSERT INTO pairs (symbol, base, quote, pip_size, price_precision, class, sessions, created_at, updated_at) VALUES (:symbol, :base, :quote, :pip_size, :price_precision, :class, :sessions, :created_at, :updated_at)"
+)
+
+type (
+	// PairStore is an interface that all currency pair storage implementations must fulfill
+	PairStore interface {
+		// Create stores a new pair
+		Create(p *Pair) error
+		// Get returns a pair by symbol, or nil if it doesn't exist
+		Get(symbol string) (*Pair, error)
+		// List returns the pairs matching a filter, ordered by symbol
+		List(f PairFilter) ([]*Pair, error)
+	}
+	// Pair is a struct representing the reference data of a single currency pair
+	Pair struct {
+		Symbol    string     `db:"symbol" json:"symbol"`                   // Base and quote currency codes (ex: "EURUSD")
+		Base      string     `db:"base" json:"base"`                       // ISO 4217 code of the currency bought or sold
+		Quote     string     `db:"quote" json:"quote"`                     // ISO 4217 code of the currency prices are in
+		PipSize   float64    `db:"pip_size" json:"pip-size"`               // Price change of a single pip
+		Precision int        `db:"price_precision" json:"price-precision"` // Decimal places prices are quoted to
+		Class     string     `db:"class" json:"class"`                     // Major, minor, or exotic
+		Sessions  StringList `db:"sessions" json:"sessions"`               // Sessions during which the pair is most liquid
+		CreatedAt time.Time  `db:"created_at" json:"created-at"`
+		UpdatedAt time.Time  `db:"updated_at" json:"updated-at"`
+	}
+	// PairFilter is a struct representing the pairs to list
+	PairFilter struct {
+		Currency string // Pairs with a currency as their base or quote, empty for all
+		Class    string // Pairs of a class, empty for all
+	}
+	// Struct representing currency pair storage within a database
+	pairStore struct {
+		db DB
+	}
+)
+
+// NewPairStore creates and returns a new instance of currency pair storage backed by a database
+func NewPairStore(db DB) PairStore { return &pairStore{db: db} }
+
+// Create stores a new pair
+func (s *pairStore) Create(p *Pair) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.NamedExec(insertPairQuery, p)
+
+	return err
+}
+
+// Get returns a pair by symbol, or nil if it doesn't exist
+func (s *pairStore) Get(symbol string) (*Pair, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pair{}
+	if err := i.Get(p, i.Rebind(selectPairQuery), symbol); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// List returns the pairs matching a filter, ordered by symbol
+func (s *pairStore) List(f PairFilter) ([]*Pair, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := selectPairsQuery+" WHERE 1 = 1", []interface{}{}
+	if f.Currency != "" {
+		query, args = query+" AND (base = ? OR quote = ?)", append(args, f.Currency, f.Currency)
+	}
+	if f.Class != "" {
+		query, args = query+" AND class = ?", append(args, f.Class)
+	}
+
+	pairs := []*Pair{}
+	err = i.Select(&pairs, i.Rebind(query+" ORDER BY symbol"), args...)
+
+	return pairs, err
+}
+
+func init() {
+	RegisterMigration(&Migration{
+		ID:   4,
+		Name: "create pairs table",
+		Up: []string{
+			`CREATE TABLE pairs (
+				symbol CHAR(6) NOT NULL PRIMARY KEY,
+				base CHAR(3) NOT NULL,
+				quote CHAR(3) NOT NULL,
+				pip_size DOUBLE PRECISION NOT NULL,
+				price_precision INTEGER NOT NULL,
+				class VARCHAR(16) NOT NULL,
+				sessions VARCHAR(255) NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			"CREATE INDEX pairs_base ON pairs (base)",
+			"CREATE INDEX pairs_quote ON pairs (quote)",
+		},
+		Down: []string{"DROP TABLE pairs"},
+	})
+}
@@ -0,0 +1,90 @@
+// database implementations
+// quotes contains storage of the latest FX quote of each pair
+package db
+
+import (
+	// Standard lib
+	"time"
+)
+
+const (
+	// Quote queries
+	selectQuotesQuery = "SELECT pair, bid, ask, source, quoted_at, received_at FROM latest_quotes ORDER BY pair"
+	insertQuoteQuery  = "INSERT INTO latest_quotes (pair, bid, ask, source, quoted_at, received_at) VALUES (:pair, :bid, :ask, :source, :quoted_at, :received_at)"
+	updateQuoteQuery  = "UPDATE latest_quotes SET bid = ?, ask = ?, source = ?, quoted_at = ?, received_at = ? WHERE pair = ?"
+)
+
+type (
+	// QuoteStore is an interface that all latest quote storage implementations must fulfill
+	QuoteStore interface {
+		// Save replaces the latest quote of a pair
+		Save(q *Quote) error
+		// List returns the latest quote of every pair, ordered by pair
+		List() ([]*Quote, error)
+	}
+	// Quote is a struct representing the latest stored quote of a single currency pair
+	Quote struct {
+		Pair       string    `db:"pair"`
+		Bid        float64   `db:"bid"`
+		Ask        float64   `db:"ask"`
+		Source     string    `db:"source"`      // Provider the quote was received from
+		QuotedAt   time.Time `db:"quoted_at"`   // When the quote was made
+		ReceivedAt time.Time `db:"received_at"` // When the quote was received
+	}
+	// Struct representing latest quote storage within a database
+	quoteStore struct {
+		db DB
+	}
+)
+
+// NewQuoteStore creates and returns a new instance of latest quote storage backed by a database
+func NewQuoteStore(db DB) QuoteStore { return &quoteStore{db: db} }
+
+// Save replaces the latest quote of a pair
+// NOTE: Updates the pair's row, inserting it if it doesn't exist, as upserts differ between dialects
+func (s *quoteStore) Save(q *Quote) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	err = execAffecting(i, updateQuoteQuery, q.Bid, q.Ask, q.Source, q.QuotedAt, q.ReceivedAt, q.Pair)
+	if err != ErrNotFound {
+		return err
+	}
+
+	_, err = i.NamedExec(insertQuoteQuery, q)
+
+	return err
+}
+
+// List returns the latest quote of every pair, ordered by pair
+func (s *quoteStore) List() ([]*Quote, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := []*Quote{}
+	err = i.Select(&quotes, selectQuotesQuery)
+
+	return quotes, err
+}
+
+func init() {
+	RegisterMigration(&Migration{
+		ID:   5,
+		Name: "create latest_quotes table",
+		Up: []string{
+			`CREATE TABLE latest_quotes (
+				pair VARCHAR(16) NOT NULL PRIMARY KEY,
+				bid DOUBLE PRECISION NOT NULL,
+				ask DOUBLE PRECISION NOT NULL,
+				source VARCHAR(64) NOT NULL,
+				quoted_at TIMESTAMP NOT NULL,
+				received_at TIMESTAMP NOT NULL
+			)`,
+		},
+		Down: []string{"DROP TABLE latest_quotes"},
+	})
+}
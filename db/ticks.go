@@ -0,0 +1,115 @@
+// database implementations
+// ticks contains time-series storage of ingested FX ticks
+package db
+
+import (
+	// Standard lib
+	"strings"
+	"time"
+)
+
+const (
+	// Tick queries
+	insertTicksQuery = "INSERT INTO ticks (pair, quoted_at, bid, ask, source) VALUES "
+	tickValues       = "(?, ?, ?, ?, ?)"
+	selectTicksQuery = "SELECT pair, quoted_at, bid, ask, source FROM ticks WHERE pair = ? AND quoted_at >= ? AND quoted_at < ? ORDER BY quoted_at"
+	deleteTicksQuery = "DELETE FROM ticks WHERE quoted_at < ?"
+)
+
+type (
+	// TickStore is an interface that all tick storage implementations must fulfill
+	TickStore interface {
+		// SaveTicks stores a batch of ticks
+		SaveTicks(ticks []*Tick) error
+		// ListTicks returns the ticks of a pair quoted within a range (inclusive of `from`), ordered by time
+		ListTicks(pair string, from, to time.Time) ([]*Tick, error)
+		// DeleteTicks deletes every tick quoted before a point in time, returning the number deleted
+		DeleteTicks(before time.Time) (int64, error)
+	}
+	// Tick is a struct representing a single stored quote of a currency pair
+	Tick struct {
+		Pair     string    `db:"pair"`
+		QuotedAt time.Time `db:"quoted_at"` // When the quote was made
+		Bid      float64   `db:"bid"`
+		Ask      float64   `db:"ask"`
+		Source   string    `db:"source"` // Provider the quote was received from
+	}
+	// Struct representing tick storage within a database
+	tickStore struct {
+		db DB
+	}
+)
+
+// NewTickStore creates and returns a new instance of tick storage backed by a database
+func NewTickStore(db DB) TickStore { return &tickStore{db: db} }
+
+// SaveTicks stores a batch of ticks
+// NOTE: Inserts every tick with a single statement, so batches should be kept well within placeholder limits
+func (s *tickStore) SaveTicks(ticks []*Tick) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	values, args := make([]string, 0, len(ticks)), make([]interface{}, 0, len(ticks)*5)
+	for _, t := range ticks {
+		values = append(values, tickValues)
+		args = append(args, t.Pair, t.QuotedAt, t.Bid, t.Ask, t.Source)
+	}
+
+	_, err = i.Exec(i.Rebind(insertTicksQuery+strings.Join(values, ", ")), args...)
+
+	return err
+}
+
+// ListTicks returns the ticks of a pair quoted within a range (inclusive of `from`), ordered by time
+func (s *tickStore) ListTicks(pair string, from, to time.Time) ([]*Tick, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	ticks := []*Tick{}
+	err = i.Select(&ticks, i.Rebind(selectTicksQuery), pair, from, to)
+
+	return ticks, err
+}
+
+// DeleteTicks deletes every tick quoted before a point in time, returning the number deleted
+func (s *tickStore) DeleteTicks(before time.Time) (int64, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := i.Exec(i.Rebind(deleteTicksQuery), before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func init() {
+	RegisterMigration(&Migration{
+		ID:   6,
+		Name: "create ticks table",
+		Up: []string{
+			// NOTE: Millisecond precision, as many ticks of a pair are quoted each second
+			`CREATE TABLE ticks (
+				pair VARCHAR(16) NOT NULL,
+				quoted_at TIMESTAMP(3) NOT NULL,
+				bid DOUBLE PRECISION NOT NULL,
+				ask DOUBLE PRECISION NOT NULL,
+				source VARCHAR(64) NOT NULL
+			)`,
+			"CREATE INDEX ticks_pair_quoted_at ON ticks (pair, quoted_at)",
+			"CREATE INDEX ticks_quoted_at ON ticks (quoted_at)",
+		},
+		Down: []string{"DROP TABLE ticks"},
+	})
+}
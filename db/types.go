@@ -0,0 +1,86 @@
+// database implementations
+// types contains helper types and functions shared by stores
+package db
+
+import (
+	// Standard lib
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	// Third-party
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// Common errors
+	ErrNilInstance = errors.New("Nil database instance detected")
+	ErrNotFound    = errors.New("Record not found")
+)
+
+type (
+	// StringList is a slice of strings stored as a single comma-separated column
+	StringList []string
+)
+
+// Value converts a string list into a comma-separated database value
+func (l StringList) Value() (driver.Value, error) { return strings.Join(l, ","), nil }
+
+// Scan converts a comma-separated database value into a string list
+func (l *StringList) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+		s = ""
+	default:
+		return errors.New("Unsupported type for string list")
+	}
+
+	*l = StringList{}
+	for _, part := range strings.Split(s, ",") {
+		if part != "" {
+			*l = append(*l, part)
+		}
+	}
+
+	return nil
+}
+
+// NewID returns a new random identifier to use as a primary key
+func NewID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// instance returns a database's sqlx.DB instance, or an error if it isn't connected
+func instance(db DB) (*sqlx.DB, error) {
+	i := db.GetInstance()
+	if i == nil {
+		return nil, ErrNilInstance
+	}
+
+	return i, nil
+}
+
+// execAffecting runs a query (rebinding placeholders for the database dialect),
+// returning `ErrNotFound` if no rows were affected
+func execAffecting(i *sqlx.DB, query string, args ...interface{}) error {
+	res, err := i.Exec(i.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,348 @@
+// database implementations
+// webhooks contains storage of webhook subscriptions, their queued deliveries, and a log of delivery attempts
+package db
+
+import (
+	// Standard lib
+	"database/sql"
+	"time"
+)
+
+const (
+	// Delivery statuses
+	DeliveryPending   = "pending"   // Waiting to be attempted, or retried
+	DeliverySucceeded = "succeeded" // Accepted by the receiver
+	DeliveryDead      = "dead"      // Failed every attempt, kept within the dead-letter queue
+
+	// Webhook queries
+	selectWebhooksQuery = "SELECT id, url, secret, event_types, sessions, active, created_at, updated_at FROM webhooks"
+	selectWebhookQuery  = selectWebhooksQuery + " WHERE id = ?"
+	insertWebhookQuery  = "INSERT INTO webhooks (id, url, secret, event_types, sessions, active, created_at, updated_at) VALUES (:id, :url, :secret, :event_types, :sessions, :active, :created_at, :updated_at)"
+	updateWebhookQuery  = "UPDATE webhooks SET url = ?, event_types = ?, sessions = ?, active = ?, updated_at = ? WHERE id = ?"
+	deleteWebhookQuery  = "DELETE FROM webhooks WHERE id = ?"
+
+	// Delivery queries
+	selectDeliveriesQuery    = "SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at FROM webhook_deliveries"
+	selectDeliveryQuery      = selectDeliveriesQuery + " WHERE id = ?"
+	selectDueDeliveriesQuery = selectDeliveriesQuery + " WHERE status = 'pending' AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?"
+	insertDeliveryQuery      = "INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at) VALUES (:id, :webhook_id, :event_id, :event_type, :payload, :status, :attempts, :next_attempt_at, :last_status_code, :last_error, :created_at, :updated_at)"
+	updateDeliveryQuery      = "UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, updated_at = ? WHERE id = ?"
+	deleteDeliveriesQuery    = "DELETE FROM webhook_deliveries WHERE webhook_id = ?"
+	// NOTE: Only succeeds if the delivery wasn't claimed since it was read, so replicas don't attempt it twice
+	claimDeliveryQuery = "UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ? AND status = 'pending' AND next_attempt_at = ?"
+
+	// Delivery attempt queries
+	selectAttemptsQuery = "SELECT id, delivery_id, attempt, status_code, error, duration_ms, created_at FROM webhook_attempts WHERE delivery_id = ? ORDER BY attempt"
+	insertAttemptQuery  = "INSERT INTO webhook_attempts (id, delivery_id, attempt, status_code, error, duration_ms, created_at) VALUES (:id, :delivery_id, :attempt, :status_code, :error, :duration_ms, :created_at)"
+	deleteAttemptsQuery = "DELETE FROM webhook_attempts WHERE delivery_id IN (SELECT id FROM webhook_deliveries WHERE webhook_id = ?)"
+)
+
+type (
+	// WebhookStore is an interface that all webhook storage implementations must fulfill
+	WebhookStore interface {
+		// Create stores a new webhook
+		Create(w *Webhook) error
+		// Get returns a webhook by ID, or nil if it doesn't exist
+		Get(id string) (*Webhook, error)
+		// List returns all webhooks
+		List() ([]*Webhook, error)
+		// Update replaces the settings of a webhook
+		Update(w *Webhook) error
+		// Delete removes a webhook, along with its deliveries and their attempts
+		Delete(id string) error
+		// CreateDelivery queues a new delivery
+		CreateDelivery(d *Delivery) error
+		// GetDelivery returns a delivery by ID, or nil if it doesn't exist
+		GetDelivery(id string) (*Delivery, error)
+		// ListDeliveries returns the most recent deliveries matching a filter
+		ListDeliveries(f DeliveryFilter) ([]*Delivery, error)
+		// DueDeliveries returns pending deliveries due to be attempted, oldest first
+		DueDeliveries(now time.Time, limit int) ([]*Delivery, error)
+		// ClaimDelivery moves a due delivery's next attempt to a later time, returning `ErrNotFound` if
+		// it was claimed or changed since it was read
+		ClaimDelivery(d *Delivery, until time.Time) error
+		// UpdateDelivery replaces the state of a delivery
+		UpdateDelivery(d *Delivery) error
+		// CreateAttempt logs an attempted delivery
+		CreateAttempt(a *DeliveryAttempt) error
+		// ListAttempts returns the logged attempts of a delivery, in order
+		ListAttempts(deliveryID string) ([]*DeliveryAttempt, error)
+	}
+	// Webhook is a struct representing a single subscription to market events
+	Webhook struct {
+		ID         string     `db:"id" json:"id"`
+		URL        string     `db:"url" json:"url"`
+		Secret     string     `db:"secret" json:"-"`                // Key signing payloads
+		EventTypes StringList `db:"event_types" json:"event-types"` // Types of events delivered, empty for all
+		Sessions   StringList `db:"sessions" json:"sessions"`       // Sessions of events delivered, empty for all
+		Active     bool       `db:"active" json:"active"`
+		CreatedAt  time.Time  `db:"created_at" json:"created-at"`
+		UpdatedAt  time.Time  `db:"updated_at" json:"updated-at"`
+	}
+	// Delivery is a struct representing a single event queued for delivery to a webhook
+	Delivery struct {
+		ID             string    `db:"id" json:"id"`
+		WebhookID      string    `db:"webhook_id" json:"webhook-id"`
+		EventID        string    `db:"event_id" json:"event-id"`
+		EventType      string    `db:"event_type" json:"event-type"`
+		Payload        string    `db:"payload" json:"payload"`
+		Status         string    `db:"status" json:"status"`
+		Attempts       int       `db:"attempts" json:"attempts"`
+		NextAttemptAt  time.Time `db:"next_attempt_at" json:"next-attempt-at"`
+		LastStatusCode int       `db:"last_status_code" json:"last-status-code"` // 0 if no response was received
+		LastError      string    `db:"last_error" json:"last-error"`
+		CreatedAt      time.Time `db:"created_at" json:"created-at"`
+		UpdatedAt      time.Time `db:"updated_at" json:"updated-at"`
+	}
+	// DeliveryAttempt is a struct representing the log of a single attempted delivery
+	DeliveryAttempt struct {
+		ID         string    `db:"id" json:"id"`
+		DeliveryID string    `db:"delivery_id" json:"delivery-id"`
+		Attempt    int       `db:"attempt" json:"attempt"`
+		StatusCode int       `db:"status_code" json:"status-code"` // 0 if no response was received
+		Error      string    `db:"error" json:"error"`
+		DurationMS int64     `db:"duration_ms" json:"duration-ms"`
+		CreatedAt  time.Time `db:"created_at" json:"created-at"`
+	}
+	// DeliveryFilter is a struct representing the deliveries to list
+	DeliveryFilter struct {
+		WebhookID string // Deliveries to a webhook, empty for all
+		Status    string // Deliveries with a status, empty for all
+		Limit     int    // Maximum number of deliveries
+	}
+	// Struct representing webhook storage within a database
+	webhookStore struct {
+		db DB
+	}
+)
+
+// NewWebhookStore creates and returns a new instance of webhook storage backed by a database
+func NewWebhookStore(db DB) WebhookStore { return &webhookStore{db: db} }
+
+// Create stores a new webhook
+func (s *webhookStore) Create(w *Webhook) error { return s.namedExec(insertWebhookQuery, w) }
+
+// Get returns a webhook by ID, or nil if it doesn't exist
+func (s *webhookStore) Get(id string) (*Webhook, error) {
+	w := &Webhook{}
+	if found, err := s.getOne(w, selectWebhookQuery, id); !found {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// List returns all webhooks
+func (s *webhookStore) List() ([]*Webhook, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := []*Webhook{}
+	err = i.Select(&webhooks, selectWebhooksQuery+" ORDER BY created_at")
+
+	return webhooks, err
+}
+
+// Update replaces the settings of a webhook
+func (s *webhookStore) Update(w *Webhook) error {
+	return s.exec(updateWebhookQuery, w.URL, w.EventTypes, w.Sessions, w.Active, w.UpdatedAt, w.ID)
+}
+
+// Delete removes a webhook, along with its deliveries and their attempts
+func (s *webhookStore) Delete(id string) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := i.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, q := range []string{deleteAttemptsQuery, deleteDeliveriesQuery} {
+		if _, err := tx.Exec(tx.Rebind(q), id); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.Exec(tx.Rebind(deleteWebhookQuery), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// CreateDelivery queues a new delivery
+func (s *webhookStore) CreateDelivery(d *Delivery) error { return s.namedExec(insertDeliveryQuery, d) }
+
+// GetDelivery returns a delivery by ID, or nil if it doesn't exist
+func (s *webhookStore) GetDelivery(id string) (*Delivery, error) {
+	d := &Delivery{}
+	if found, err := s.getOne(d, selectDeliveryQuery, id); !found {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ListDeliveries returns the most recent deliveries matching a filter
+func (s *webhookStore) ListDeliveries(f DeliveryFilter) ([]*Delivery, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := selectDeliveriesQuery+" WHERE 1 = 1", []interface{}{}
+	if f.WebhookID != "" {
+		query, args = query+" AND webhook_id = ?", append(args, f.WebhookID)
+	}
+	if f.Status != "" {
+		query, args = query+" AND status = ?", append(args, f.Status)
+	}
+	query, args = query+" ORDER BY created_at DESC LIMIT ?", append(args, f.Limit)
+
+	deliveries := []*Delivery{}
+	err = i.Select(&deliveries, i.Rebind(query), args...)
+
+	return deliveries, err
+}
+
+// DueDeliveries returns pending deliveries due to be attempted, oldest first
+func (s *webhookStore) DueDeliveries(now time.Time, limit int) ([]*Delivery, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []*Delivery{}
+	err = i.Select(&deliveries, i.Rebind(selectDueDeliveriesQuery), now.UTC(), limit)
+
+	return deliveries, err
+}
+
+// ClaimDelivery moves a due delivery's next attempt to a later time, returning `ErrNotFound` if
+// it was claimed or changed since it was read
+func (s *webhookStore) ClaimDelivery(d *Delivery, until time.Time) error {
+	if err := s.exec(claimDeliveryQuery, until, d.ID, d.NextAttemptAt); err != nil {
+		return err
+	}
+	d.NextAttemptAt = until
+
+	return nil
+}
+
+// UpdateDelivery replaces the state of a delivery
+func (s *webhookStore) UpdateDelivery(d *Delivery) error {
+	return s.exec(updateDeliveryQuery, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.UpdatedAt, d.ID)
+}
+
+// CreateAttempt logs an attempted delivery
+func (s *webhookStore) CreateAttempt(a *DeliveryAttempt) error {
+	return s.namedExec(insertAttemptQuery, a)
+}
+
+// ListAttempts returns the logged attempts of a delivery, in order
+func (s *webhookStore) ListAttempts(deliveryID string) ([]*DeliveryAttempt, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := []*DeliveryAttempt{}
+	err = i.Select(&attempts, i.Rebind(selectAttemptsQuery), deliveryID)
+
+	return attempts, err
+}
+
+// getOne scans the single row matching a query into a destination, returning whether it was found
+func (s *webhookStore) getOne(dest interface{}, query string, args ...interface{}) (bool, error) {
+	i, err := instance(s.db)
+	if err != nil {
+		return false, err
+	}
+
+	if err := i.Get(dest, i.Rebind(query), args...); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// namedExec runs an insert query with named parameters
+func (s *webhookStore) namedExec(query string, arg interface{}) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.NamedExec(query, arg)
+
+	return err
+}
+
+// exec runs an update query, returning `ErrNotFound` if no rows were affected
+func (s *webhookStore) exec(query string, args ...interface{}) error {
+	i, err := instance(s.db)
+	if err != nil {
+		return err
+	}
+
+	return execAffecting(i, query, args...)
+}
+
+func init() {
+	RegisterMigration(&Migration{
+		ID:   3,
+		Name: "create webhook tables",
+		Up: []string{
+			`CREATE TABLE webhooks (
+				id VARCHAR(32) NOT NULL PRIMARY KEY,
+				url VARCHAR(2048) NOT NULL,
+				secret VARCHAR(128) NOT NULL,
+				event_types VARCHAR(1024) NOT NULL,
+				sessions VARCHAR(1024) NOT NULL,
+				active BOOLEAN NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE webhook_deliveries (
+				id VARCHAR(32) NOT NULL PRIMARY KEY,
+				webhook_id VARCHAR(32) NOT NULL,
+				event_id VARCHAR(255) NOT NULL,
+				event_type VARCHAR(64) NOT NULL,
+				payload TEXT NOT NULL,
+				status VARCHAR(16) NOT NULL,
+				attempts INTEGER NOT NULL,
+				next_attempt_at TIMESTAMP NOT NULL,
+				last_status_code INTEGER NOT NULL,
+				last_error VARCHAR(1024) NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			"CREATE INDEX webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at)",
+			"CREATE INDEX webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at)",
+			`CREATE TABLE webhook_attempts (
+				id VARCHAR(32) NOT NULL PRIMARY KEY,
+				delivery_id VARCHAR(32) NOT NULL,
+				attempt INTEGER NOT NULL,
+				status_code INTEGER NOT NULL,
+				error VARCHAR(1024) NOT NULL,
+				duration_ms BIGINT NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			)`,
+			"CREATE INDEX webhook_attempts_delivery ON webhook_attempts (delivery_id)",
+		},
+		Down: []string{"DROP TABLE webhook_attempts", "DROP TABLE webhook_deliveries", "DROP TABLE webhooks"},
+	})
+}
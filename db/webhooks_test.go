@@ -0,0 +1,109 @@
+// Tests the webhooks.go file
+package db
+
+import (
+	// Standard lib
+	"time"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("webhooks.go", func() {
+	var (
+		db    DB
+		store WebhookStore
+		now   = time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+	)
+
+	// newDelivery queues a new delivery, due at a time
+	newDelivery := func(id, status string, due time.Time) *Delivery {
+		d := &Delivery{
+			ID:            id,
+			WebhookID:     "wh",
+			EventID:       "event-" + id,
+			EventType:     "session-open",
+			Payload:       "{}",
+			Status:        status,
+			NextAttemptAt: due,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		Expect(store.CreateDelivery(d)).To(Succeed())
+
+		return d
+	}
+
+	BeforeEach(func() {
+		db = newMigratedTestDB()
+		store = NewWebhookStore(db)
+		Expect(store.Create(&Webhook{ID: "wh", URL: "https://example.com", Active: true, CreatedAt: now, UpdatedAt: now})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Describe("`DueDeliveries` method", func() {
+		It("Returns pending deliveries due to be attempted, oldest first", func() {
+			newDelivery("late", "pending", now.Add(-time.Minute))
+			newDelivery("early", "pending", now.Add(-time.Hour))
+			newDelivery("later", "pending", now.Add(-time.Second))
+			newDelivery("future", "pending", now.Add(time.Minute))
+			newDelivery("done", "delivered", now.Add(-2*time.Hour))
+
+			// Call method
+			due, err := store.DueDeliveries(now, 2)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(due).To(HaveLen(2))
+			Expect(due[0].ID).To(Equal("early"))
+			Expect(due[1].ID).To(Equal("late"))
+		})
+	})
+
+	Describe("`ClaimDelivery` method", func() {
+		It("Claims a due delivery only once", func() {
+			newDelivery("d", "pending", now.Add(-time.Minute))
+			due, _ := store.DueDeliveries(now, 10)
+			Expect(due).To(HaveLen(1))
+			first, second := due[0], *due[0]
+
+			// Call method
+			err := store.ClaimDelivery(first, now.Add(time.Minute))
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(first.NextAttemptAt).To(Equal(now.Add(time.Minute)))
+			Expect(store.DueDeliveries(now, 10)).To(BeEmpty())
+
+			// Verify a worker holding the delivery as it was read loses the claim
+			Expect(store.ClaimDelivery(&second, now.Add(time.Minute))).To(Equal(ErrNotFound))
+
+			d, _ := store.GetDelivery("d")
+			Expect(d.NextAttemptAt).To(BeTemporally("==", now.Add(time.Minute)))
+		})
+
+		It("Doesn't claim deliveries which are no longer pending", func() {
+			d := newDelivery("d", "pending", now.Add(-time.Minute))
+			updated := *d
+			updated.Status, updated.Attempts = "delivered", 1
+			Expect(store.UpdateDelivery(&updated)).To(Succeed())
+
+			// Call method
+			err := store.ClaimDelivery(d, now.Add(time.Minute))
+
+			// Verify output
+			Expect(err).To(Equal(ErrNotFound))
+		})
+
+		It("Returns an error for unknown deliveries", func() {
+			err := store.ClaimDelivery(&Delivery{ID: "missing", NextAttemptAt: now}, now.Add(time.Minute))
+
+			// Verify output
+			Expect(err).To(Equal(ErrNotFound))
+		})
+	})
+})
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	// Standard lib
+	"encoding/json"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/alerts"
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/provider"
+	"github.com/deezone/forex-clock/webhooks"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	goutils "github.com/marksost/go-utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Routes
+	AlertsRoute       = "/alerts"
+	AlertRoute        = "/alerts/{id}"
+	AlertHistoryRoute = "/alert-history"
+
+	// Number of triggers listed by default, and at most
+	defaultAlertHistoryLimit = 50
+	maxAlertHistoryLimit     = 500
+)
+
+type (
+	// Struct representing a route handler for alert routes
+	AlertsHandler struct {
+		store db.AlertStore // Storage of alerts and their triggers
+	}
+	// AlertRequest is a struct defining properties of "create alert" and "update alert" requests
+	AlertRequest struct {
+		Name       string     `json:"name"`
+		Type       string     `json:"type"`
+		Pair       string     `json:"pair"`        // Pair or index, required by price and spread alerts
+		Session    string     `json:"session"`     // Required by session alerts
+		Threshold  *float64   `json:"threshold"`   // Required by price and spread alerts
+		Channels   []string   `json:"channels"`    // Channels notifying triggers
+		WebhookURL string     `json:"webhook-url"` // Required by the webhook channel
+		Email      string     `json:"email"`       // Required by the email channel
+		Cooldown   *int       `json:"cooldown"`    // Seconds, defaults to `alerts.cooldown`
+		Repeat     bool       `json:"repeat"`      // Whether the alert stays active once triggered
+		ExpiresAt  *time.Time `json:"expires-at"`
+		Active     *bool      `json:"active"` // Defaults to true when creating
+	}
+	// AlertResponse is a struct defining properties of responses containing a newly created alert
+	// NOTE: This is the only time the secret signing its webhook notifications is returned
+	AlertResponse struct {
+		// Embedded field
+		*db.Alert
+		Secret string `json:"secret"`
+	}
+)
+
+// NewAlertsHandler creates and returns a new instance of an alerts handler
+func NewAlertsHandler(store db.AlertStore) *AlertsHandler {
+	return &AlertsHandler{store: store}
+}
+
+// Alerts is an http handler used to fulfill "alerts" requests, listing the client's alerts (GET)
+// or creating a new alert (POST)
+// NOTE: Alerts belong to the client that created them, and are only listed, returned, updated, or deleted for it
+func (h AlertsHandler) Alerts(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		h.list(w, req)
+	case http.MethodPost:
+		h.create(w, req)
+	default:
+		helpers.MethodNotAllowed(w, req)
+	}
+}
+
+// Alert is an http handler used to fulfill "alert" requests, returning (GET), updating (PUT)
+// or deleting (DELETE) an alert
+func (h AlertsHandler) Alert(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+
+	switch req.Method {
+	case http.MethodGet:
+		if a := h.get(w, req, id); a != nil {
+			helpers.OK(w, req, a)
+		}
+	case http.MethodPut:
+		h.update(w, req, id)
+	case http.MethodDelete:
+		if err := h.store.Delete(alertOwner(req), id); err != nil {
+			h.storeError(w, req, err)
+			return
+		}
+
+		log.WithField("id", id).Info("Alert deleted")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		helpers.MethodNotAllowed(w, req)
+	}
+}
+
+// History is an http handler used to fulfill "alert history" requests, listing the most recent triggers of the
+// client's alerts, optionally of an alert (`alert`), along with the channels that notified them
+func (h AlertsHandler) History(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	limit, err := intParam(req, "limit", defaultAlertHistoryLimit, 1, maxAlertHistoryLimit)
+	if err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+	f := db.AlertEventFilter{Owner: alertOwner(req), AlertID: req.URL.Query().Get("alert"), Limit: limit}
+
+	events, err := h.store.ListEvents(f)
+	if err != nil {
+		log.WithError(err).Error("Error listing alert triggers")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	data := make([]interface{}, 0, len(events))
+	for _, ev := range events {
+		data = append(data, ev)
+	}
+
+	helpers.OKCollection(w, req, data)
+}
+
+// list sends all of the client's alerts
+func (h AlertsHandler) list(w http.ResponseWriter, req *http.Request) {
+	list, err := h.store.List(alertOwner(req))
+	if err != nil {
+		log.WithError(err).Error("Error listing alerts")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	data := make([]interface{}, 0, len(list))
+	for _, a := range list {
+		data = append(data, a)
+	}
+
+	helpers.OKCollection(w, req, data)
+}
+
+// create stores a new alert from the request body, generating the secret signing its webhook notifications
+func (h AlertsHandler) create(w http.ResponseWriter, req *http.Request) {
+	body := h.decode(w, req)
+	if body == nil {
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		log.WithError(err).Error("Error generating alert secret")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	a := &db.Alert{
+		ID:        db.NewID(),
+		Owner:     alertOwner(req),
+		Secret:    secret,
+		Cooldown:  config.GetInstance().Alerts.Cooldown,
+		Status:    db.AlertActive,
+		CreatedAt: now,
+	}
+	applyAlert(a, body, now)
+
+	if err := h.store.Create(a); err != nil {
+		log.WithError(err).Error("Error creating alert")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	log.WithFields(log.Fields{"id": a.ID, "type": a.Type}).Info("Alert created")
+	helpers.Created(w, req, &AlertResponse{Alert: a, Secret: secret})
+}
+
+// update replaces the settings of an alert from the request body
+// NOTE: The secret and trigger count are kept, and the alert's status is kept unless `active` is given
+func (h AlertsHandler) update(w http.ResponseWriter, req *http.Request, id string) {
+	a := h.get(w, req, id)
+	if a == nil {
+		return
+	}
+
+	body := h.decode(w, req)
+	if body == nil {
+		return
+	}
+
+	applyAlert(a, body, time.Now().UTC().Truncate(time.Second))
+
+	if err := h.store.Update(a); err != nil {
+		h.storeError(w, req, err)
+		return
+	}
+
+	log.WithField("id", id).Info("Alert updated")
+	helpers.OK(w, req, a)
+}
+
+// get returns one of the client's alerts, sending the matching response if it can't be
+func (h AlertsHandler) get(w http.ResponseWriter, req *http.Request, id string) *db.Alert {
+	a, err := h.store.Get(alertOwner(req), id)
+	if err != nil {
+		log.WithError(err).Error("Error getting alert")
+		helpers.InternalError(w, req)
+		return nil
+	}
+	if a == nil {
+		helpers.NotFound(w, req)
+		return nil
+	}
+
+	return a
+}
+
+// decode decodes and validates an alert request body, sending a bad request response if it's invalid
+func (h AlertsHandler) decode(w http.ResponseWriter, req *http.Request) *AlertRequest {
+	body := &AlertRequest{}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: "Invalid request body: " + err.Error()}})
+		return nil
+	}
+
+	errs := []*helpers.Error{}
+	switch {
+	case alerts.QuoteType(body.Type):
+		// NOTE: Indices within `quotes.indices` (ex: VIX) are alerted on like pairs
+		if provider.IsIndex(body.Pair) {
+			body.Pair = provider.NormalizePair(body.Pair)
+		} else if p, err := pairs.New(body.Pair); err != nil {
+			errs = append(errs, &helpers.Error{Message: "`pair` is invalid: " + err.Error()})
+		} else {
+			body.Pair = p.Symbol
+		}
+		if body.Threshold == nil || *body.Threshold <= 0 {
+			errs = append(errs, &helpers.Error{Message: "`threshold` must be a positive number"})
+		}
+		body.Session = ""
+	case alerts.SessionType(body.Type):
+		if body.Session == "" {
+			errs = append(errs, &helpers.Error{Message: "`session` is required by " + body.Type + " alerts"})
+		} else {
+			errs = append(errs, validSessions([]string{body.Session})...)
+		}
+		body.Pair, body.Threshold = "", nil
+	default:
+		errs = append(errs, &helpers.Error{Message: "`type` must be one of: " + strings.Join(alerts.Types, ", ")})
+	}
+
+	if len(body.Channels) == 0 {
+		errs = append(errs, &helpers.Error{Message: "`channels` must contain at least one channel"})
+	}
+	for _, c := range body.Channels {
+		if err := alerts.ValidChannel(c); err != nil {
+			errs = append(errs, &helpers.Error{Message: c + ": " + err.Error()})
+		}
+	}
+	if goutils.SliceContains(alerts.ChannelWebhook, body.Channels) {
+		if u, err := url.Parse(body.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, &helpers.Error{Message: "`webhook-url` must be an absolute http or https URL"})
+		} else if err := webhooks.ValidHost(u.Hostname()); err != nil {
+			errs = append(errs, &helpers.Error{Message: "`webhook-url` is invalid: " + err.Error()})
+		}
+	}
+	if goutils.SliceContains(alerts.ChannelEmail, body.Channels) {
+		if addr, err := mail.ParseAddress(body.Email); err != nil {
+			errs = append(errs, &helpers.Error{Message: "`email` must be a valid email address"})
+		} else {
+			body.Email = addr.Address
+		}
+	}
+
+	if body.Cooldown != nil && *body.Cooldown < 0 {
+		errs = append(errs, &helpers.Error{Message: "`cooldown` can't be negative"})
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		errs = append(errs, &helpers.Error{Message: "`expires-at` must be in the future"})
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return nil
+	}
+
+	return body
+}
+
+// storeError sends the response matching an error returned when updating an alert
+func (h AlertsHandler) storeError(w http.ResponseWriter, req *http.Request, err error) {
+	if err == db.ErrNotFound {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	log.WithError(err).Error("Error updating alert")
+	helpers.InternalError(w, req)
+}
+
+// alertOwner returns the owner of alerts created by a request's principal, or an empty string for anonymous requests
+// NOTE: Owners are qualified by the type of principal, as API key IDs and JWT subjects may collide
+func alertOwner(req *http.Request) string {
+	p := auth.FromContext(req.Context())
+	if p == nil {
+		return ""
+	}
+
+	return p.Type + ":" + p.ID
+}
+
+// applyAlert sets the settings of an alert from a validated request body
+func applyAlert(a *db.Alert, body *AlertRequest, now time.Time) {
+	a.Name, a.Type, a.Pair, a.Session = body.Name, body.Type, body.Pair, body.Session
+	a.Channels, a.WebhookURL, a.Email, a.Repeat = body.Channels, body.WebhookURL, body.Email, body.Repeat
+	a.Threshold = 0
+	if body.Threshold != nil {
+		a.Threshold = *body.Threshold
+	}
+	if body.Cooldown != nil {
+		a.Cooldown = *body.Cooldown
+	}
+	a.ExpiresAt = nil
+	if body.ExpiresAt != nil {
+		expires := body.ExpiresAt.UTC().Truncate(time.Second)
+		a.ExpiresAt = &expires
+	}
+	if body.Active != nil {
+		a.Status = db.AlertDisabled
+		if *body.Active {
+			a.Status = db.AlertActive
+		}
+	}
+	a.UpdatedAt = now
+}
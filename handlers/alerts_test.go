@@ -0,0 +1,137 @@
+// Tests the alerts.go file
+package handlers
+
+import (
+	// Standard lib
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/db/dbtest"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("alerts.go", func() {
+	var (
+		conn   db.DB
+		router *mux.Router
+
+		// Clients creating alerts
+		owner = &auth.Principal{ID: "k1", Type: auth.PrincipalTypeAPIKey, Scopes: []string{auth.ScopeAlerts}}
+		other = &auth.Principal{ID: "k2", Type: auth.PrincipalTypeAPIKey, Scopes: []string{auth.ScopeAlerts}}
+	)
+
+	// serve makes a request as a client, returning the recorded response
+	serve := func(p *auth.Principal, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req = req.WithContext(auth.NewContext(req.Context(), p))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// count returns the number of resources within a collection response
+	count := func(w *httptest.ResponseRecorder) int {
+		body := &struct {
+			Meta struct {
+				Count int `json:"count"`
+			} `json:"meta"`
+		}{}
+		Expect(json.Unmarshal(w.Body.Bytes(), body)).To(Succeed())
+
+		return body.Meta.Count
+	}
+
+	BeforeEach(func() {
+		conn = dbtest.New()
+		h := NewAlertsHandler(db.NewAlertStore(conn))
+
+		router = mux.NewRouter()
+		router.HandleFunc(AlertsRoute, h.Alerts)
+		router.HandleFunc(AlertRoute, h.Alert)
+		router.HandleFunc(AlertHistoryRoute, h.History)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+	})
+
+	It("Only lets clients use the alerts they created", func() {
+		w := serve(owner, http.MethodPost, AlertsRoute, `{"type": "price-above", "pair": "EURUSD", "threshold": 1.1, "channels": ["log"]}`)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+
+		created := &struct {
+			Data struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}{}
+		Expect(json.Unmarshal(w.Body.Bytes(), created)).To(Succeed())
+		route := AlertsRoute + "/" + created.Data.ID
+
+		// Verify output
+		Expect(count(serve(owner, http.MethodGet, AlertsRoute, ""))).To(Equal(1))
+		Expect(serve(owner, http.MethodGet, route, "").Code).To(Equal(http.StatusOK))
+
+		Expect(count(serve(other, http.MethodGet, AlertsRoute, ""))).To(Equal(0))
+		Expect(count(serve(nil, http.MethodGet, AlertsRoute, ""))).To(Equal(0))
+		Expect(serve(other, http.MethodGet, route, "").Code).To(Equal(http.StatusNotFound))
+		Expect(serve(other, http.MethodPut, route, `{"type": "price-above", "pair": "EURUSD", "threshold": 1.2, "channels": ["log"]}`).Code).To(Equal(http.StatusNotFound))
+		Expect(serve(other, http.MethodDelete, route, "").Code).To(Equal(http.StatusNotFound))
+
+		// NOTE: Principals of different types may share an ID
+		jwt := &auth.Principal{ID: "k1", Type: auth.PrincipalTypeJWT, Scopes: []string{auth.ScopeAlerts}}
+		Expect(serve(jwt, http.MethodGet, route, "").Code).To(Equal(http.StatusNotFound))
+
+		Expect(serve(owner, http.MethodDelete, route, "").Code).To(Equal(http.StatusNoContent))
+		Expect(count(serve(owner, http.MethodGet, AlertsRoute, ""))).To(Equal(0))
+	})
+
+	It("Creates alerts on pairs and indices", func() {
+		for pair, expected := range map[string]string{"eur/usd": "EURUSD", "vix": "VIX"} {
+			w := serve(owner, http.MethodPost, AlertsRoute, `{"type": "price-above", "pair": "`+pair+`", "threshold": 30, "channels": ["log"]}`)
+
+			// Verify output
+			Expect(w.Code).To(Equal(http.StatusCreated), pair)
+			Expect(w.Body.String()).To(ContainSubstring(`"pair":"`+expected+`"`), pair)
+		}
+
+		w := serve(owner, http.MethodPost, AlertsRoute, `{"type": "price-above", "pair": "SPX", "threshold": 5000, "channels": ["log"]}`)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("`pair` is invalid"))
+	})
+
+	It("Rejects webhook URLs of private addresses", func() {
+		for _, target := range []string{"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://169.254.169.254/latest", "https://[::1]/hook"} {
+			w := serve(owner, http.MethodPost, AlertsRoute, `{"type": "price-above", "pair": "EURUSD", "threshold": 1.1, "channels": ["webhook"], "webhook-url": "`+target+`"}`)
+
+			// Verify output
+			Expect(w.Code).To(Equal(http.StatusBadRequest), target)
+			Expect(w.Body.String()).To(ContainSubstring("`webhook-url` is invalid"), target)
+		}
+
+		w := serve(owner, http.MethodPost, AlertsRoute, `{"type": "price-above", "pair": "EURUSD", "threshold": 1.1, "channels": ["webhook"], "webhook-url": "https://example.com/hook"}`)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+	})
+
+	It("Validates the limit of triggers listed", func() {
+		for _, limit := range []string{"0", "501", "ten"} {
+			w := serve(owner, http.MethodGet, AlertHistoryRoute+"?limit="+limit, "")
+
+			// Verify output
+			Expect(w.Code).To(Equal(http.StatusBadRequest), limit)
+			Expect(w.Body.String()).To(ContainSubstring("Invalid `limit` parameter, expected an integer from 1 to 500"), limit)
+		}
+
+		Expect(serve(owner, http.MethodGet, AlertHistoryRoute+"?limit=500", "").Code).To(Equal(http.StatusOK))
+	})
+})
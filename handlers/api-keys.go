@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	// Standard lib
+	"encoding/json"
+	"net/http"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/ratelimit"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Routes
+	APIKeysRoute      = "/admin/api-keys"
+	APIKeyRoute       = "/admin/api-keys/{id}"
+	APIKeyRotateRoute = "/admin/api-keys/{id}/rotate"
+	APIKeyUsageRoute  = "/admin/api-keys/{id}/usage"
+
+	// Number of characters of a key stored as its displayed prefix
+	apiKeyDisplayPrefix = 8
+)
+
+type (
+	// Struct representing a route handler for API key administration routes
+	APIKeysHandler struct {
+		store   db.APIKeyStore     // Storage of API keys
+		limiter *ratelimit.Limiter // Limiter tracking daily quotas
+	}
+	// APIKeyRequest is a struct defining properties of "create API key" requests
+	APIKeyRequest struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+	// APIKeyResponse is a struct defining properties of responses containing a newly generated key
+	// NOTE: This is the only time the key itself is returned
+	APIKeyResponse struct {
+		// Embedded field
+		*db.APIKey
+		Key string `json:"key"`
+	}
+	// APIKeyUsageResponse is a struct defining properties of "API key usage" responses
+	APIKeyUsageResponse struct {
+		ID    string                 `json:"id"`
+		Quota *ratelimit.QuotaStatus `json:"quota"` // Daily quota, null when no quota is configured
+	}
+)
+
+// NewAPIKeysHandler creates and returns a new instance of an API keys handler
+func NewAPIKeysHandler(store db.APIKeyStore, limiter *ratelimit.Limiter) *APIKeysHandler {
+	return &APIKeysHandler{store: store, limiter: limiter}
+}
+
+// APIKeys is an http handler used to fulfill "API keys" requests, listing all keys (GET)
+// or creating a new key (POST)
+func (h APIKeysHandler) APIKeys(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		h.list(w, req)
+	case http.MethodPost:
+		h.create(w, req)
+	default:
+		helpers.MethodNotAllowed(w, req)
+	}
+}
+
+// APIKey is an http handler used to fulfill "API key" requests, returning (GET) or revoking (DELETE) a key
+func (h APIKeysHandler) APIKey(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+
+	switch req.Method {
+	case http.MethodGet:
+		k, err := h.store.Get(id)
+		if err != nil {
+			log.WithError(err).Error("Error getting API key")
+			helpers.InternalError(w, req)
+			return
+		}
+		if k == nil {
+			helpers.NotFound(w, req)
+			return
+		}
+
+		helpers.OK(w, req, k)
+	case http.MethodDelete:
+		if err := h.store.Revoke(id); err != nil {
+			h.storeError(w, req, err)
+			return
+		}
+
+		log.WithField("id", id).Info("API key revoked")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		helpers.MethodNotAllowed(w, req)
+	}
+}
+
+// Rotate is an http handler used to fulfill "rotate API key" requests, replacing the value of an
+// active key while keeping its name and scopes
+func (h APIKeysHandler) Rotate(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodPost {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	id := mux.Vars(req)["id"]
+	key, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		log.WithError(err).Error("Error generating API key")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	if err := h.store.Rotate(id, key[:apiKeyDisplayPrefix], hash); err != nil {
+		h.storeError(w, req, err)
+		return
+	}
+
+	k, err := h.store.Get(id)
+	if err != nil || k == nil {
+		log.WithError(err).Error("Error getting rotated API key")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	log.WithField("id", id).Info("API key rotated")
+	helpers.OK(w, req, &APIKeyResponse{APIKey: k, Key: key})
+}
+
+// Usage is an http handler used to fulfill "API key usage" requests, returning the use of a key's daily quota
+func (h APIKeysHandler) Usage(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	k, err := h.store.Get(mux.Vars(req)["id"])
+	if err != nil {
+		log.WithError(err).Error("Error getting API key")
+		helpers.InternalError(w, req)
+		return
+	}
+	if k == nil {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	q, err := h.limiter.Quota(k.ID, time.Now())
+	if err != nil {
+		log.WithError(err).Error("Error getting API key quota")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	helpers.OK(w, req, &APIKeyUsageResponse{ID: k.ID, Quota: q})
+}
+
+// list sends all API keys
+func (h APIKeysHandler) list(w http.ResponseWriter, req *http.Request) {
+	keys, err := h.store.List()
+	if err != nil {
+		log.WithError(err).Error("Error listing API keys")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	data := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		data = append(data, k)
+	}
+
+	helpers.OKCollection(w, req, data)
+}
+
+// create generates and stores a new API key from the request body
+func (h APIKeysHandler) create(w http.ResponseWriter, req *http.Request) {
+	body := &APIKeyRequest{}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: "Invalid request body: " + err.Error()}})
+		return
+	}
+
+	errs := []*helpers.Error{}
+	if body.Name == "" {
+		errs = append(errs, &helpers.Error{Message: "`name` is required"})
+	}
+	if len(body.Scopes) == 0 {
+		errs = append(errs, &helpers.Error{Message: "`scopes` must contain at least one scope"})
+	}
+	for _, s := range body.Scopes {
+		if !auth.ValidScope(s) {
+			errs = append(errs, &helpers.Error{Message: "Unknown scope: " + s})
+		}
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	key, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		log.WithError(err).Error("Error generating API key")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	k := &db.APIKey{
+		ID:        db.NewID(),
+		Name:      body.Name,
+		Prefix:    key[:apiKeyDisplayPrefix],
+		Hash:      hash,
+		Scopes:    body.Scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := h.store.Create(k); err != nil {
+		log.WithError(err).Error("Error creating API key")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	log.WithFields(log.Fields{"id": k.ID, "scopes": k.Scopes}).Info("API key created")
+	helpers.Created(w, req, &APIKeyResponse{APIKey: k, Key: key})
+}
+
+// storeError sends the response matching an error returned when updating a key
+func (h APIKeysHandler) storeError(w http.ResponseWriter, req *http.Request, err error) {
+	if err == db.ErrNotFound {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	log.WithError(err).Error("Error updating API key")
+	helpers.InternalError(w, req)
+}
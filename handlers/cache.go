@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+
+	// Internal
+	"github.com/deezone/forex-clock/cache"
+	"github.com/deezone/forex-clock/helpers"
+)
+
+const (
+	// Routes
+	CacheStatsRoute = "/admin/cache"
+)
+
+type (
+	// Struct representing a route handler for cache administration routes
+	CacheHandler struct{}
+)
+
+// NewCacheHandler creates and returns a new instance of a cache handler
+func NewCacheHandler() *CacheHandler { return &CacheHandler{} }
+
+// Stats is an http handler used to fulfill "cache stats" requests, returning the hits, misses, and size of every cache
+func (h CacheHandler) Stats(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	data := make([]interface{}, 0)
+	for _, c := range cache.All() {
+		data = append(data, c.Stats())
+	}
+
+	// Stats change with every request
+	helpers.SetCachePolicy(w, helpers.NoStorePolicy)
+
+	// Use helper response method
+	helpers.OKCollection(w, req, data)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/candles"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/provider"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Routes
+	CandlesRoute = "/candles/{pair}"
+
+	// Query parameter defaults and limits
+	DefaultCandleInterval = "1h"
+	DefaultCandleLimit    = 500
+	MaxCandleLimit        = 5000
+)
+
+type (
+	// Struct representing a route handler for candle routes
+	CandlesHandler struct {
+		store db.CandleStore // Storage of candles aggregated from ticks
+	}
+	// Struct representing a page of a pair's candles requested by query parameters
+	candleQuery struct {
+		pair     string
+		interval *candles.Interval
+		from     time.Time
+		to       time.Time
+		limit    int
+	}
+)
+
+var (
+	// Cache policy of candle responses, which must be revalidated as the latest candle changes every tick
+	candlesCachePolicy = &helpers.CachePolicy{NoCache: true}
+)
+
+// NewCandlesHandler creates and returns a new instance of a candles handler
+func NewCandlesHandler(store db.CandleStore) *CandlesHandler { return &CandlesHandler{store: store} }
+
+// Candles is an http handler used to fulfill "candles" requests, returning a page of a pair's candles of an interval
+// (`interval`) opening within a range (`from` to `to`, RFC 3339), oldest first
+// NOTE: `from` defaults to `limit` intervals before `to`, which defaults to now
+func (h CandlesHandler) Candles(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	q, errs := parseCandleQuery(req)
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	// NOTE: Reads an extra candle to tell whether there's a next page
+	list, err := h.store.List(db.CandleFilter{Pair: q.pair, Interval: q.interval.Name, From: q.from, To: q.to, Limit: q.limit + 1})
+	if err != nil {
+		log.WithError(err).Error("Error listing candles")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	p := &helpers.Pagination{Limit: q.limit}
+	if len(list) > q.limit {
+		p.Next = q.next(req, list[q.limit].OpenTime)
+		list = list[:q.limit]
+	}
+
+	data := make([]interface{}, 0, len(list))
+	for _, c := range list {
+		data = append(data, c)
+	}
+
+	helpers.SetCachePolicy(w, candlesCachePolicy)
+
+	// Use helper response method
+	helpers.OKPage(w, req, data, p)
+}
+
+// parseCandleQuery parses the pair (`pair` route variable), interval (`interval`), range (`from` to `to`), and page
+// size (`limit`) of candles requested, returning errors of invalid parameters
+func parseCandleQuery(req *http.Request) (*candleQuery, []*helpers.Error) {
+	q := &candleQuery{pair: provider.NormalizePair(mux.Vars(req)["pair"])}
+	name := req.URL.Query().Get("interval")
+	if name == "" {
+		name = DefaultCandleInterval
+	}
+
+	errs := []*helpers.Error{}
+	if _, err := pairs.New(q.pair); err != nil {
+		errs = append(errs, &helpers.Error{Message: "Invalid currency pair: " + mux.Vars(req)["pair"]})
+	}
+	if q.interval = candles.LookupInterval(name); q.interval == nil {
+		errs = append(errs, &helpers.Error{Message: "Unknown interval: " + name})
+	}
+
+	var err error
+	if q.limit, err = intParam(req, "limit", DefaultCandleLimit, 1, MaxCandleLimit); err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if q.to, err = timeParam(req, "to", time.Now().UTC()); err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if q.interval != nil {
+		if q.from, err = timeParam(req, "from", q.to.Add(-time.Duration(q.limit)*q.interval.Duration)); err != nil {
+			errs = append(errs, &helpers.Error{Message: err.Error()})
+		}
+	}
+	if len(errs) == 0 && !q.from.Before(q.to) {
+		errs = append(errs, &helpers.Error{Message: "Invalid range, `from` must be before `to`"})
+	}
+
+	return q, errs
+}
+
+// next returns the relative URL of the next page, starting at a candle's open time
+func (q *candleQuery) next(req *http.Request, from time.Time) string {
+	u := *req.URL
+	query := u.Query()
+	query.Set("from", from.UTC().Format(time.RFC3339))
+	query.Set("to", q.to.UTC().Format(time.RFC3339))
+	u.RawQuery = query.Encode()
+
+	return u.RequestURI()
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/deezone/forex-clock/config"
 	"github.com/deezone/forex-clock/db"
 	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/provider"
 
 	// Third-party
 	"github.com/marksost/go-utils"
@@ -23,14 +24,16 @@ const (
 	VersionRoute = "/version"
 
 	// Ready statuses
-	ReadyStatusOK    = "ok"
-	ReadyStatusError = "error"
+	ReadyStatusOK       = "ok"
+	ReadyStatusError    = "error"
+	ReadyStatusDisabled = "disabled"
 )
 
 type (
 	// Struct representing a route handler for health / ready / version routes
 	HealthHandler struct {
-		db  db.DB   // DB instance that the handler can use
+		db       db.DB             // DB instance that the handler can use
+		provider provider.Provider // Source of FX quotes, nil when market data is disabled
 	}
 	// HealthResponse is a struct defining properties all "health" responses should contain
 	HealthResponse struct {
@@ -40,9 +43,11 @@ type (
 	ReadyResponse struct {
 		// Embeeded field
 		*HealthResponse
-		Service string `json:"service"`
-		DB      string `json:"db"`
-		DBType  string `json:"db-type"`
+		Service      string `json:"service"`
+		DB           string `json:"db"`
+		DBType       string `json:"db-type"`
+		Provider     string `json:"provider"`
+		ProviderType string `json:"provider-type"`
 	}
 	// VersionResponse is a struct defining properties all "version" responses should contain
 	VersionResponse struct {
@@ -53,9 +58,10 @@ type (
 )
 
 // NewHealthHandler creates and returns a new instance of a health handler
-func NewHealthHandler(db db.DB) *HealthHandler {
+func NewHealthHandler(db db.DB, p provider.Provider) *HealthHandler {
 	return &HealthHandler{
-		db:  db,
+		db:       db,
+		provider: p,
 	}
 }
 
@@ -67,13 +73,22 @@ func NewHealthResponse() *HealthResponse {
 }
 
 // NewReadyResponse creates and returns a new instance of a ready response
-func NewReadyResponse(db db.DB) *ReadyResponse {
-	return &ReadyResponse{
+func NewReadyResponse(db db.DB, p provider.Provider) *ReadyResponse {
+	resp := &ReadyResponse{
 		HealthResponse: NewHealthResponse(),
 		Service:        ReadyStatusOK,
 		DB:             checkDB(db),
 		DBType:         db.String(),
+		Provider:       ReadyStatusDisabled,
+		ProviderType:   provider.TypeNone,
 	}
+
+	if p != nil {
+		resp.Provider = checkProvider(p)
+		resp.ProviderType = p.Name()
+	}
+
+	return resp
 }
 
 // NewVersionResponse creates and returns a new instance of a version response
@@ -92,6 +107,9 @@ func (h HealthHandler) Health(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Probes must always reach the service
+	helpers.SetCachePolicy(w, helpers.NoStorePolicy)
+
 	// Use helper response method
 	helpers.OK(w, req, NewHealthResponse())
 }
@@ -104,11 +122,14 @@ func (h HealthHandler) Ready(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Probes must always reach the service
+	helpers.SetCachePolicy(w, helpers.NoStorePolicy)
+
 	// Form response
-	resp := NewReadyResponse(h.db)
+	resp := NewReadyResponse(h.db, h.provider)
 
 	// Ready checks to ensure aren't errors
-	resps := []string{resp.DB}
+	resps := []string{resp.DB, resp.Provider}
 
 	// Check for errors, output 500 response
 	if goutils.SliceContains(ReadyStatusError, resps) {
@@ -149,3 +170,13 @@ func checkDB(db db.DB) string {
 
 	return ReadyStatusOK
 }
+
+// checkProvider checks the health of a source of FX quotes
+func checkProvider(p provider.Provider) string {
+	if err := p.Health(); err != nil {
+		log.WithError(err).Error("Error checking FX quote provider")
+		return ReadyStatusError
+	}
+
+	return ReadyStatusOK
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	// Standard lib
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/indicators"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Routes
+	IndicatorsRoute = "/indicators/{pair}"
+)
+
+type (
+	// Struct representing a route handler for technical indicator routes
+	IndicatorsHandler struct {
+		store db.CandleStore // Storage of candles indicators are computed from
+	}
+	// IndicatorPoint is a struct representing the values of an indicator at a single candle
+	IndicatorPoint struct {
+		Time   time.Time          `json:"time"`   // Open time of the candle
+		Values map[string]float64 `json:"values"` // Value of each of the indicator's series, by name
+	}
+)
+
+// NewIndicatorsHandler creates and returns a new instance of an indicators handler
+func NewIndicatorsHandler(store db.CandleStore) *IndicatorsHandler {
+	return &IndicatorsHandler{store: store}
+}
+
+// Indicators is an http handler used to fulfill "indicators" requests, returning a page of the values of an indicator
+// (`name`, with comma-separated positional `params`) computed from a pair's candles, accepting the same parameters as
+// candles requests
+// NOTE: Candles before the range are read to warm the indicator up, and candles without enough history are omitted
+func (h IndicatorsHandler) Indicators(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	q, errs := parseCandleQuery(req)
+	ind, err := indicatorParam(req)
+	if err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	// Read the candles warming the indicator up, then the page
+	// NOTE: Reads an extra candle to tell whether there's a next page
+	list := []*db.Candle{}
+	if ind.WarmUp() > 0 {
+		list, err = h.store.List(db.CandleFilter{Pair: q.pair, Interval: q.interval.Name, To: q.from, Limit: ind.WarmUp(), Latest: true})
+	}
+	warmUp := len(list)
+	if err == nil {
+		var page []*db.Candle
+		page, err = h.store.List(db.CandleFilter{Pair: q.pair, Interval: q.interval.Name, From: q.from, To: q.to, Limit: q.limit + 1})
+		list = append(list, page...)
+	}
+	if err != nil {
+		log.WithError(err).Error("Error listing candles")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	p := &helpers.Pagination{Limit: q.limit}
+	if len(list)-warmUp > q.limit {
+		p.Next = q.next(req, list[warmUp+q.limit].OpenTime)
+		list = list[:warmUp+q.limit]
+	}
+
+	bars := make([]indicators.Bar, len(list))
+	for n, c := range list {
+		bars[n] = indicators.Bar{Open: c.Open, High: c.High, Low: c.Low, Close: c.Close}
+	}
+	series, outputs := ind.Compute(bars), ind.Outputs()
+
+	data := make([]interface{}, 0, len(list)-warmUp)
+	for n := warmUp; n < len(list); n++ {
+		pt := &IndicatorPoint{Time: list[n].OpenTime, Values: map[string]float64{}}
+		for s, name := range outputs {
+			if !math.IsNaN(series[s][n]) {
+				pt.Values[name] = series[s][n]
+			}
+		}
+		if len(pt.Values) == len(outputs) {
+			data = append(data, pt)
+		}
+	}
+
+	helpers.SetCachePolicy(w, candlesCachePolicy)
+
+	// Use helper response method
+	helpers.OKPage(w, req, data, p)
+}
+
+// indicatorParam creates the indicator named by the `name` query parameter, from the comma-separated positional
+// parameters of the `params` query parameter
+func indicatorParam(req *http.Request) (indicators.Indicator, error) {
+	q := req.URL.Query()
+	d := indicators.Lookup(q.Get("name"))
+	if d == nil {
+		names := []string{}
+		for _, d := range indicators.Definitions {
+			names = append(names, d.Name)
+		}
+		return nil, &paramError{name: "name", expected: "one of " + strings.Join(names, ", ")}
+	}
+
+	params := []float64{}
+	for _, v := range config.SplitList(q.Get("params")) {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, &paramError{name: "params", expected: "comma-separated numbers"}
+		}
+		params = append(params, f)
+	}
+
+	return d.New(params)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/provider"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	goutils "github.com/marksost/go-utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Routes
+	PairsRoute = "/pairs"
+	PairRoute  = "/pairs/{symbol}"
+
+	// Caching
+	// NOTE: Pairs are only added at start up
+	PairsAge = time.Hour // Time a pairs response is fresh
+)
+
+type (
+	// Struct representing a route handler for currency pair routes
+	PairsHandler struct {
+		store db.PairStore // Storage of currency pairs
+	}
+	// PairResponse is a struct defining properties of "pair" responses
+	PairResponse struct {
+		// Embedded field
+		*db.Pair
+		BaseCurrency  *pairs.Currency `json:"base-currency"`
+		QuoteCurrency *pairs.Currency `json:"quote-currency"`
+	}
+)
+
+var (
+	// Cache policy of pairs responses
+	pairsCachePolicy = &helpers.CachePolicy{MaxAge: PairsAge}
+)
+
+// NewPairsHandler creates and returns a new instance of a pairs handler
+func NewPairsHandler(store db.PairStore) *PairsHandler { return &PairsHandler{store: store} }
+
+// Pairs is an http handler used to fulfill "pairs" requests, listing every currency pair, optionally only
+// those including a currency (`currency`, ISO 4217 code) or of a class (`class`)
+func (h PairsHandler) Pairs(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	q := req.URL.Query()
+	f := db.PairFilter{Currency: strings.ToUpper(q.Get("currency")), Class: q.Get("class")}
+
+	errs := []*helpers.Error{}
+	if f.Currency != "" && pairs.LookupCurrency(f.Currency) == nil {
+		errs = append(errs, &helpers.Error{Message: "Unknown ISO 4217 currency: " + q.Get("currency")})
+	}
+	if f.Class != "" && !goutils.SliceContains(f.Class, pairs.Classes) {
+		errs = append(errs, &helpers.Error{Message: "Unknown class: " + f.Class})
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	list, err := h.store.List(f)
+	if err != nil {
+		log.WithError(err).Error("Error listing currency pairs")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	data := make([]interface{}, 0, len(list))
+	for _, p := range list {
+		data = append(data, p)
+	}
+
+	helpers.SetCachePolicy(w, pairsCachePolicy)
+
+	// Use helper response method
+	helpers.OKCollection(w, req, data)
+}
+
+// Pair is an http handler used to fulfill "pair" requests, returning a single currency pair along with its
+// base and quote currencies
+func (h PairsHandler) Pair(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	p, err := h.store.Get(provider.NormalizePair(mux.Vars(req)["symbol"]))
+	if err != nil {
+		log.WithError(err).Error("Error getting currency pair")
+		helpers.InternalError(w, req)
+		return
+	}
+	if p == nil {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	helpers.SetCachePolicy(w, pairsCachePolicy)
+
+	// Use helper response method
+	helpers.OK(w, req, &PairResponse{
+		Pair:          p,
+		BaseCurrency:  pairs.LookupCurrency(p.Base),
+		QuoteCurrency: pairs.LookupCurrency(p.Quote),
+	})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	// Standard lib
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// Struct representing an invalid query parameter
+	paramError struct {
+		name     string // Name of the parameter
+		expected string // Description of the expected value
+	}
+)
+
+// Error returns a human-readable description of the invalid parameter
+func (e *paramError) Error() string {
+	return "Invalid `" + e.name + "` parameter, expected " + e.expected
+}
+
+// timeParam parses an optional RFC 3339 query parameter
+func timeParam(req *http.Request, name string, def time.Time) (time.Time, error) {
+	v := req.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return def, &paramError{name: name, expected: "an RFC 3339 time"}
+	}
+
+	return t, nil
+}
+
+// intParam parses an optional integer query parameter within a range
+func intParam(req *http.Request, name string, def, min, max int) (int, error) {
+	v := req.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		return def, &paramError{name: name, expected: "an integer from " + strconv.Itoa(min) + " to " + strconv.Itoa(max)}
+	}
+
+	return n, nil
+}
+
+// floatParam parses an optional numeric query parameter within a range
+// NOTE: "NaN" is parsed as a number, but is rejected as it is neither within nor outside of any range
+func floatParam(req *http.Request, name string, def, min, max float64) (float64, error) {
+	v := req.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || f < min || f > max {
+		return def, &paramError{name: name, expected: "a number from " + strconv.FormatFloat(min, 'f', -1, 64) + " to " + strconv.FormatFloat(max, 'f', -1, 64)}
+	}
+
+	return f, nil
+}
+
+// boolParam parses an optional boolean query parameter
+func boolParam(req *http.Request, name string, def bool) (bool, error) {
+	v := req.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def, &paramError{name: name, expected: "true or false"}
+	}
+
+	return b, nil
+}
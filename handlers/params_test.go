@@ -0,0 +1,48 @@
+// Tests the params.go file
+package handlers
+
+import (
+	// Standard lib
+	"math"
+	"net/http"
+	"net/http/httptest"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("params.go", func() {
+	// request returns a request with a query string
+	request := func(query string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+	}
+
+	Describe("`floatParam` method", func() {
+		It("Parses numbers within a range", func() {
+			for query, expected := range map[string]float64{"": 5, "amount=0": 0, "amount=2.5": 2.5, "amount=1e1": 10} {
+				// Call method
+				f, err := floatParam(request(query), "amount", 5, 0, 10)
+
+				// Verify output
+				Expect(err).To(Not(HaveOccurred()), query)
+				Expect(f).To(Equal(expected), query)
+			}
+		})
+
+		It("Rejects invalid numbers and numbers outside of the range", func() {
+			for _, v := range []string{"NaN", "nan", "-NaN", "Inf", "-Inf", "+Infinity", "10.5", "-1", "five"} {
+				// Call method
+				f, err := floatParam(request("amount="+v), "amount", 5, 0, 10)
+
+				// Verify output
+				Expect(err).To(MatchError("Invalid `amount` parameter, expected a number from 0 to 10"), v)
+				Expect(f).To(Equal(5.0), v)
+			}
+
+			// Verify NaN is rejected by unbounded ranges
+			_, err := floatParam(request("amount=NaN"), "amount", 0, math.Inf(-1), math.Inf(1))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
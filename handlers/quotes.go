@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/quotes"
+
+	// Third-party
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Routes
+	QuotesRoute  = "/quotes"
+	QuoteRoute   = "/quotes/{pair}"
+	ConvertRoute = "/convert"
+
+	// Largest amount converted
+	MaxConvertAmount = 1e15
+)
+
+type (
+	// Struct representing a route handler for FX quote routes
+	QuotesHandler struct {
+		store *quotes.Store // Snapshot of the latest quote of each pair
+	}
+)
+
+var (
+	// Cache policy of quote responses, which must be revalidated as quotes change every tick
+	quotesCachePolicy = &helpers.CachePolicy{NoCache: true}
+)
+
+// NewQuotesHandler creates and returns a new instance of a quotes handler
+func NewQuotesHandler(store *quotes.Store) *QuotesHandler { return &QuotesHandler{store: store} }
+
+// Quotes is an http handler used to fulfill "quotes" requests, returning the latest quote of every pair, optionally
+// only those of a set of pairs (`pairs`, comma-separated), deriving those that aren't quoted
+func (h QuotesHandler) Quotes(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	list := h.store.List(nil)
+	if symbols := config.SplitList(req.URL.Query().Get("pairs")); len(symbols) > 0 {
+		list = h.store.CrossList(symbols)
+	}
+	data := make([]interface{}, 0, len(list))
+	for _, q := range list {
+		data = append(data, q)
+	}
+
+	helpers.SetCachePolicy(w, quotesCachePolicy)
+
+	// Use helper response method
+	helpers.OKCollection(w, req, data)
+}
+
+// Quote is an http handler used to fulfill "quote" requests, returning the latest quote of a single pair, deriving it
+// when it isn't quoted
+func (h QuotesHandler) Quote(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	q := h.store.Cross(mux.Vars(req)["pair"])
+	if q == nil {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	helpers.SetCachePolicy(w, quotesCachePolicy)
+
+	// Use helper response method
+	helpers.OK(w, req, q)
+}
+
+// Convert is an http handler used to fulfill "convert" requests, converting an amount (`amount`, defaulting to 1) of
+// one currency (`from`) into another (`to`) at the latest mid price
+func (h QuotesHandler) Convert(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	q := req.URL.Query()
+	errs := []*helpers.Error{}
+	for _, name := range []string{"from", "to"} {
+		if pairs.LookupCurrency(strings.ToUpper(q.Get(name))) == nil {
+			errs = append(errs, &helpers.Error{Message: "Invalid `" + name + "` parameter, expected an ISO 4217 currency code"})
+		}
+	}
+	amount, err := floatParam(req, "amount", 1, 0, MaxConvertAmount)
+	if err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	c, err := h.store.Convert(q.Get("from"), q.Get("to"), amount)
+	if err != nil {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	helpers.SetCachePolicy(w, quotesCachePolicy)
+
+	// Use helper response method
+	helpers.OK(w, req, c)
+}
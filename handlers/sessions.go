@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/ical"
+	"github.com/deezone/forex-clock/sessions"
+
+	// Third-party
+	"github.com/marksost/go-utils"
+)
+
+const (
+	// Routes
+	SessionsRoute         = "/sessions"
+	SessionsNextRoute     = "/sessions/next"
+	SessionsCalendarRoute = "/sessions/calendar"
+	SessionsICSRoute      = "/sessions/calendar.ics"
+	SessionsDSTRoute      = "/sessions/dst"
+
+	// Limits
+	DefaultCalendarDays = 7
+	MaxCalendarDays     = 92
+	DefaultDSTDays      = 365
+	MaxDSTDays          = 731
+	MaxNextEvents       = 100
+
+	// Caching
+	MaxLiveSessionsAge = 5 * time.Minute // Longest time a response for the current time is fresh
+	FixedSessionsAge   = time.Hour       // Time a response for a fixed point in time, or a calendar, is fresh
+)
+
+type (
+	// Struct representing a route handler for trading session routes
+	SessionsHandler struct{}
+	// DSTResponse is a struct defining properties of "daylight saving time" responses
+	DSTResponse struct {
+		ClockChanges []*sessions.ClockChange `json:"clock-changes"`
+		Shifts       []*sessions.Shift       `json:"shifts"`
+	}
+)
+
+// NewSessionsHandler creates and returns a new instance of a sessions handler
+func NewSessionsHandler() *SessionsHandler { return &SessionsHandler{} }
+
+// Sessions is an http handler used to fulfill "sessions" requests, returning the state of the
+// market and every session at a point in time (`at`, RFC 3339, defaults to now)
+func (h SessionsHandler) Sessions(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	t, err := timeParam(req, "at", time.Now())
+	if err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+
+	e := sessions.GetInstance()
+	helpers.SetCachePolicy(w, sessionsCachePolicy(req, e, t))
+
+	// Use helper response method
+	helpers.OK(w, req, e.Status(t))
+}
+
+// Next is an http handler used to fulfill "next sessions" requests, returning the next
+// market events after a point in time (`at`, RFC 3339, defaults to now)
+func (h SessionsHandler) Next(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	t, err := timeParam(req, "at", time.Now())
+	if err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+
+	count, err := intParam(req, "count", 5, 1, MaxNextEvents)
+	if err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+
+	e := sessions.GetInstance()
+	helpers.SetCachePolicy(w, sessionsCachePolicy(req, e, t))
+
+	// Use helper response method
+	helpers.OKCollection(w, req, eventsData(e.Next(t, count)))
+}
+
+// Calendar is an http handler used to fulfill "session calendar" requests, returning all market
+// events within a number of days (`days`) from a date (`from`, YYYY-MM-DD, defaults to today),
+// optionally for a single session (`session`). Requests for the `ics` format (`format=ics`, or
+// `Accept: text/calendar`) are sent an iCalendar feed, see `ICS`
+func (h SessionsHandler) Calendar(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	enc, ok := helpers.Negotiate(req, helpers.FormatJSON, ical.Format)
+	if !ok {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: "Unsupported `format`, expected json or ics"}})
+		return
+	}
+	if enc != helpers.JSONEncoder {
+		h.ics(w, req, enc)
+		return
+	}
+
+	from, days, session, errs := calendarParams(req, DefaultCalendarDays, MaxCalendarDays)
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	e := sessions.GetInstance()
+	events := make([]*sessions.Event, 0)
+	for _, ev := range e.Events(from, from.AddDate(0, 0, days)) {
+		if session == "" || ev.Session == session || goutils.SliceContains(session, ev.Sessions) {
+			events = append(events, ev)
+		}
+	}
+
+	// Calendars only change when session settings are reloaded
+	helpers.SetCachePolicy(w, &helpers.CachePolicy{MaxAge: FixedSessionsAge})
+	setCalendarLastModified(w, req, e)
+
+	// Use helper response method
+	helpers.OKCollection(w, req, eventsData(events))
+}
+
+// ICS is an http handler used to fulfill "session calendar feed" requests, returning an iCalendar feed of
+// session opens within a number of days (`days`, defaults to the most allowed) from a date (`from`, YYYY-MM-DD,
+// defaults to today), optionally for a set of sessions (`sessions`, comma-separated). Overlaps (`overlaps`)
+// and holiday closures (`holidays`) are included unless excluded
+func (h SessionsHandler) ICS(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	h.ics(w, req, ical.Encoder{})
+}
+
+// ics sends an iCalendar feed of the session schedule
+func (h SessionsHandler) ics(w http.ResponseWriter, req *http.Request, enc helpers.Encoder) {
+	from, days, session, errs := calendarParams(req, MaxCalendarDays, MaxCalendarDays)
+
+	f := ical.Filter{Sessions: config.SplitList(req.URL.Query().Get("sessions"))}
+	if session != "" {
+		f.Sessions = append(f.Sessions, session)
+	}
+	errs = append(errs, validSessions(f.Sessions)...)
+
+	var err error
+	if f.Overlaps, err = boolParam(req, "overlaps", true); err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if f.Holidays, err = boolParam(req, "holidays", true); err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	e := sessions.GetInstance()
+	cal := ical.Schedule(e, from, from.AddDate(0, 0, days), f)
+	cal.Refresh = FixedSessionsAge
+
+	// Calendars only change when session settings are reloaded
+	helpers.SetCachePolicy(w, &helpers.CachePolicy{MaxAge: FixedSessionsAge})
+	setCalendarLastModified(w, req, e)
+
+	// Use helper response method
+	helpers.OKEncoded(w, req, enc, cal)
+}
+
+// DST is an http handler used to fulfill "daylight saving time" requests, returning the changes to the UTC
+// offsets of session cities within a number of days (`days`, defaults to a year) from a date (`from`, YYYY-MM-DD,
+// defaults to today), along with the session shifts they cause, optionally for a single session (`session`)
+func (h SessionsHandler) DST(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	from, days, session, errs := calendarParams(req, DefaultDSTDays, MaxDSTDays)
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	e := sessions.GetInstance()
+	to := from.AddDate(0, 0, days)
+	resp := &DSTResponse{ClockChanges: make([]*sessions.ClockChange, 0), Shifts: make([]*sessions.Shift, 0)}
+	for _, c := range e.ClockChanges(from, to) {
+		if session == "" || c.Session == session {
+			resp.ClockChanges = append(resp.ClockChanges, c)
+		}
+	}
+	for _, sh := range e.Shifts(from, to) {
+		if session == "" || goutils.SliceContains(session, sh.Changed) || goutils.SliceContains(session, sh.Pending) {
+			resp.Shifts = append(resp.Shifts, sh)
+		}
+	}
+
+	// Clock changes only change when session settings are reloaded
+	helpers.SetCachePolicy(w, &helpers.CachePolicy{MaxAge: FixedSessionsAge})
+	setCalendarLastModified(w, req, e)
+
+	// Use helper response method
+	helpers.OK(w, req, resp)
+}
+
+// setCalendarLastModified sets the `Last-Modified` header of a calendar response, when its first date was given
+// NOTE: Calendars starting today change at midnight, which isn't reflected by when session settings were modified
+func setCalendarLastModified(w http.ResponseWriter, req *http.Request, e *sessions.Engine) {
+	if req.URL.Query().Get("from") != "" {
+		helpers.SetLastModified(w, e.Modified())
+	}
+}
+
+// sessionsCachePolicy returns the cache policy of a response describing sessions at a point in time.
+// Responses for the current time are fresh until the next market event, responses for a fixed time
+// only change when session settings are reloaded
+func sessionsCachePolicy(req *http.Request, e *sessions.Engine, t time.Time) *helpers.CachePolicy {
+	if req.URL.Query().Get("at") != "" {
+		return &helpers.CachePolicy{MaxAge: FixedSessionsAge}
+	}
+
+	maxAge := MaxLiveSessionsAge
+	if next := e.Next(t, 1); len(next) > 0 {
+		if d := next[0].Time.Sub(t).Truncate(time.Second); d < maxAge {
+			maxAge = d
+		}
+	}
+
+	return &helpers.CachePolicy{MaxAge: maxAge}
+}
+
+// calendarParams parses and validates the parameters of a calendar request
+func calendarParams(req *http.Request, defaultDays, maxDays int) (time.Time, int, string, []*helpers.Error) {
+	errs := make([]*helpers.Error, 0)
+
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := req.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			errs = append(errs, &helpers.Error{Message: "Invalid `from` date, expected YYYY-MM-DD"})
+		}
+		from = t
+	}
+
+	days, err := intParam(req, "days", defaultDays, 1, maxDays)
+	if err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+
+	session := req.URL.Query().Get("session")
+	if session != "" && sessions.GetInstance().Session(session) == nil {
+		errs = append(errs, &helpers.Error{Message: "Unknown session: " + session})
+	}
+
+	return from, days, session, errs
+}
+
+// eventsData converts a slice of events to a slice of interfaces for collection responses
+func eventsData(events []*sessions.Event) []interface{} {
+	data := make([]interface{}, 0, len(events))
+	for _, ev := range events {
+		data = append(data, ev)
+	}
+
+	return data
+}
@@ -0,0 +1,57 @@
+// Tests the sessions.go file
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"net/http/httptest"
+
+	// Internal
+	"github.com/deezone/forex-clock/helpers"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sessions.go", func() {
+	var h *SessionsHandler
+
+	// serve makes a request to a handler, returning the recorded response
+	serve := func(fn http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		fn(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		return w
+	}
+
+	BeforeEach(func() {
+		h = NewSessionsHandler()
+	})
+
+	Describe("Calendar methods", func() {
+		It("Only send a last modified time for calendars starting on a given date", func() {
+			for _, route := range []struct {
+				fn   http.HandlerFunc
+				path string
+			}{
+				{h.Calendar, SessionsCalendarRoute},
+				{h.ICS, SessionsICSRoute},
+				{h.DST, SessionsDSTRoute},
+			} {
+				// Call method
+				w := serve(route.fn, route.path+"?from=2024-06-10&days=5")
+
+				// Verify output
+				Expect(w.Code).To(Equal(http.StatusOK), route.path)
+				Expect(w.Header().Get(helpers.LastModifiedHeader)).NotTo(BeEmpty(), route.path)
+
+				// NOTE: Calendars starting today change at midnight, so clients always revalidate them by entity tag
+				w = serve(route.fn, route.path+"?days=5")
+				Expect(w.Code).To(Equal(http.StatusOK), route.path)
+				Expect(w.Header().Get(helpers.LastModifiedHeader)).To(BeEmpty(), route.path)
+				Expect(w.Header().Get(helpers.ETagHeader)).NotTo(BeEmpty(), route.path)
+			}
+		})
+	})
+})
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"strconv"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/provider"
+	"github.com/deezone/forex-clock/stats"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Routes
+	SessionStatsRoute     = "/stats/sessions"
+	SessionPairStatsRoute = "/stats/sessions/{pair}"
+
+	// Limits
+	MaxStatsLookback = 365 // Longest lookback period, in days
+	MaxStatsPairs    = 30  // Most pairs per request, the number of pairs seeded by default
+)
+
+type (
+	// Struct representing a route handler for statistics routes
+	StatsHandler struct {
+		service *stats.Service // Computation of statistics from stored candles
+	}
+)
+
+// NewStatsHandler creates and returns a new instance of a statistics handler
+func NewStatsHandler(service *stats.Service) *StatsHandler { return &StatsHandler{service: service} }
+
+// Sessions is an http handler used to fulfill "session statistics" requests, returning the statistics of every session
+// of a set of pairs (`pairs`, up to `MaxStatsPairs` comma-separated, defaulting to `pairs.seed`) over a number of days
+// (`lookback`)
+func (h StatsHandler) Sessions(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	symbols := config.SplitList(req.URL.Query().Get("pairs"))
+	if len(symbols) == 0 {
+		symbols = config.SplitList(config.GetInstance().Pairs.Seed)
+	} else if len(symbols) > MaxStatsPairs {
+		helpers.BadRequest(w, req, []*helpers.Error{
+			{Message: "Invalid `pairs` parameter, expected at most " + strconv.Itoa(MaxStatsPairs) + " comma-separated pairs"},
+		})
+		return
+	}
+
+	errs := []*helpers.Error{}
+	for _, s := range provider.NormalizePairs(symbols) {
+		if _, err := pairs.New(s); err != nil {
+			errs = append(errs, &helpers.Error{Message: "Invalid currency pair: " + s})
+		}
+	}
+	lookback, err := intParam(req, "lookback", config.GetInstance().Stats.Lookback, 1, MaxStatsLookback)
+	if err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	now := time.Now().UTC()
+	data := make([]interface{}, 0, len(symbols))
+	for _, s := range provider.NormalizePairs(symbols) {
+		ps, err := h.service.Pair(s, lookback, now)
+		if err != nil {
+			log.WithError(err).WithField("pair", s).Error("Error computing session statistics")
+			helpers.InternalError(w, req)
+			return
+		}
+		data = append(data, ps)
+	}
+
+	helpers.SetCachePolicy(w, statsCachePolicy())
+
+	// Use helper response method
+	helpers.OKCollection(w, req, data)
+}
+
+// Pair is an http handler used to fulfill "pair session statistics" requests, returning the statistics of every
+// session of a single pair over a number of days (`lookback`)
+func (h StatsHandler) Pair(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	pair := provider.NormalizePair(mux.Vars(req)["pair"])
+
+	errs := []*helpers.Error{}
+	if _, err := pairs.New(pair); err != nil {
+		errs = append(errs, &helpers.Error{Message: "Invalid currency pair: " + mux.Vars(req)["pair"]})
+	}
+	lookback, err := intParam(req, "lookback", config.GetInstance().Stats.Lookback, 1, MaxStatsLookback)
+	if err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	ps, err := h.service.Pair(pair, lookback, time.Now().UTC())
+	if err != nil {
+		log.WithError(err).WithField("pair", pair).Error("Error computing session statistics")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	helpers.SetCachePolicy(w, statsCachePolicy())
+
+	// Use helper response method
+	helpers.OK(w, req, ps)
+}
+
+// statsCachePolicy returns the cache policy of statistics responses, which are fresh for as long as they're cached
+func statsCachePolicy() *helpers.CachePolicy {
+	return &helpers.CachePolicy{MaxAge: time.Duration(config.GetInstance().Stats.CacheTTL) * time.Second}
+}
@@ -0,0 +1,63 @@
+// Tests the stats.go file
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/cache"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/db/dbtest"
+	"github.com/deezone/forex-clock/stats"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("stats.go", func() {
+	var (
+		conn db.DB
+		h    *StatsHandler
+	)
+
+	BeforeEach(func() {
+		conn = dbtest.New()
+		h = NewStatsHandler(stats.NewService(db.NewCandleStore(conn), cache.New("stats-test", cache.NewMemoryBackend(100))))
+	})
+
+	AfterEach(func() {
+		conn.Close()
+	})
+
+	Describe("`Sessions` method", func() {
+		seed := config.SplitList(config.GetInstance().Pairs.Seed)
+
+		It("Returns the statistics of up to as many pairs as are seeded by default", func() {
+			Expect(seed).To(HaveLen(MaxStatsPairs))
+			w := httptest.NewRecorder()
+
+			// Call method
+			h.Sessions(w, httptest.NewRequest(http.MethodGet, SessionStatsRoute+"?lookback=1&pairs="+strings.Join(seed, ","), nil))
+
+			// Verify output
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Rejects requests for too many pairs", func() {
+			w := httptest.NewRecorder()
+			list := append(seed[:len(seed):len(seed)], "EURUSD")
+
+			// Call method
+			h.Sessions(w, httptest.NewRequest(http.MethodGet, SessionStatsRoute+"?pairs="+strings.Join(list, ","), nil))
+
+			// Verify output
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("expected at most 30 comma-separated pairs"))
+		})
+	})
+})
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	// Standard lib
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/sessions"
+	"github.com/deezone/forex-clock/stream"
+
+	// Third-party
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Routes
+	StreamWebSocketRoute = "/stream/ws"
+	StreamEventsRoute    = "/stream/events"
+
+	// Content type of server-sent event streams
+	EventStreamContentType = "text/event-stream"
+
+	// Header containing the ID of the last event received by a reconnecting client
+	LastEventIDHeader = "Last-Event-ID"
+
+	// Time (in milliseconds) server-sent event clients wait before reconnecting
+	eventStreamRetry = 5000
+
+	// Actions clients may send to change their subscription
+	StreamActionSubscribe   = "subscribe"
+	StreamActionUnsubscribe = "unsubscribe"
+
+	// Largest message accepted from clients
+	maxStreamClientMessage = 4096
+)
+
+type (
+	// Struct representing a route handler for market event stream routes
+	StreamHandler struct {
+		hub      *stream.Hub // Hub publishing market events
+		upgrader websocket.Upgrader
+	}
+	// StreamClientMessage is a struct defining properties of messages sent by WebSocket clients
+	StreamClientMessage struct {
+		Action   string   `json:"action"`   // "subscribe" or "unsubscribe"
+		Sessions []string `json:"sessions"` // Sessions to add or remove, all sessions when subscribing to none
+	}
+)
+
+// NewStreamHandler creates and returns a new instance of a stream handler
+func NewStreamHandler(hub *stream.Hub) *StreamHandler {
+	h := &StreamHandler{hub: hub}
+	h.upgrader = websocket.Upgrader{CheckOrigin: allowedOrigin}
+
+	return h
+}
+
+// WebSocket is an http handler used to fulfill "market event stream" requests, upgrading the connection to a
+// WebSocket and sending market events as they occur, optionally for a set of sessions (`sessions`, comma-separated)
+func (h StreamHandler) WebSocket(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	ids, errs := streamSessionsParam(req)
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	// NOTE: The upgrader responds to invalid handshakes itself
+	conn, err := h.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(ids)
+	defer h.hub.Unsubscribe(sub)
+
+	// Read client messages in the background, as only one goroutine may read
+	changes := make(chan []*helpers.Error, 1)
+	closed := make(chan struct{})
+	go h.read(conn, sub, changes, closed)
+
+	// Acknowledge the initial subscription
+	if h.write(conn, subscribedMessage(sub, nil)) != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(time.Duration(config.GetInstance().Stream.HeartbeatInterval) * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		var m interface{}
+
+		select {
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				closeStream(conn, sub.Err())
+				return
+			}
+			m = msg
+		case errs := <-changes:
+			m = subscribedMessage(sub, errs)
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, streamDeadline()); err != nil {
+				return
+			}
+			m = &stream.Message{Type: stream.MessageHeartbeat, Time: time.Now().UTC()}
+		case <-closed:
+			return
+		}
+
+		if err := h.write(conn, m); err != nil {
+			log.WithError(err).Debug("Error writing to stream client")
+			return
+		}
+	}
+}
+
+// Events is an http handler used to fulfill "market event feed" requests, sending market events as server-sent
+// events as they occur, optionally for a set of sessions (`sessions`, comma-separated). Reconnecting clients
+// receive the recent events they missed since their `Last-Event-ID`
+func (h StreamHandler) Events(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	ids, errs := streamSessionsParam(req)
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	lastEventID := req.Header.Get(LastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = req.URL.Query().Get("last-event-id")
+	}
+
+	// NOTE: The server's write timeout is extended before every write, so streams outlive it
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(streamDeadline()); err != nil {
+		log.WithError(err).Warn("Error extending write deadline, the stream will end at the server's write timeout")
+	}
+
+	sub := h.hub.SubscribeFrom(ids, lastEventID)
+	defer h.hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", EventStreamContentType)
+	helpers.SetCachePolicy(w, helpers.NoStorePolicy)
+	w.WriteHeader(http.StatusOK)
+
+	// send writes a part of the stream and flushes it to the client
+	send := func(format string, args ...interface{}) error {
+		rc.SetWriteDeadline(streamDeadline())
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	// Start with the reconnection delay, and a comment describing the subscription
+	subscribed := "all sessions"
+	if ids := sub.Sessions(); len(ids) > 0 {
+		subscribed = strings.Join(ids, ",")
+	}
+	if send("retry: %d\n: %s %s\n\n", eventStreamRetry, stream.MessageSubscribed, subscribed) != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(time.Duration(config.GetInstance().Stream.HeartbeatInterval) * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+
+		select {
+		case m, ok := <-sub.Messages():
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(m)
+			err = send("id: %s\nevent: %s\ndata: %s\n\n", m.ID, m.Type, data)
+		case t := <-heartbeat.C:
+			// Comments are ignored by clients, but keep proxies from closing idle connections
+			err = send(": %s %s\n\n", stream.MessageHeartbeat, t.UTC().Format(time.RFC3339))
+		case <-req.Context().Done():
+			return
+		}
+
+		if err != nil {
+			log.WithError(err).Debug("Error writing to stream client")
+			return
+		}
+	}
+}
+
+// read reads subscription changes from a client until the connection is closed
+// NOTE: Connections are considered closed if neither a message nor a pong is received within two heartbeats
+func (h StreamHandler) read(conn *websocket.Conn, sub *stream.Subscriber, changes chan<- []*helpers.Error, closed chan<- struct{}) {
+	defer close(closed)
+
+	timeout := 2 * time.Duration(config.GetInstance().Stream.HeartbeatInterval) * time.Second
+	conn.SetReadLimit(maxStreamClientMessage)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	conn.SetPongHandler(func(string) error { return conn.SetReadDeadline(time.Now().Add(timeout)) })
+
+	for {
+		m := &StreamClientMessage{}
+		if err := conn.ReadJSON(m); err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(timeout))
+
+		errs := validSessions(m.Sessions)
+		if len(errs) == 0 {
+			switch m.Action {
+			case StreamActionSubscribe:
+				sub.Subscribe(m.Sessions)
+			case StreamActionUnsubscribe:
+				sub.Unsubscribe(m.Sessions)
+			default:
+				errs = append(errs, &helpers.Error{Message: "Unknown action: " + m.Action})
+			}
+		}
+
+		// NOTE: Changes are acknowledged by the writing goroutine, dropped if one is already pending
+		select {
+		case changes <- errs:
+		default:
+		}
+	}
+}
+
+// write writes a message as JSON, waiting no longer than the configured timeout
+func (h StreamHandler) write(conn *websocket.Conn, m interface{}) error {
+	conn.SetWriteDeadline(streamDeadline())
+
+	return conn.WriteJSON(m)
+}
+
+// closeStream sends a close message describing why a subscription ended
+func closeStream(conn *websocket.Conn, err error) {
+	code, reason := websocket.CloseNormalClosure, ""
+	switch err {
+	case stream.ErrHubClosed:
+		code, reason = websocket.CloseGoingAway, err.Error()
+	case stream.ErrSlowConsumer:
+		code, reason = websocket.ClosePolicyViolation, err.Error()
+	}
+
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), streamDeadline())
+}
+
+// subscribedMessage returns a message acknowledging a subscription, or describing why it couldn't be changed
+func subscribedMessage(sub *stream.Subscriber, errs []*helpers.Error) *stream.Message {
+	if len(errs) > 0 {
+		return &stream.Message{Type: stream.MessageError, Time: time.Now().UTC(), Data: &stream.ErrorData{Errors: errs}}
+	}
+
+	return &stream.Message{
+		Type: stream.MessageSubscribed,
+		Time: time.Now().UTC(),
+		Data: &stream.SubscribedData{Sessions: sub.Sessions()},
+	}
+}
+
+// streamSessionsParam parses and validates the sessions a stream is filtered by
+func streamSessionsParam(req *http.Request) ([]string, []*helpers.Error) {
+	ids := config.SplitList(req.URL.Query().Get("sessions"))
+
+	return ids, validSessions(ids)
+}
+
+// validSessions returns errors for any unknown session IDs
+func validSessions(ids []string) []*helpers.Error {
+	errs := make([]*helpers.Error, 0)
+	for _, id := range ids {
+		if sessions.GetInstance().Session(id) == nil {
+			errs = append(errs, &helpers.Error{Message: "Unknown session: " + id})
+		}
+	}
+
+	return errs
+}
+
+// allowedOrigin returns a boolean indicating if a WebSocket handshake's origin is allowed by CORS settings
+// NOTE: Requests without an origin aren't made by browsers, and are always allowed
+func allowedOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	for _, allowed := range config.SplitList(config.GetInstance().CORS.AllowedOrigins) {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// streamDeadline returns the time writes to stream clients must complete by
+func streamDeadline() time.Time {
+	return time.Now().Add(time.Duration(config.GetInstance().Stream.WriteTimeout) * time.Second)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/strength"
+
+	// Third-party
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Routes
+	StrengthRoute       = "/strength"
+	StrengthWindowRoute = "/strength/{window}"
+)
+
+type (
+	// Struct representing a route handler for currency strength routes
+	StrengthHandler struct {
+		service *strength.Service // Measurement of currency strength from the latest quotes
+	}
+)
+
+// NewStrengthHandler creates and returns a new instance of a currency strength handler
+func NewStrengthHandler(service *strength.Service) *StrengthHandler {
+	return &StrengthHandler{service: service}
+}
+
+// Strength is an http handler used to fulfill "currency strength" requests, returning the strength of every major
+// currency over every window, ranked strongest first
+func (h StrengthHandler) Strength(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	list := h.service.Meters(time.Now())
+	data := make([]interface{}, 0, len(list))
+	for _, m := range list {
+		data = append(data, m)
+	}
+
+	// NOTE: Strength changes with every quote, like quotes themselves
+	helpers.SetCachePolicy(w, quotesCachePolicy)
+
+	// Use helper response method
+	helpers.OKCollection(w, req, data)
+}
+
+// Window is an http handler used to fulfill "currency strength window" requests, returning the strength of every
+// major currency over a single window (`intraday`, `session`, or `daily`), ranked strongest first
+func (h StrengthHandler) Window(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	m, err := h.service.Meter(mux.Vars(req)["window"], time.Now())
+	if err != nil {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	helpers.SetCachePolicy(w, quotesCachePolicy)
+
+	// Use helper response method
+	helpers.OK(w, req, m)
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	// Standard lib
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/sessions"
+	"github.com/deezone/forex-clock/tz"
+
+	// Third-party
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Routes
+	TimeRoute        = "/time"
+	TimeConvertRoute = "/time/convert"
+	TimeZonesRoute   = "/time/zones"
+	TimeZoneRoute    = "/time/zones/{zone:.+}"
+
+	// Limits
+	MaxConvertZones = 50
+
+	// Caching
+	// NOTE: The time zone database is embedded, so only changes when the application is deployed
+	FixedTimeAge = 24 * time.Hour // Time a response for a fixed point in time is fresh
+)
+
+type (
+	// Struct representing a route handler for time zone routes
+	TimeHandler struct{}
+	// CityTime is a struct defining properties of the time within a session's city
+	CityTime struct {
+		Session string `json:"session"`
+		City    string `json:"city"`
+		// Embedded field
+		*tz.Zone
+	}
+	// ConversionResponse is a struct defining properties of "time conversion" responses
+	ConversionResponse struct {
+		From *tz.Zone   `json:"from"`
+		To   []*tz.Zone `json:"to"` // In requested order
+	}
+)
+
+var (
+	// Formats of local times accepted by conversions, interpreted within the `from` zone
+	localTimeFormats = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+)
+
+// NewTimeHandler creates and returns a new instance of a time handler
+func NewTimeHandler() *TimeHandler { return &TimeHandler{} }
+
+// Time is an http handler used to fulfill "time" requests, returning the time within every session's city
+// at a point in time (`at`, RFC 3339, defaults to now)
+func (h TimeHandler) Time(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	t, err := timeParam(req, "at", time.Now())
+	if err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+
+	e := sessions.GetInstance()
+	data := make([]interface{}, 0, len(e.Sessions()))
+	for _, s := range e.Sessions() {
+		data = append(data, &CityTime{Session: s.ID, City: s.Name, Zone: tz.At(s.Location, t)})
+	}
+
+	// Session cities change when session settings are reloaded
+	p := timeCachePolicy(req)
+	if !p.NoCache {
+		p.MaxAge = FixedSessionsAge
+	}
+	helpers.SetCachePolicy(w, p)
+	helpers.SetLastModified(w, e.Modified())
+
+	// Use helper response method
+	helpers.OKCollection(w, req, data)
+}
+
+// Convert is an http handler used to fulfill "time conversion" requests, converting a time (`at`, defaults
+// to now) from a zone (`from`, defaults to UTC) to one or more zones (`to`, comma-separated). Times are either
+// RFC 3339 instants, or local times (YYYY-MM-DDTHH:MM[:SS]) within the `from` zone
+func (h TimeHandler) Convert(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	errs := make([]*helpers.Error, 0)
+
+	from, err := tz.Load(req.URL.Query().Get("from"))
+	if err != nil {
+		errs = append(errs, &helpers.Error{Message: "Unknown `from` time zone: " + req.URL.Query().Get("from")})
+	}
+
+	names := config.SplitList(req.URL.Query().Get("to"))
+	if len(names) == 0 || len(names) > MaxConvertZones {
+		errs = append(errs, &helpers.Error{Message: "Invalid `to` parameter, expected 1 to " + strconv.Itoa(MaxConvertZones) + " comma-separated time zones"})
+	}
+
+	to := make([]*time.Location, 0, len(names))
+	for _, name := range names {
+		loc, err := tz.Load(name)
+		if err != nil {
+			errs = append(errs, &helpers.Error{Message: "Unknown `to` time zone: " + name})
+			continue
+		}
+		to = append(to, loc)
+	}
+
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	t, err := convertTime(req.URL.Query().Get("at"), from)
+	if err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+
+	resp := &ConversionResponse{From: tz.At(from, t), To: make([]*tz.Zone, 0, len(to))}
+	for _, loc := range to {
+		resp.To = append(resp.To, tz.At(loc, t))
+	}
+
+	helpers.SetCachePolicy(w, timeCachePolicy(req))
+
+	// Use helper response method
+	helpers.OK(w, req, resp)
+}
+
+// Zones is an http handler used to fulfill "time zones" requests, returning every time zone within the
+// database at a point in time (`at`, RFC 3339, defaults to now), optionally only those whose names contain
+// a string (`q`, case-insensitive)
+func (h TimeHandler) Zones(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	t, err := timeParam(req, "at", time.Now())
+	if err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+
+	q := strings.ToLower(req.URL.Query().Get("q"))
+	data := make([]interface{}, 0)
+	for _, name := range tz.Names() {
+		if q != "" && !strings.Contains(strings.ToLower(name), q) {
+			continue
+		}
+
+		loc, err := tz.Load(name)
+		if err != nil {
+			continue
+		}
+		data = append(data, tz.At(loc, t))
+	}
+
+	helpers.SetCachePolicy(w, timeCachePolicy(req))
+
+	// Use helper response method
+	helpers.OKCollection(w, req, data)
+}
+
+// Zone is an http handler used to fulfill "time zone" requests, returning a single time zone at a point
+// in time (`at`, RFC 3339, defaults to now)
+func (h TimeHandler) Zone(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	loc, err := tz.Load(mux.Vars(req)["zone"])
+	if err != nil {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	t, err := timeParam(req, "at", time.Now())
+	if err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+
+	helpers.SetCachePolicy(w, timeCachePolicy(req))
+
+	// Use helper response method
+	helpers.OK(w, req, tz.At(loc, t))
+}
+
+// convertTime parses the time of a conversion, either an RFC 3339 instant or a local time within a zone
+func convertTime(v string, loc *time.Location) (time.Time, error) {
+	if v == "" {
+		return time.Now(), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	for _, format := range localTimeFormats {
+		wall, err := time.Parse(format, v)
+		if err != nil {
+			continue
+		}
+
+		t, err := tz.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), loc)
+		if err != nil {
+			return t, &paramError{name: "at", expected: "a local time that occurs within the `from` time zone"}
+		}
+		return t, nil
+	}
+
+	return time.Time{}, &paramError{name: "at", expected: "an RFC 3339 time or a local time (YYYY-MM-DDTHH:MM[:SS])"}
+}
+
+// timeCachePolicy returns the cache policy of a response describing times. Responses for the current time
+// must be revalidated, responses for a fixed time only change when the application is deployed
+func timeCachePolicy(req *http.Request) *helpers.CachePolicy {
+	if req.URL.Query().Get("at") != "" {
+		return &helpers.CachePolicy{MaxAge: FixedTimeAge}
+	}
+
+	return &helpers.CachePolicy{NoCache: true}
+}
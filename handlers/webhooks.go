@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	// Standard lib
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/sessions"
+	"github.com/deezone/forex-clock/webhooks"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	goutils "github.com/marksost/go-utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Routes
+	WebhooksRoute             = "/admin/webhooks"
+	WebhookRoute              = "/admin/webhooks/{id}"
+	WebhookDeliveriesRoute    = "/admin/webhook-deliveries"
+	WebhookDeliveryRoute      = "/admin/webhook-deliveries/{id}"
+	WebhookDeliveryRetryRoute = "/admin/webhook-deliveries/{id}/retry"
+
+	// Number of deliveries listed by default, and at most
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type (
+	// Struct representing a route handler for webhook administration routes
+	WebhooksHandler struct {
+		store      db.WebhookStore      // Storage of webhooks and their deliveries
+		dispatcher *webhooks.Dispatcher // Dispatcher retrying dead deliveries
+	}
+	// WebhookRequest is a struct defining properties of "create webhook" and "update webhook" requests
+	WebhookRequest struct {
+		URL        string   `json:"url"`
+		EventTypes []string `json:"event-types"` // Types of events delivered, empty for all
+		Sessions   []string `json:"sessions"`    // Sessions of events delivered, empty for all
+		Active     *bool    `json:"active"`      // Defaults to true when creating
+	}
+	// WebhookResponse is a struct defining properties of responses containing a newly created webhook
+	// NOTE: This is the only time the secret is returned
+	WebhookResponse struct {
+		// Embedded field
+		*db.Webhook
+		Secret string `json:"secret"`
+	}
+	// WebhookDeliveryResponse is a struct defining properties of "webhook delivery" responses
+	WebhookDeliveryResponse struct {
+		// Embedded field
+		*db.Delivery
+		Log []*db.DeliveryAttempt `json:"log"` // Attempts made, in order
+	}
+)
+
+// NewWebhooksHandler creates and returns a new instance of a webhooks handler
+func NewWebhooksHandler(store db.WebhookStore, dispatcher *webhooks.Dispatcher) *WebhooksHandler {
+	return &WebhooksHandler{store: store, dispatcher: dispatcher}
+}
+
+// Webhooks is an http handler used to fulfill "webhooks" requests, listing all webhooks (GET)
+// or subscribing a new webhook to market events (POST)
+func (h WebhooksHandler) Webhooks(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		h.list(w, req)
+	case http.MethodPost:
+		h.create(w, req)
+	default:
+		helpers.MethodNotAllowed(w, req)
+	}
+}
+
+// Webhook is an http handler used to fulfill "webhook" requests, returning (GET), updating (PUT)
+// or deleting (DELETE) a webhook
+func (h WebhooksHandler) Webhook(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+
+	switch req.Method {
+	case http.MethodGet:
+		if wh := h.get(w, req, id); wh != nil {
+			helpers.OK(w, req, wh)
+		}
+	case http.MethodPut:
+		h.update(w, req, id)
+	case http.MethodDelete:
+		if err := h.store.Delete(id); err != nil {
+			h.storeError(w, req, err)
+			return
+		}
+
+		log.WithField("id", id).Info("Webhook deleted")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		helpers.MethodNotAllowed(w, req)
+	}
+}
+
+// Deliveries is an http handler used to fulfill "webhook deliveries" requests, listing the most recent deliveries,
+// optionally of a webhook (`webhook`) or with a status (`status`). Listing dead deliveries reads the dead-letter queue
+func (h WebhooksHandler) Deliveries(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	q := req.URL.Query()
+	f := db.DeliveryFilter{WebhookID: q.Get("webhook"), Status: q.Get("status")}
+
+	errs := []*helpers.Error{}
+	if f.Status != "" && !goutils.SliceContains(f.Status, []string{db.DeliveryPending, db.DeliverySucceeded, db.DeliveryDead}) {
+		errs = append(errs, &helpers.Error{Message: "Unknown status: " + f.Status})
+	}
+	var err error
+	if f.Limit, err = intParam(req, "limit", defaultDeliveriesLimit, 1, maxDeliveriesLimit); err != nil {
+		errs = append(errs, &helpers.Error{Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return
+	}
+
+	deliveries, err := h.store.ListDeliveries(f)
+	if err != nil {
+		log.WithError(err).Error("Error listing webhook deliveries")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	data := make([]interface{}, 0, len(deliveries))
+	for _, d := range deliveries {
+		data = append(data, d)
+	}
+
+	helpers.OKCollection(w, req, data)
+}
+
+// Delivery is an http handler used to fulfill "webhook delivery" requests, returning a delivery along with
+// the log of its attempts
+func (h WebhooksHandler) Delivery(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodGet {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	d, err := h.store.GetDelivery(mux.Vars(req)["id"])
+	if err != nil {
+		log.WithError(err).Error("Error getting webhook delivery")
+		helpers.InternalError(w, req)
+		return
+	}
+	if d == nil {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	attempts, err := h.store.ListAttempts(d.ID)
+	if err != nil {
+		log.WithError(err).Error("Error listing webhook delivery attempts")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	helpers.OK(w, req, &WebhookDeliveryResponse{Delivery: d, Log: attempts})
+}
+
+// Retry is an http handler used to fulfill "retry webhook delivery" requests, moving a dead delivery
+// back to the queue to be attempted once more
+func (h WebhooksHandler) Retry(w http.ResponseWriter, req *http.Request) {
+	// Check for valid method
+	if req.Method != http.MethodPost {
+		helpers.MethodNotAllowed(w, req)
+		return
+	}
+
+	d, err := h.dispatcher.Retry(mux.Vars(req)["id"])
+	if err == webhooks.ErrNotDead {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: err.Error()}})
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("Error retrying webhook delivery")
+		helpers.InternalError(w, req)
+		return
+	}
+	if d == nil {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	log.WithField("id", d.ID).Info("Webhook delivery requeued")
+	helpers.OK(w, req, d)
+}
+
+// list sends all webhooks
+func (h WebhooksHandler) list(w http.ResponseWriter, req *http.Request) {
+	hooks, err := h.store.List()
+	if err != nil {
+		log.WithError(err).Error("Error listing webhooks")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	data := make([]interface{}, 0, len(hooks))
+	for _, wh := range hooks {
+		data = append(data, wh)
+	}
+
+	helpers.OKCollection(w, req, data)
+}
+
+// create stores a new webhook from the request body, generating its secret
+func (h WebhooksHandler) create(w http.ResponseWriter, req *http.Request) {
+	body := h.decode(w, req)
+	if body == nil {
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		log.WithError(err).Error("Error generating webhook secret")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	wh := &db.Webhook{
+		ID:         db.NewID(),
+		URL:        body.URL,
+		Secret:     secret,
+		EventTypes: body.EventTypes,
+		Sessions:   body.Sessions,
+		Active:     body.Active == nil || *body.Active,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := h.store.Create(wh); err != nil {
+		log.WithError(err).Error("Error creating webhook")
+		helpers.InternalError(w, req)
+		return
+	}
+
+	log.WithFields(log.Fields{"id": wh.ID, "url": wh.URL}).Info("Webhook created")
+	helpers.Created(w, req, &WebhookResponse{Webhook: wh, Secret: secret})
+}
+
+// update replaces the settings of a webhook from the request body
+// NOTE: The secret is kept, and the webhook's active state is kept unless given
+func (h WebhooksHandler) update(w http.ResponseWriter, req *http.Request, id string) {
+	wh := h.get(w, req, id)
+	if wh == nil {
+		return
+	}
+
+	body := h.decode(w, req)
+	if body == nil {
+		return
+	}
+
+	wh.URL, wh.EventTypes, wh.Sessions = body.URL, body.EventTypes, body.Sessions
+	if body.Active != nil {
+		wh.Active = *body.Active
+	}
+	wh.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	if err := h.store.Update(wh); err != nil {
+		h.storeError(w, req, err)
+		return
+	}
+
+	log.WithField("id", id).Info("Webhook updated")
+	helpers.OK(w, req, wh)
+}
+
+// get returns a webhook, sending the matching response if it can't be
+func (h WebhooksHandler) get(w http.ResponseWriter, req *http.Request, id string) *db.Webhook {
+	wh, err := h.store.Get(id)
+	if err != nil {
+		log.WithError(err).Error("Error getting webhook")
+		helpers.InternalError(w, req)
+		return nil
+	}
+	if wh == nil {
+		helpers.NotFound(w, req)
+		return nil
+	}
+
+	return wh
+}
+
+// decode decodes and validates a webhook request body, sending a bad request response if it's invalid
+func (h WebhooksHandler) decode(w http.ResponseWriter, req *http.Request) *WebhookRequest {
+	body := &WebhookRequest{}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+		helpers.BadRequest(w, req, []*helpers.Error{{Message: "Invalid request body: " + err.Error()}})
+		return nil
+	}
+
+	errs := []*helpers.Error{}
+	if u, err := url.Parse(body.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, &helpers.Error{Message: "`url` must be an absolute http or https URL"})
+	} else if err := webhooks.ValidHost(u.Hostname()); err != nil {
+		errs = append(errs, &helpers.Error{Message: "`url` is invalid: " + err.Error()})
+	}
+	for _, t := range body.EventTypes {
+		if !goutils.SliceContains(t, sessions.EventTypes) {
+			errs = append(errs, &helpers.Error{Message: "Unknown event type: " + t})
+		}
+	}
+	errs = append(errs, validSessions(body.Sessions)...)
+	if len(errs) > 0 {
+		helpers.BadRequest(w, req, errs)
+		return nil
+	}
+
+	return body
+}
+
+// storeError sends the response matching an error returned when updating a webhook
+func (h WebhooksHandler) storeError(w http.ResponseWriter, req *http.Request, err error) {
+	if err == db.ErrNotFound {
+		helpers.NotFound(w, req)
+		return
+	}
+
+	log.WithError(err).Error("Error updating webhook")
+	helpers.InternalError(w, req)
+}
@@ -0,0 +1,85 @@
+// Tests the webhooks.go file
+package handlers
+
+import (
+	// Standard lib
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/db/dbtest"
+	"github.com/deezone/forex-clock/stream"
+	"github.com/deezone/forex-clock/webhooks"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("webhooks.go", func() {
+	var (
+		conn   db.DB
+		router *mux.Router
+	)
+
+	// serve makes a request, returning the recorded response
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+		return w
+	}
+
+	BeforeEach(func() {
+		conn = dbtest.New()
+		store := db.NewWebhookStore(conn)
+		h := NewWebhooksHandler(store, webhooks.NewDispatcher(store, stream.NewHub()))
+
+		router = mux.NewRouter()
+		router.HandleFunc(WebhooksRoute, h.Webhooks)
+		router.HandleFunc(WebhookRoute, h.Webhook)
+		router.HandleFunc(WebhookDeliveriesRoute, h.Deliveries)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+	})
+
+	It("Rejects webhook URLs of private addresses", func() {
+		for _, target := range []string{"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://169.254.169.254/latest", "https://[::1]/hook"} {
+			w := serve(http.MethodPost, WebhooksRoute, `{"url": "`+target+`"}`)
+
+			// Verify output
+			Expect(w.Code).To(Equal(http.StatusBadRequest), target)
+			Expect(w.Body.String()).To(ContainSubstring("`url` is invalid"), target)
+		}
+
+		w := serve(http.MethodPost, WebhooksRoute, `{"url": "https://example.com/hook"}`)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+
+		created := &struct {
+			Data struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}{}
+		Expect(json.Unmarshal(w.Body.Bytes(), created)).To(Succeed())
+		w = serve(http.MethodPut, WebhooksRoute+"/"+created.Data.ID, `{"url": "http://10.0.0.1/hook"}`)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("Validates the limit of deliveries listed", func() {
+		for _, limit := range []string{"0", "501", "ten"} {
+			w := serve(http.MethodGet, WebhookDeliveriesRoute+"?limit="+limit, "")
+
+			// Verify output
+			Expect(w.Code).To(Equal(http.StatusBadRequest), limit)
+			Expect(w.Body.String()).To(ContainSubstring("Invalid `limit` parameter, expected an integer from 1 to 500"), limit)
+		}
+
+		Expect(serve(http.MethodGet, WebhookDeliveriesRoute+"?limit=500", "").Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,178 @@
+// helpers package contains helper functions and structs to use throughout the application
+// caching contains HTTP caching helpers: entity tags, conditional requests, and Cache-Control policies
+package helpers
+
+import (
+	// Standard lib
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+)
+
+const (
+	// Caching headers
+	CacheControlHeader    = "Cache-Control"
+	ETagHeader            = "ETag"
+	LastModifiedHeader    = "Last-Modified"
+	IfNoneMatchHeader     = "If-None-Match"
+	IfModifiedSinceHeader = "If-Modified-Since"
+	VaryHeader            = "Vary"
+
+	// Headers containing request credentials
+	AuthorizationHeader = "Authorization"
+	APIKeyHeader        = "X-API-Key"
+)
+
+type (
+	// CachePolicy is a struct representing the caching directives of a response
+	CachePolicy struct {
+		MaxAge               time.Duration // How long the response is fresh
+		StaleWhileRevalidate time.Duration // How long a stale response may be used while revalidating
+		Private              bool          // Whether only the client may cache the response, not shared caches
+		NoCache              bool          // Whether the response must be revalidated before every use
+		NoStore              bool          // Whether the response must not be cached at all
+		Immutable            bool          // Whether the response never changes while fresh
+	}
+)
+
+var (
+	// Common cache policies
+	NoStorePolicy = &CachePolicy{NoStore: true}
+)
+
+// String returns the policy as a `Cache-Control` header value
+func (p *CachePolicy) String() string {
+	if p.NoStore {
+		return "no-store"
+	}
+
+	directives := []string{}
+	if p.Private {
+		directives = append(directives, "private")
+	} else {
+		directives = append(directives, "public")
+	}
+	if p.NoCache {
+		directives = append(directives, "no-cache")
+	}
+	directives = append(directives, "max-age="+strconv.Itoa(int(p.MaxAge/time.Second)))
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+strconv.Itoa(int(p.StaleWhileRevalidate/time.Second)))
+	}
+	if p.Immutable {
+		directives = append(directives, "immutable")
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// SetCachePolicy sets the `Cache-Control` header of a response
+// When authentication is required, responses are private and vary by credentials, so shared caches never serve
+// them to clients without credentials, or with credentials lacking the route's scope
+// NOTE: Must be called before the response is sent
+func SetCachePolicy(w http.ResponseWriter, p *CachePolicy) {
+	if config.GetInstance().Auth.Required && !p.NoStore {
+		if !p.Private {
+			private := *p
+			private.Private = true
+			p = &private
+		}
+		AddVary(w.Header(), AuthorizationHeader)
+		AddVary(w.Header(), APIKeyHeader)
+	}
+
+	w.Header().Set(CacheControlHeader, p.String())
+}
+
+// AddVary adds a header name to the `Vary` header, unless already present
+func AddVary(h http.Header, name string) {
+	for _, v := range h[VaryHeader] {
+		for _, existing := range strings.Split(v, ",") {
+			if e := strings.TrimSpace(existing); e == "*" || strings.EqualFold(e, name) {
+				return
+			}
+		}
+	}
+
+	h.Add(VaryHeader, name)
+}
+
+// SetLastModified sets the `Last-Modified` header of a response, used to answer `If-Modified-Since` requests
+// NOTE: Must be called before the response is sent
+func SetLastModified(w http.ResponseWriter, t time.Time) {
+	w.Header().Set(LastModifiedHeader, t.UTC().Format(http.TimeFormat))
+}
+
+// ETag returns a strong entity tag of a response body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModified returns a boolean indicating if a conditional request's validators match a response's headers,
+// meaning the client's copy is current
+// NOTE: `If-Modified-Since` is ignored when `If-None-Match` is present
+func NotModified(req *http.Request, h http.Header) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := req.Header.Get(IfNoneMatchHeader); inm != "" {
+		etag := h.Get(ETagHeader)
+		if etag == "" {
+			return false
+		}
+
+		// NOTE: Uses weak comparison, so responses weakened by compression still match
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := req.Header.Get(IfModifiedSinceHeader); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+
+		modified, err := http.ParseTime(h.Get(LastModifiedHeader))
+		if err != nil {
+			return false
+		}
+
+		return !modified.After(since)
+	}
+
+	return false
+}
+
+// writeCacheable sends an OK response body of a content type with an entity tag, or a Not Modified response
+// if the request's validators match
+func writeCacheable(w http.ResponseWriter, req *http.Request, contentType string, body []byte) {
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	if h.Get(ETagHeader) == "" {
+		h.Set(ETagHeader, ETag(body))
+	}
+
+	if NotModified(req, h) {
+		h.Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
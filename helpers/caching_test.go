@@ -0,0 +1,207 @@
+// Tests the caching.go file
+package helpers
+
+import (
+	// Standard lib
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("caching.go", func() {
+	Describe("`CachePolicy` methods", func() {
+		It("Formats policies as `Cache-Control` header values", func() {
+			// Set test data
+			input := map[string]*CachePolicy{
+				"public, max-age=0":                                        {},
+				"public, max-age=60":                                       {MaxAge: time.Minute},
+				"private, no-cache, max-age=0":                             {Private: true, NoCache: true},
+				"public, max-age=30, stale-while-revalidate=10":            {MaxAge: 30 * time.Second, StaleWhileRevalidate: 10 * time.Second},
+				"public, max-age=3600, immutable":                          {MaxAge: time.Hour, Immutable: true},
+				"no-store":                                                 NoStorePolicy,
+				"private, max-age=90, stale-while-revalidate=5, immutable": {MaxAge: 90 * time.Second, StaleWhileRevalidate: 5 * time.Second, Private: true, Immutable: true},
+			}
+
+			// Loop through test data
+			for expected, p := range input {
+				// Verify result
+				Expect(p.String()).To(Equal(expected))
+			}
+		})
+
+		Context("When authentication is required", func() {
+			BeforeEach(func() {
+				config.GetInstance().Auth.Required = true
+			})
+
+			AfterEach(func() {
+				config.GetInstance().Auth.Required = false
+			})
+
+			It("Sets private policies varying by credentials", func() {
+				p := &CachePolicy{MaxAge: time.Minute}
+				w := httptest.NewRecorder()
+				w.Header().Set(VaryHeader, "Accept-Encoding, X-API-Key")
+				SetCachePolicy(w, p)
+
+				// Verify response
+				Expect(w.Header().Get(CacheControlHeader)).To(Equal("private, max-age=60"))
+				Expect(w.Header()[VaryHeader]).To(Equal([]string{"Accept-Encoding, X-API-Key", "Authorization"}))
+				Expect(p.Private).To(BeFalse())
+
+				// Verify responses that aren't stored are unchanged
+				w = httptest.NewRecorder()
+				SetCachePolicy(w, NoStorePolicy)
+				Expect(w.Header().Get(CacheControlHeader)).To(Equal("no-store"))
+				Expect(w.Header().Get(VaryHeader)).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Entity tags", func() {
+		It("Returns strong, quoted tags that only depend on the body", func() {
+			a, b := ETag([]byte(`{"a":1}`)), ETag([]byte(`{"a":2}`))
+
+			// Verify results
+			Expect(a).To(HavePrefix(`"`))
+			Expect(a).To(HaveSuffix(`"`))
+			Expect(a).To(Equal(ETag([]byte(`{"a":1}`))))
+			Expect(a).ToNot(Equal(b))
+		})
+	})
+
+	Describe("Conditional requests", func() {
+		var (
+			// Headers of the response being checked
+			h http.Header
+			// Last modified time of the response
+			modified time.Time
+		)
+
+		BeforeEach(func() {
+			modified = time.Date(2020, time.March, 2, 12, 0, 0, 0, time.UTC)
+
+			h = http.Header{}
+			h.Set(ETagHeader, `"abc"`)
+			h.Set(LastModifiedHeader, modified.Format(http.TimeFormat))
+		})
+
+		It("Matches `If-None-Match` headers", func() {
+			// Set test data
+			input := map[string]bool{
+				`"abc"`:          true,
+				`W/"abc"`:        true,
+				`"xyz", "abc"`:   true,
+				`*`:              true,
+				`"xyz"`:          false,
+				`"abc-modified"`: false,
+			}
+
+			// Loop through test data
+			for header, expected := range input {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set(IfNoneMatchHeader, header)
+
+				// Verify result
+				Expect(NotModified(req, h)).To(Equal(expected), header)
+			}
+		})
+
+		It("Matches `If-Modified-Since` headers", func() {
+			// Set test data
+			input := map[string]bool{
+				modified.Format(http.TimeFormat):                   true,
+				modified.Add(time.Hour).Format(http.TimeFormat):    true,
+				modified.Add(-time.Second).Format(http.TimeFormat): false,
+				"not a date": false,
+			}
+
+			// Loop through test data
+			for header, expected := range input {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set(IfModifiedSinceHeader, header)
+
+				// Verify result
+				Expect(NotModified(req, h)).To(Equal(expected), header)
+			}
+		})
+
+		It("Ignores `If-Modified-Since` when `If-None-Match` is present", func() {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(IfNoneMatchHeader, `"xyz"`)
+			req.Header.Set(IfModifiedSinceHeader, modified.Format(http.TimeFormat))
+
+			// Verify result
+			Expect(NotModified(req, h)).To(BeFalse())
+		})
+
+		It("Ignores validators of requests that aren't reads", func() {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set(IfNoneMatchHeader, `"abc"`)
+
+			// Verify result
+			Expect(NotModified(req, h)).To(BeFalse())
+		})
+	})
+
+	Describe("OK responses", func() {
+		It("Sends bodies with an entity tag and cache policy", func() {
+			w := httptest.NewRecorder()
+			SetCachePolicy(w, &CachePolicy{MaxAge: time.Minute})
+			OK(w, httptest.NewRequest(http.MethodGet, "/", nil), map[string]int{"a": 1})
+
+			// Verify response
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal(ResponseContentType))
+			Expect(w.Header().Get(CacheControlHeader)).To(Equal("public, max-age=60"))
+			Expect(w.Header().Get(ETagHeader)).To(Equal(ETag(w.Body.Bytes())))
+		})
+
+		It("Sends Not Modified responses to matching conditional requests", func() {
+			first := httptest.NewRecorder()
+			OKCollection(first, httptest.NewRequest(http.MethodGet, "/", nil), []interface{}{1, 2})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(IfNoneMatchHeader, first.Header().Get(ETagHeader))
+			w := httptest.NewRecorder()
+			OKCollection(w, req, []interface{}{1, 2})
+
+			// Verify response
+			Expect(w.Code).To(Equal(http.StatusNotModified))
+			Expect(w.Body.Len()).To(BeZero())
+			Expect(w.Header().Get(ETagHeader)).To(Equal(first.Header().Get(ETagHeader)))
+
+			// A changed body no longer matches
+			w = httptest.NewRecorder()
+			OKCollection(w, req, []interface{}{1, 2, 3})
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Doesn't share response bodies between concurrent requests", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					w := httptest.NewRecorder()
+					OK(w, httptest.NewRequest(http.MethodGet, "/", nil), i)
+
+					// Verify the response contains its own data
+					Expect(w.Body.String()).To(Equal(`{"meta":{},"data":` + strconv.Itoa(i) + `}`))
+				}(i)
+			}
+			wg.Wait()
+		})
+	})
+})
@@ -0,0 +1,99 @@
+// helpers package contains helper functions and structs to use throughout the application
+// encoding contains response encoders, letting handlers send resources in formats other than JSON
+package helpers
+
+import (
+	// Standard lib
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// Default response format
+	FormatJSON = "json"
+)
+
+type (
+	// Encoder is an interface that all response encoders must fulfill
+	Encoder interface {
+		// ContentType returns the media type of encoded responses
+		ContentType() string
+		// Encode encodes a resource as a response body
+		Encode(data interface{}) ([]byte, error)
+	}
+	// Struct representing the JSON encoder of response bodies
+	jsonEncoder struct{}
+)
+
+var (
+	// Encoder of JSON responses
+	JSONEncoder Encoder = jsonEncoder{}
+
+	// Registered encoders, by format name
+	encoders     = map[string]Encoder{FormatJSON: JSONEncoder}
+	encodersLock sync.RWMutex
+)
+
+// RegisterEncoder registers an encoder for a format, named by the `format` query parameter (ex: "ics")
+func RegisterEncoder(format string, e Encoder) {
+	encodersLock.Lock()
+	defer encodersLock.Unlock()
+
+	encoders[format] = e
+}
+
+// Negotiate returns the encoder of a request's response, chosen from a handler's formats (the first being its
+// default) by the `format` query parameter or else the `Accept` header. Returns false for unsupported formats
+// NOTE: Unmatched `Accept` headers fall back to the default format rather than being rejected
+func Negotiate(req *http.Request, formats ...string) (Encoder, bool) {
+	encodersLock.RLock()
+	defer encodersLock.RUnlock()
+
+	if len(formats) == 0 {
+		formats = []string{FormatJSON}
+	}
+
+	if f := req.URL.Query().Get("format"); f != "" {
+		for _, format := range formats {
+			if f == format && encoders[format] != nil {
+				return encoders[format], true
+			}
+		}
+		return nil, false
+	}
+
+	for _, accepted := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		for _, format := range formats {
+			if e := encoders[format]; e != nil && e.ContentType() == mediaType {
+				return e, true
+			}
+		}
+	}
+
+	return encoders[formats[0]], encoders[formats[0]] != nil
+}
+
+// OKEncoded sends an OK response with a body encoded by an encoder
+// NOTE: Responses include an entity tag, and conditional requests matching it are sent a Not Modified response
+func OKEncoded(w http.ResponseWriter, req *http.Request, e Encoder, data interface{}) {
+	body, err := e.Encode(data)
+	if err != nil {
+		InternalError(w, req)
+		return
+	}
+
+	writeCacheable(w, req, e.ContentType(), body)
+}
+
+// ContentType returns the media type of JSON responses
+func (jsonEncoder) ContentType() string { return ResponseContentType }
+
+// Encode encodes a resource as JSON
+func (jsonEncoder) Encode(data interface{}) ([]byte, error) { return json.Marshal(data) }
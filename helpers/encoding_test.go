@@ -0,0 +1,98 @@
+// Tests the encoding.go file
+package helpers
+
+import (
+	// Standard lib
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing an encoder of plain text responses used within tests
+	textEncoder struct{}
+)
+
+func (textEncoder) ContentType() string { return "text/plain" }
+
+func (textEncoder) Encode(data interface{}) ([]byte, error) {
+	s, ok := data.(string)
+	if !ok {
+		return nil, errors.New("Not a string")
+	}
+	return []byte(strings.ToUpper(s)), nil
+}
+
+var _ = Describe("encoding.go", func() {
+	BeforeEach(func() {
+		RegisterEncoder("text", textEncoder{})
+	})
+
+	Describe("`Negotiate` method", func() {
+		It("Chooses formats by the `format` parameter", func() {
+			e, ok := Negotiate(httptest.NewRequest("GET", "/?format=text", nil), FormatJSON, "text")
+			Expect(ok).To(BeTrue())
+			Expect(e).To(Equal(textEncoder{}))
+
+			// Verify formats a handler doesn't support
+			_, ok = Negotiate(httptest.NewRequest("GET", "/?format=text", nil))
+			Expect(ok).To(BeFalse())
+			_, ok = Negotiate(httptest.NewRequest("GET", "/?format=xml", nil), FormatJSON, "xml")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Chooses formats by the `Accept` header", func() {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", "application/xml, text/plain;q=0.9")
+
+			e, ok := Negotiate(req, FormatJSON, "text")
+			Expect(ok).To(BeTrue())
+			Expect(e).To(Equal(textEncoder{}))
+		})
+
+		It("Falls back to the default format", func() {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", "application/xml")
+
+			e, ok := Negotiate(req, FormatJSON, "text")
+			Expect(ok).To(BeTrue())
+			Expect(e).To(Equal(JSONEncoder))
+
+			e, _ = Negotiate(httptest.NewRequest("GET", "/", nil))
+			Expect(e).To(Equal(JSONEncoder))
+		})
+	})
+
+	Describe("`OKEncoded` method", func() {
+		It("Sends encoded, cacheable responses", func() {
+			w := httptest.NewRecorder()
+			OKEncoded(w, httptest.NewRequest("GET", "/", nil), textEncoder{}, "london")
+
+			// Verify response
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("text/plain"))
+			Expect(w.Header().Get(ETagHeader)).To(Equal(ETag([]byte("LONDON"))))
+			Expect(w.Body.String()).To(Equal("LONDON"))
+
+			// Verify conditional requests
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set(IfNoneMatchHeader, ETag([]byte("LONDON")))
+			w = httptest.NewRecorder()
+			OKEncoded(w, req, textEncoder{}, "london")
+			Expect(w.Code).To(Equal(http.StatusNotModified))
+		})
+
+		It("Sends an error when encoding fails", func() {
+			w := httptest.NewRecorder()
+			OKEncoded(w, httptest.NewRequest("GET", "/", nil), textEncoder{}, 42)
+
+			// Verify response
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
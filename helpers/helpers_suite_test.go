@@ -0,0 +1,20 @@
+// Test suite setup for the helpers package
+package helpers
+
+import (
+	// Standard lib
+	"testing"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Tests the helpers package
+func TestHelpers(t *testing.T) {
+	// Register gomega fail handler
+	RegisterFailHandler(Fail)
+
+	// Have go's testing package run package specs
+	RunSpecs(t, "Helpers Suite")
+}
@@ -16,7 +16,13 @@ const (
 type (
 	// Meta informatation about the collection response
 	CollectionMeta struct {
-		Count int `json:"count"`
+		Count      int         `json:"count"`
+		Pagination *Pagination `json:"pagination,omitempty"` // Set on paginated collections only
+	}
+	// Pagination is a struct representing the position of a page within a paginated collection
+	Pagination struct {
+		Limit int    `json:"limit"`          // Largest number of resources within a page
+		Next  string `json:"next,omitempty"` // Relative URL of the next page, empty on the last page
 	}
 	// Meta informatation about the error response
 	ErrorMeta struct{}
@@ -57,6 +63,10 @@ var (
 		Code: http.StatusCreated,
 		Meta: &ResourceMeta{},
 	}
+	ForbiddenResponse = &ResourceResponse{ // 403
+		Code: http.StatusForbidden,
+		Meta: &ResourceMeta{},
+	}
 	MethodNotAllowedResponse = &ResourceResponse{ // 405
 		Code: http.StatusMethodNotAllowed,
 		Meta: &ResourceMeta{},
@@ -82,6 +92,14 @@ var (
 		Code: http.StatusInternalServerError,
 		Meta: &ResourceMeta{},
 	}
+	ServiceUnavailableResponse = &ResourceResponse{ // 503
+		Code: http.StatusServiceUnavailable,
+		Meta: &ResourceMeta{},
+	}
+	TooManyRequestsResponse = &ResourceResponse{ // 429
+		Code: http.StatusTooManyRequests,
+		Meta: &ResourceMeta{},
+	}
 	UnauthorizedResponse = &ResourceResponse{ // 401
 		Code: http.StatusUnauthorized,
 		Meta: &ResourceMeta{},
@@ -94,11 +112,12 @@ func BadRequest(w http.ResponseWriter, req *http.Request, errors []*Error) {
 	w.Header().Set("Content-Type", ResponseContentType)
 	w.WriteHeader(http.StatusBadRequest)
 
-	// Set errors on response
-	BadRequestResponse.Errors = errors
+	// Set errors on a copy of the response, as the shared response may be used concurrently
+	response := *BadRequestResponse
+	response.Errors = errors
 
 	// Form output
-	json, _ := json.Marshal(*BadRequestResponse)
+	json, _ := json.Marshal(response)
 
 	w.Write([]byte(json))
 }
@@ -109,11 +128,24 @@ func Created(w http.ResponseWriter, req *http.Request, data interface{}) {
 	w.Header().Set("Content-Type", ResponseContentType)
 	w.WriteHeader(http.StatusCreated)
 
-	// Set body of response
-	CreatedResponseResource.Data = data
+	// Set body on a copy of the response, as the shared response may be used concurrently
+	response := *CreatedResponseResource
+	response.Data = data
 
 	// Form output
-	json, _ := json.Marshal(*CreatedResponseResource)
+	json, _ := json.Marshal(response)
+
+	w.Write([]byte(json))
+}
+
+// Forbidden sends a Forbidden response with JSON-encoded body
+func Forbidden(w http.ResponseWriter, req *http.Request) {
+	// Set content type and stauts code
+	w.Header().Set("Content-Type", ResponseContentType)
+	w.WriteHeader(http.StatusForbidden)
+
+	// Form output
+	json, _ := json.Marshal(*ForbiddenResponse)
 
 	w.Write([]byte(json))
 }
@@ -166,17 +198,78 @@ func NotFound(w http.ResponseWriter, req *http.Request) {
 	w.Write([]byte(json))
 }
 
-// OK sends an OK response with JSON-encoded body
-func OK(w http.ResponseWriter, req *http.Request, data interface{}) {
-	// Set content type and status code
+// ServiceUnavailable sends a Service Unavailable response with JSON-encoded body
+func ServiceUnavailable(w http.ResponseWriter, req *http.Request) {
+	// Set content type and stauts code
+	w.Header().Set("Content-Type", ResponseContentType)
+	w.WriteHeader(http.StatusServiceUnavailable)
+
+	// Form output
+	json, _ := json.Marshal(*ServiceUnavailableResponse)
+
+	w.Write([]byte(json))
+}
+
+// TooManyRequests sends a Too Many Requests response with JSON-encoded body
+func TooManyRequests(w http.ResponseWriter, req *http.Request) {
+	// Set content type and stauts code
 	w.Header().Set("Content-Type", ResponseContentType)
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(http.StatusTooManyRequests)
 
-	// Set body of response
-	OKResponseResource.Data = data
+	// Form output
+	json, _ := json.Marshal(*TooManyRequestsResponse)
+
+	w.Write([]byte(json))
+}
+
+// Unauthorized sends an Unauthorized response with JSON-encoded body
+func Unauthorized(w http.ResponseWriter, req *http.Request) {
+	// Set content type, authentication challenge, and stauts code
+	w.Header().Set("Content-Type", ResponseContentType)
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.WriteHeader(http.StatusUnauthorized)
 
 	// Form output
-	json, _ := json.Marshal(*OKResponseResource)
+	json, _ := json.Marshal(*UnauthorizedResponse)
 
 	w.Write([]byte(json))
 }
+
+// OKCollection sends an OK response with a JSON-encoded collection body
+// NOTE: Responses include an entity tag, and conditional requests matching it are sent a Not Modified response
+func OKCollection(w http.ResponseWriter, req *http.Request, data []interface{}) {
+	// Form output
+	json, _ := json.Marshal(CollectionResponse{
+		Code: http.StatusOK,
+		Meta: &CollectionMeta{Count: len(data)},
+		Data: data,
+	})
+
+	writeCacheable(w, req, ResponseContentType, json)
+}
+
+// OKPage sends an OK response with a JSON-encoded body of a single page of a paginated collection
+// NOTE: Responses include an entity tag, and conditional requests matching it are sent a Not Modified response
+func OKPage(w http.ResponseWriter, req *http.Request, data []interface{}, p *Pagination) {
+	// Form output
+	json, _ := json.Marshal(CollectionResponse{
+		Code: http.StatusOK,
+		Meta: &CollectionMeta{Count: len(data), Pagination: p},
+		Data: data,
+	})
+
+	writeCacheable(w, req, ResponseContentType, json)
+}
+
+// OK sends an OK response with JSON-encoded body
+// NOTE: Responses include an entity tag, and conditional requests matching it are sent a Not Modified response
+func OK(w http.ResponseWriter, req *http.Request, data interface{}) {
+	// Set body on a copy of the response, as the shared response may be used concurrently
+	response := *OKResponseResource
+	response.Data = data
+
+	// Form output
+	json, _ := json.Marshal(response)
+
+	writeCacheable(w, req, ResponseContentType, json)
+}
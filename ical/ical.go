@@ -0,0 +1,247 @@
+// ical package contains the rendering of market schedules as iCalendar (RFC 5545) feeds, which calendar
+// applications (ex: Outlook, Google Calendar) subscribe to
+package ical
+
+import (
+	// Standard lib
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	// Internal
+	"github.com/deezone/forex-clock/helpers"
+)
+
+const (
+	// Format name and media type of iCalendar responses
+	Format      = "ics"
+	ContentType = "text/calendar"
+
+	// Identifier of the product creating calendars
+	ProductID = "-//forex-clock//Market Schedule//EN"
+
+	// Longest line, in octets, before it's folded
+	maxLineLength = 75
+
+	// Date and time value formats
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405"
+	utcFormat      = "20060102T150405Z"
+)
+
+var (
+	// Errors encoding calendars
+	ErrNotCalendar = errors.New("Only calendars can be encoded as iCalendar")
+
+	// Weekday names used by recurrence rules
+	weekdays = map[time.Weekday]string{
+		time.Sunday: "SU", time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE",
+		time.Thursday: "TH", time.Friday: "FR", time.Saturday: "SA",
+	}
+)
+
+type (
+	// Calendar is a struct representing a single iCalendar object, containing events and the time zones they use
+	Calendar struct {
+		Name    string        // Display name of the calendar
+		From    time.Time     // Start of the range covered, used to describe time zone transitions
+		To      time.Time     // End of the range covered
+		Stamp   time.Time     // When the calendar's contents last changed
+		Refresh time.Duration // How often subscribed clients should refresh, 0 to leave it to clients
+		Events  []*Event
+	}
+	// Event is a struct representing a single event, or a series of events
+	Event struct {
+		UID        string
+		Summary    string
+		Categories []string
+		Start      time.Time
+		End        time.Time
+		AllDay     bool           // Whether the event spans whole dates, from the start date until the end date
+		Location   *time.Location // Time zone of the event's local times, UTC times are used if nil
+		Recurrence *Recurrence    // How the event repeats, nil if it doesn't
+	}
+	// Recurrence is a struct representing the weekly repetition of an event
+	Recurrence struct {
+		Weekdays []time.Weekday // Days of the week the event occurs on
+		Until    time.Time      // Start of the last occurrence
+		Except   []time.Time    // Starts of occurrences that don't occur
+	}
+	// Encoder is a struct representing the encoder of iCalendar responses
+	Encoder struct{}
+	// Struct representing a buffer of content lines
+	writer struct {
+		bytes.Buffer
+	}
+)
+
+// ContentType returns the media type of iCalendar responses
+func (Encoder) ContentType() string { return ContentType }
+
+// Encode encodes a calendar as iCalendar
+func (Encoder) Encode(data interface{}) ([]byte, error) {
+	c, ok := data.(*Calendar)
+	if !ok {
+		return nil, ErrNotCalendar
+	}
+
+	return c.Encode(), nil
+}
+
+// Encode returns the calendar as iCalendar, with CRLF line endings and lines folded at 75 octets
+func (c *Calendar) Encode() []byte {
+	w := &writer{}
+
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", ProductID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.Refresh > 0 {
+		w.line("REFRESH-INTERVAL;VALUE=DURATION", duration(c.Refresh))
+		w.line("X-PUBLISHED-TTL", duration(c.Refresh))
+	}
+
+	// Describe every time zone events use
+	for _, loc := range c.locations() {
+		writeTimezone(w, loc, c.From, c.To)
+	}
+
+	for _, e := range c.Events {
+		c.writeEvent(w, e)
+	}
+
+	w.line("END", "VCALENDAR")
+
+	return w.Bytes()
+}
+
+// writeEvent writes a single event component
+func (c *Calendar) writeEvent(w *writer, e *Event) {
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", e.UID)
+	w.line("DTSTAMP", c.Stamp.UTC().Format(utcFormat))
+
+	switch {
+	case e.AllDay:
+		w.line("DTSTART;VALUE=DATE", e.Start.Format(dateFormat))
+		w.line("DTEND;VALUE=DATE", e.End.Format(dateFormat))
+		w.line("TRANSP", "TRANSPARENT")
+	case e.Location != nil:
+		tzid := ";TZID=" + e.Location.String()
+		w.line("DTSTART"+tzid, e.Start.In(e.Location).Format(dateTimeFormat))
+		w.line("DTEND"+tzid, e.End.In(e.Location).Format(dateTimeFormat))
+	default:
+		w.line("DTSTART", e.Start.UTC().Format(utcFormat))
+		w.line("DTEND", e.End.UTC().Format(utcFormat))
+	}
+
+	if r := e.Recurrence; r != nil {
+		days := make([]string, 0, len(r.Weekdays))
+		for _, d := range r.Weekdays {
+			days = append(days, weekdays[d])
+		}
+
+		// NOTE: Must be a UTC time when the start has a time zone
+		w.line("RRULE", "FREQ=WEEKLY;BYDAY="+strings.Join(days, ",")+";UNTIL="+r.Until.UTC().Format(utcFormat))
+
+		if len(r.Except) > 0 {
+			except := make([]string, 0, len(r.Except))
+			for _, t := range r.Except {
+				if e.Location != nil {
+					except = append(except, t.In(e.Location).Format(dateTimeFormat))
+				} else {
+					except = append(except, t.UTC().Format(utcFormat))
+				}
+			}
+
+			name := "EXDATE"
+			if e.Location != nil {
+				name += ";TZID=" + e.Location.String()
+			}
+			w.line(name, strings.Join(except, ","))
+		}
+	}
+
+	w.line("SUMMARY", escape(e.Summary))
+	if len(e.Categories) > 0 {
+		categories := make([]string, 0, len(e.Categories))
+		for _, category := range e.Categories {
+			categories = append(categories, escape(category))
+		}
+		w.line("CATEGORIES", strings.Join(categories, ","))
+	}
+	w.line("END", "VEVENT")
+}
+
+// locations returns the time zones used by the calendar's events, sorted by name
+func (c *Calendar) locations() []*time.Location {
+	seen := map[string]*time.Location{}
+	for _, e := range c.Events {
+		if e.Location != nil && !e.AllDay {
+			seen[e.Location.String()] = e.Location
+		}
+	}
+
+	locations := make([]*time.Location, 0, len(seen))
+	for _, loc := range seen {
+		locations = append(locations, loc)
+	}
+	sort.Slice(locations, func(a, b int) bool { return locations[a].String() < locations[b].String() })
+
+	return locations
+}
+
+// line writes a content line, folding it into lines no longer than 75 octets
+// NOTE: Folds never split a multi-byte character
+func (w *writer) line(name, value string) {
+	s := name + ":" + value
+
+	limit := maxLineLength
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+
+		w.WriteString(s[:i])
+		w.WriteString("\r\n ")
+		s = s[i:]
+
+		// Continuation lines start with a space
+		limit = maxLineLength - 1
+	}
+
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+// escape escapes a text value
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// duration formats a duration as an iCalendar duration (ex: "PT12H")
+func duration(d time.Duration) string {
+	s := "PT"
+	if h := int(d / time.Hour); h > 0 {
+		s += strconv.Itoa(h) + "H"
+	}
+	if m := int(d % time.Hour / time.Minute); m > 0 || s == "PT" {
+		s += strconv.Itoa(m) + "M"
+	}
+
+	return s
+}
+
+func init() {
+	// Let handlers negotiate iCalendar responses
+	helpers.RegisterEncoder(Format, Encoder{})
+}
@@ -0,0 +1,181 @@
+// Tests the ical.go and timezone.go files
+package ical
+
+import (
+	// Standard lib
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/helpers"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ical.go", func() {
+	var (
+		// Time zones of test events
+		london, _ = time.LoadLocation("Europe/London")
+		tokyo, _  = time.LoadLocation("Asia/Tokyo")
+		// Test calendar
+		c *Calendar
+	)
+
+	BeforeEach(func() {
+		c = &Calendar{
+			Name:  "Test",
+			From:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			Stamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		}
+	})
+
+	// unfold returns the lines of an encoded calendar, joining folded lines
+	unfold := func(b []byte) []string {
+		return strings.Split(strings.TrimSuffix(strings.Replace(string(b), "\r\n ", "", -1), "\r\n"), "\r\n")
+	}
+
+	Describe("Encoding", func() {
+		It("Encodes calendars with CRLF line endings", func() {
+			b := c.Encode()
+
+			// Verify output
+			Expect(string(b)).To(HavePrefix("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:" + ProductID + "\r\n"))
+			Expect(string(b)).To(HaveSuffix("END:VCALENDAR\r\n"))
+			Expect(strings.Count(string(b), "\n")).To(Equal(strings.Count(string(b), "\r\n")))
+		})
+
+		It("Encodes events in UTC, local, and whole-day times", func() {
+			c.Events = []*Event{
+				{UID: "a", Summary: "UTC", Start: time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC), End: time.Date(2024, 6, 3, 16, 0, 0, 0, time.UTC)},
+				{UID: "b", Summary: "Local", Start: time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC), End: time.Date(2024, 6, 3, 16, 0, 0, 0, time.UTC), Location: london},
+				{UID: "c", Summary: "Day", Start: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC), AllDay: true},
+			}
+			lines := unfold(c.Encode())
+
+			// Verify output
+			Expect(lines).To(ContainElement("DTSTART:20240603T120000Z"))
+			Expect(lines).To(ContainElement("DTSTART;TZID=Europe/London:20240603T080000"))
+			Expect(lines).To(ContainElement("DTEND;TZID=Europe/London:20240603T170000"))
+			Expect(lines).To(ContainElement("DTSTART;VALUE=DATE:20241225"))
+			Expect(lines).To(ContainElement("DTEND;VALUE=DATE:20241226"))
+			Expect(lines).To(ContainElement("DTSTAMP:20240101T120000Z"))
+			Expect(strings.Count(strings.Join(lines, "\n"), "BEGIN:VEVENT")).To(Equal(3))
+		})
+
+		It("Encodes recurrence rules and exceptions", func() {
+			c.Events = []*Event{{
+				UID:      "series",
+				Summary:  "Series",
+				Start:    time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+				End:      time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC),
+				Location: tokyo,
+				Recurrence: &Recurrence{
+					Weekdays: []time.Weekday{time.Monday, time.Friday},
+					Until:    time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC),
+					Except:   []time.Time{time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)},
+				},
+			}}
+			lines := unfold(c.Encode())
+
+			// Verify output
+			Expect(lines).To(ContainElement("RRULE:FREQ=WEEKLY;BYDAY=MO,FR;UNTIL=20240628T000000Z"))
+			Expect(lines).To(ContainElement("EXDATE;TZID=Asia/Tokyo:20240607T090000,20240610T090000"))
+		})
+
+		It("Escapes text and folds long lines", func() {
+			summary := strings.Repeat("Sydney, Tokyo; London \\ New York — ", 4)
+			c.Events = []*Event{{UID: "a", Summary: summary, Categories: []string{"A,B"}, Start: c.From, End: c.From}}
+			b := c.Encode()
+
+			// Verify every line is folded within 75 octets, without splitting characters
+			for _, line := range strings.Split(string(b), "\r\n") {
+				Expect(len(line)).To(BeNumerically("<=", 75))
+				Expect(strings.ToValidUTF8(line, "?")).To(Equal(line))
+			}
+
+			// Verify unfolded values
+			lines := unfold(b)
+			Expect(lines).To(ContainElement("SUMMARY:" + strings.Repeat(`Sydney\, Tokyo\; London \\ New York — `, 4)))
+			Expect(lines).To(ContainElement(`CATEGORIES:A\,B`))
+		})
+
+		It("Registers an encoder for the `ics` format", func() {
+			e := Encoder{}
+			b, err := e.Encode(c)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(b).To(Equal(c.Encode()))
+
+			_, err = e.Encode("not a calendar")
+			Expect(err).To(Equal(ErrNotCalendar))
+		})
+	})
+
+	Describe("Time zones", func() {
+		It("Describes the time zones events use", func() {
+			c.Events = []*Event{
+				{UID: "a", Start: c.From, End: c.From, Location: london},
+				{UID: "b", Start: c.From, End: c.From, Location: tokyo},
+				{UID: "c", Start: c.From, End: c.From, Location: london},
+			}
+			s := strings.Join(unfold(c.Encode()), "\n")
+
+			// Verify output
+			Expect(strings.Count(s, "BEGIN:VTIMEZONE")).To(Equal(2))
+			Expect(strings.Index(s, "TZID:Asia/Tokyo")).To(BeNumerically("<", strings.Index(s, "TZID:Europe/London")))
+			Expect(strings.Index(s, "END:VTIMEZONE")).To(BeNumerically("<", strings.Index(s, "BEGIN:VEVENT")))
+		})
+
+		It("Lists daylight saving time transitions", func() {
+			w := &writer{}
+			writeTimezone(w, london, c.From, c.To)
+
+			// Verify output
+			Expect(w.String()).To(ContainSubstring(strings.Join([]string{
+				"BEGIN:DAYLIGHT",
+				"DTSTART:20240331T010000",
+				"TZOFFSETFROM:+0000",
+				"TZOFFSETTO:+0100",
+				"TZNAME:BST",
+				"END:DAYLIGHT",
+				"BEGIN:STANDARD",
+				"DTSTART:20241027T020000",
+				"TZOFFSETFROM:+0100",
+				"TZOFFSETTO:+0000",
+				"TZNAME:GMT",
+				"END:STANDARD",
+			}, "\r\n")))
+		})
+
+		It("Describes time zones without transitions", func() {
+			w := &writer{}
+			writeTimezone(w, tokyo, c.From, c.To)
+
+			// Verify output
+			Expect(strings.Count(w.String(), "BEGIN:STANDARD")).To(Equal(1))
+			Expect(w.String()).To(Not(ContainSubstring("DAYLIGHT")))
+			Expect(w.String()).To(ContainSubstring("TZOFFSETFROM:+0900\r\nTZOFFSETTO:+0900\r\nTZNAME:JST\r\n"))
+		})
+
+		It("Formats offsets", func() {
+			Expect(offset(0)).To(Equal("+0000"))
+			Expect(offset(-4*3600 - 30*60)).To(Equal("-0430"))
+			Expect(offset(5*3600 + 45*60)).To(Equal("+0545"))
+			Expect(offset(-(17*60 + 30))).To(Equal("-001730"))
+		})
+	})
+
+	It("Is negotiated by `format` parameter or `Accept` header", func() {
+		e, ok := helpers.Negotiate(httptest.NewRequest("GET", "/?format=ics", nil), helpers.FormatJSON, Format)
+		Expect(ok).To(BeTrue())
+		Expect(e.ContentType()).To(Equal(ContentType))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "text/calendar; charset=utf-8")
+		e, _ = helpers.Negotiate(req, helpers.FormatJSON, Format)
+		Expect(e.ContentType()).To(Equal(ContentType))
+	})
+})
@@ -0,0 +1,193 @@
+// ical package contains the rendering of market schedules as iCalendar (RFC 5545) feeds
+// schedule contains the conversion of the session engine's schedule into calendar events. Regular session
+// occurrences become a single weekly series per session, in the session's time zone, with occurrences
+// that don't happen (holidays, or those cut short by the weekly market schedule) excluded
+package ical
+
+import (
+	// Standard lib
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/sessions"
+)
+
+const (
+	// Suffix of event UIDs, making them globally unique
+	uidDomain = "@forex-clock"
+)
+
+type (
+	// Filter is a struct representing the parts of a schedule included within a calendar
+	Filter struct {
+		Sessions []string // Sessions included, empty for all
+		Overlaps bool     // Whether periods during which sessions overlap are included
+		Holidays bool     // Whether holiday closures are included
+	}
+)
+
+// Schedule returns a calendar of the sessions opening within a time range (inclusive of `from`, exclusive of `to`),
+// along with the overlaps and holiday closures within it
+func Schedule(e *sessions.Engine, from, to time.Time, f Filter) *Calendar {
+	c := &Calendar{Name: "FOREX sessions", From: from, To: to, Stamp: e.Modified(), Events: []*Event{}}
+
+	included := map[string]bool{}
+	for _, id := range f.Sessions {
+		included[id] = true
+	}
+
+	intervals := e.Intervals(from, to)
+	for _, s := range e.Sessions() {
+		if len(included) > 0 && !included[s.ID] {
+			continue
+		}
+
+		own := []*sessions.Interval{}
+		for _, i := range intervals {
+			if i.Session == s && !i.Start.Before(from) && i.Start.Before(to) {
+				own = append(own, i)
+			}
+		}
+
+		c.Events = append(c.Events, sessionEvents(s, own, f.Holidays)...)
+	}
+
+	if f.Overlaps {
+		for _, o := range e.Overlaps(from, to) {
+			if o.Start.Before(from) || !o.Start.Before(to) || !overlapIncluded(o, included) {
+				continue
+			}
+
+			names := make([]string, 0, len(o.Sessions))
+			for _, id := range o.Sessions {
+				names = append(names, e.Session(id).Name)
+			}
+
+			c.Events = append(c.Events, &Event{
+				UID:        sessions.NewEvent(sessions.EventOverlapStart, o.Start, "", o.Sessions).ID + uidDomain,
+				Summary:    strings.Join(names, " / ") + " overlap",
+				Categories: []string{"Overlap"},
+				Start:      o.Start,
+				End:        o.End,
+			})
+		}
+	}
+
+	return c
+}
+
+// sessionEvents returns the events of a session's occurrences: a weekly series of its regular occurrences,
+// single events for occurrences cut short by the weekly market schedule, and optionally holiday closures
+func sessionEvents(s *sessions.Session, intervals []*sessions.Interval, holidays bool) []*Event {
+	events := []*Event{}
+
+	var regular []*sessions.Interval
+	for _, i := range intervals {
+		switch {
+		case i.Holiday:
+			if holidays {
+				day := i.Start.In(s.Location)
+				date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+				events = append(events, &Event{
+					UID:        sessions.NewEvent(sessions.EventHolidayClosed, i.Start, s.ID, nil).ID + uidDomain,
+					Summary:    s.Name + " closed (holiday)",
+					Categories: []string{"Holiday"},
+					Start:      date,
+					End:        date.AddDate(0, 0, 1),
+					AllDay:     true,
+				})
+			}
+		case isRegular(s, i):
+			regular = append(regular, i)
+		default:
+			events = append(events, &Event{
+				UID:        sessions.NewEvent(sessions.EventSessionOpen, i.Start, s.ID, nil).ID + uidDomain,
+				Summary:    s.Name + " session",
+				Categories: []string{"Session"},
+				Start:      i.Start,
+				End:        i.End,
+				Location:   s.Location,
+			})
+		}
+	}
+
+	if len(regular) == 0 {
+		return events
+	}
+
+	// Exclude weekdays between the first and last regular occurrences that have none
+	first, last := regular[0], regular[len(regular)-1]
+	occurs := map[string]bool{}
+	for _, i := range regular {
+		occurs[localDate(s, i.Start)] = true
+	}
+
+	except := []time.Time{}
+	start := first.Start.In(s.Location)
+	for day := start; !day.After(last.Start); day = nextDay(day) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday && !occurs[localDate(s, day)] {
+			except = append(except, day)
+		}
+	}
+
+	series := &Event{
+		UID:        s.ID + "-session" + uidDomain,
+		Summary:    s.Name + " session",
+		Categories: []string{"Session"},
+		Start:      first.Start,
+		End:        first.End,
+		Location:   s.Location,
+		Recurrence: &Recurrence{
+			Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Until:    last.Start,
+			Except:   except,
+		},
+	}
+
+	return append([]*Event{series}, events...)
+}
+
+// isRegular returns a boolean indicating if an occurrence opens and closes at the session's local times
+func isRegular(s *sessions.Session, i *sessions.Interval) bool {
+	start, end := i.Start.In(s.Location), i.End.In(s.Location)
+	if minutes(start) != s.Open || minutes(end) != s.Close {
+		return false
+	}
+
+	// Sessions closing before they open close on the following day
+	days := 0
+	if s.Close <= s.Open {
+		days = 1
+	}
+	y, m, d := start.Date()
+
+	return localDate(s, end) == localDate(s, time.Date(y, m, d+days, 0, 0, 0, 0, s.Location))
+}
+
+// overlapIncluded returns a boolean indicating if an overlap involves an included session
+func overlapIncluded(o *sessions.Overlap, included map[string]bool) bool {
+	if len(included) == 0 {
+		return true
+	}
+
+	for _, id := range o.Sessions {
+		if included[id] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// localDate returns a session's local date at a point in time
+func localDate(s *sessions.Session, t time.Time) string { return t.In(s.Location).Format(dateFormat) }
+
+// nextDay returns the same local time on the following date
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, t.Hour(), t.Minute(), 0, 0, t.Location())
+}
+
+// minutes returns the minutes since midnight of a local time
+func minutes(t time.Time) int { return t.Hour()*60 + t.Minute() }
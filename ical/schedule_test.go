@@ -0,0 +1,145 @@
+// Tests the schedule.go file
+package ical
+
+import (
+	// Standard lib
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/sessions"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("schedule.go", func() {
+	var (
+		// Engine to build calendars from
+		e *sessions.Engine
+		// Range of test calendars, three weeks over the end of the year
+		from = time.Date(2024, 12, 16, 0, 0, 0, 0, time.UTC)
+		to   = from.AddDate(0, 0, 21)
+		// Filter including everything
+		all = Filter{Overlaps: true, Holidays: true}
+	)
+
+	BeforeEach(func() {
+		var err error
+		e, err = sessions.NewEngine(config.Sessions{Holidays: "01-01,12-25,london:12-26", WeekOpen: "Sun 17:00", WeekClose: "Fri 17:00", TimeZone: "America/New_York"})
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	// find returns the events with a summary
+	find := func(c *Calendar, summary string) []*Event {
+		events := []*Event{}
+		for _, ev := range c.Events {
+			if ev.Summary == summary {
+				events = append(events, ev)
+			}
+		}
+		return events
+	}
+
+	It("Describes regular session occurrences as a weekly series", func() {
+		c := Schedule(e, from, to, all)
+
+		// Verify series
+		series := find(c, "London session")
+		Expect(series).To(HaveLen(1))
+		Expect(series[0].UID).To(Equal("london-session@forex-clock"))
+		Expect(series[0].Location.String()).To(Equal("Europe/London"))
+		Expect(series[0].Start).To(BeTemporally("==", time.Date(2024, 12, 16, 8, 0, 0, 0, time.UTC)))
+		Expect(series[0].End).To(BeTemporally("==", time.Date(2024, 12, 16, 17, 0, 0, 0, time.UTC)))
+		Expect(series[0].Recurrence.Weekdays).To(Equal([]time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}))
+		Expect(series[0].Recurrence.Until).To(BeTemporally("==", time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC)))
+
+		// Verify holidays are excluded from the series
+		except := []string{}
+		for _, t := range series[0].Recurrence.Except {
+			except = append(except, t.Format("2006-01-02"))
+		}
+		Expect(except).To(Equal([]string{"2024-12-25", "2024-12-26", "2025-01-01"}))
+	})
+
+	It("Describes holiday closures as whole-day events", func() {
+		c := Schedule(e, from, to, all)
+
+		// Verify events
+		holidays := find(c, "London closed (holiday)")
+		Expect(holidays).To(HaveLen(3))
+		Expect(holidays[1].AllDay).To(BeTrue())
+		Expect(holidays[1].Start).To(BeTemporally("==", time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC)))
+		Expect(holidays[1].End).To(BeTemporally("==", time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC)))
+
+		// Market-wide holidays close every session
+		Expect(find(c, "Tokyo closed (holiday)")).To(HaveLen(2))
+
+		// Verify holidays may be excluded
+		Expect(find(Schedule(e, from, to, Filter{}), "London closed (holiday)")).To(BeEmpty())
+	})
+
+	It("Describes occurrences cut short by the weekly market schedule as single events", func() {
+		// Sydney (AEDT) opens at 20:00 UTC on Sundays, but the market opens at 22:00 UTC (EST)
+		c := Schedule(e, from, to, Filter{Sessions: []string{sessions.SessionSydney}})
+
+		// Verify events
+		events := find(c, "Sydney session")
+		Expect(events[0].Recurrence).To(Not(BeNil()))
+
+		clipped := events[1:]
+		Expect(clipped).To(HaveLen(3))
+		for _, ev := range clipped {
+			Expect(ev.Recurrence).To(BeNil())
+			Expect(ev.Start.UTC().Weekday()).To(Equal(time.Sunday))
+			Expect(ev.Start.UTC().Hour()).To(Equal(22))
+			Expect(ev.Start.In(ev.Location).Hour()).To(Equal(9))
+		}
+
+		// Verify the series excludes the same occurrences, until its last regular occurrence
+		except := []string{}
+		for _, t := range events[0].Recurrence.Except {
+			except = append(except, t.Format("2006-01-02"))
+		}
+		Expect(except).To(Equal([]string{"2024-12-23", "2024-12-25", "2024-12-30", "2025-01-01"}))
+	})
+
+	It("Describes overlaps", func() {
+		june := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+		c := Schedule(e, june, june.AddDate(0, 0, 1), all)
+
+		// Verify events
+		overlaps := find(c, "London / New York overlap")
+		Expect(overlaps).To(HaveLen(1))
+		Expect(overlaps[0].Location).To(BeNil())
+		Expect(overlaps[0].Start).To(BeTemporally("==", time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)))
+		Expect(overlaps[0].End).To(BeTemporally("==", time.Date(2024, 6, 3, 16, 0, 0, 0, time.UTC)))
+		Expect(overlaps[0].Categories).To(Equal([]string{"Overlap"}))
+
+		// Verify overlaps are filtered by session, and may be excluded
+		filtered := Schedule(e, june, june.AddDate(0, 0, 1), Filter{Sessions: []string{sessions.SessionTokyo}, Overlaps: true})
+		Expect(find(filtered, "London / New York overlap")).To(BeEmpty())
+		Expect(find(filtered, "Sydney / Tokyo overlap")).To(HaveLen(1))
+		Expect(find(Schedule(e, june, june.AddDate(0, 0, 1), Filter{}), "London / New York overlap")).To(BeEmpty())
+	})
+
+	It("Filters sessions", func() {
+		c := Schedule(e, from, to, Filter{Sessions: []string{sessions.SessionLondon}})
+
+		for _, ev := range c.Events {
+			Expect(ev.Summary).To(HavePrefix("London"))
+		}
+	})
+
+	It("Encodes schedules", func() {
+		lines := strings.Split(strings.Replace(string(Schedule(e, from, to, Filter{Sessions: []string{sessions.SessionLondon}}).Encode()), "\r\n ", "", -1), "\r\n")
+
+		// Verify output
+		Expect(lines).To(ContainElement("TZID:Europe/London"))
+		Expect(lines).To(ContainElement("DTSTART;TZID=Europe/London:20241216T080000"))
+		Expect(lines).To(ContainElement("RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20250103T080000Z"))
+		Expect(lines).To(ContainElement("EXDATE;TZID=Europe/London:20241225T080000,20241226T080000,20250101T080000"))
+	})
+})
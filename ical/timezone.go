@@ -0,0 +1,56 @@
+// ical package contains the rendering of market schedules as iCalendar (RFC 5545) feeds
+// timezone contains the description of time zones as VTIMEZONE components, listing their transitions
+package ical
+
+import (
+	// Standard lib
+	"fmt"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/tz"
+)
+
+const (
+	// Time before and after a calendar's range that transitions are described for
+	transitionMargin = 7 * 24 * time.Hour
+)
+
+// writeTimezone writes a VTIMEZONE component describing a time zone's offsets within a time range
+func writeTimezone(w *writer, loc *time.Location, from, to time.Time) {
+	w.line("BEGIN", "VTIMEZONE")
+	w.line("TZID", loc.String())
+	w.line("X-LIC-LOCATION", loc.String())
+
+	for _, t := range tz.Transitions(loc, from.Add(-transitionMargin), to.Add(transitionMargin)) {
+		kind := "STANDARD"
+		if t.DST {
+			kind = "DAYLIGHT"
+		}
+
+		// NOTE: Transition starts are local times before the transition
+		w.line("BEGIN", kind)
+		w.line("DTSTART", t.At.UTC().Add(time.Duration(t.From)*time.Second).Format(dateTimeFormat))
+		w.line("TZOFFSETFROM", offset(t.From))
+		w.line("TZOFFSETTO", offset(t.To))
+		w.line("TZNAME", escape(t.Abbreviation))
+		w.line("END", kind)
+	}
+
+	w.line("END", "VTIMEZONE")
+}
+
+// offset formats an offset from UTC (ex: "+0100", "-0430")
+func offset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+
+	return s
+}
@@ -0,0 +1,256 @@
+// indicators package contains technical indicators computed from price series, with no dependencies outside of the
+// standard library so other services can import it. Every indicator returns series of the same length as its input,
+// with NaN values until it has warmed up, so values line up with the bars they were computed from
+package indicators
+
+import (
+	// Standard lib
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// Indicator names
+	NameSMA       = "sma"
+	NameEMA       = "ema"
+	NameRSI       = "rsi"
+	NameMACD      = "macd"
+	NameBollinger = "bollinger"
+	NameATR       = "atr"
+	NamePivots    = "pivots"
+)
+
+var (
+	// Errors of indicators that can't be created
+	ErrUnknownIndicator = errors.New("Unknown indicator")
+	ErrTooManyParams    = errors.New("Too many parameters")
+)
+
+type (
+	// Bar is a struct representing the open, high, low, and close prices of a single period
+	Bar struct {
+		Open  float64
+		High  float64
+		Low   float64
+		Close float64
+	}
+	// Indicator is an interface that all indicators created by name must fulfill
+	Indicator interface {
+		// WarmUp returns the number of leading bars without values
+		WarmUp() int
+		// Outputs returns the names of the indicator's series (ex: "macd", "signal", "histogram")
+		Outputs() []string
+		// Compute returns each of the indicator's series, of the same length as the bars, NaN while warming up
+		Compute(bars []Bar) [][]float64
+	}
+	// Param is a struct representing a single numeric parameter of an indicator
+	Param struct {
+		Name    string  `json:"name"`
+		Default float64 `json:"default"`
+		Min     float64 `json:"min"`
+		Max     float64 `json:"max"`
+		Integer bool    `json:"integer"` // Whether the parameter must be a whole number
+	}
+	// Definition is a struct representing an indicator that can be created by name
+	Definition struct {
+		Name   string   `json:"name"`
+		Params []*Param `json:"params"`
+		create func(p []float64) Indicator
+	}
+	// Struct representing an indicator defined by its warm-up, outputs, and computation
+	indicator struct {
+		warmUp  int
+		outputs []string
+		compute func(bars []Bar) [][]float64
+	}
+)
+
+var (
+	// Indicators that can be created by name
+	Definitions = []*Definition{
+		{
+			Name:   NameSMA,
+			Params: []*Param{{Name: "period", Default: 20, Min: 1, Max: 1000, Integer: true}},
+			create: func(p []float64) Indicator {
+				n := int(p[0])
+				return single(n-1, NameSMA, func(bars []Bar) []float64 { return SMA(closes(bars), n) })
+			},
+		},
+		{
+			Name:   NameEMA,
+			Params: []*Param{{Name: "period", Default: 20, Min: 1, Max: 1000, Integer: true}},
+			create: func(p []float64) Indicator {
+				n := int(p[0])
+				return single(n-1, NameEMA, func(bars []Bar) []float64 { return EMA(closes(bars), n) })
+			},
+		},
+		{
+			Name:   NameRSI,
+			Params: []*Param{{Name: "period", Default: 14, Min: 1, Max: 1000, Integer: true}},
+			create: func(p []float64) Indicator {
+				n := int(p[0])
+				return single(n, NameRSI, func(bars []Bar) []float64 { return RSI(closes(bars), n) })
+			},
+		},
+		{
+			Name: NameMACD,
+			Params: []*Param{
+				{Name: "fast", Default: 12, Min: 1, Max: 1000, Integer: true},
+				{Name: "slow", Default: 26, Min: 1, Max: 1000, Integer: true},
+				{Name: "signal", Default: 9, Min: 1, Max: 1000, Integer: true},
+			},
+			create: func(p []float64) Indicator {
+				fast, slow, signal := int(p[0]), int(p[1]), int(p[2])
+				return &indicator{
+					warmUp:  MACDWarmUp(fast, slow, signal),
+					outputs: []string{"macd", "signal", "histogram"},
+					compute: func(bars []Bar) [][]float64 {
+						m, s, h := MACD(closes(bars), fast, slow, signal)
+						return [][]float64{m, s, h}
+					},
+				}
+			},
+		},
+		{
+			Name: NameBollinger,
+			Params: []*Param{
+				{Name: "period", Default: 20, Min: 1, Max: 1000, Integer: true},
+				{Name: "deviations", Default: 2, Min: 0.1, Max: 10},
+			},
+			create: func(p []float64) Indicator {
+				n, k := int(p[0]), p[1]
+				return &indicator{
+					warmUp:  n - 1,
+					outputs: []string{"middle", "upper", "lower"},
+					compute: func(bars []Bar) [][]float64 {
+						m, u, l := Bollinger(closes(bars), n, k)
+						return [][]float64{m, u, l}
+					},
+				}
+			},
+		},
+		{
+			Name:   NameATR,
+			Params: []*Param{{Name: "period", Default: 14, Min: 1, Max: 1000, Integer: true}},
+			create: func(p []float64) Indicator {
+				n := int(p[0])
+				return single(n, NameATR, func(bars []Bar) []float64 { return ATR(bars, n) })
+			},
+		},
+		{
+			Name: NamePivots,
+			create: func(p []float64) Indicator {
+				return &indicator{
+					warmUp:  1,
+					outputs: []string{"pivot", "r1", "r2", "r3", "s1", "s2", "s3"},
+					compute: func(bars []Bar) [][]float64 {
+						l := Pivots(bars)
+						return [][]float64{l.Pivot, l.R1, l.R2, l.R3, l.S1, l.S2, l.S3}
+					},
+				}
+			},
+		},
+	}
+)
+
+// Lookup returns the definition of an indicator by name, or nil if it doesn't exist
+func Lookup(name string) *Definition {
+	for _, d := range Definitions {
+		if d.Name == strings.ToLower(name) {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// New creates and returns an indicator by name, from positional parameters, using defaults for those not given
+func New(name string, params []float64) (Indicator, error) {
+	d := Lookup(name)
+	if d == nil {
+		return nil, ErrUnknownIndicator
+	}
+
+	return d.New(params)
+}
+
+// New creates and returns the indicator, from positional parameters, using defaults for those not given
+func (d *Definition) New(params []float64) (Indicator, error) {
+	if len(params) > len(d.Params) {
+		return nil, ErrTooManyParams
+	}
+
+	p := make([]float64, len(d.Params))
+	for n, param := range d.Params {
+		p[n] = param.Default
+		if n >= len(params) {
+			continue
+		}
+
+		v := params[n]
+		if math.IsNaN(v) || v < param.Min || v > param.Max || (param.Integer && v != math.Trunc(v)) {
+			return nil, fmt.Errorf("Invalid %s parameter, expected a %s from %g to %g", param.Name, param.kind(), param.Min, param.Max)
+		}
+		p[n] = v
+	}
+
+	return d.create(p), nil
+}
+
+// WarmUp returns the number of leading bars without values
+func (i *indicator) WarmUp() int { return i.warmUp }
+
+// Outputs returns the names of the indicator's series
+func (i *indicator) Outputs() []string { return i.outputs }
+
+// Compute returns each of the indicator's series, NaN until every series has warmed up
+func (i *indicator) Compute(bars []Bar) [][]float64 {
+	series := i.compute(bars)
+	for _, s := range series {
+		for n := 0; n < i.warmUp && n < len(s); n++ {
+			s[n] = math.NaN()
+		}
+	}
+
+	return series
+}
+
+// kind returns a description of the values a parameter accepts
+func (p *Param) kind() string {
+	if p.Integer {
+		return "whole number"
+	}
+
+	return "number"
+}
+
+// single returns an indicator with a single series
+func single(warmUp int, output string, compute func(bars []Bar) []float64) Indicator {
+	return &indicator{
+		warmUp:  warmUp,
+		outputs: []string{output},
+		compute: func(bars []Bar) [][]float64 { return [][]float64{compute(bars)} },
+	}
+}
+
+// closes returns the closing prices of bars
+func closes(bars []Bar) []float64 {
+	values := make([]float64, len(bars))
+	for n, b := range bars {
+		values[n] = b.Close
+	}
+
+	return values
+}
+
+// nans returns a series of NaN values
+func nans(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+
+	return values
+}
@@ -0,0 +1,221 @@
+// Tests the indicators.go, moving-averages.go, oscillators.go, volatility.go, and pivots.go files
+package indicators
+
+import (
+	// Standard lib
+	"math"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing a series computed by an indicator, and its reference values
+	seriesTest struct {
+		name     string
+		actual   []float64
+		expected []float64 // NaN while warming up
+		within   float64   // Tolerance of reference values
+	}
+)
+
+var (
+	// NaN, used within reference values while warming up
+	nan = math.NaN()
+
+	// Closing prices of StockCharts' moving average and Bollinger Band examples, whose values are rounded to cents
+	averageCloses = []float64{
+		22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29, 22.15, 22.39, 22.38, 22.61, 23.36,
+		24.05, 23.75, 23.83, 23.95, 23.63, 23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+	}
+	// Closing prices of StockCharts' RSI example
+	rsiCloses = []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	}
+	// Bars with a gap, used by the average true range and pivot examples
+	gapBars = []Bar{
+		{High: 10, Low: 8, Close: 9},
+		{High: 11, Low: 9, Close: 10},
+		{High: 12, Low: 10, Close: 11},
+		{High: 15, Low: 11, Close: 14},
+		{High: 14, Low: 12, Close: 13},
+		{High: 20, Low: 19, Close: 19.5},
+	}
+)
+
+// repeat returns a series of NaN values followed by values
+func repeat(warmUp int, values ...float64) []float64 {
+	return append(nans(warmUp), values...)
+}
+
+// expectSeries verifies series match their reference values
+func expectSeries(tests []seriesTest) {
+	for _, t := range tests {
+		Expect(t.actual).To(HaveLen(len(t.expected)), t.name)
+		for n, e := range t.expected {
+			if math.IsNaN(e) {
+				Expect(math.IsNaN(t.actual[n])).To(BeTrue(), "%s[%d] should be warming up", t.name, n)
+				continue
+			}
+			Expect(t.actual[n]).To(BeNumerically("~", e, t.within), "%s[%d]", t.name, n)
+		}
+	}
+}
+
+var _ = Describe("moving-averages.go", func() {
+	It("Computes simple and exponential moving averages", func() {
+		expectSeries([]seriesTest{
+			{
+				name:   "SMA(10)",
+				actual: SMA(averageCloses, 10),
+				expected: repeat(9, 22.22, 22.21, 22.23, 22.26, 22.30, 22.42, 22.61, 22.77, 22.91, 23.08, 23.21, 23.38,
+					23.52, 23.65, 23.71, 23.68, 23.61, 23.51, 23.43, 23.28, 23.13),
+				within: 0.0051,
+			},
+			{
+				name:   "EMA(10)",
+				actual: EMA(averageCloses, 10),
+				expected: repeat(9, 22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34, 23.43,
+					23.51, 23.53, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92),
+				within: 0.0051,
+			},
+			{name: "SMA(1)", actual: SMA([]float64{1, 2, 3}, 1), expected: []float64{1, 2, 3}},
+			{name: "SMA(5) of fewer values", actual: SMA([]float64{1, 2, 3}, 5), expected: repeat(3)},
+			{name: "EMA(5) of fewer values", actual: EMA([]float64{1, 2, 3}, 5), expected: repeat(3)},
+			{name: "EMA(0)", actual: EMA([]float64{1, 2, 3}, 0), expected: repeat(3)},
+		})
+	})
+})
+
+var _ = Describe("oscillators.go", func() {
+	It("Computes Wilder's relative strength index", func() {
+		expectSeries([]seriesTest{
+			{
+				name:     "RSI(14)",
+				actual:   RSI(rsiCloses, 14),
+				expected: repeat(14, 70.46, 66.25, 66.48, 69.35, 66.29, 57.92),
+				within:   0.005,
+			},
+			{name: "RSI(2) rising", actual: RSI([]float64{1, 2, 3, 4}, 2), expected: repeat(2, 100, 100)},
+			{name: "RSI(2) flat", actual: RSI([]float64{1, 1, 1}, 2), expected: repeat(2, 50)},
+			{name: "RSI(14) of fewer values", actual: RSI(rsiCloses[:14], 14), expected: repeat(14)},
+		})
+	})
+
+	It("Computes the moving average convergence divergence", func() {
+		values := []float64{10, 11, 12, 11, 13, 14, 13, 15, 16, 15, 17}
+		line, signal, hist := MACD(values, 2, 4, 3)
+
+		// Verify output
+		expectSeries([]seriesTest{
+			{name: "MACD line", actual: line, expected: repeat(3, 0.1667, 0.5889, 0.7830, 0.3463, 0.7000, 0.8507, 0.3873, 0.7247), within: 0.0001},
+			{name: "MACD signal", actual: signal, expected: repeat(5, 0.5128, 0.4296, 0.5648, 0.7077, 0.5475, 0.6361), within: 0.0001},
+			{name: "MACD histogram", actual: hist, expected: repeat(5, 0.2701, -0.0833, 0.1352, 0.1430, -0.1602, 0.0886), within: 0.0001},
+		})
+		Expect(MACDWarmUp(2, 4, 3)).To(Equal(5))
+		Expect(MACDWarmUp(12, 26, 9)).To(Equal(33))
+	})
+})
+
+var _ = Describe("volatility.go", func() {
+	It("Computes Bollinger Bands", func() {
+		middle, upper, lower := Bollinger(averageCloses, 20, 2)
+
+		// Verify output
+		expectSeries([]seriesTest{
+			{name: "Bollinger middle", actual: middle, expected: repeat(19, 22.7155, 22.7930, 22.8770, 22.9555, 23.0065, 23.0525, 23.1125, 23.1350, 23.1685, 23.1765, 23.1705), within: 0.0001},
+			{name: "Bollinger upper", actual: upper[29:], expected: []float64{24.4355}, within: 0.0001},
+			{name: "Bollinger lower", actual: lower[19:20], expected: []float64{21.3049}, within: 0.0001},
+		})
+	})
+
+	It("Computes Wilder's average true range", func() {
+		expectSeries([]seriesTest{
+			{name: "True range", actual: TrueRange(gapBars), expected: []float64{2, 2, 2, 4, 2, 7}},
+			{name: "ATR(3)", actual: ATR(gapBars, 3), expected: repeat(3, 2.6667, 2.4444, 3.9630), within: 0.0001},
+			{name: "ATR(6) of fewer bars", actual: ATR(gapBars, 6), expected: repeat(6)},
+		})
+	})
+})
+
+var _ = Describe("pivots.go", func() {
+	It("Computes classic pivot points from the previous bar", func() {
+		l := Pivots([]Bar{{High: 1.1050, Low: 1.0950, Close: 1.1000}, {High: 1.1, Low: 1.1, Close: 1.1}})
+
+		// Verify output
+		expectSeries([]seriesTest{
+			{name: "Pivot", actual: l.Pivot, expected: repeat(1, 1.1), within: 1e-9},
+			{name: "R1", actual: l.R1, expected: repeat(1, 1.105), within: 1e-9},
+			{name: "R2", actual: l.R2, expected: repeat(1, 1.11), within: 1e-9},
+			{name: "R3", actual: l.R3, expected: repeat(1, 1.115), within: 1e-9},
+			{name: "S1", actual: l.S1, expected: repeat(1, 1.095), within: 1e-9},
+			{name: "S2", actual: l.S2, expected: repeat(1, 1.09), within: 1e-9},
+			{name: "S3", actual: l.S3, expected: repeat(1, 1.085), within: 1e-9},
+		})
+	})
+})
+
+var _ = Describe("indicators.go", func() {
+	It("Creates indicators by name, with default parameters", func() {
+		tests := []struct {
+			name    string
+			params  []float64
+			warmUp  int
+			outputs []string
+		}{
+			{name: "sma", warmUp: 19, outputs: []string{"sma"}},
+			{name: "EMA", params: []float64{10}, warmUp: 9, outputs: []string{"ema"}},
+			{name: "rsi", warmUp: 14, outputs: []string{"rsi"}},
+			{name: "macd", params: []float64{5}, warmUp: 33, outputs: []string{"macd", "signal", "histogram"}},
+			{name: "bollinger", params: []float64{20, 2.5}, warmUp: 19, outputs: []string{"middle", "upper", "lower"}},
+			{name: "atr", warmUp: 14, outputs: []string{"atr"}},
+			{name: "pivots", warmUp: 1, outputs: []string{"pivot", "r1", "r2", "r3", "s1", "s2", "s3"}},
+		}
+
+		for _, t := range tests {
+			i, err := New(t.name, t.params)
+			Expect(err).To(Not(HaveOccurred()), t.name)
+			Expect(i.WarmUp()).To(Equal(t.warmUp), t.name)
+			Expect(i.Outputs()).To(Equal(t.outputs), t.name)
+		}
+	})
+
+	It("Rejects unknown indicators and invalid parameters", func() {
+		tests := []struct {
+			name   string
+			params []float64
+		}{
+			{name: "stochastic"},
+			{name: "sma", params: []float64{0}},
+			{name: "sma", params: []float64{14.5}},
+			{name: "sma", params: []float64{14, 2}},
+			{name: "bollinger", params: []float64{20, nan}},
+			{name: "pivots", params: []float64{1}},
+		}
+
+		for _, t := range tests {
+			_, err := New(t.name, t.params)
+			Expect(err).To(HaveOccurred(), "%s %v", t.name, t.params)
+		}
+	})
+
+	It("Leaves every series warming up until the indicator has warmed up", func() {
+		bars := make([]Bar, len(averageCloses))
+		for n, c := range averageCloses {
+			bars[n] = Bar{Open: c, High: c + 0.1, Low: c - 0.1, Close: c}
+		}
+
+		i, _ := New(NameMACD, []float64{3, 6, 4})
+		series := i.Compute(bars)
+
+		// Verify output
+		Expect(series).To(HaveLen(3))
+		for _, s := range series {
+			Expect(s).To(HaveLen(len(bars)))
+			Expect(math.IsNaN(s[i.WarmUp()-1])).To(BeTrue())
+			Expect(math.IsNaN(s[i.WarmUp()])).To(BeFalse())
+		}
+	})
+})
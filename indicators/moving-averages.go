@@ -0,0 +1,51 @@
+// indicators package contains technical indicators computed from price series
+// moving-averages contains simple and exponential moving averages
+package indicators
+
+// SMA returns the simple moving average of values over a period, the mean of the last `period` values
+// NOTE: The first `period - 1` values are NaN
+func SMA(values []float64, period int) []float64 {
+	out := nans(len(values))
+	if period < 1 {
+		return out
+	}
+
+	sum := 0.0
+	for n, v := range values {
+		sum += v
+		if n >= period {
+			sum -= values[n-period]
+		}
+		if n >= period-1 {
+			out[n] = sum / float64(period)
+		}
+	}
+
+	return out
+}
+
+// EMA returns the exponential moving average of values over a period, weighting each value by `2 / (period + 1)`
+// NOTE: Seeded with the simple moving average of the first `period` values, so the first `period - 1` values are NaN
+func EMA(values []float64, period int) []float64 {
+	out := nans(len(values))
+	if period < 1 || len(values) < period {
+		return out
+	}
+
+	return ema(values, period, 2/float64(period+1), out)
+}
+
+// ema smooths values with a weight, seeded with the simple moving average of the first `period` values
+func ema(values []float64, period int, weight float64, out []float64) []float64 {
+	sum := 0.0
+	for _, v := range values[:period] {
+		sum += v
+	}
+	out[period-1] = sum / float64(period)
+
+	for n := period; n < len(values); n++ {
+		out[n] = out[n-1] + weight*(values[n]-out[n-1])
+	}
+
+	return out
+}
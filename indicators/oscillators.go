@@ -0,0 +1,84 @@
+// indicators package contains technical indicators computed from price series
+// oscillators contains the relative strength index and moving average convergence divergence
+package indicators
+
+import (
+	// Standard lib
+	"math"
+)
+
+// RSI returns Wilder's relative strength index of values over a period, from 0 to 100
+// NOTE: Averages gains and losses over the first `period` changes, so the first `period` values are NaN
+func RSI(values []float64, period int) []float64 {
+	out := nans(len(values))
+	if period < 1 || len(values) <= period {
+		return out
+	}
+
+	gain, loss := 0.0, 0.0
+	for n := 1; n < len(values); n++ {
+		change := values[n] - values[n-1]
+		up, down := math.Max(change, 0), math.Max(-change, 0)
+
+		if n <= period {
+			gain, loss = gain+up/float64(period), loss+down/float64(period)
+			if n < period {
+				continue
+			}
+		} else {
+			gain = (gain*float64(period-1) + up) / float64(period)
+			loss = (loss*float64(period-1) + down) / float64(period)
+		}
+
+		switch {
+		case loss == 0 && gain == 0:
+			out[n] = 50
+		case loss == 0:
+			out[n] = 100
+		default:
+			out[n] = 100 - 100/(1+gain/loss)
+		}
+	}
+
+	return out
+}
+
+// MACD returns the moving average convergence divergence of values: the difference between a fast and slow
+// exponential moving average, its exponential moving average over a signal period, and the difference between them
+// NOTE: The MACD line has values once the slow average does, the signal and histogram `signal - 1` values later
+func MACD(values []float64, fast, slow, signal int) ([]float64, []float64, []float64) {
+	line, sig, hist := nans(len(values)), nans(len(values)), nans(len(values))
+	if fast < 1 || slow < 1 || signal < 1 {
+		return line, sig, hist
+	}
+
+	f, s := EMA(values, fast), EMA(values, slow)
+	start := len(values)
+	for n := range values {
+		if !math.IsNaN(f[n]) && !math.IsNaN(s[n]) {
+			line[n] = f[n] - s[n]
+			if n < start {
+				start = n
+			}
+		}
+	}
+
+	if len(values)-start >= signal {
+		e := EMA(line[start:], signal)
+		for n, v := range e {
+			sig[start+n] = v
+			hist[start+n] = line[start+n] - v
+		}
+	}
+
+	return line, sig, hist
+}
+
+// MACDWarmUp returns the number of leading values without a MACD signal
+func MACDWarmUp(fast, slow, signal int) int {
+	if fast > slow {
+		slow = fast
+	}
+
+	return slow + signal - 2
+}
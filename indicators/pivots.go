@@ -0,0 +1,36 @@
+// indicators package contains technical indicators computed from price series
+// pivots contains classic floor trader pivot points
+package indicators
+
+type (
+	// Levels is a struct representing pivot point support and resistance series
+	Levels struct {
+		Pivot []float64
+		R1    []float64
+		R2    []float64
+		R3    []float64
+		S1    []float64
+		S2    []float64
+		S3    []float64
+	}
+)
+
+// Pivots returns the classic pivot points of each bar, computed from the high, low, and close of the previous bar
+// (ex: a daily bar's levels come from the previous day)
+// NOTE: The first bar has no previous bar, so its values are NaN
+func Pivots(bars []Bar) *Levels {
+	n := len(bars)
+	l := &Levels{Pivot: nans(n), R1: nans(n), R2: nans(n), R3: nans(n), S1: nans(n), S2: nans(n), S3: nans(n)}
+
+	for i := 1; i < n; i++ {
+		h, lo, c := bars[i-1].High, bars[i-1].Low, bars[i-1].Close
+		p := (h + lo + c) / 3
+
+		l.Pivot[i] = p
+		l.R1[i], l.S1[i] = 2*p-lo, 2*p-h
+		l.R2[i], l.S2[i] = p+(h-lo), p-(h-lo)
+		l.R3[i], l.S3[i] = h+2*(p-lo), lo-2*(h-p)
+	}
+
+	return l
+}
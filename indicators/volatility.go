@@ -0,0 +1,62 @@
+// indicators package contains technical indicators computed from price series
+// volatility contains Bollinger Bands and the average true range
+package indicators
+
+import (
+	// Standard lib
+	"math"
+)
+
+// Bollinger returns Bollinger Bands of values over a period: the simple moving average, and bands a number of
+// standard deviations (of the population) above and below it
+// NOTE: The first `period - 1` values are NaN
+func Bollinger(values []float64, period int, deviations float64) ([]float64, []float64, []float64) {
+	middle, upper, lower := SMA(values, period), nans(len(values)), nans(len(values))
+
+	for n := range values {
+		if math.IsNaN(middle[n]) {
+			continue
+		}
+
+		variance := 0.0
+		for _, v := range values[n-period+1 : n+1] {
+			variance += (v - middle[n]) * (v - middle[n])
+		}
+		d := deviations * math.Sqrt(variance/float64(period))
+
+		upper[n], lower[n] = middle[n]+d, middle[n]-d
+	}
+
+	return middle, upper, lower
+}
+
+// TrueRange returns the true range of each bar: the greatest of its range, and the distances from the previous
+// close to its high and low
+// NOTE: The first bar has no previous close, so its true range is its range
+func TrueRange(bars []Bar) []float64 {
+	out := make([]float64, len(bars))
+	for n, b := range bars {
+		out[n] = b.High - b.Low
+		if n > 0 {
+			prev := bars[n-1].Close
+			out[n] = math.Max(out[n], math.Max(math.Abs(b.High-prev), math.Abs(b.Low-prev)))
+		}
+	}
+
+	return out
+}
+
+// ATR returns Wilder's average true range of bars over a period
+// NOTE: Seeded with the mean true range of the `period` bars following the first, which has no previous close, so
+// the first `period` values are NaN
+func ATR(bars []Bar, period int) []float64 {
+	out := nans(len(bars))
+	if period < 1 || len(bars) <= period {
+		return out
+	}
+
+	tr := TrueRange(bars)
+	ema(tr[1:], period, 1/float64(period), out[1:])
+
+	return out
+}
@@ -5,53 +5,16 @@
 package main
 
 import (
+	// Standard lib
 	"os"
-	"os/signal"
 
 	// Internal
-	"github.com/deezone/forex-clock/config"
-	"github.com/deezone/forex-clock/server"
-
-	// Third-party
-	log "github.com/sirupsen/logrus"
+	"github.com/deezone/forex-clock/cli"
 )
 
 // Main function
 // Starting point for application - `go run`
+// NOTE: Runs the HTTP server when no command is provided, see `cli.Run` for available commands
 func main() {
-	m := "Starting forex-clock application..."
-	log.Info(m)
-
-	// Initialize configuration
-	config.Init()
-
-	m = "Configuration loaded..."
-	log.Info(m)
-
-	// Create new server
-	s := server.NewServer()
-
-	m = "Creating new server..."
-	log.Info(m)
-
-	// Start server
-	m = "Starting server..."
-	log.Info(m)
-	if err := s.Start(); err != nil {
-		panic("Error starting application. Error was: " + err.Error())
-	}
-
-	// Listen for and exit the application on SIGKILL or SIGINT
-	stop := make(chan os.Signal)
-	signal.Notify(stop, os.Interrupt, os.Kill)
-
-	select {
-	case <-stop:
-		// Attempt to stop the server
-		s.Stop()
-
-		// Log shut down
-		m = "Server is shutting down..."
-		log.Info(m)
-	}
+	os.Exit(cli.Run(os.Args[1:]))
 }
@@ -0,0 +1,70 @@
+// pairs package contains currency pair reference data
+// currencies contains the ISO 4217 currency list bundled within the binary
+package pairs
+
+import (
+	// Standard lib
+	_ "embed"
+	"encoding/csv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Currency is a struct representing a single ISO 4217 currency
+	Currency struct {
+		Code       string `json:"code"`        // Three letter code (ex: "EUR")
+		Numeric    string `json:"numeric"`     // Three digit code (ex: "978")
+		Name       string `json:"name"`        // English name
+		MinorUnits *int   `json:"minor-units"` // Decimal places of the minor unit, nil if not applicable (ex: gold)
+	}
+)
+
+var (
+	// ISO 4217 currency list, from Debian's iso-codes package along with the standard's minor units
+	//go:embed iso4217.csv
+	iso4217 string
+
+	// Currencies by code, parsed from the bundled list
+	currencies = map[string]*Currency{}
+	codes      = []string{}
+)
+
+// Currencies returns every ISO 4217 currency, sorted by code
+func Currencies() []*Currency {
+	list := make([]*Currency, 0, len(codes))
+	for _, code := range codes {
+		list = append(list, currencies[code])
+	}
+
+	return list
+}
+
+// LookupCurrency returns an ISO 4217 currency by code (case-insensitive), or nil if it doesn't exist
+func LookupCurrency(code string) *Currency {
+	return currencies[strings.ToUpper(strings.TrimSpace(code))]
+}
+
+func init() {
+	records, err := csv.NewReader(strings.NewReader(iso4217)).ReadAll()
+	if err != nil {
+		panic("Invalid ISO 4217 currency list: " + err.Error())
+	}
+
+	// NOTE: Skips the header row
+	for _, r := range records[1:] {
+		c := &Currency{Code: r[0], Numeric: r[1], Name: r[3]}
+		if r[2] != "" {
+			units, err := strconv.Atoi(r[2])
+			if err != nil {
+				panic("Invalid minor units of " + c.Code + ": " + r[2])
+			}
+			c.MinorUnits = &units
+		}
+
+		currencies[c.Code] = c
+		codes = append(codes, c.Code)
+	}
+	sort.Strings(codes)
+}
@@ -0,0 +1,180 @@
+// pairs package contains currency pair reference data: each pair's base and quote currencies, pip size, price
+// precision, classification, and the sessions during which it's most liquid. Pairs are persisted within the
+// database, and derived from the ISO 4217 currency list bundled within the binary
+package pairs
+
+import (
+	// Standard lib
+	"errors"
+	"fmt"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/provider"
+	"github.com/deezone/forex-clock/sessions"
+)
+
+const (
+	// Pair classes
+	ClassMajor  = "major"  // The US dollar against another major currency
+	ClassMinor  = "minor"  // Two major currencies other than the US dollar
+	ClassExotic = "exotic" // Any pair including a currency that isn't major
+)
+
+var (
+	// Pair classes, in order
+	Classes = []string{ClassMajor, ClassMinor, ClassExotic}
+
+	// Most traded currencies, pairs of which are majors or minors
+	majorCurrencies = map[string]bool{
+		"USD": true, "EUR": true, "JPY": true, "GBP": true, "CHF": true, "AUD": true, "CAD": true, "NZD": true,
+	}
+
+	// Precious metals, the only currencies without minor units which are traded as pairs
+	// NOTE: Metals are quoted per troy ounce, silver to a finer pip than gold, platinum, and palladium
+	metalPipSizes = map[string]float64{"XAU": 0.01, "XAG": 0.001, "XPT": 0.01, "XPD": 0.01}
+
+	// Sessions during which each currency's home market is open
+	// NOTE: Currencies without a home session are only liquid during the sessions of the other currency
+	currencySessions = map[string][]string{
+		// Asia-Pacific
+		"AUD": {sessions.SessionSydney}, "NZD": {sessions.SessionSydney},
+		"JPY": {sessions.SessionTokyo}, "CNY": {sessions.SessionTokyo}, "HKD": {sessions.SessionTokyo},
+		"SGD": {sessions.SessionTokyo}, "KRW": {sessions.SessionTokyo}, "TWD": {sessions.SessionTokyo},
+		"THB": {sessions.SessionTokyo}, "IDR": {sessions.SessionTokyo}, "MYR": {sessions.SessionTokyo},
+		"PHP": {sessions.SessionTokyo}, "INR": {sessions.SessionTokyo},
+		// Europe, the Middle East, and Africa
+		"EUR": {sessions.SessionLondon}, "GBP": {sessions.SessionLondon}, "CHF": {sessions.SessionLondon},
+		"SEK": {sessions.SessionLondon}, "NOK": {sessions.SessionLondon}, "DKK": {sessions.SessionLondon},
+		"PLN": {sessions.SessionLondon}, "HUF": {sessions.SessionLondon}, "CZK": {sessions.SessionLondon},
+		"RON": {sessions.SessionLondon}, "TRY": {sessions.SessionLondon}, "ZAR": {sessions.SessionLondon},
+		"ILS": {sessions.SessionLondon},
+		// Americas
+		"USD": {sessions.SessionNewYork}, "CAD": {sessions.SessionNewYork}, "MXN": {sessions.SessionNewYork},
+		"BRL": {sessions.SessionNewYork}, "CLP": {sessions.SessionNewYork}, "COP": {sessions.SessionNewYork},
+		"PEN": {sessions.SessionNewYork},
+		// Metals trade in London and New York
+		"XAU": {sessions.SessionLondon, sessions.SessionNewYork}, "XAG": {sessions.SessionLondon, sessions.SessionNewYork},
+		"XPT": {sessions.SessionLondon, sessions.SessionNewYork}, "XPD": {sessions.SessionLondon, sessions.SessionNewYork},
+	}
+
+	// Order sessions are listed in, following the trading day
+	sessionOrder = []string{sessions.SessionSydney, sessions.SessionTokyo, sessions.SessionLondon, sessions.SessionNewYork}
+
+	// Error returned for symbols that aren't valid currency pairs
+	ErrInvalidSymbol = errors.New("Invalid currency pair, expected two ISO 4217 currency codes (ex: EURUSD)")
+)
+
+// New returns the reference data of a currency pair, from its symbol (ex: "EUR/USD")
+func New(symbol string) (*db.Pair, error) {
+	symbol = provider.NormalizePair(symbol)
+	if len(symbol) != 6 {
+		return nil, ErrInvalidSymbol
+	}
+
+	base, quote := LookupCurrency(symbol[:3]), LookupCurrency(symbol[3:])
+	if base == nil || quote == nil || base == quote {
+		return nil, ErrInvalidSymbol
+	}
+	for _, c := range []*Currency{base, quote} {
+		if c.MinorUnits == nil && metalPipSizes[c.Code] == 0 {
+			return nil, fmt.Errorf("%s (%s) isn't a traded currency", c.Code, c.Name)
+		}
+	}
+	if _, ok := metalPipSizes[quote.Code]; ok {
+		return nil, fmt.Errorf("%s (%s) can only be the base currency of a pair", quote.Code, quote.Name)
+	}
+
+	pip := pipSize(base, quote)
+
+	return &db.Pair{
+		Symbol:    symbol,
+		Base:      base.Code,
+		Quote:     quote.Code,
+		PipSize:   pip,
+		Precision: decimals(pip) + 1,
+		Class:     classify(base.Code, quote.Code),
+		Sessions:  liquidSessions(base.Code, quote.Code),
+	}, nil
+}
+
+// Seed adds any missing pairs of a set of symbols to a store, returning the pairs added
+func Seed(store db.PairStore, symbols []string) ([]*db.Pair, error) {
+	added := []*db.Pair{}
+	for _, symbol := range symbols {
+		p, err := New(symbol)
+		if err != nil {
+			return added, fmt.Errorf("Unable to seed %s: %s", symbol, err)
+		}
+
+		existing, err := store.Get(p.Symbol)
+		if err != nil {
+			return added, err
+		}
+		if existing != nil {
+			continue
+		}
+
+		p.CreatedAt = time.Now().UTC()
+		p.UpdatedAt = p.CreatedAt
+		if err := store.Create(p); err != nil {
+			return added, err
+		}
+		added = append(added, p)
+	}
+
+	return added, nil
+}
+
+// pipSize returns the price change of a single pip of a pair: the hundredth of a unit for metals, and quote
+// currencies without minor units (ex: USDJPY), otherwise the ten thousandth of a unit (ex: EURUSD)
+func pipSize(base, quote *Currency) float64 {
+	if pip, ok := metalPipSizes[base.Code]; ok {
+		return pip
+	}
+	if *quote.MinorUnits == 0 {
+		return 0.01
+	}
+
+	return 0.0001
+}
+
+// decimals returns the number of decimal places of a pip size
+func decimals(pip float64) int {
+	n := 0
+	for ; pip < 0.999 && n < 8; n++ {
+		pip *= 10
+	}
+
+	return n
+}
+
+// classify returns the class of a pair of currencies
+func classify(base, quote string) string {
+	switch {
+	case !majorCurrencies[base] || !majorCurrencies[quote]:
+		return ClassExotic
+	case base == "USD" || quote == "USD":
+		return ClassMajor
+	}
+
+	return ClassMinor
+}
+
+// liquidSessions returns the sessions of either currency's home market, in the order of the trading day
+func liquidSessions(base, quote string) db.StringList {
+	home := map[string]bool{}
+	for _, s := range append(currencySessions[base], currencySessions[quote]...) {
+		home[s] = true
+	}
+
+	list := db.StringList{}
+	for _, s := range sessionOrder {
+		if home[s] {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
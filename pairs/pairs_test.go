@@ -0,0 +1,150 @@
+// Tests the pairs.go and currencies.go files
+package pairs
+
+import (
+	// Standard lib
+	"errors"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing in-memory pair storage used throughout tests
+	memoryPairStore struct {
+		pairs map[string]*db.Pair
+		err   error // Error returned by every method, if set
+	}
+)
+
+func (s *memoryPairStore) Create(p *db.Pair) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.pairs[p.Symbol] = p
+	return nil
+}
+
+func (s *memoryPairStore) Get(symbol string) (*db.Pair, error) { return s.pairs[symbol], s.err }
+
+func (s *memoryPairStore) List(f db.PairFilter) ([]*db.Pair, error) { return nil, s.err }
+
+var _ = Describe("currencies.go", func() {
+	It("Lists every ISO 4217 currency", func() {
+		list := Currencies()
+
+		// Verify output
+		Expect(len(list)).To(BeNumerically(">", 150))
+		Expect(list[0].Code < list[len(list)-1].Code).To(BeTrue())
+	})
+
+	It("Looks up currencies by code", func() {
+		c := LookupCurrency(" eur")
+		Expect(c).To(Not(BeNil()))
+		Expect(c.Name).To(Equal("Euro"))
+		Expect(c.Numeric).To(Equal("978"))
+		Expect(*c.MinorUnits).To(Equal(2))
+
+		Expect(*LookupCurrency("JPY").MinorUnits).To(Equal(0))
+		Expect(*LookupCurrency("KWD").MinorUnits).To(Equal(3))
+		Expect(LookupCurrency("XAU").MinorUnits).To(BeNil())
+		Expect(LookupCurrency("ABC")).To(BeNil())
+	})
+})
+
+var _ = Describe("pairs.go", func() {
+	Describe("`New` method", func() {
+		It("Derives the reference data of pairs", func() {
+			p, err := New("eur/usd")
+			Expect(err).To(Not(HaveOccurred()))
+
+			// Verify output
+			Expect(p.Symbol).To(Equal("EURUSD"))
+			Expect(p.Base).To(Equal("EUR"))
+			Expect(p.Quote).To(Equal("USD"))
+			Expect(p.PipSize).To(Equal(0.0001))
+			Expect(p.Precision).To(Equal(5))
+			Expect(p.Class).To(Equal(ClassMajor))
+			Expect(p.Sessions).To(Equal(db.StringList{"london", "new-york"}))
+		})
+
+		It("Uses larger pips for quote currencies without minor units, and metals", func() {
+			for symbol, expected := range map[string][]float64{
+				"USDJPY": {0.01, 3},
+				"EURJPY": {0.01, 3},
+				"USDKRW": {0.01, 3},
+				"XAUUSD": {0.01, 3},
+				"XAGUSD": {0.001, 4},
+				"USDKWD": {0.0001, 5},
+			} {
+				p, err := New(symbol)
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(p.PipSize).To(Equal(expected[0]), symbol)
+				Expect(float64(p.Precision)).To(Equal(expected[1]), symbol)
+			}
+		})
+
+		It("Classifies pairs", func() {
+			for symbol, class := range map[string]string{
+				"USDCAD": ClassMajor,
+				"NZDUSD": ClassMajor,
+				"EURGBP": ClassMinor,
+				"AUDNZD": ClassMinor,
+				"USDTRY": ClassExotic,
+				"EURPLN": ClassExotic,
+				"XAUUSD": ClassExotic,
+			} {
+				p, _ := New(symbol)
+				Expect(p.Class).To(Equal(class), symbol)
+			}
+		})
+
+		It("Lists the sessions of either currency's home market", func() {
+			for symbol, list := range map[string]db.StringList{
+				"AUDJPY": {"sydney", "tokyo"},
+				"GBPJPY": {"tokyo", "london"},
+				"AUDUSD": {"sydney", "new-york"},
+				"EURCHF": {"london"},
+				"USDKZT": {"new-york"},
+			} {
+				p, _ := New(symbol)
+				Expect(p.Sessions).To(Equal(list), symbol)
+			}
+		})
+
+		It("Returns errors for invalid pairs", func() {
+			for _, symbol := range []string{"", "EUR", "EURUSDX", "EURXYZ", "EUREUR", "XXXUSD", "USDXAU"} {
+				_, err := New(symbol)
+				Expect(err).To(HaveOccurred(), symbol)
+			}
+		})
+	})
+
+	Describe("`Seed` method", func() {
+		It("Adds missing pairs", func() {
+			store := &memoryPairStore{pairs: map[string]*db.Pair{"EURUSD": {Symbol: "EURUSD"}}}
+
+			added, err := Seed(store, []string{"EURUSD", "usd/jpy"})
+			Expect(err).To(Not(HaveOccurred()))
+
+			// Verify output
+			Expect(added).To(HaveLen(1))
+			Expect(added[0].Symbol).To(Equal("USDJPY"))
+			Expect(added[0].CreatedAt).To(Not(BeZero()))
+			Expect(store.pairs).To(HaveKey("USDJPY"))
+			Expect(store.pairs["EURUSD"].Base).To(BeEmpty())
+		})
+
+		It("Returns errors for invalid pairs, and store errors", func() {
+			_, err := Seed(&memoryPairStore{pairs: map[string]*db.Pair{}}, []string{"EURUSD", "EURXYZ"})
+			Expect(err).To(HaveOccurred())
+
+			_, err = Seed(&memoryPairStore{err: errors.New("Unreachable")}, []string{"EURUSD"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,122 @@
+// provider package contains the sources of FX quotes
+// feed contains the fan-out of received ticks to subscribers shared by every provider, along with the latest tick
+// of each pair
+package provider
+
+import (
+	// Standard lib
+	"context"
+	"sort"
+	"sync"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	// Struct representing the ticks received by a provider
+	feed struct {
+		mutex  sync.Mutex
+		subs   map[*subscription]bool // Open subscriptions
+		latest map[string]*Tick       // Latest tick of each pair
+		closed bool
+	}
+	// Struct representing a single subscriber to a feed
+	subscription struct {
+		pairs map[string]bool // Pairs subscribed to, empty for all
+		ticks chan *Tick
+	}
+)
+
+// newFeed creates and returns a new feed without ticks
+func newFeed() *feed {
+	return &feed{subs: map[*subscription]bool{}, latest: map[string]*Tick{}}
+}
+
+// publish records a tick as the latest of its pair and sends it to every matching subscriber
+func (f *feed) publish(t *Tick) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.latest[t.Pair] = t
+
+	for s := range f.subs {
+		if len(s.pairs) > 0 && !s.pairs[t.Pair] {
+			continue
+		}
+
+		select {
+		case s.ticks <- t:
+		default:
+			log.WithField("pair", t.Pair).Debug("Dropping tick for a slow subscriber")
+		}
+	}
+}
+
+// subscribe adds a subscription to a set of pairs, removed once the context is done
+func (f *feed) subscribe(ctx context.Context, pairs []string) (<-chan *Tick, error) {
+	s := &subscription{pairs: map[string]bool{}, ticks: make(chan *Tick, subscriptionBuffer)}
+	for _, pair := range NormalizePairs(pairs) {
+		s.pairs[pair] = true
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.closed {
+		return nil, ErrClosed
+	}
+	f.subs[s] = true
+
+	go func() {
+		<-ctx.Done()
+		f.unsubscribe(s)
+	}()
+
+	return s.ticks, nil
+}
+
+// unsubscribe removes a subscription and closes its channel, if it's still open
+func (f *feed) unsubscribe(s *subscription) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.subs[s] {
+		delete(f.subs, s)
+		close(s.ticks)
+	}
+}
+
+// snapshot returns the latest tick of a set of pairs (all pairs if none are given), sorted by pair
+func (f *feed) snapshot(pairs []string) []*Tick {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	ticks := make([]*Tick, 0, len(f.latest))
+	if len(pairs) == 0 {
+		for _, t := range f.latest {
+			ticks = append(ticks, t)
+		}
+	} else {
+		for _, pair := range NormalizePairs(pairs) {
+			if t, ok := f.latest[pair]; ok {
+				ticks = append(ticks, t)
+			}
+		}
+	}
+
+	sort.Slice(ticks, func(a, b int) bool { return ticks[a].Pair < ticks[b].Pair })
+
+	return ticks
+}
+
+// close closes every subscription, and refuses new ones
+func (f *feed) close() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.closed = true
+	for s := range f.subs {
+		delete(f.subs, s)
+		close(s.ticks)
+	}
+}
@@ -0,0 +1,228 @@
+// provider package contains the sources of FX quotes
+// http contains the HTTP polling provider, which polls an endpoint returning the latest ticks, along with a handler
+// serving the latest ticks of any provider in the same format, used to run a local mock
+package provider
+
+import (
+	// Standard lib
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	// HTTP is a struct representing a provider polling an HTTP endpoint for the latest ticks
+	HTTP struct {
+		feed     *feed // Received ticks
+		settings config.ProviderHTTP
+		pairs    []string // Pairs requested, empty for all
+		client   *http.Client
+		mutex    sync.Mutex
+		err      error         // Error of the last poll
+		stop     chan struct{} // Closed to stop polling, nil until started
+		done     chan struct{} // Closed once polling stops
+	}
+)
+
+// NewHTTP creates and returns a new HTTP polling provider
+func NewHTTP(c config.Provider) *HTTP {
+	return &HTTP{
+		feed:     newFeed(),
+		settings: c.HTTP,
+		pairs:    NormalizePairs(config.SplitList(c.Pairs)),
+		client:   &http.Client{Timeout: time.Duration(c.HTTP.Timeout) * time.Second},
+		err:      ErrNotStarted,
+	}
+}
+
+// SnapshotHandler returns an http handler serving the latest ticks of a provider as a JSON array, optionally only
+// those of a set of pairs (`pairs`, comma-separated), the format polled by the HTTP provider
+func SnapshotHandler(p Provider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		ticks, err := p.Snapshot(req.Context(), config.SplitList(req.URL.Query().Get("pairs")))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ticks)
+	})
+}
+
+// Name returns the type of provider
+func (h *HTTP) Name() string { return TypeHTTP }
+
+// Start polls the endpoint once, then continues polling in the background
+// NOTE: A failed first poll is returned, but polling continues
+func (h *HTTP) Start() error {
+	h.mutex.Lock()
+	if h.stop != nil {
+		h.mutex.Unlock()
+		return errors.New("HTTP provider has already been started")
+	}
+	h.stop, h.done = make(chan struct{}), make(chan struct{})
+	h.mutex.Unlock()
+
+	err := h.poll()
+	go h.run(h.stop, h.done)
+
+	return err
+}
+
+// Close stops polling and closes every subscription
+func (h *HTTP) Close() error {
+	h.mutex.Lock()
+	stop, done := h.stop, h.done
+	h.mutex.Unlock()
+
+	if stop != nil {
+		select {
+		case <-stop:
+		default:
+			close(stop)
+		}
+		<-done
+	}
+
+	h.mutex.Lock()
+	h.err = ErrClosed
+	h.mutex.Unlock()
+
+	h.feed.close()
+
+	return nil
+}
+
+// Subscribe returns a channel receiving polled ticks of a set of pairs
+func (h *HTTP) Subscribe(ctx context.Context, pairs []string) (<-chan *Tick, error) {
+	return h.feed.subscribe(ctx, pairs)
+}
+
+// Snapshot returns the latest polled tick of a set of pairs
+func (h *HTTP) Snapshot(ctx context.Context, pairs []string) ([]*Tick, error) {
+	return h.feed.snapshot(pairs), nil
+}
+
+// Health returns the error of the last poll
+func (h *HTTP) Health() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.err
+}
+
+// run polls the endpoint every interval until stopped
+func (h *HTTP) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(time.Duration(h.settings.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := h.poll(); err != nil {
+				log.WithError(err).Warn("Error polling FX quotes")
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// poll requests the latest ticks, publishing those newer than the latest of their pair
+func (h *HTTP) poll() error {
+	ticks, err := h.fetch()
+
+	h.mutex.Lock()
+	h.err = err
+	h.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	latest := map[string]*Tick{}
+	for _, t := range h.feed.snapshot(nil) {
+		latest[t.Pair] = t
+	}
+
+	now := time.Now().UTC()
+	for _, t := range ticks {
+		t.Pair = NormalizePair(t.Pair)
+		if err := t.validate(); err != nil {
+			log.WithError(err).Warn("Skipping invalid FX quote")
+			continue
+		}
+		if t.Time.IsZero() {
+			t.Time = now
+		}
+		if t.Source == "" {
+			t.Source = TypeHTTP
+		}
+
+		if prev, ok := latest[t.Pair]; ok && !t.Time.After(prev.Time) {
+			continue
+		}
+		h.feed.publish(t)
+	}
+
+	return nil
+}
+
+// fetch requests the latest ticks from the endpoint
+func (h *HTTP) fetch() ([]*Tick, error) {
+	u, err := url.Parse(h.settings.URL)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid provider URL: %s", err)
+	}
+	if len(h.pairs) > 0 {
+		q := u.Query()
+		q.Set("pairs", strings.Join(h.pairs, ","))
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if h.settings.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.settings.Token)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Provider responded with status %d", resp.StatusCode)
+	}
+
+	ticks := []*Tick{}
+	if err := json.NewDecoder(resp.Body).Decode(&ticks); err != nil {
+		return nil, fmt.Errorf("Invalid provider response: %s", err)
+	}
+
+	return ticks, nil
+}
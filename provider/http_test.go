@@ -0,0 +1,137 @@
+// Tests the http.go file
+package provider
+
+import (
+	// Standard lib
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("http.go", func() {
+	var (
+		mutex  sync.Mutex
+		ticks  []*Tick
+		status int
+		auth   string
+		pairs  string
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		ticks, status, auth, pairs = []*Tick{}, http.StatusOK, "", ""
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			auth, pairs = req.Header.Get("Authorization"), req.URL.Query().Get("pairs")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(ticks)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// set sets the response of the test server
+	set := func(s int, t ...*Tick) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		status, ticks = s, t
+	}
+
+	// newProvider creates a provider polling the test server
+	newProvider := func(token string) *HTTP {
+		return NewHTTP(config.Provider{
+			Pairs: "eur/usd,usd/jpy",
+			HTTP:  config.ProviderHTTP{URL: server.URL, Token: token, Interval: 1, Timeout: 1},
+		})
+	}
+
+	It("Polls the endpoint for the latest ticks", func() {
+		at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		set(http.StatusOK, &Tick{Pair: "eur/usd", Bid: 1.08, Ask: 1.0801, Time: at}, &Tick{Pair: "USDJPY", Bid: 150.1, Ask: 150.12})
+
+		h := newProvider("secret")
+		Expect(h.Health()).To(Equal(ErrNotStarted))
+		received, _ := h.Subscribe(context.Background(), nil)
+		Expect(h.Start()).To(Succeed())
+		defer h.Close()
+
+		// Verify request
+		mutex.Lock()
+		Expect(auth).To(Equal("Bearer secret"))
+		Expect(pairs).To(Equal("EURUSD,USDJPY"))
+		mutex.Unlock()
+
+		// Verify output
+		Expect(h.Health()).To(Succeed())
+		var t *Tick
+		Expect(received).To(Receive(&t))
+		Expect(t.Pair).To(Equal("EURUSD"))
+		Expect(t.Source).To(Equal(TypeHTTP))
+		Expect(received).To(Receive(&t))
+		Expect(t.Time).To(Not(BeZero()))
+
+		snapshot, _ := h.Snapshot(context.Background(), []string{"EURUSD"})
+		Expect(snapshot).To(HaveLen(1))
+		Expect(snapshot[0].Time).To(Equal(at))
+
+		// Verify only newer ticks are published
+		set(http.StatusOK, &Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: at}, &Tick{Pair: "EURUSD", Bid: 1.09, Ask: 1.0901, Time: at.Add(time.Second)})
+		Eventually(received, 3*time.Second).Should(Receive(&t))
+		Expect(t.Bid).To(Equal(1.09))
+		Expect(received).To(Not(Receive()))
+	})
+
+	It("Reports failed polls", func() {
+		set(http.StatusInternalServerError)
+
+		h := newProvider("")
+		Expect(h.Start()).To(HaveOccurred())
+		defer h.Close()
+
+		// Verify output
+		Expect(h.Health()).To(HaveOccurred())
+		mutex.Lock()
+		Expect(auth).To(BeEmpty())
+		mutex.Unlock()
+
+		// Verify recovery
+		set(http.StatusOK, &Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801})
+		Eventually(h.Health, 3*time.Second).Should(Succeed())
+
+		Expect(h.Close()).To(Succeed())
+		Expect(h.Health()).To(Equal(ErrClosed))
+	})
+
+	It("Serves the latest ticks of a provider in the format it polls", func() {
+		f := &HTTP{feed: newFeed()}
+		f.feed.publish(&Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: time.Now().UTC(), Source: TypeReplay})
+		f.feed.publish(&Tick{Pair: "GBPUSD", Bid: 1.26, Ask: 1.2602, Time: time.Now().UTC(), Source: TypeReplay})
+		mock := httptest.NewServer(SnapshotHandler(f))
+		defer mock.Close()
+
+		h := NewHTTP(config.Provider{Pairs: "EURUSD", HTTP: config.ProviderHTTP{URL: mock.URL, Interval: 1, Timeout: 1}})
+		Expect(h.Start()).To(Succeed())
+		defer h.Close()
+
+		// Verify output
+		snapshot, _ := h.Snapshot(context.Background(), nil)
+		Expect(snapshot).To(HaveLen(1))
+		Expect(snapshot[0].Pair).To(Equal("EURUSD"))
+		Expect(snapshot[0].Source).To(Equal(TypeReplay))
+	})
+})
@@ -0,0 +1,119 @@
+// provider package contains the sources of FX quotes. Every source fulfills the `Provider` interface, delivering
+// ticks of currency pairs to subscribers, keeping the latest tick of each pair, and reporting its health. Sources
+// are chosen by the `provider.type` setting:
+// - `replay` - plays historical ticks from a CSV or JSON Lines file, at their recorded or an accelerated pace
+// - `http` - polls an HTTP endpoint returning the latest ticks, such as a local mock (see `SnapshotHandler`)
+package provider
+
+import (
+	// Standard lib
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+)
+
+const (
+	// Supported provider types
+	TypeNone   = "none"
+	TypeReplay = "replay"
+	TypeHTTP   = "http"
+
+	// Number of ticks buffered per subscription before ticks are dropped
+	subscriptionBuffer = 256
+)
+
+type (
+	// Provider is an interface that all sources of FX quotes must fulfill
+	Provider interface {
+		// Name returns the type of provider (ex: "replay")
+		Name() string
+		// Start starts receiving ticks in the background
+		Start() error
+		// Close stops receiving ticks and closes every subscription
+		Close() error
+		// Subscribe returns a channel receiving ticks of a set of pairs (all pairs if none are given), closed once
+		// the context is done or the provider is closed
+		// NOTE: Ticks are dropped for subscribers that fall behind, as only the latest quote matters
+		Subscribe(ctx context.Context, pairs []string) (<-chan *Tick, error)
+		// Snapshot returns the latest tick of a set of pairs (all pairs if none are given), sorted by pair
+		Snapshot(ctx context.Context, pairs []string) ([]*Tick, error)
+		// Health returns an error if the provider isn't receiving ticks
+		Health() error
+	}
+	// Tick is a struct representing a single quote of a currency pair, or of an index within `quotes.indices`
+	Tick struct {
+		Pair   string    `json:"pair"`   // Currency pair, without a separator (ex: "EURUSD"), or index (ex: "VIX")
+		Bid    float64   `json:"bid"`    // Price the market buys the base currency at
+		Ask    float64   `json:"ask"`    // Price the market sells the base currency at
+		Time   time.Time `json:"time"`   // When the quote was made
+		Source string    `json:"source"` // Provider the quote was received from
+	}
+)
+
+var (
+	// Errors returned by providers
+	ErrNotStarted = errors.New("Provider has not been started")
+	ErrClosed     = errors.New("Provider is closed")
+)
+
+// New creates and returns the provider configured within configuration settings, nil if market data is disabled
+func New(c config.Provider) (Provider, error) {
+	switch c.Type {
+	case TypeNone:
+		return nil, nil
+	case TypeReplay:
+		return NewReplay(c), nil
+	case TypeHTTP:
+		return NewHTTP(c), nil
+	}
+
+	return nil, fmt.Errorf("Unknown provider type: %s", c.Type)
+}
+
+// NormalizePair returns the canonical form of a currency pair, uppercase without a separator (ex: "eur/usd"
+// becomes "EURUSD")
+func NormalizePair(pair string) string {
+	return strings.ToUpper(strings.NewReplacer("/", "", "-", "", "_", "", " ", "").Replace(strings.TrimSpace(pair)))
+}
+
+// NormalizePairs returns the canonical form of a set of currency pairs
+func NormalizePairs(pairs []string) []string {
+	normalized := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		normalized = append(normalized, NormalizePair(pair))
+	}
+
+	return normalized
+}
+
+// IsIndex returns a boolean indicating if a symbol is an index quoted alongside currency pairs, within
+// `quotes.indices`
+func IsIndex(symbol string) bool {
+	symbol = NormalizePair(symbol)
+	for _, index := range NormalizePairs(config.SplitList(config.GetInstance().Quotes.Indices)) {
+		if symbol != "" && symbol == index {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validate returns an error if a tick can't be used
+func (t *Tick) validate() error {
+	switch {
+	case len(t.Pair) != 6 && !IsIndex(t.Pair):
+		return fmt.Errorf("Invalid pair %q, expected six letters (ex: EURUSD) or an index within `quotes.indices`", t.Pair)
+	case t.Bid <= 0 || t.Ask <= 0:
+		return fmt.Errorf("Invalid %s prices, expected positive bid and ask", t.Pair)
+	case t.Ask < t.Bid:
+		return fmt.Errorf("Invalid %s prices, ask %v is below bid %v", t.Pair, t.Ask, t.Bid)
+	}
+
+	return nil
+}
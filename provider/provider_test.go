@@ -0,0 +1,144 @@
+// Tests the provider.go and feed.go files
+package provider
+
+import (
+	// Standard lib
+	"context"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("provider.go", func() {
+	Describe("`New` method", func() {
+		It("Creates the configured provider", func() {
+			p, err := New(config.Provider{Type: TypeNone})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(p).To(BeNil())
+
+			p, err = New(config.Provider{Type: TypeReplay})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(p.Name()).To(Equal(TypeReplay))
+
+			p, err = New(config.Provider{Type: TypeHTTP})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(p.Name()).To(Equal(TypeHTTP))
+
+			_, err = New(config.Provider{Type: "carrier-pigeon"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("Normalizes currency pairs", func() {
+		Expect(NormalizePairs([]string{"eur/usd", " GBP-JPY ", "aud_nzd", "USDCHF"})).
+			To(Equal([]string{"EURUSD", "GBPJPY", "AUDNZD", "USDCHF"}))
+	})
+
+	It("Validates ticks", func() {
+		Expect((&Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801}).validate()).To(Succeed())
+		Expect((&Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.08}).validate()).To(Succeed())
+		Expect((&Tick{Pair: "EUR", Bid: 1.08, Ask: 1.0801}).validate()).To(HaveOccurred())
+		Expect((&Tick{Pair: "EURUSD", Bid: 0, Ask: 1.0801}).validate()).To(HaveOccurred())
+		Expect((&Tick{Pair: "EURUSD", Bid: 1.0802, Ask: 1.0801}).validate()).To(HaveOccurred())
+
+		// Verify indices
+		Expect((&Tick{Pair: "VIX", Bid: 14.2, Ask: 14.25}).validate()).To(Succeed())
+		Expect((&Tick{Pair: "SPX", Bid: 5300, Ask: 5301}).validate()).To(HaveOccurred())
+	})
+
+	It("Recognizes indices within `quotes.indices`", func() {
+		defer func(prev string) { config.GetInstance().Quotes.Indices = prev }(config.GetInstance().Quotes.Indices)
+
+		// Verify output
+		Expect(IsIndex("VIX")).To(BeTrue())
+		Expect(IsIndex("vix")).To(BeTrue())
+		Expect(IsIndex("EURUSD")).To(BeFalse())
+		Expect(IsIndex("")).To(BeFalse())
+
+		config.GetInstance().Quotes.Indices = "vix, dxy"
+		Expect(IsIndex("DXY")).To(BeTrue())
+		config.GetInstance().Quotes.Indices = ""
+		Expect(IsIndex("VIX")).To(BeFalse())
+	})
+})
+
+var _ = Describe("feed.go", func() {
+	var (
+		f   *feed
+		now = time.Now().UTC()
+	)
+
+	BeforeEach(func() {
+		f = newFeed()
+	})
+
+	It("Sends ticks to matching subscribers", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		all, err := f.subscribe(ctx, nil)
+		Expect(err).To(Not(HaveOccurred()))
+		euro, err := f.subscribe(ctx, []string{"eur/usd"})
+		Expect(err).To(Not(HaveOccurred()))
+
+		f.publish(&Tick{Pair: "GBPUSD", Bid: 1.27, Ask: 1.2701, Time: now})
+		f.publish(&Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now})
+
+		// Verify output
+		Expect((<-all).Pair).To(Equal("GBPUSD"))
+		Expect((<-all).Pair).To(Equal("EURUSD"))
+		Expect((<-euro).Pair).To(Equal("EURUSD"))
+		Expect(euro).To(Not(Receive()))
+	})
+
+	It("Drops ticks for slow subscribers", func() {
+		ticks, _ := f.subscribe(context.Background(), nil)
+		for n := 0; n < subscriptionBuffer+10; n++ {
+			f.publish(&Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now})
+		}
+
+		// Verify output
+		Expect(ticks).To(HaveLen(subscriptionBuffer))
+	})
+
+	It("Closes subscriptions once their context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		ticks, _ := f.subscribe(ctx, nil)
+		cancel()
+
+		// Verify output
+		Eventually(ticks).Should(BeClosed())
+	})
+
+	It("Keeps the latest tick of each pair", func() {
+		f.publish(&Tick{Pair: "USDJPY", Bid: 151.1, Ask: 151.12, Time: now})
+		f.publish(&Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now})
+		f.publish(&Tick{Pair: "EURUSD", Bid: 1.09, Ask: 1.0901, Time: now})
+
+		// Verify output
+		ticks := f.snapshot(nil)
+		Expect(ticks).To(HaveLen(2))
+		Expect(ticks[0].Pair).To(Equal("EURUSD"))
+		Expect(ticks[0].Bid).To(Equal(1.09))
+		Expect(ticks[1].Pair).To(Equal("USDJPY"))
+
+		ticks = f.snapshot([]string{"usd/jpy", "GBPUSD"})
+		Expect(ticks).To(HaveLen(1))
+		Expect(ticks[0].Pair).To(Equal("USDJPY"))
+	})
+
+	It("Closes every subscription, and refuses new ones, once closed", func() {
+		ticks, _ := f.subscribe(context.Background(), nil)
+		f.close()
+
+		// Verify output
+		Expect(ticks).To(BeClosed())
+		_, err := f.subscribe(context.Background(), nil)
+		Expect(err).To(Equal(ErrClosed))
+	})
+})
@@ -0,0 +1,280 @@
+// provider package contains the sources of FX quotes
+// replay contains the replay provider, which plays historical ticks from a CSV or JSON Lines file
+package provider
+
+import (
+	// Standard lib
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+)
+
+const (
+	// Supported replay file formats
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+type (
+	// Replay is a struct representing a provider playing historical ticks from a file
+	Replay struct {
+		feed     *feed // Played ticks
+		settings config.ProviderReplay
+		pairs    map[string]bool // Pairs played, empty for all
+		mutex    sync.Mutex
+		err      error         // Error loading the file, or ErrReplayFinished once playback ends
+		stop     chan struct{} // Closed to stop playback, nil until started
+		done     chan struct{} // Closed once playback stops
+	}
+)
+
+var (
+	// Error reported once playback ends without looping
+	ErrReplayFinished = errors.New("Replay has finished")
+)
+
+// NewReplay creates and returns a new replay provider
+// NOTE: The file is read when the provider is started
+func NewReplay(c config.Provider) *Replay {
+	r := &Replay{feed: newFeed(), settings: c.Replay, pairs: map[string]bool{}, err: ErrNotStarted}
+	for _, pair := range NormalizePairs(config.SplitList(c.Pairs)) {
+		r.pairs[pair] = true
+	}
+
+	return r
+}
+
+// ReadTicks reads ticks from a CSV file (with a header row naming the `time`, `pair`, `bid`, and `ask` columns)
+// or a JSON Lines file (with an object of the same fields per line), sorted by time
+func ReadTicks(rd io.Reader, format string) ([]*Tick, error) {
+	var (
+		ticks []*Tick
+		err   error
+	)
+
+	switch format {
+	case FormatCSV:
+		ticks, err = readCSV(rd)
+	case FormatJSONL:
+		ticks, err = readJSONL(rd)
+	default:
+		return nil, fmt.Errorf("Unknown tick file format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for n, t := range ticks {
+		t.Pair = NormalizePair(t.Pair)
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("Tick %d: %s", n+1, err)
+		}
+	}
+	sort.SliceStable(ticks, func(a, b int) bool { return ticks[a].Time.Before(ticks[b].Time) })
+
+	return ticks, nil
+}
+
+// Name returns the type of provider
+func (r *Replay) Name() string { return TypeReplay }
+
+// Start reads the file and starts playback in the background
+func (r *Replay) Start() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stop != nil {
+		return errors.New("Replay has already been started")
+	}
+
+	ticks, err := r.load()
+	if err != nil {
+		r.err = err
+		return err
+	}
+
+	r.err = nil
+	r.stop, r.done = make(chan struct{}), make(chan struct{})
+	go r.play(ticks, r.stop, r.done)
+
+	return nil
+}
+
+// Close stops playback and closes every subscription
+func (r *Replay) Close() error {
+	r.mutex.Lock()
+	stop, done := r.stop, r.done
+	r.mutex.Unlock()
+
+	if stop != nil {
+		select {
+		case <-stop:
+		default:
+			close(stop)
+		}
+		<-done
+	}
+
+	r.mutex.Lock()
+	r.err = ErrClosed
+	r.mutex.Unlock()
+
+	r.feed.close()
+
+	return nil
+}
+
+// Subscribe returns a channel receiving played ticks of a set of pairs
+func (r *Replay) Subscribe(ctx context.Context, pairs []string) (<-chan *Tick, error) {
+	return r.feed.subscribe(ctx, pairs)
+}
+
+// Snapshot returns the latest played tick of a set of pairs
+func (r *Replay) Snapshot(ctx context.Context, pairs []string) ([]*Tick, error) {
+	return r.feed.snapshot(pairs), nil
+}
+
+// Health returns an error if the file couldn't be read, or playback isn't in progress
+func (r *Replay) Health() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.err
+}
+
+// load reads the ticks of played pairs from the file
+func (r *Replay) load() ([]*Tick, error) {
+	f, err := os.Open(r.settings.File)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open replay file: %s", err)
+	}
+	defer f.Close()
+
+	all, err := ReadTicks(f, strings.TrimPrefix(strings.ToLower(filepath.Ext(r.settings.File)), "."))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read replay file: %s", err)
+	}
+
+	ticks := make([]*Tick, 0, len(all))
+	for _, t := range all {
+		if len(r.pairs) == 0 || r.pairs[t.Pair] {
+			ticks = append(ticks, t)
+		}
+	}
+	if len(ticks) == 0 {
+		return nil, errors.New("Replay file has no ticks to play")
+	}
+
+	return ticks, nil
+}
+
+// play publishes ticks at their recorded pace divided by the playback speed, until stopped
+func (r *Replay) play(ticks []*Tick, stop, done chan struct{}) {
+	defer close(done)
+
+	for {
+		for n, t := range ticks {
+			if n > 0 && r.settings.Speed > 0 {
+				select {
+				case <-time.After(t.Time.Sub(ticks[n-1].Time) / time.Duration(r.settings.Speed)):
+				case <-stop:
+					return
+				}
+			} else {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+			}
+
+			played := *t
+			played.Source = TypeReplay
+			if r.settings.Rebase {
+				played.Time = time.Now().UTC()
+			}
+			r.feed.publish(&played)
+		}
+
+		if !r.settings.Loop || r.settings.Speed == 0 {
+			break
+		}
+	}
+
+	r.mutex.Lock()
+	r.err = ErrReplayFinished
+	r.mutex.Unlock()
+}
+
+// readCSV reads ticks from a CSV file with a header row
+func readCSV(rd io.Reader) ([]*Tick, error) {
+	records, err := csv.NewReader(rd).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []*Tick{}, nil
+	}
+
+	columns := map[string]int{}
+	for n, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = n
+	}
+	for _, name := range []string{"time", "pair", "bid", "ask"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("Missing `%s` column", name)
+		}
+	}
+
+	ticks := make([]*Tick, 0, len(records)-1)
+	for n, record := range records[1:] {
+		t := &Tick{Pair: record[columns["pair"]]}
+		if t.Time, err = time.Parse(time.RFC3339Nano, record[columns["time"]]); err != nil {
+			return nil, fmt.Errorf("Line %d: invalid time, expected RFC 3339", n+2)
+		}
+		if t.Bid, err = strconv.ParseFloat(record[columns["bid"]], 64); err != nil {
+			return nil, fmt.Errorf("Line %d: invalid bid", n+2)
+		}
+		if t.Ask, err = strconv.ParseFloat(record[columns["ask"]], 64); err != nil {
+			return nil, fmt.Errorf("Line %d: invalid ask", n+2)
+		}
+		ticks = append(ticks, t)
+	}
+
+	return ticks, nil
+}
+
+// readJSONL reads ticks from a JSON Lines file, skipping blank lines
+func readJSONL(rd io.Reader) ([]*Tick, error) {
+	ticks := make([]*Tick, 0)
+
+	scanner := bufio.NewScanner(rd)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		t := &Tick{}
+		if err := json.Unmarshal([]byte(line), t); err != nil {
+			return nil, fmt.Errorf("Line %d: %s", n, err)
+		}
+		ticks = append(ticks, t)
+	}
+
+	return ticks, scanner.Err()
+}
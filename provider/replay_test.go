@@ -0,0 +1,141 @@
+// Tests the replay.go file
+package provider
+
+import (
+	// Standard lib
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	// Ticks used throughout replay tests
+	replayCSV = `time,pair,bid,ask
+2024-03-01T12:00:01Z,GBP/USD,1.2650,1.2652
+2024-03-01T12:00:00Z,EUR/USD,1.0800,1.0801
+2024-03-01T12:00:02Z,EUR/USD,1.0802,1.0803
+`
+	replayJSONL = `{"time":"2024-03-01T12:00:00Z","pair":"EURUSD","bid":1.08,"ask":1.0801}
+
+{"time":"2024-03-01T12:00:01Z","pair":"usdjpy","bid":150.1,"ask":150.12}
+`
+)
+
+var _ = Describe("replay.go", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir, _ = ioutil.TempDir("", "replay")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	// write writes a replay file to the temporary directory, returning its path
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		return path
+	}
+
+	Describe("`ReadTicks` method", func() {
+		It("Reads CSV files sorted by time", func() {
+			ticks, err := ReadTicks(strings.NewReader(replayCSV), FormatCSV)
+			Expect(err).To(Not(HaveOccurred()))
+
+			// Verify output
+			Expect(ticks).To(HaveLen(3))
+			Expect(ticks[0].Pair).To(Equal("EURUSD"))
+			Expect(ticks[0].Bid).To(Equal(1.08))
+			Expect(ticks[1].Pair).To(Equal("GBPUSD"))
+			Expect(ticks[2].Time).To(Equal(time.Date(2024, 3, 1, 12, 0, 2, 0, time.UTC)))
+		})
+
+		It("Reads JSON Lines files", func() {
+			ticks, err := ReadTicks(strings.NewReader(replayJSONL), FormatJSONL)
+			Expect(err).To(Not(HaveOccurred()))
+
+			// Verify output
+			Expect(ticks).To(HaveLen(2))
+			Expect(ticks[1].Pair).To(Equal("USDJPY"))
+			Expect(ticks[1].Ask).To(Equal(150.12))
+		})
+
+		It("Returns errors for invalid files", func() {
+			for contents, format := range map[string]string{
+				"time,pair,bid\n2024-03-01T12:00:00Z,EURUSD,1.08\n":       FormatCSV,
+				"time,pair,bid,ask\nyesterday,EURUSD,1.08,1.0801\n":       FormatCSV,
+				"time,pair,bid,ask\n2024-03-01T12:00:00Z,EURUSD,x,1.08\n": FormatCSV,
+				"time,pair,bid,ask\n2024-03-01T12:00:00Z,EUR,1.08,1.08\n": FormatCSV,
+				`{"pair":"EURUSD","bid":`:                                 FormatJSONL,
+				replayCSV:                                                 "xml",
+			} {
+				_, err := ReadTicks(strings.NewReader(contents), format)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	It("Plays every tick at once without a playback speed", func() {
+		r := NewReplay(config.Provider{Pairs: "eur/usd", Replay: config.ProviderReplay{File: write("ticks.csv", replayCSV)}})
+		Expect(r.Health()).To(Equal(ErrNotStarted))
+
+		ticks, err := r.Subscribe(context.Background(), nil)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(r.Start()).To(Succeed())
+		defer r.Close()
+
+		// Verify output, only configured pairs are played
+		Expect((<-ticks).Bid).To(Equal(1.08))
+		played := <-ticks
+		Expect(played.Bid).To(Equal(1.0802))
+		Expect(played.Source).To(Equal(TypeReplay))
+		Expect(played.Time).To(Equal(time.Date(2024, 3, 1, 12, 0, 2, 0, time.UTC)))
+		Eventually(r.Health).Should(Equal(ErrReplayFinished))
+
+		snapshot, _ := r.Snapshot(context.Background(), nil)
+		Expect(snapshot).To(HaveLen(1))
+		Expect(snapshot[0].Pair).To(Equal("EURUSD"))
+	})
+
+	It("Plays ticks at an accelerated pace, stamped with the time they're played", func() {
+		settings := config.ProviderReplay{File: write("ticks.jsonl", replayJSONL), Speed: 100, Loop: true, Rebase: true}
+		r := NewReplay(config.Provider{Replay: settings})
+
+		ticks, _ := r.Subscribe(context.Background(), []string{"USDJPY"})
+		start := time.Now().UTC()
+		Expect(r.Start()).To(Succeed())
+
+		// Verify output, loops until closed
+		for n := 0; n < 3; n++ {
+			var played *Tick
+			Eventually(ticks).Should(Receive(&played))
+			Expect(played.Pair).To(Equal("USDJPY"))
+			Expect(played.Time).To(BeTemporally(">=", start))
+		}
+		Expect(r.Health()).To(Succeed())
+
+		Expect(r.Close()).To(Succeed())
+		Expect(r.Health()).To(Equal(ErrClosed))
+		Eventually(ticks).Should(BeClosed())
+	})
+
+	It("Reports files that can't be played", func() {
+		for _, path := range []string{filepath.Join(dir, "missing.csv"), write("ticks.txt", replayCSV), write("empty.csv", "time,pair,bid,ask\n")} {
+			r := NewReplay(config.Provider{Replay: config.ProviderReplay{File: path}})
+			Expect(r.Start()).To(HaveOccurred())
+			Expect(r.Health()).To(HaveOccurred())
+		}
+	})
+})
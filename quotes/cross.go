@@ -0,0 +1,248 @@
+// quotes package contains the snapshot of the latest FX quote of each pair
+// cross contains the derivation of quotes of pairs that aren't quoted, from quotes of other pairs
+package quotes
+
+import (
+	// Standard lib
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/provider"
+)
+
+const (
+	// Source of derived quotes
+	SourceCross = "cross"
+
+	// Decimal places of conversions into currencies without minor units (ex: gold)
+	conversionPlaces = 6
+
+	// Fraction of the last decimal place within which derived prices are treated as exact, as multiplying prices
+	// leaves floating point errors (ex: 1.08 * 150 = 162.00000000000003)
+	roundingTolerance = 1e-6
+)
+
+var (
+	// Errors of conversions that can't be made
+	ErrUnknownCurrency = errors.New("Unknown ISO 4217 currency")
+	ErrNoQuote         = errors.New("No quote is available to convert between the currencies")
+)
+
+type (
+	// Conversion is a struct representing an amount of one currency converted into another
+	Conversion struct {
+		From   string  `json:"from"`            // ISO 4217 code of the currency converted from
+		To     string  `json:"to"`              // ISO 4217 code of the currency converted into
+		Amount float64 `json:"amount"`          // Amount of the currency converted from
+		Rate   float64 `json:"rate"`            // Mid price of the currency converted from, in the other
+		Result float64 `json:"result"`          // Amount of the currency converted into
+		Quote  *Quote  `json:"quote,omitempty"` // Quote the rate is from, omitted when converting into the same currency
+	}
+	// Struct representing a quote of one currency in another, from a stored quote in either direction
+	leg struct {
+		e        *entry
+		inverted bool // Whether the stored quote is of the other currency in the first
+	}
+	// Struct representing a way of deriving a pair's quote
+	path struct {
+		currencies []string
+		legs       []leg
+	}
+)
+
+// Cross returns the latest quote of a pair, deriving it when it isn't quoted, or nil if it can't be derived
+// NOTE: Quotes are derived by inverting the quote of the reverse pair, or through a pivot currency within
+// `quotes.pivots`, using the path whose oldest quote is the most recent
+func (s *Store) Cross(pair string) *Quote {
+	pair = provider.NormalizePair(pair)
+	if q := s.Get(pair); q != nil {
+		return q
+	}
+
+	p, err := pairs.New(pair)
+	if err != nil {
+		return nil
+	}
+
+	s.mutex.RLock()
+	paths := s.paths(p.Base, p.Quote)
+	s.mutex.RUnlock()
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// Use the freshest path, preferring fewer legs, then pivots in order of preference
+	best := paths[0]
+	for _, c := range paths[1:] {
+		if c.time().After(best.time()) {
+			best = c
+		}
+	}
+
+	return best.quote(p.Symbol, p.Precision, time.Now())
+}
+
+// CrossList returns the latest quote of a set of pairs, deriving those that aren't quoted, sorted by pair
+func (s *Store) CrossList(symbols []string) []*Quote {
+	list := []*Quote{}
+	for _, pair := range provider.NormalizePairs(symbols) {
+		if q := s.Cross(pair); q != nil {
+			list = append(list, q)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Pair < list[b].Pair })
+
+	return list
+}
+
+// paths returns every way of deriving a quote of one currency in another, from an inverted quote, then through each
+// pivot currency in order of preference
+// NOTE: Must be called while holding the mutex
+func (s *Store) paths(base, quote string) []*path {
+	paths := []*path{}
+	if l, ok := s.leg(base, quote); ok {
+		paths = append(paths, &path{currencies: []string{base, quote}, legs: []leg{l}})
+	}
+
+	for _, pivot := range config.SplitList(strings.ToUpper(config.GetInstance().Quotes.Pivots)) {
+		if pivot == base || pivot == quote {
+			continue
+		}
+
+		first, ok := s.leg(base, pivot)
+		if !ok {
+			continue
+		}
+		second, ok := s.leg(pivot, quote)
+		if !ok {
+			continue
+		}
+		paths = append(paths, &path{currencies: []string{base, pivot, quote}, legs: []leg{first, second}})
+	}
+
+	return paths
+}
+
+// leg returns the stored quote of one currency in another, in either direction
+// NOTE: Must be called while holding the mutex
+func (s *Store) leg(from, to string) (leg, bool) {
+	if e, ok := s.latest[from+to]; ok {
+		return leg{e: e}, true
+	}
+	if e, ok := s.latest[to+from]; ok {
+		return leg{e: e, inverted: true}, true
+	}
+
+	return leg{}, false
+}
+
+// rates returns the bid and ask of the leg's first currency in its second
+func (l leg) rates() (float64, float64) {
+	if l.inverted {
+		return 1 / l.e.tick.Ask, 1 / l.e.tick.Bid
+	}
+
+	return l.e.tick.Bid, l.e.tick.Ask
+}
+
+// time returns when the oldest quote of the path was made
+func (p *path) time() time.Time {
+	t := p.legs[0].e.tick.Time
+	for _, l := range p.legs[1:] {
+		if l.e.tick.Time.Before(t) {
+			t = l.e.tick.Time
+		}
+	}
+
+	return t
+}
+
+// quote returns the quote of a pair derived through the path, with prices rounded to a precision, at a point in time
+// NOTE: Bids and asks are multiplied along the path, so the spread combines the spread of every leg. Bids are rounded
+// down and asks up, so the derived spread is never narrower than the combined spread. The quote is as old as the
+// oldest quote, and arrived while the market was closed if any quote did
+func (p *path) quote(pair string, precision int, now time.Time) *Quote {
+	bid, ask := 1.0, 1.0
+	e := &entry{tick: provider.Tick{Pair: pair, Time: p.time(), Source: SourceCross}}
+	legs := make([]string, 0, len(p.legs))
+	for _, l := range p.legs {
+		b, a := l.rates()
+		bid, ask = bid*b, ask*a
+
+		if e.receivedAt.IsZero() || l.e.receivedAt.Before(e.receivedAt) {
+			e.receivedAt = l.e.receivedAt
+		}
+		e.closed = e.closed || l.e.closed
+		legs = append(legs, l.e.tick.Pair)
+	}
+	e.tick.Bid, e.tick.Ask = roundDown(bid, precision), roundUp(ask, precision)
+
+	q := e.quote(now)
+	q.Path, q.Legs = p.currencies, legs
+
+	return q
+}
+
+// Convert returns the conversion of an amount of one currency into another at the latest mid price, deriving the
+// quote of the pair when it isn't quoted
+// NOTE: Rates are rounded to the pair's precision, or six significant digits when only the reverse pair can be quoted
+// (ex: USD in XAU), and results to the minor units of the currency converted into
+func (s *Store) Convert(from, to string, amount float64) (*Conversion, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	target := pairs.LookupCurrency(to)
+	if pairs.LookupCurrency(from) == nil || target == nil {
+		return nil, ErrUnknownCurrency
+	}
+
+	c := &Conversion{From: from, To: to, Amount: amount, Rate: 1}
+	if from != to {
+		if p, err := pairs.New(from + to); err == nil {
+			if c.Quote = s.Cross(p.Symbol); c.Quote != nil {
+				c.Rate = round(c.Quote.Mid, p.Precision)
+			}
+		} else if c.Quote = s.Cross(to + from); c.Quote != nil {
+			c.Rate = roundSignificant(1/c.Quote.Mid, 6)
+		}
+		if c.Quote == nil {
+			return nil, ErrNoQuote
+		}
+	}
+
+	places := conversionPlaces
+	if target.MinorUnits != nil {
+		places = *target.MinorUnits
+	}
+	c.Result = round(amount*c.Rate, places)
+
+	return c, nil
+}
+
+// roundSignificant rounds a number to a number of significant digits
+func roundSignificant(n float64, digits int) float64 {
+	if n == 0 {
+		return 0
+	}
+
+	return round(n, digits-1-int(math.Floor(math.Log10(math.Abs(n)))))
+}
+
+// roundDown rounds a number down to a number of decimal places
+func roundDown(n float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+
+	return math.Floor(n*p+roundingTolerance) / p
+}
+
+// roundUp rounds a number up to a number of decimal places
+func roundUp(n float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+
+	return math.Ceil(n*p-roundingTolerance) / p
+}
@@ -0,0 +1,152 @@
+// Tests the cross.go file
+package quotes
+
+import (
+	// Standard lib
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/provider"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cross.go", func() {
+	var (
+		s   *Store
+		now time.Time
+	)
+
+	// put stores a quote of a pair made a number of seconds ago
+	put := func(pair string, bid, ask float64, age int) {
+		t := now.Add(-time.Duration(age) * time.Second)
+		s.put(&provider.Tick{Pair: pair, Bid: bid, Ask: ask, Time: t, Source: "replay"}, t, false)
+	}
+
+	BeforeEach(func() {
+		s = NewStore(nil)
+		now = time.Now().UTC()
+		put("EURUSD", 1.08, 1.0802, 0)
+		put("USDJPY", 150.00, 150.02, 0)
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Quotes.Pivots = "USD"
+	})
+
+	It("Derives quotes through a pivot currency, combining spreads", func() {
+		q := s.Cross("eur/jpy")
+
+		// Verify output
+		Expect(q).To(Not(BeNil()))
+		Expect(q.Pair).To(Equal("EURJPY"))
+		Expect(q.Bid).To(Equal(162.0))
+		Expect(q.Ask).To(Equal(162.052))
+		Expect(q.Spread).To(Equal(5.2))
+		Expect(q.Source).To(Equal(SourceCross))
+		Expect(q.Path).To(Equal([]string{"EUR", "USD", "JPY"}))
+		Expect(q.Legs).To(Equal([]string{"EURUSD", "USDJPY"}))
+	})
+
+	It("Rounds derived bids down and asks up", func() {
+		put("EURUSD", 1.0803, 1.0806, 0)
+		put("USDJPY", 150.01, 150.03, 0)
+
+		// Verify output
+		q := s.Cross("EURJPY")
+		Expect(q.Bid).To(Equal(162.055))
+		Expect(q.Ask).To(Equal(162.123))
+		Expect(q.Spread).To(Equal(6.8))
+	})
+
+	It("Derives quotes by inverting the reverse pair", func() {
+		q := s.Cross("USDEUR")
+
+		// Verify output
+		Expect(q.Bid).To(Equal(0.92575))
+		Expect(q.Ask).To(Equal(0.92593))
+		Expect(q.Path).To(Equal([]string{"USD", "EUR"}))
+		Expect(q.Legs).To(Equal([]string{"EURUSD"}))
+	})
+
+	It("Returns quoted pairs as they are", func() {
+		q := s.Cross("EURUSD")
+
+		// Verify output
+		Expect(q.Source).To(Equal("replay"))
+		Expect(q.Path).To(BeEmpty())
+		Expect(s.Cross("GBPCHF")).To(BeNil())
+		Expect(s.Cross("EURXYZ")).To(BeNil())
+	})
+
+	It("Uses the path whose oldest quote is the most recent", func() {
+		config.GetInstance().Quotes.Pivots = "USD,EUR"
+		put("GBPUSD", 1.26, 1.2602, 20)
+		put("EURGBP", 0.855, 0.8552, 0)
+		put("EURJPY", 162.0, 162.03, 5)
+
+		// Verify output
+		q := s.Cross("GBPJPY")
+		Expect(q.Path).To(Equal([]string{"GBP", "EUR", "JPY"}))
+		Expect(q.Legs).To(Equal([]string{"EURGBP", "EURJPY"}))
+		Expect(q.Age).To(BeNumerically("~", 5, 1))
+
+		put("GBPUSD", 1.26, 1.2602, 0)
+		q = s.Cross("GBPJPY")
+		Expect(q.Path).To(Equal([]string{"GBP", "USD", "JPY"}))
+
+		config.GetInstance().Quotes.Pivots = ""
+		Expect(s.Cross("GBPJPY")).To(BeNil())
+	})
+
+	It("Flags derived quotes as stale when any quote is", func() {
+		s = NewStore(nil)
+		put("EURUSD", 1.08, 1.0802, 0)
+		put("USDJPY", 150.00, 150.02, 60)
+
+		// Verify output
+		q := s.Cross("EURJPY")
+		Expect(q.Stale).To(BeTrue())
+		Expect(q.StaleReasons).To(Equal([]string{StaleReasonAge}))
+	})
+
+	It("Lists quotes of a set of pairs, deriving those that aren't quoted", func() {
+		list := s.CrossList([]string{"USDJPY", "EURJPY", "GBPCHF", "EURUSD"})
+
+		// Verify output
+		Expect(list).To(HaveLen(3))
+		Expect(list[0].Pair).To(Equal("EURJPY"))
+		Expect(list[2].Pair).To(Equal("USDJPY"))
+	})
+
+	It("Converts amounts, rounding to the pair's precision and the currency's minor units", func() {
+		put("XAUUSD", 2300, 2301, 0)
+
+		c, err := s.Convert("eur", "JPY", 100)
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Verify output
+		Expect(c.Rate).To(Equal(162.026))
+		Expect(c.Result).To(Equal(16203.0))
+		Expect(c.Quote.Pair).To(Equal("EURJPY"))
+
+		c, _ = s.Convert("USD", "XAU", 1000)
+		Expect(c.Rate).To(Equal(0.000434688))
+		Expect(c.Result).To(Equal(0.434688))
+		Expect(c.Quote.Pair).To(Equal("XAUUSD"))
+
+		c, _ = s.Convert("EUR", "EUR", 12.5)
+		Expect(c.Rate).To(Equal(1.0))
+		Expect(c.Result).To(Equal(12.5))
+		Expect(c.Quote).To(BeNil())
+
+		// Verify errors
+		_, err = s.Convert("EUR", "XYZ", 1)
+		Expect(err).To(Equal(ErrUnknownCurrency))
+		_, err = s.Convert("GBP", "CHF", 1)
+		Expect(err).To(Equal(ErrNoQuote))
+	})
+})
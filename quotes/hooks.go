@@ -0,0 +1,52 @@
+// quotes package contains the snapshot of the latest FX quote of each pair
+// hooks contains the hook persisting quotes within the database
+package quotes
+
+import (
+	// Internal
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/provider"
+	"github.com/deezone/forex-clock/sessions"
+)
+
+type (
+	// DBHook is a struct representing a hook saving the latest quote of each pair within the database
+	DBHook struct {
+		store db.QuoteStore
+	}
+)
+
+// NewDBHook creates and returns a new hook saving quotes to a store
+func NewDBHook(store db.QuoteStore) *DBHook { return &DBHook{store: store} }
+
+// Save replaces the stored quote of the quote's pair
+func (h *DBHook) Save(q *Quote) error {
+	return h.store.Save(&db.Quote{
+		Pair:       q.Pair,
+		Bid:        q.Bid,
+		Ask:        q.Ask,
+		Source:     q.Source,
+		QuotedAt:   q.Time.UTC(),
+		ReceivedAt: q.ReceivedAt.UTC(),
+	})
+}
+
+// Restore adds every stored quote to a store, returning the number of quotes restored
+// NOTE: Quotes are added without being passed to hooks, and are kept only if they're the latest of their pair
+func (h *DBHook) Restore(s *Store) (int, error) {
+	stored, err := h.store.List()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, q := range stored {
+		t := &provider.Tick{Pair: q.Pair, Bid: q.Bid, Ask: q.Ask, Time: q.QuotedAt.UTC(), Source: q.Source}
+		received := q.ReceivedAt.UTC()
+		if s.put(t, received, !sessions.GetInstance().MarketOpen(received)) {
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,270 @@
+// quotes package contains the snapshot of the latest FX quote of each pair. Ticks are ingested from the configured
+// provider into an in-memory store safe for concurrent use, which derives each quote's mid price and spread in pips,
+// and flags quotes as stale when they're older than their pair's threshold, or arrived while the market was closed.
+// Quotes of pairs that aren't quoted are derived from quotes of other pairs through pivot currencies. Hooks receive
+// every quote stored, such as to persist quotes within the database
+package quotes
+
+import (
+	// Standard lib
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/provider"
+	"github.com/deezone/forex-clock/sessions"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Reasons quotes are stale
+	StaleReasonAge          = "age"           // Older than the pair's threshold
+	StaleReasonMarketClosed = "market-closed" // Arrived while the market was closed for the week
+
+	// Pip size and precision of pairs without reference data
+	defaultPipSize   = 0.0001
+	defaultPrecision = 5
+
+	// Point size (used as the pip size of spreads) and precision of indices (ex: VIX)
+	indexPointSize = 0.01
+	indexPrecision = 2
+)
+
+type (
+	// Quote is a struct representing the latest quote of a single currency pair
+	Quote struct {
+		Pair         string    `json:"pair"`
+		Bid          float64   `json:"bid"`
+		Ask          float64   `json:"ask"`
+		Mid          float64   `json:"mid"`
+		Spread       float64   `json:"spread"`      // Difference between the ask and bid, in pips
+		Time         time.Time `json:"time"`        // When the quote was made
+		ReceivedAt   time.Time `json:"received-at"` // When the quote was received
+		Age          float64   `json:"age"`         // Seconds since the quote was made
+		Source       string    `json:"source"`      // Provider the quote was received from
+		Stale        bool      `json:"stale"`
+		StaleReasons []string  `json:"stale-reasons,omitempty"`
+		Path         []string  `json:"path,omitempty"` // Currencies a derived quote passes through (ex: EUR, USD, JPY)
+		Legs         []string  `json:"legs,omitempty"` // Pairs a derived quote is derived from
+	}
+	// Hook is an interface that receivers of every quote stored must fulfill
+	Hook interface {
+		// Save is called with every quote stored, from the goroutine ingesting ticks
+		Save(q *Quote) error
+	}
+	// Store is a struct representing the latest quote of each pair, ingested from a provider
+	Store struct {
+		provider provider.Provider // Source of ticks, nil when market data is disabled
+		hooks    []Hook
+		mutex    sync.RWMutex
+		latest   map[string]*entry
+		cancel   context.CancelFunc // Cancels the subscription, nil when not running
+		done     chan struct{}      // Closed once ingestion stops
+	}
+	// Interface that hooks able to restore quotes saved before starting fulfill
+	restorer interface {
+		Restore(s *Store) (int, error)
+	}
+	// Struct representing a stored tick
+	entry struct {
+		tick       provider.Tick
+		receivedAt time.Time
+		closed     bool // Whether the market was closed when the tick was received
+	}
+)
+
+// NewStore creates and returns a new, empty store of quotes ingested from a provider
+func NewStore(p provider.Provider, hooks ...Hook) *Store {
+	return &Store{provider: p, hooks: hooks, latest: map[string]*entry{}}
+}
+
+// StaleAfter returns the age after which quotes of a pair are stale
+func StaleAfter(pair string) time.Duration {
+	c := config.GetInstance().Quotes
+	for _, m := range config.SplitList(c.StaleAfterPairs) {
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 || provider.NormalizePair(parts[0]) != pair {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+
+	return time.Duration(c.StaleAfter) * time.Second
+}
+
+// Start restores quotes saved by hooks before starting, then starts ingesting ticks of every pair from the provider
+// NOTE: Must be called before starting the provider, so its first ticks aren't missed
+func (s *Store) Start() error {
+	for _, h := range s.hooks {
+		if r, ok := h.(restorer); ok {
+			n, err := r.Restore(s)
+			if err != nil {
+				log.WithError(err).Error("Error restoring FX quotes")
+				continue
+			}
+			log.WithField("quotes", n).Info("Restored FX quotes")
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.provider == nil || s.cancel != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks, err := s.provider.Subscribe(ctx, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	s.cancel, s.done = cancel, make(chan struct{})
+	go s.run(ticks, s.done)
+
+	return nil
+}
+
+// Close stops ingesting ticks, keeping the latest quotes
+func (s *Store) Close() {
+	s.mutex.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mutex.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Put stores a tick as the latest quote of its pair, unless a later quote is already stored, then passes the quote
+// to every hook
+func (s *Store) Put(t *provider.Tick) {
+	now := time.Now().UTC()
+	if !s.put(t, now, !sessions.GetInstance().MarketOpen(now)) {
+		return
+	}
+
+	q := s.Get(t.Pair)
+	for _, h := range s.hooks {
+		if err := h.Save(q); err != nil {
+			log.WithError(err).WithField("pair", t.Pair).Warn("Error saving FX quote")
+		}
+	}
+}
+
+// Get returns the latest quote of a pair, or nil if none has been received
+func (s *Store) Get(pair string) *Quote {
+	s.mutex.RLock()
+	e, ok := s.latest[provider.NormalizePair(pair)]
+	s.mutex.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	return e.quote(time.Now())
+}
+
+// List returns the latest quote of a set of pairs (all pairs if none are given), sorted by pair
+func (s *Store) List(symbols []string) []*Quote {
+	now := time.Now()
+
+	s.mutex.RLock()
+	entries := make([]*entry, 0, len(s.latest))
+	if len(symbols) == 0 {
+		for _, e := range s.latest {
+			entries = append(entries, e)
+		}
+	} else {
+		for _, pair := range provider.NormalizePairs(symbols) {
+			if e, ok := s.latest[pair]; ok {
+				entries = append(entries, e)
+			}
+		}
+	}
+	s.mutex.RUnlock()
+
+	list := make([]*Quote, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e.quote(now))
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Pair < list[b].Pair })
+
+	return list
+}
+
+// put stores a tick received at a point in time, returning whether it's the latest of its pair
+func (s *Store) put(t *provider.Tick, receivedAt time.Time, closed bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if prev, ok := s.latest[t.Pair]; ok && t.Time.Before(prev.tick.Time) {
+		return false
+	}
+	s.latest[t.Pair] = &entry{tick: *t, receivedAt: receivedAt, closed: closed}
+
+	return true
+}
+
+// run stores received ticks until the subscription is closed
+func (s *Store) run(ticks <-chan *provider.Tick, done chan struct{}) {
+	defer close(done)
+
+	for t := range ticks {
+		s.Put(t)
+	}
+}
+
+// quote returns the quote of a stored tick at a point in time
+func (e *entry) quote(now time.Time) *Quote {
+	pip, precision := defaultPipSize, defaultPrecision
+	if provider.IsIndex(e.tick.Pair) {
+		pip, precision = indexPointSize, indexPrecision
+	} else if p, err := pairs.New(e.tick.Pair); err == nil {
+		pip, precision = p.PipSize, p.Precision
+	}
+
+	q := &Quote{
+		Pair:       e.tick.Pair,
+		Bid:        e.tick.Bid,
+		Ask:        e.tick.Ask,
+		Mid:        round((e.tick.Bid+e.tick.Ask)/2, precision+1),
+		Spread:     round((e.tick.Ask-e.tick.Bid)/pip, 1),
+		Time:       e.tick.Time,
+		ReceivedAt: e.receivedAt,
+		Age:        round(math.Max(0, now.Sub(e.tick.Time).Seconds()), 3),
+		Source:     e.tick.Source,
+	}
+
+	if now.Sub(e.tick.Time) > StaleAfter(q.Pair) {
+		q.StaleReasons = append(q.StaleReasons, StaleReasonAge)
+	}
+	if e.closed {
+		q.StaleReasons = append(q.StaleReasons, StaleReasonMarketClosed)
+	}
+	q.Stale = len(q.StaleReasons) > 0
+
+	return q
+}
+
+// round rounds a number to a number of decimal places
+func round(n float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+
+	return math.Round(n*p) / p
+}
@@ -0,0 +1,224 @@
+// Tests the quotes.go and hooks.go files
+package quotes
+
+import (
+	// Standard lib
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/provider"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing a hook recording quotes, used throughout tests
+	recordingHook struct {
+		mutex  sync.Mutex
+		quotes []*Quote
+	}
+	// Struct representing in-memory latest quote storage, used throughout tests
+	memoryQuoteStore struct {
+		quotes map[string]*db.Quote
+		err    error // Error returned by every method, if set
+	}
+)
+
+func (h *recordingHook) Save(q *Quote) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.quotes = append(h.quotes, q)
+	return nil
+}
+
+func (h *recordingHook) saved() []*Quote {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return append([]*Quote{}, h.quotes...)
+}
+
+func (s *memoryQuoteStore) Save(q *db.Quote) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.quotes[q.Pair] = q
+	return nil
+}
+
+func (s *memoryQuoteStore) List() ([]*db.Quote, error) {
+	list := []*db.Quote{}
+	for _, q := range s.quotes {
+		list = append(list, q)
+	}
+	return list, s.err
+}
+
+var _ = Describe("quotes.go", func() {
+	var (
+		s   *Store
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		s = NewStore(nil)
+		now = time.Now().UTC()
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Quotes.StaleAfter = 30
+		config.GetInstance().Quotes.StaleAfterPairs = ""
+	})
+
+	It("Derives the mid price and spread in pips of quotes", func() {
+		s.put(&provider.Tick{Pair: "EURUSD", Bid: 1.08001, Ask: 1.08013, Time: now, Source: "replay"}, now, false)
+		s.put(&provider.Tick{Pair: "USDJPY", Bid: 151.102, Ask: 151.117, Time: now, Source: "replay"}, now, false)
+
+		// Verify output
+		q := s.Get("eur/usd")
+		Expect(q.Pair).To(Equal("EURUSD"))
+		Expect(q.Mid).To(Equal(1.08007))
+		Expect(q.Spread).To(Equal(1.2))
+		Expect(q.Source).To(Equal("replay"))
+		Expect(q.ReceivedAt).To(Equal(now))
+		Expect(q.Stale).To(BeFalse())
+		Expect(q.StaleReasons).To(BeEmpty())
+
+		q = s.Get("USDJPY")
+		Expect(q.Mid).To(Equal(151.1095))
+		Expect(q.Spread).To(Equal(1.5))
+
+		Expect(s.Get("GBPUSD")).To(BeNil())
+	})
+
+	It("Quotes indices in points", func() {
+		s.put(&provider.Tick{Pair: "VIX", Bid: 14.21, Ask: 14.26, Time: now, Source: "replay"}, now, false)
+
+		// Verify output
+		q := s.Cross("vix")
+		Expect(q.Mid).To(Equal(14.235))
+		Expect(q.Spread).To(Equal(5.0))
+	})
+
+	It("Keeps the latest quote of each pair", func() {
+		s.put(&provider.Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now}, now, false)
+		Expect(s.put(&provider.Tick{Pair: "EURUSD", Bid: 1.07, Ask: 1.0701, Time: now.Add(-time.Second)}, now, false)).To(BeFalse())
+
+		// Verify output
+		Expect(s.Get("EURUSD").Bid).To(Equal(1.08))
+	})
+
+	It("Lists the latest quotes of a set of pairs", func() {
+		for _, pair := range []string{"USDJPY", "EURUSD", "GBPUSD"} {
+			s.put(&provider.Tick{Pair: pair, Bid: 1, Ask: 1.0001, Time: now}, now, false)
+		}
+
+		// Verify output
+		list := s.List(nil)
+		Expect(list).To(HaveLen(3))
+		Expect(list[0].Pair).To(Equal("EURUSD"))
+		Expect(list[2].Pair).To(Equal("USDJPY"))
+
+		list = s.List([]string{"usd/jpy", "AUDUSD", "GBPUSD"})
+		Expect(list).To(HaveLen(2))
+		Expect(list[0].Pair).To(Equal("GBPUSD"))
+	})
+
+	It("Flags quotes older than their pair's threshold as stale", func() {
+		config.GetInstance().Quotes.StaleAfterPairs = "usd/try=300, EURUSD=x"
+
+		s.put(&provider.Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now.Add(-time.Minute)}, now, false)
+		s.put(&provider.Tick{Pair: "USDTRY", Bid: 32.1, Ask: 32.15, Time: now.Add(-time.Minute)}, now, false)
+
+		// Verify output
+		Expect(StaleAfter("EURUSD")).To(Equal(30 * time.Second))
+		Expect(StaleAfter("USDTRY")).To(Equal(300 * time.Second))
+
+		q := s.Get("EURUSD")
+		Expect(q.Stale).To(BeTrue())
+		Expect(q.StaleReasons).To(Equal([]string{StaleReasonAge}))
+		Expect(q.Age).To(BeNumerically("~", 60, 1))
+		Expect(s.Get("USDTRY").Stale).To(BeFalse())
+	})
+
+	It("Flags quotes arriving while the market is closed as stale", func() {
+		s.put(&provider.Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now}, now, true)
+
+		// Verify output
+		q := s.Get("EURUSD")
+		Expect(q.Stale).To(BeTrue())
+		Expect(q.StaleReasons).To(Equal([]string{StaleReasonMarketClosed}))
+	})
+
+	It("Ingests ticks from a provider, passing quotes to hooks", func() {
+		dir, _ := ioutil.TempDir("", "quotes")
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "ticks.csv")
+		Expect(ioutil.WriteFile(file, []byte("time,pair,bid,ask\n2024-03-01T12:00:00Z,EURUSD,1.08,1.0801\n2024-03-01T12:00:01Z,USDJPY,150.1,150.12\n"), 0644)).To(Succeed())
+
+		p := provider.NewReplay(config.Provider{Replay: config.ProviderReplay{File: file}})
+		hook := &recordingHook{}
+		s = NewStore(p, hook)
+		Expect(s.Start()).To(Succeed())
+		Expect(p.Start()).To(Succeed())
+
+		// Verify output
+		Eventually(func() []*Quote { return s.List(nil) }).Should(HaveLen(2))
+		Expect(s.Get("USDJPY").Time).To(Equal(time.Date(2024, 3, 1, 12, 0, 1, 0, time.UTC)))
+		Expect(s.Get("USDJPY").Stale).To(BeTrue())
+		Expect(hook.saved()).To(HaveLen(2))
+
+		s.Close()
+		p.Close()
+
+		// Verify quotes are kept once closed
+		Expect(s.List(nil)).To(HaveLen(2))
+	})
+
+	It("Passes only the latest quote of each pair to hooks", func() {
+		hook := &recordingHook{}
+		s = NewStore(nil, hook)
+
+		s.Put(&provider.Tick{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now})
+		s.Put(&provider.Tick{Pair: "EURUSD", Bid: 1.07, Ask: 1.0701, Time: now.Add(-time.Second)})
+
+		// Verify output
+		Expect(hook.saved()).To(HaveLen(1))
+		Expect(hook.saved()[0].Bid).To(Equal(1.08))
+	})
+})
+
+var _ = Describe("hooks.go", func() {
+	It("Saves quotes, and restores them into a store", func() {
+		store := &memoryQuoteStore{quotes: map[string]*db.Quote{}}
+		hook := NewDBHook(store)
+		now := time.Now().UTC()
+
+		Expect(hook.Save(&Quote{Pair: "EURUSD", Bid: 1.08, Ask: 1.0801, Time: now, ReceivedAt: now, Source: "http"})).To(Succeed())
+		Expect(store.quotes["EURUSD"].QuotedAt).To(Equal(now))
+
+		// Verify quotes are restored when the store starts
+		s := NewStore(nil, hook)
+		Expect(s.Start()).To(Succeed())
+		q := s.Get("EURUSD")
+		Expect(q).To(Not(BeNil()))
+		Expect(q.Bid).To(Equal(1.08))
+		Expect(q.Source).To(Equal("http"))
+
+		// Verify errors
+		store.err = errors.New("Unreachable")
+		Expect(hook.Save(q)).To(HaveOccurred())
+		_, err := hook.Restore(s)
+		Expect(err).To(HaveOccurred())
+	})
+})
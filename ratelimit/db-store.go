@@ -0,0 +1,184 @@
+// ratelimit package contains per-client rate limiting and daily request quotas
+// db-store contains a store keeping limiter state within the database, sharing limits between replicas
+package ratelimit
+
+import (
+	// Standard lib
+	"database/sql"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/db"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Bucket queries
+	// NOTE: Rows are created empty, then locked, so concurrent requests for a new key are serialized
+	insertBucketQuery  = "INSERT INTO rate_limit_buckets (bucket_key, tokens, full_at) VALUES (?, 0, ?) ON CONFLICT (bucket_key) DO NOTHING"
+	selectBucketQuery  = "SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = ? FOR UPDATE"
+	updateBucketQuery  = "UPDATE rate_limit_buckets SET tokens = ?, updated_at = ?, full_at = ? WHERE bucket_key = ?"
+	deleteBucketsQuery = "DELETE FROM rate_limit_buckets WHERE full_at <= ?"
+
+	// Counter queries
+	// NOTE: Expired counters are restarted in place
+	incrCounterQuery = `INSERT INTO rate_limit_counters (counter_key, value, expires_at) VALUES (?, 1, ?)
+		ON CONFLICT (counter_key) DO UPDATE SET
+			value = CASE WHEN rate_limit_counters.expires_at <= ? THEN 1 ELSE rate_limit_counters.value + 1 END,
+			expires_at = CASE WHEN rate_limit_counters.expires_at <= ? THEN EXCLUDED.expires_at ELSE rate_limit_counters.expires_at END
+		RETURNING value`
+	selectCounterQuery  = "SELECT value FROM rate_limit_counters WHERE counter_key = ? AND expires_at > ?"
+	deleteCountersQuery = "DELETE FROM rate_limit_counters WHERE expires_at <= ?"
+
+	// MySQL variants of the upsert queries, which have no `ON CONFLICT` or `RETURNING` clauses
+	// NOTE: Assignments are made in order, so the value is set before the expiry it compares against changes
+	mysqlInsertBucketQuery = "INSERT IGNORE INTO rate_limit_buckets (bucket_key, tokens, full_at) VALUES (?, 0, ?)"
+	mysqlIncrCounterQuery  = `INSERT INTO rate_limit_counters (counter_key, value, expires_at) VALUES (?, 1, ?)
+		ON DUPLICATE KEY UPDATE
+			value = IF(expires_at <= ?, 1, value + 1),
+			expires_at = IF(expires_at <= ?, VALUES(expires_at), expires_at)`
+	mysqlSelectCounterQuery = "SELECT value FROM rate_limit_counters WHERE counter_key = ?"
+
+	// Name of the MySQL driver
+	mysqlDriver = "mysql"
+)
+
+type (
+	// DBStore is a struct representing a store keeping limiter state within the database
+	DBStore struct {
+		db    db.DB
+		mutex sync.Mutex
+		swept time.Time // When expired state was last removed
+	}
+)
+
+// NewDBStore creates and returns a new instance of a database-backed store
+func NewDBStore(d db.DB) *DBStore { return &DBStore{db: d} }
+
+// Take removes a token from a bucket, refilling it for the time since it was last used
+func (s *DBStore) Take(key string, l Limit, now time.Time) (*Result, error) {
+	i := s.db.GetInstance()
+	if i == nil {
+		return nil, db.ErrNilInstance
+	}
+	// NOTE: Times are stored as UTC, as columns don't include a time zone
+	now = now.UTC()
+	s.sweep(now)
+
+	tx, err := i.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	insert := insertBucketQuery
+	if i.DriverName() == mysqlDriver {
+		insert = mysqlInsertBucketQuery
+	}
+	if _, err := tx.Exec(tx.Rebind(insert), key, now); err != nil {
+		return nil, err
+	}
+
+	var tokens float64
+	var updated sql.NullTime
+	if err := tx.QueryRowx(tx.Rebind(selectBucketQuery), key).Scan(&tokens, &updated); err != nil {
+		return nil, err
+	}
+
+	tokens, r := take(tokens, updated.Time, l, now)
+	if _, err := tx.Exec(tx.Rebind(updateBucketQuery), tokens, now, now.Add(r.Reset), key); err != nil {
+		return nil, err
+	}
+
+	return r, tx.Commit()
+}
+
+// Incr increments a counter expiring at a point in time, returning its new value
+func (s *DBStore) Incr(key string, expires time.Time, now time.Time) (int64, error) {
+	i := s.db.GetInstance()
+	if i == nil {
+		return 0, db.ErrNilInstance
+	}
+	now, expires = now.UTC(), expires.UTC()
+	s.sweep(now)
+
+	var value int64
+	if i.DriverName() != mysqlDriver {
+		err := i.QueryRowx(i.Rebind(incrCounterQuery), key, expires, now, now).Scan(&value)
+		return value, err
+	}
+
+	// The counter's row stays locked until the transaction ends, so the value read is the one just set
+	tx, err := i.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(mysqlIncrCounterQuery, key, expires, now, now); err != nil {
+		return 0, err
+	}
+	if err := tx.QueryRowx(mysqlSelectCounterQuery, key).Scan(&value); err != nil {
+		return 0, err
+	}
+
+	return value, tx.Commit()
+}
+
+// Count returns the value of a counter, 0 if it doesn't exist or has expired
+func (s *DBStore) Count(key string, now time.Time) (int64, error) {
+	i := s.db.GetInstance()
+	if i == nil {
+		return 0, db.ErrNilInstance
+	}
+
+	var value int64
+	err := i.QueryRowx(i.Rebind(selectCounterQuery), key, now.UTC()).Scan(&value)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return value, err
+}
+
+// sweep periodically removes full buckets and expired counters, which are equivalent to missing ones
+func (s *DBStore) sweep(now time.Time) {
+	s.mutex.Lock()
+	if now.Sub(s.swept) < sweepInterval {
+		s.mutex.Unlock()
+		return
+	}
+	s.swept = now
+	s.mutex.Unlock()
+
+	i := s.db.GetInstance()
+	for _, q := range []string{deleteBucketsQuery, deleteCountersQuery} {
+		if _, err := i.Exec(i.Rebind(q), now); err != nil {
+			log.WithError(err).Warn("Error removing expired rate limit state")
+		}
+	}
+}
+
+func init() {
+	db.RegisterMigration(&db.Migration{
+		ID:   2,
+		Name: "create rate limit tables",
+		Up: []string{
+			`CREATE TABLE rate_limit_buckets (
+				bucket_key VARCHAR(512) NOT NULL PRIMARY KEY,
+				tokens DOUBLE PRECISION NOT NULL,
+				updated_at TIMESTAMP NULL,
+				full_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE rate_limit_counters (
+				counter_key VARCHAR(512) NOT NULL PRIMARY KEY,
+				value BIGINT NOT NULL,
+				expires_at TIMESTAMP NOT NULL
+			)`,
+		},
+		Down: []string{"DROP TABLE rate_limit_counters", "DROP TABLE rate_limit_buckets"},
+	})
+}
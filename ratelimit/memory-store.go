@@ -0,0 +1,109 @@
+// ratelimit package contains per-client rate limiting and daily request quotas
+// memory-store contains a store keeping limiter state in memory, limiting each replica separately
+package ratelimit
+
+import (
+	// Standard lib
+	"sync"
+	"time"
+)
+
+const (
+	// How often expired buckets and counters are removed
+	sweepInterval = time.Minute
+)
+
+type (
+	// MemoryStore is a struct representing a store keeping limiter state in memory
+	MemoryStore struct {
+		mutex    sync.Mutex
+		buckets  map[string]*bucket
+		counters map[string]*counter
+		swept    time.Time // When expired state was last removed
+	}
+	// Struct representing the state of a single token bucket
+	bucket struct {
+		tokens  float64
+		updated time.Time
+		full    time.Time // When the bucket will be full again, after which it can be removed
+	}
+	// Struct representing a single counter
+	counter struct {
+		value   int64
+		expires time.Time
+	}
+)
+
+// NewMemoryStore creates and returns a new instance of an in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, counters: map[string]*counter{}}
+}
+
+// Take removes a token from a bucket, refilling it for the time since it was last used
+func (s *MemoryStore) Take(key string, l Limit, now time.Time) (*Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{}
+		s.buckets[key] = b
+	}
+
+	tokens, r := take(b.tokens, b.updated, l, now)
+	b.tokens, b.updated, b.full = tokens, now, now.Add(r.Reset)
+
+	return r, nil
+}
+
+// Incr increments a counter expiring at a point in time, returning its new value
+func (s *MemoryStore) Incr(key string, expires time.Time, now time.Time) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sweep(now)
+
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expires) {
+		c = &counter{expires: expires}
+		s.counters[key] = c
+	}
+	c.value++
+
+	return c.value, nil
+}
+
+// Count returns the value of a counter, 0 if it doesn't exist or has expired
+func (s *MemoryStore) Count(key string, now time.Time) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expires) {
+		return 0, nil
+	}
+
+	return c.value, nil
+}
+
+// sweep periodically removes full buckets and expired counters, which are equivalent to missing ones
+// NOTE: Must be called while holding the mutex
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < sweepInterval {
+		return
+	}
+	s.swept = now
+
+	for k, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, k)
+		}
+	}
+	for k, c := range s.counters {
+		if !now.Before(c.expires) {
+			delete(s.counters, k)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// ratelimit package contains per-client rate limiting with token buckets, and daily request quotas.
+// Limiter state is kept in a store, either in memory or shared between replicas (database or Redis-compatible)
+package ratelimit
+
+import (
+	// Standard lib
+	"fmt"
+	"math"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+)
+
+const (
+	// Supported stores
+	StoreMemory = "memory"
+	StoreDB     = "db"
+	StoreRedis  = "redis"
+
+	// Prefix of daily quota counter keys
+	quotaKeyPrefix = "quota:"
+	// Prefix of failed authentication counter keys
+	failureKeyPrefix = "auth-failures:"
+)
+
+type (
+	// Store is an interface that all limiter state stores must fulfill
+	Store interface {
+		// Take removes a token from a bucket, refilling it for the time since it was last used
+		Take(key string, l Limit, now time.Time) (*Result, error)
+		// Incr increments a counter expiring at a point in time, returning its new value
+		Incr(key string, expires time.Time, now time.Time) (int64, error)
+		// Count returns the value of a counter, 0 if it doesn't exist or has expired
+		Count(key string, now time.Time) (int64, error)
+	}
+	// Limit is a struct representing the size and refill rate of a token bucket
+	Limit struct {
+		Rate  float64 // Tokens added per second
+		Burst int     // Capacity of the bucket
+	}
+	// Result is a struct representing the outcome of taking a token from a bucket
+	Result struct {
+		Allowed    bool          // Whether a token was taken
+		Limit      int           // Capacity of the bucket
+		Remaining  int           // Whole tokens remaining
+		Reset      time.Duration // Time until the bucket is full again
+		RetryAfter time.Duration // Time until a token is available, when not allowed
+	}
+	// QuotaStatus is a struct representing the use of a daily quota
+	QuotaStatus struct {
+		Limit     int       `json:"limit"`
+		Used      int64     `json:"used"`
+		Remaining int       `json:"remaining"`
+		Reset     time.Time `json:"reset"`
+	}
+	// Limiter is a struct representing a rate limiter, reading limits from configuration for every request
+	// so they can be reloaded
+	Limiter struct {
+		store Store
+	}
+)
+
+// NewStore creates and returns the store configured within configuration settings
+func NewStore(c config.RateLimit, d db.DB) (Store, error) {
+	switch c.Store {
+	case StoreMemory:
+		return NewMemoryStore(), nil
+	case StoreDB:
+		return NewDBStore(d), nil
+	case StoreRedis:
+		return NewRedisStore(c.Redis), nil
+	}
+
+	return nil, fmt.Errorf("Unknown rate limit store: %s", c.Store)
+}
+
+// NewLimiter creates and returns a new instance of a limiter backed by a store
+func NewLimiter(store Store) *Limiter { return &Limiter{store: store} }
+
+// ConfiguredLimit returns the limit within configuration settings
+func ConfiguredLimit(c config.RateLimit) Limit {
+	return Limit{Rate: float64(c.Rate) / 60, Burst: c.Burst}
+}
+
+// Allow takes a token from the bucket of a key, using the configured limit
+func (l *Limiter) Allow(key string, now time.Time) (*Result, error) {
+	return l.store.Take(key, ConfiguredLimit(config.GetInstance().RateLimit), now)
+}
+
+// UseQuota counts a request against the daily quota of an API key, returning the quota's status
+// NOTE: Returns nil if no quota is configured
+func (l *Limiter) UseQuota(id string, now time.Time) (*QuotaStatus, error) {
+	limit := config.GetInstance().RateLimit.DailyQuota
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	reset := nextDay(now)
+	used, err := l.store.Incr(quotaKey(id, now), reset, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return newQuotaStatus(limit, used, reset), nil
+}
+
+// Quota returns the status of the daily quota of an API key, without counting a request
+// NOTE: Returns nil if no quota is configured
+func (l *Limiter) Quota(id string, now time.Time) (*QuotaStatus, error) {
+	limit := config.GetInstance().RateLimit.DailyQuota
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	used, err := l.store.Count(quotaKey(id, now), now)
+	if err != nil {
+		return nil, err
+	}
+
+	return newQuotaStatus(limit, used, nextDay(now)), nil
+}
+
+// AuthBlocked returns a boolean indicating if a client IP has failed authentication as many times within the
+// current minute as allowed, and the time until the minute ends
+func (l *Limiter) AuthBlocked(ip string, now time.Time) (bool, time.Duration, error) {
+	limit := config.GetInstance().RateLimit.AuthFailures
+	if limit <= 0 {
+		return false, 0, nil
+	}
+
+	failures, err := l.store.Count(failureKey(ip, now), now)
+	if err != nil {
+		return false, 0, err
+	}
+
+	return failures >= int64(limit), nextMinute(now).Sub(now), nil
+}
+
+// FailAuth counts a failed authentication against a client IP
+func (l *Limiter) FailAuth(ip string, now time.Time) error {
+	if config.GetInstance().RateLimit.AuthFailures <= 0 {
+		return nil
+	}
+
+	_, err := l.store.Incr(failureKey(ip, now), nextMinute(now), now)
+
+	return err
+}
+
+// Exceeded returns a boolean indicating if more requests were made than the quota allows
+func (q *QuotaStatus) Exceeded() bool { return q.Used > int64(q.Limit) }
+
+// take applies a request to a token bucket's state, returning its new number of tokens and the result
+// NOTE: Shared by stores that keep bucket state themselves
+func take(tokens float64, updated time.Time, l Limit, now time.Time) (float64, *Result) {
+	burst := float64(l.Burst)
+
+	// Refill for the time elapsed, a new bucket starts full
+	if updated.IsZero() {
+		tokens = burst
+	} else if elapsed := now.Sub(updated).Seconds(); elapsed > 0 {
+		tokens = math.Min(burst, tokens+elapsed*l.Rate)
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	return tokens, newResult(tokens, allowed, l)
+}
+
+// newResult creates and returns the result of taking a token from a bucket, given its remaining tokens
+func newResult(tokens float64, allowed bool, l Limit) *Result {
+	r := &Result{
+		Allowed:   allowed,
+		Limit:     l.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(l.Burst) - tokens) / l.Rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / l.Rate)
+	}
+
+	return r
+}
+
+// newQuotaStatus creates and returns a new quota status
+func newQuotaStatus(limit int, used int64, reset time.Time) *QuotaStatus {
+	remaining := int64(limit) - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &QuotaStatus{Limit: limit, Used: used, Remaining: int(remaining), Reset: reset}
+}
+
+// quotaKey returns the key of the daily quota counter of an API key
+func quotaKey(id string, now time.Time) string {
+	return quotaKeyPrefix + id + ":" + now.UTC().Format("2006-01-02")
+}
+
+// failureKey returns the key of the failed authentication counter of a client IP
+func failureKey(ip string, now time.Time) string {
+	return failureKeyPrefix + ip + ":" + now.UTC().Format("2006-01-02T15:04")
+}
+
+// nextMinute returns the start of the next minute
+func nextMinute(now time.Time) time.Time { return now.UTC().Truncate(time.Minute).Add(time.Minute) }
+
+// nextDay returns the start of the next UTC day
+func nextDay(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// seconds converts a number of seconds into a duration
+func seconds(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
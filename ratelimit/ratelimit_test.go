@@ -0,0 +1,338 @@
+// Tests the ratelimit.go, memory-store.go, redis-store.go, and db-store.go files
+package ratelimit
+
+import (
+	// Standard lib
+	"errors"
+	"regexp"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db/dbtest"
+
+	// Third-party
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// storeBehavior describes the behavior every store must have
+func storeBehavior(newStore func() Store) {
+	var (
+		// Store to test
+		s Store
+		// Limit of 1 token per second, bursting to 3
+		l = Limit{Rate: 1, Burst: 3}
+		// Start time of tests
+		start = time.Date(2024, 6, 5, 13, 30, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		s = newStore()
+	})
+
+	It("Allows bursts, then refills at the limit's rate", func() {
+		for i := 2; i >= 0; i-- {
+			r, err := s.Take("client", l, start)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(r.Allowed).To(BeTrue())
+			Expect(r.Remaining).To(Equal(i))
+			Expect(r.Limit).To(Equal(3))
+		}
+
+		r, err := s.Take("client", l, start)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(r.Allowed).To(BeFalse())
+		Expect(r.RetryAfter).To(Equal(time.Second))
+		Expect(r.Reset).To(Equal(3 * time.Second))
+
+		// Other keys have their own buckets
+		r, _ = s.Take("other", l, start)
+		Expect(r.Allowed).To(BeTrue())
+
+		// Half a second only refills half a token
+		r, _ = s.Take("client", l, start.Add(500*time.Millisecond))
+		Expect(r.Allowed).To(BeFalse())
+
+		r, _ = s.Take("client", l, start.Add(1500*time.Millisecond))
+		Expect(r.Allowed).To(BeTrue())
+		Expect(r.Remaining).To(Equal(0))
+	})
+
+	It("Counts until a counter expires", func() {
+		expires := start.Add(time.Hour)
+		for i := int64(1); i <= 3; i++ {
+			v, err := s.Incr("counter", expires, start)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(v).To(Equal(i))
+		}
+
+		v, err := s.Count("counter", start)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(v).To(Equal(int64(3)))
+
+		v, _ = s.Count("missing", start)
+		Expect(v).To(Equal(int64(0)))
+	})
+}
+
+var _ = Describe("ratelimit.go", func() {
+	Describe("`take` method", func() {
+		It("Never refills a bucket above its capacity", func() {
+			l := Limit{Rate: 10, Burst: 5}
+			now := time.Now()
+
+			tokens, r := take(0, now.Add(-time.Hour), l, now)
+			Expect(tokens).To(Equal(4.0))
+			Expect(r.Allowed).To(BeTrue())
+			Expect(r.Reset).To(Equal(100 * time.Millisecond))
+		})
+	})
+
+	Describe("`Limiter` struct", func() {
+		var (
+			// Limiter to test
+			l *Limiter
+			// Time of requests
+			now = time.Date(2024, 6, 5, 23, 0, 0, 0, time.UTC)
+			// Original failed authentication limit
+			failures = config.GetInstance().RateLimit.AuthFailures
+		)
+
+		BeforeEach(func() {
+			l = NewLimiter(NewMemoryStore())
+		})
+
+		AfterEach(func() {
+			config.GetInstance().RateLimit.DailyQuota = 0
+			config.GetInstance().RateLimit.AuthFailures = failures
+		})
+
+		It("Reads the limit from configuration", func() {
+			config.GetInstance().RateLimit.Burst = 1
+
+			r, _ := l.Allow("client", now)
+			Expect(r.Allowed).To(BeTrue())
+			r, _ = l.Allow("client", now)
+			Expect(r.Allowed).To(BeFalse())
+		})
+
+		It("Tracks daily quotas per key, resetting at midnight UTC", func() {
+			q, err := l.UseQuota("key-1", now)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(q).To(BeNil())
+
+			config.GetInstance().RateLimit.DailyQuota = 2
+			for i := 0; i < 3; i++ {
+				q, _ = l.UseQuota("key-1", now)
+			}
+			Expect(q.Used).To(Equal(int64(3)))
+			Expect(q.Remaining).To(Equal(0))
+			Expect(q.Exceeded()).To(BeTrue())
+			Expect(q.Reset).To(Equal(time.Date(2024, 6, 6, 0, 0, 0, 0, time.UTC)))
+
+			q, _ = l.Quota("key-2", now)
+			Expect(q.Used).To(Equal(int64(0)))
+
+			q, _ = l.Quota("key-1", now.Add(2*time.Hour))
+			Expect(q.Used).To(Equal(int64(0)))
+			Expect(q.Exceeded()).To(BeFalse())
+		})
+
+		It("Blocks client IPs once they've failed authentication too many times within a minute", func() {
+			config.GetInstance().RateLimit.AuthFailures = 2
+			at := now.Add(30 * time.Second)
+			for i := 0; i < 2; i++ {
+				blocked, _, err := l.AuthBlocked("10.0.0.1", at)
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(blocked).To(BeFalse())
+				Expect(l.FailAuth("10.0.0.1", at)).To(Succeed())
+			}
+
+			// Verify output
+			blocked, retry, _ := l.AuthBlocked("10.0.0.1", at)
+			Expect(blocked).To(BeTrue())
+			Expect(retry).To(Equal(30 * time.Second))
+
+			blocked, _, _ = l.AuthBlocked("10.0.0.2", at)
+			Expect(blocked).To(BeFalse())
+			blocked, _, _ = l.AuthBlocked("10.0.0.1", at.Add(time.Minute))
+			Expect(blocked).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("memory-store.go", func() {
+	storeBehavior(func() Store { return NewMemoryStore() })
+})
+
+var _ = Describe("redis-store.go", func() {
+	var (
+		// In-process Redis-compatible server
+		m *miniredis.Miniredis
+	)
+
+	BeforeEach(func() {
+		var err error
+		m, err = miniredis.Run()
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	AfterEach(func() {
+		m.Close()
+	})
+
+	storeBehavior(func() Store {
+		return NewRedisStore(config.RateLimitRedis{Address: m.Addr(), Prefix: "test:"})
+	})
+
+	It("Prefixes and expires keys", func() {
+		s := NewRedisStore(config.RateLimitRedis{Address: m.Addr(), Prefix: "test:"})
+		s.Take("client", Limit{Rate: 1, Burst: 3}, time.Now())
+
+		Expect(m.Exists("test:client")).To(BeTrue())
+		Expect(m.TTL("test:client")).To(BeNumerically(">", 0))
+	})
+})
+
+var _ = Describe("db-store.go", func() {
+	var (
+		// Mocked database and store to test
+		mock sqlmock.Sqlmock
+		s    *DBStore
+		// Limit of 1 token per second, bursting to 3
+		l = Limit{Rate: 1, Burst: 3}
+		// Start time of tests, when expired state was last removed
+		start = time.Date(2024, 6, 5, 13, 30, 0, 0, time.UTC)
+	)
+
+	// newStore creates a store using a mocked database, whose driver sets the dialect of queries
+	newStore := func(driver string) {
+		conn, m, err := sqlmock.New()
+		Expect(err).To(Not(HaveOccurred()))
+
+		mock = m
+		s = NewDBStore(dbtest.Wrap(sqlx.NewDb(conn, driver)))
+		s.swept = start
+	}
+
+	// query returns a pattern matching a query exactly
+	query := func(q string) string { return "^" + regexp.QuoteMeta(q) + "$" }
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("When using Postgres", func() {
+		BeforeEach(func() {
+			newStore("postgres")
+		})
+
+		It("Takes tokens from a locked bucket, creating it if needed", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(query("INSERT INTO rate_limit_buckets (bucket_key, tokens, full_at) VALUES ($1, 0, $2) ON CONFLICT (bucket_key) DO NOTHING")).
+				WithArgs("client", start).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(query("SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = $1 FOR UPDATE")).
+				WithArgs("client").WillReturnRows(sqlmock.NewRows([]string{"tokens", "updated_at"}).AddRow(0.0, nil))
+			mock.ExpectExec(query("UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2, full_at = $3 WHERE bucket_key = $4")).
+				WithArgs(2.0, start, start.Add(time.Second), "client").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			// Call method
+			r, err := s.Take("client", l, start)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(r.Allowed).To(BeTrue())
+			Expect(r.Remaining).To(Equal(2))
+		})
+
+		It("Increments counters with a single upsert", func() {
+			expires := start.Add(time.Hour)
+			mock.ExpectQuery(`^INSERT INTO rate_limit_counters .+ ON CONFLICT \(counter_key\) DO UPDATE SET .+ RETURNING value$`).
+				WithArgs("counter", expires, start, start).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(4))
+
+			// Call method
+			v, err := s.Incr("counter", expires, start)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(v).To(Equal(int64(4)))
+		})
+	})
+
+	Context("When using MySQL", func() {
+		BeforeEach(func() {
+			newStore("mysql")
+		})
+
+		It("Takes tokens from a locked bucket, creating it if needed", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(query("INSERT IGNORE INTO rate_limit_buckets (bucket_key, tokens, full_at) VALUES (?, 0, ?)")).
+				WithArgs("client", start).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(query("SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = ? FOR UPDATE")).
+				WithArgs("client").WillReturnRows(sqlmock.NewRows([]string{"tokens", "updated_at"}).AddRow(0.5, start.Add(-time.Second)))
+			mock.ExpectExec(query("UPDATE rate_limit_buckets SET tokens = ?, updated_at = ?, full_at = ? WHERE bucket_key = ?")).
+				WithArgs(0.5, start, start.Add(2500*time.Millisecond), "client").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			// Call method
+			r, err := s.Take("client", l, start)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(r.Allowed).To(BeTrue())
+			Expect(r.Remaining).To(Equal(0))
+		})
+
+		It("Increments counters, reading the value within the same transaction", func() {
+			expires := start.Add(time.Hour)
+			mock.ExpectBegin()
+			mock.ExpectExec(`^INSERT INTO rate_limit_counters .+ ON DUPLICATE KEY UPDATE\s+value = IF\(expires_at <= \?, 1, value \+ 1\),\s+expires_at = IF`).
+				WithArgs("counter", expires, start, start).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectQuery(query("SELECT value FROM rate_limit_counters WHERE counter_key = ?")).
+				WithArgs("counter").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(4))
+			mock.ExpectCommit()
+
+			// Call method
+			v, err := s.Incr("counter", expires, start)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(v).To(Equal(int64(4)))
+		})
+
+		It("Rolls back failed increments", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec("^INSERT INTO rate_limit_counters").WillReturnError(errors.New("Deadlock found"))
+			mock.ExpectRollback()
+
+			// Call method
+			_, err := s.Incr("counter", start.Add(time.Hour), start)
+
+			// Verify output
+			Expect(err).To(MatchError("Deadlock found"))
+		})
+	})
+
+	It("Periodically removes full buckets and expired counters", func() {
+		newStore("postgres")
+		later := start.Add(sweepInterval)
+		for _, at := range []time.Time{later.Add(-time.Second), later} {
+			if !at.Before(later) {
+				mock.ExpectExec(query("DELETE FROM rate_limit_buckets WHERE full_at <= $1")).WithArgs(later).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(query("DELETE FROM rate_limit_counters WHERE expires_at <= $1")).WithArgs(later).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectQuery("^INSERT INTO rate_limit_counters").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+
+			// Call method
+			_, err := s.Incr("counter", at.Add(time.Hour), at)
+
+			// Verify output
+			Expect(err).To(Not(HaveOccurred()))
+		}
+	})
+})
@@ -0,0 +1,146 @@
+// ratelimit package contains per-client rate limiting and daily request quotas
+// redis-store contains a store keeping limiter state within a Redis-compatible server, sharing limits between replicas
+package ratelimit
+
+import (
+	// Standard lib
+	"strconv"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// Connection pool settings
+	redisMaxIdle     = 10
+	redisIdleTimeout = 5 * time.Minute
+	redisTimeout     = 2 * time.Second
+)
+
+var (
+	// Script applying a request to a token bucket, stored as a hash of its tokens and last update (in ms)
+	// NOTE: Mirrors `take`, running atomically within the server. The bucket expires once full again
+	takeScript = redis.NewScript(1, `
+		local burst = tonumber(ARGV[1])
+		local rate = tonumber(ARGV[2])
+		local now = tonumber(ARGV[3])
+		local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated")
+		local tokens = tonumber(bucket[1])
+		if tokens == nil then
+			tokens = burst
+		else
+			local elapsed = now - tonumber(bucket[2])
+			if elapsed > 0 then
+				tokens = math.min(burst, tokens + elapsed * rate)
+			end
+		end
+		local allowed = 0
+		if tokens >= 1 then
+			tokens = tokens - 1
+			allowed = 1
+		end
+		redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+		redis.call("PEXPIRE", KEYS[1], math.max(1, math.ceil((burst - tokens) / rate)))
+		return {allowed, tostring(tokens)}
+	`)
+
+	// Script incrementing a counter, setting its time to live (in ms) when it's created
+	incrScript = redis.NewScript(1, `
+		local value = redis.call("INCR", KEYS[1])
+		if value == 1 then
+			redis.call("PEXPIRE", KEYS[1], ARGV[1])
+		end
+		return value
+	`)
+)
+
+type (
+	// RedisStore is a struct representing a store keeping limiter state within a Redis-compatible server
+	RedisStore struct {
+		pool   *redis.Pool
+		prefix string // Prefix of all keys
+	}
+)
+
+// NewRedisStore creates and returns a new instance of a Redis-backed store
+// NOTE: Connections are made when needed, so an unavailable server doesn't prevent start up
+func NewRedisStore(c config.RateLimitRedis) *RedisStore {
+	return &RedisStore{
+		pool: &redis.Pool{
+			MaxIdle:     redisMaxIdle,
+			IdleTimeout: redisIdleTimeout,
+			Dial: func() (redis.Conn, error) {
+				return redis.Dial("tcp", c.Address,
+					redis.DialPassword(c.Password),
+					redis.DialDatabase(c.DB),
+					redis.DialConnectTimeout(redisTimeout),
+					redis.DialReadTimeout(redisTimeout),
+					redis.DialWriteTimeout(redisTimeout),
+				)
+			},
+		},
+		prefix: c.Prefix,
+	}
+}
+
+// Take removes a token from a bucket, refilling it for the time since it was last used
+func (s *RedisStore) Take(key string, l Limit, now time.Time) (*Result, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	// NOTE: Times are in milliseconds, so the rate is converted to tokens per millisecond
+	values, err := redis.Values(takeScript.Do(conn, s.prefix+key, l.Burst, l.Rate/1000, millis(now)))
+	if err != nil {
+		return nil, err
+	}
+
+	var allowed int
+	var tokens string
+	if _, err := redis.Scan(values, &allowed, &tokens); err != nil {
+		return nil, err
+	}
+
+	t, err := strconv.ParseFloat(tokens, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return newResult(t, allowed == 1, l), nil
+}
+
+// Incr increments a counter expiring at a point in time, returning its new value
+func (s *RedisStore) Incr(key string, expires time.Time, now time.Time) (int64, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	// NOTE: Expiry is relative to `now`, so counters don't depend on the server's clock
+	ttl := millis(expires) - millis(now)
+	if ttl < 1 {
+		ttl = 1
+	}
+
+	return redis.Int64(incrScript.Do(conn, s.prefix+key, ttl))
+}
+
+// Count returns the value of a counter, 0 if it doesn't exist or has expired
+func (s *RedisStore) Count(key string, now time.Time) (int64, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	value, err := redis.Int64(conn.Do("GET", s.prefix+key))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+
+	return value, err
+}
+
+// Close closes all connections to the server
+func (s *RedisStore) Close() error { return s.pool.Close() }
+
+// millis returns a time as milliseconds since the Unix epoch
+func millis(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
@@ -0,0 +1,141 @@
+// middleware of the HTTP server
+// The auth middleware authenticates requests and authorizes access to routes based on scopes
+package middleware
+
+import (
+	// Standard Lib
+	"net/http"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/ratelimit"
+
+	// Third Party
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	// Struct representing auth middleware
+	Auth struct {
+		authenticators []auth.Authenticator // Authenticators to try, in order
+		limiter        *ratelimit.Limiter   // Limiter counting failed authentications, nil to not limit them
+		exempt         map[string]bool      // Paths that are never authenticated
+	}
+)
+
+// NewAuth creates and returns a new instance of auth middleware
+// NOTE: Failed authentications aren't limited when the limiter is nil
+func NewAuth(limiter *ratelimit.Limiter, authenticators ...auth.Authenticator) Auth {
+	exempt := map[string]bool{}
+	for _, p := range ExemptPaths {
+		exempt[p] = true
+	}
+
+	return Auth{authenticators: authenticators, limiter: limiter, exempt: exempt}
+}
+
+// Handler handles the processing of the request
+// The auth middleware handler authenticates any credentials within the request, adding the resulting
+// principal to the request context. Requests with invalid credentials are rejected, while requests
+// without credentials continue anonymously and are authorized by `RequireScope`. Requests whose credentials
+// can't be checked (ex: during a database outage) are sent a Service Unavailable response. Failed
+// authentications are counted against the client IP, and once too many fail requests with credentials are
+// rejected before they're checked
+func (m Auth) Handler(next http.Handler) http.Handler {
+	// Middleware handler function
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		if m.exempt[req.URL.Path] {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if auth.HasCredentials(req) && m.blocked(w, req) {
+			return
+		}
+
+		for _, a := range m.authenticators {
+			p, err := a.Authenticate(req)
+			if err == auth.ErrNoCredentials {
+				continue
+			}
+			if err != nil && auth.InvalidCredentials(err) {
+				log.WithError(err).Debug("Rejecting request with invalid credentials")
+				m.fail(req)
+				helpers.Unauthorized(w, req)
+				return
+			}
+			if err != nil {
+				log.WithError(err).Error("Error authenticating request")
+				helpers.ServiceUnavailable(w, req)
+				return
+			}
+
+			// Pass the request through with the principal
+			next.ServeHTTP(w, req.WithContext(auth.NewContext(req.Context(), p)))
+			return
+		}
+
+		// Pass the request through anonymously
+		next.ServeHTTP(w, req)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// blocked rejects a request if its client IP has failed authentication too many times, returning a boolean
+// indicating if it was rejected
+// NOTE: Limiter errors allow the request, so an unavailable store doesn't cause an outage
+func (m Auth) blocked(w http.ResponseWriter, req *http.Request) bool {
+	c := config.GetInstance().RateLimit
+	if m.limiter == nil || !c.Enabled {
+		return false
+	}
+
+	blocked, retry, err := m.limiter.AuthBlocked(clientIP(req, c), time.Now())
+	if err != nil {
+		log.WithError(err).Error("Error checking failed authentications")
+		return false
+	}
+	if !blocked {
+		return false
+	}
+
+	w.Header().Set(RetryAfterHeader, ceilSeconds(retry))
+	helpers.TooManyRequests(w, req)
+
+	return true
+}
+
+// fail counts a failed authentication against the client IP of a request
+func (m Auth) fail(req *http.Request) {
+	c := config.GetInstance().RateLimit
+	if m.limiter == nil || !c.Enabled {
+		return
+	}
+
+	if err := m.limiter.FailAuth(clientIP(req, c), time.Now()); err != nil {
+		log.WithError(err).Error("Error counting failed authentication")
+	}
+}
+
+// RequireScope wraps a handler, only allowing requests from principals granted a scope
+// Anonymous requests are sent an Unauthorized response, principals without the scope a Forbidden response
+func RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		p := auth.FromContext(req.Context())
+		if p == nil {
+			helpers.Unauthorized(w, req)
+			return
+		}
+
+		if !p.HasScope(scope) {
+			helpers.Forbidden(w, req)
+			return
+		}
+
+		next(w, req)
+	}
+}
@@ -0,0 +1,137 @@
+// Tests the auth.go file
+package middleware
+
+import (
+	// Standard lib
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	// Internal
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/ratelimit"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing an authenticator accepting a single static token
+	staticAuthenticator struct {
+		token     string
+		principal *auth.Principal
+	}
+)
+
+func (a staticAuthenticator) Authenticate(req *http.Request) (*auth.Principal, error) {
+	token := req.Header.Get(auth.APIKeyHeader)
+	if token == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	if token == "unavailable" {
+		return nil, errors.New("database is locked")
+	}
+	if token != a.token {
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	return a.principal, nil
+}
+
+var _ = Describe("auth.go", func() {
+	var (
+		// Handler to test
+		h http.Handler
+		// Original configuration
+		original config.RateLimit
+	)
+
+	// serve makes a request with an optional token, returning the recorded response
+	serve := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set(auth.APIKeyHeader, token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		return w
+	}
+
+	BeforeEach(func() {
+		c := config.GetInstance()
+		original = c.RateLimit
+		c.RateLimit.Enabled = true
+		c.RateLimit.AuthFailures = 3
+
+		a := staticAuthenticator{
+			token:     "reader",
+			principal: &auth.Principal{ID: "reader", Type: auth.PrincipalTypeAPIKey, Scopes: []string{auth.ScopeSessionsRead}},
+		}
+		protected := RequireScope(auth.ScopeSessionsRead, func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(auth.FromContext(req.Context()).ID))
+		})
+		admin := RequireScope(auth.ScopeAdmin, func(w http.ResponseWriter, req *http.Request) {})
+
+		m := http.NewServeMux()
+		m.HandleFunc("/health", func(w http.ResponseWriter, req *http.Request) {})
+		m.HandleFunc("/sessions", protected)
+		m.HandleFunc("/admin", admin)
+		h = NewAuth(ratelimit.NewLimiter(ratelimit.NewMemoryStore()), a).Handler(m)
+	})
+
+	AfterEach(func() {
+		config.GetInstance().RateLimit = original
+	})
+
+	It("Passes requests with valid credentials and scopes", func() {
+		w := serve("/sessions", "reader")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("reader"))
+	})
+
+	It("Rejects anonymous requests to protected routes", func() {
+		w := serve("/sessions", "")
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		Expect(w.Header().Get("WWW-Authenticate")).To(Equal("Bearer"))
+	})
+
+	It("Rejects requests with invalid credentials", func() {
+		Expect(serve("/sessions", "wrong").Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("Answers Service Unavailable when credentials can't be checked", func() {
+		Expect(serve("/sessions", "unavailable").Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("Rejects requests with credentials from clients that failed authentication too many times", func() {
+		for i := 0; i < 3; i++ {
+			Expect(serve("/sessions", "wrong").Code).To(Equal(http.StatusUnauthorized))
+		}
+
+		// Verify output
+		w := serve("/sessions", "wrong")
+		Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(w.Header().Get(RetryAfterHeader)).NotTo(BeEmpty())
+		Expect(serve("/sessions", "reader").Code).To(Equal(http.StatusTooManyRequests))
+
+		// Anonymous requests and other clients are unaffected
+		Expect(serve("/sessions", "").Code).To(Equal(http.StatusUnauthorized))
+		req := httptest.NewRequest("GET", "/sessions", nil)
+		req.RemoteAddr = "10.0.0.2:1234"
+		req.Header.Set(auth.APIKeyHeader, "reader")
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("Forbids requests missing the required scope", func() {
+		Expect(serve("/admin", "reader").Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("Never authenticates exempt paths", func() {
+		Expect(serve("/health", "wrong").Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,302 @@
+// middleware of the HTTP server
+// The compression middleware compresses responses with gzip or brotli, negotiated with the `Accept-Encoding` header
+package middleware
+
+import (
+	// Standard Lib
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+
+	// Third-party
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	// Supported encodings
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+
+	// Content type of streamed server-sent events, which are never compressed
+	EventStreamContentType = "text/event-stream"
+)
+
+type (
+	// Struct representing compression middleware
+	Compression struct {
+		gzipPool   sync.Pool // Pool of reusable gzip writers
+		brotliPool sync.Pool // Pool of reusable brotli writers
+	}
+	// Struct representing a response writer that compresses responses once they're large enough
+	// NOTE: The decision to compress is made once the minimum size is buffered, the response ends, or is flushed
+	compressWriter struct {
+		http.ResponseWriter
+		m        *Compression       // Middleware owning the encoder pools
+		encoding string             // Negotiated encoding, empty if the client doesn't accept a supported encoding
+		settings config.Compression // Settings read at the start of the request
+		status   int                // Status code set by the handler
+		decided  bool               // Whether headers have been written and the decision to compress made
+		buf      []byte             // Response body buffered before deciding
+		encoder  io.WriteCloser     // Encoder of the response body, nil if not compressing
+	}
+	// Interface of encoders that can flush buffered data
+	flusher interface {
+		Flush() error
+	}
+)
+
+// NewCompression creates and returns a new instance of compression middleware
+// NOTE: Compression levels are read once, as writers are pooled
+func NewCompression() *Compression {
+	c := config.GetInstance().Compression
+
+	m := &Compression{}
+	m.gzipPool.New = func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, c.GzipLevel)
+		return w
+	}
+	m.brotliPool.New = func() interface{} {
+		return brotli.NewWriterLevel(nil, c.BrotliQuality)
+	}
+
+	return m
+}
+
+// Handler handles the processing of the request
+// The compression middleware handler compresses responses of allowed content types above a minimum size
+func (m *Compression) Handler(next http.Handler) http.Handler {
+	// Middleware handler function
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		c := config.GetInstance().Compression
+		if !c.Enabled {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: w,
+			m:              m,
+			encoding:       NegotiateEncoding(req.Header.Get("Accept-Encoding")),
+			settings:       c,
+			status:         http.StatusOK,
+		}
+		defer cw.close()
+
+		// Pass the request through
+		next.ServeHTTP(cw, req)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// NegotiateEncoding returns the supported encoding most preferred by an `Accept-Encoding` header, preferring
+// brotli when equally preferred, or an empty string if none are accepted
+func NegotiateEncoding(header string) string {
+	q := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name == "" {
+			continue
+		}
+
+		weight := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					weight = v
+				}
+			}
+		}
+		q[name] = weight
+	}
+
+	// Encodings not listed are acceptable when the wildcard is
+	weight := func(name string) float64 {
+		if v, ok := q[name]; ok {
+			return v
+		}
+		return q["*"]
+	}
+
+	br, gz := weight(EncodingBrotli), weight(EncodingGzip)
+	switch {
+	case br > 0 && br >= gz:
+		return EncodingBrotli
+	case gz > 0:
+		return EncodingGzip
+	}
+
+	return ""
+}
+
+// WriteHeader records the status code, which is written once the decision to compress is made
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided {
+		return
+	}
+	w.status = code
+
+	// Responses without a body are never compressed
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+// Write buffers the response body until it reaches the minimum size, then writes it
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) >= w.settings.MinSize {
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(b), nil
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush writes buffered data to the client
+// NOTE: Responses flushed before the decision to compress are streaming, and aren't compressed
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(false)
+	}
+
+	if f, ok := w.encoder.(flusher); ok {
+		f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack allows handlers to take over the connection (ex: WebSocket upgrades)
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("Response writer doesn't support hijacking")
+	}
+
+	// The connection is no longer an HTTP response, so nothing is left to decide
+	w.decided = true
+
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped response writer, allowing handlers to control it (ex: extending write deadlines)
+func (w *compressWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// decide writes headers, compressing the response if requested and it's eligible, then writes the buffered body
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	h := w.Header()
+
+	// Detect the content type as the standard library would, so it can be checked
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	eligible := h.Get("Content-Encoding") == "" && w.allowedType(h.Get("Content-Type"))
+	if eligible {
+		// The response depends on the `Accept-Encoding` header, whether or not this one is compressed
+		helpers.AddVary(h, "Accept-Encoding")
+	}
+
+	if compress && eligible && w.encoding != "" {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+
+		// The compressed body differs from the identity body, so a strong validator no longer applies
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+
+		w.encoder = w.m.encoder(w.encoding, w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+
+	return err
+}
+
+// close finishes the response, writing any buffered body and returning the encoder to its pool
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(false)
+	}
+
+	if w.encoder == nil {
+		return
+	}
+
+	w.encoder.Close()
+	switch e := w.encoder.(type) {
+	case *gzip.Writer:
+		w.m.gzipPool.Put(e)
+	case *brotli.Writer:
+		w.m.brotliPool.Put(e)
+	}
+	w.encoder = nil
+}
+
+// allowedType returns a boolean indicating if a content type may be compressed
+func (w *compressWriter) allowedType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if mediaType == "" || mediaType == EventStreamContentType {
+		return false
+	}
+
+	for _, t := range config.SplitList(w.settings.ContentTypes) {
+		t = strings.ToLower(t)
+		if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encoder returns a pooled encoder of an encoding writing to a writer
+func (m *Compression) encoder(encoding string, w io.Writer) io.WriteCloser {
+	if encoding == EncodingBrotli {
+		e := m.brotliPool.Get().(*brotli.Writer)
+		e.Reset(w)
+		return e
+	}
+
+	e := m.gzipPool.Get().(*gzip.Writer)
+	e.Reset(w)
+
+	return e
+}
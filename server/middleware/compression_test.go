@@ -0,0 +1,147 @@
+// Tests the compression.go file
+package middleware
+
+import (
+	// Standard lib
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	"github.com/andybalholm/brotli"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("compression.go", func() {
+	var (
+		// Body of large responses
+		large = `{"data":"` + strings.Repeat("forex ", 500) + `"}`
+		// Original configuration
+		original config.Compression
+	)
+
+	// serve makes a request accepting encodings to a handler, returning the recorded response
+	serve := func(acceptEncoding string, fn http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/sessions/calendar", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		NewCompression().Handler(fn).ServeHTTP(w, req)
+
+		return w
+	}
+
+	// jsonHandler returns a handler writing a JSON body
+	jsonHandler := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"abc"`)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(body))
+		}
+	}
+
+	BeforeEach(func() {
+		c := config.GetInstance()
+		original = c.Compression
+		c.Compression.Enabled = true
+		c.Compression.MinSize = 1024
+		c.Compression.ContentTypes = "application/json,text/*"
+		c.Compression.GzipLevel = 6
+		c.Compression.BrotliQuality = 4
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Compression = original
+	})
+
+	Describe("`NegotiateEncoding` method", func() {
+		It("Picks the most preferred supported encoding", func() {
+			Expect(NegotiateEncoding("")).To(Equal(""))
+			Expect(NegotiateEncoding("gzip, deflate")).To(Equal(EncodingGzip))
+			Expect(NegotiateEncoding("gzip, deflate, br")).To(Equal(EncodingBrotli))
+			Expect(NegotiateEncoding("br;q=0.5, gzip;q=0.8")).To(Equal(EncodingGzip))
+			Expect(NegotiateEncoding("br;q=0, *")).To(Equal(EncodingGzip))
+			Expect(NegotiateEncoding("*;q=0")).To(Equal(""))
+			Expect(NegotiateEncoding("identity")).To(Equal(""))
+		})
+	})
+
+	Describe("`Handler` method", func() {
+		It("Compresses large responses with gzip", func() {
+			w := serve("gzip", jsonHandler(large))
+			Expect(w.Header().Get("Content-Encoding")).To(Equal(EncodingGzip))
+			Expect(w.Header().Get("Vary")).To(Equal("Accept-Encoding"))
+			Expect(w.Header().Get("ETag")).To(Equal(`W/"abc"`))
+			Expect(w.Body.Len()).To(BeNumerically("<", len(large)))
+
+			r, err := gzip.NewReader(w.Body)
+			Expect(err).To(Not(HaveOccurred()))
+			b, _ := ioutil.ReadAll(r)
+			Expect(string(b)).To(Equal(large))
+		})
+
+		It("Compresses large responses with brotli", func() {
+			w := serve("gzip, br", jsonHandler(large))
+			Expect(w.Header().Get("Content-Encoding")).To(Equal(EncodingBrotli))
+
+			b, _ := ioutil.ReadAll(brotli.NewReader(w.Body))
+			Expect(string(b)).To(Equal(large))
+		})
+
+		It("Doesn't compress small responses or for clients without support, but varies", func() {
+			w := serve("gzip", jsonHandler(`{"data":[]}`))
+			Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(w.Header().Get("Vary")).To(Equal("Accept-Encoding"))
+			Expect(w.Body.String()).To(Equal(`{"data":[]}`))
+
+			w = serve("", jsonHandler(large))
+			Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(w.Header().Get("Vary")).To(Equal("Accept-Encoding"))
+			Expect(w.Body.String()).To(Equal(large))
+		})
+
+		It("Doesn't compress content types outside the allowlist", func() {
+			w := serve("gzip", func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				w.Header().Add("Vary", "Origin")
+				w.Write([]byte(large))
+			})
+			Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(w.Header()["Vary"]).To(Equal([]string{"Origin"}))
+		})
+
+		It("Doesn't compress streaming responses", func() {
+			w := serve("gzip", func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Write([]byte("data: 1\n\n"))
+				w.(http.Flusher).Flush()
+				w.Write([]byte(large))
+			})
+			Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(w.Body.String()).To(Equal("data: 1\n\n" + large))
+			Expect(w.Flushed).To(BeTrue())
+
+			w = serve("gzip", func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", EventStreamContentType)
+				w.Write([]byte(large))
+			})
+			Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+		})
+
+		It("Passes responses through when disabled", func() {
+			config.GetInstance().Compression.Enabled = false
+
+			w := serve("gzip", jsonHandler(large))
+			Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(w.Header().Get("Vary")).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,72 @@
+// middleware of the HTTP server
+// The CORS middleware handles cross-origin resource sharing based on configuration values,
+// picking up changes made by a configuration reload
+package middleware
+
+import (
+	// Standard Lib
+	"net/http"
+	"sync"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	"github.com/rs/cors"
+)
+
+type (
+	// Struct representing CORS middleware
+	CORS struct {
+		mutex    sync.Mutex  // Mutex guarding the handler and the settings it was built from
+		settings config.CORS // The configuration settings the current handler was built from
+		handler  *cors.Cors  // The current CORS handler
+	}
+)
+
+// NewCORS creates and returns a new instance of CORS middleware
+func NewCORS() *CORS {
+	c := config.GetInstance().CORS
+
+	return &CORS{
+		settings: c,
+		handler:  newCORSHandler(c),
+	}
+}
+
+// Handler handles the processing of the request
+// The CORS middleware handler adds CORS headers to requests and responds to pre-flight requests
+func (m *CORS) Handler(next http.Handler) http.Handler {
+	// Middleware handler function
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		m.current().ServeHTTP(w, req, next.ServeHTTP)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// current returns the CORS handler for the current configuration, rebuilding it if settings have changed
+func (m *CORS) current() *cors.Cors {
+	c := config.GetInstance().CORS
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if c != m.settings {
+		m.settings = c
+		m.handler = newCORSHandler(c)
+	}
+
+	return m.handler
+}
+
+// newCORSHandler creates a CORS handler from configuration settings
+func newCORSHandler(c config.CORS) *cors.Cors {
+	return cors.New(cors.Options{
+		AllowedOrigins:   config.SplitList(c.AllowedOrigins),
+		AllowedMethods:   config.SplitList(c.AllowedMethods),
+		AllowedHeaders:   config.SplitList(c.AllowedHeaders),
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	})
+}
@@ -2,19 +2,37 @@
 package middleware
 
 import (
+	// Internal
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/ratelimit"
 
 	// Third-party
 	"github.com/justinas/alice"
-	"github.com/rs/cors"
+)
+
+type (
+	// Struct representing the dependencies of middleware within the chain
+	Options struct {
+		Authenticators []auth.Authenticator // Authenticators to try, in order
+		Limiter        *ratelimit.Limiter   // Limiter of request rates, nil to disable rate limiting
+	}
+)
+
+var (
+	// Paths that are never authenticated or rate limited (ex: health checks)
+	ExemptPaths = []string{"/health", "/ready", "/version"}
 )
 
 // NewMiddleware creates and returns a new instance of a middleware chain
-func NewMiddleware() alice.Chain {
+func NewMiddleware(o Options) alice.Chain {
 	return alice.New(
 		NewVersion().Handler,
 		NewPreflight().Handler,
-		cors.New(cors.Options{}).Handler,
+		NewCORS().Handler,
 		NewLogger().Handler,
+		NewCompression().Handler,
 		NewRecovery().Handler,
+		NewAuth(o.Limiter, o.Authenticators...).Handler,
+		NewRateLimit(o.Limiter).Handler,
 	)
 }
\ No newline at end of file
@@ -0,0 +1,195 @@
+// middleware of the HTTP server
+// The rate limit middleware limits the rate of requests per client, and counts requests against daily quotas
+package middleware
+
+import (
+	// Standard Lib
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/ratelimit"
+
+	// Third Party
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Rate limit headers
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+
+	// Daily quota headers
+	QuotaLimitHeader     = "X-Quota-Limit"
+	QuotaRemainingHeader = "X-Quota-Remaining"
+	QuotaResetHeader     = "X-Quota-Reset"
+
+	// Header containing the client IP when behind a proxy
+	ForwardedForHeader = "X-Forwarded-For"
+
+	// Route of requests that don't match any route, sharing a single bucket
+	UnmatchedRoute = "unmatched"
+)
+
+type (
+	// Struct representing rate limit middleware
+	RateLimit struct {
+		limiter *ratelimit.Limiter // Limiter to take request tokens from
+		exempt  map[string]bool    // Paths that are never limited
+	}
+)
+
+// NewRateLimit creates and returns a new instance of rate limit middleware
+// NOTE: Requests aren't limited when the limiter is nil
+func NewRateLimit(limiter *ratelimit.Limiter) RateLimit {
+	exempt := map[string]bool{}
+	for _, p := range ExemptPaths {
+		exempt[p] = true
+	}
+
+	return RateLimit{limiter: limiter, exempt: exempt}
+}
+
+// Handler handles the processing of the request
+// The rate limit middleware handler takes a token from the client's bucket, rejecting the request if none are
+// available or the client's daily quota has been used, and adds headers describing the client's limits
+func (m RateLimit) Handler(next http.Handler) http.Handler {
+	// Middleware handler function
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		c := config.GetInstance().RateLimit
+		if m.limiter == nil || !c.Enabled || m.exempt[req.URL.Path] {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		now := time.Now()
+		p := auth.FromContext(req.Context())
+
+		// NOTE: Limiter errors allow the request, so an unavailable store doesn't cause an outage
+		r, err := m.limiter.Allow(rateLimitKey(c, req, p, next), now)
+		if err != nil {
+			log.WithError(err).Error("Error checking rate limit")
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		h := w.Header()
+		h.Set(RateLimitLimitHeader, strconv.Itoa(r.Limit))
+		h.Set(RateLimitRemainingHeader, strconv.Itoa(r.Remaining))
+		h.Set(RateLimitResetHeader, ceilSeconds(r.Reset))
+
+		if !r.Allowed {
+			h.Set(RetryAfterHeader, ceilSeconds(r.RetryAfter))
+			helpers.TooManyRequests(w, req)
+			return
+		}
+
+		// Count the request against the daily quota of authenticated clients
+		if p != nil {
+			q, err := m.limiter.UseQuota(p.ID, now)
+			if err != nil {
+				log.WithError(err).Error("Error checking daily quota")
+			}
+			if q != nil {
+				h.Set(QuotaLimitHeader, strconv.Itoa(q.Limit))
+				h.Set(QuotaRemainingHeader, strconv.Itoa(q.Remaining))
+				h.Set(QuotaResetHeader, ceilSeconds(q.Reset.Sub(now)))
+
+				if q.Exceeded() {
+					h.Set(RetryAfterHeader, ceilSeconds(q.Reset.Sub(now)))
+					helpers.TooManyRequests(w, req)
+					return
+				}
+			}
+		}
+
+		// Pass the request through
+		next.ServeHTTP(w, req)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// rateLimitKey returns the key of the bucket a request takes a token from
+func rateLimitKey(c config.RateLimit, req *http.Request, p *auth.Principal, next http.Handler) string {
+	parts := []string{}
+	for _, by := range strings.Split(c.KeyBy, "+") {
+		switch by {
+		case "key":
+			// Anonymous requests are limited by IP
+			if p != nil {
+				parts = append(parts, "key:"+p.ID)
+			} else {
+				parts = append(parts, "ip:"+clientIP(req, c))
+			}
+		case "ip":
+			parts = append(parts, "ip:"+clientIP(req, c))
+		case "route":
+			parts = append(parts, "route:"+routeTemplate(req, next))
+		}
+	}
+
+	return strings.Join(parts, "|")
+}
+
+// routeTemplate returns the path template of the route a request matches (ex: "/alerts/{id}"), so requests to
+// every path of a route share a bucket, and requests to unknown paths can't each create one
+func routeTemplate(req *http.Request, next http.Handler) string {
+	route := mux.CurrentRoute(req)
+
+	// The router only sets the current route once it handles the request, after this middleware
+	if r, ok := next.(*mux.Router); ok && route == nil {
+		match := &mux.RouteMatch{}
+		if r.Match(req, match) {
+			route = match.Route
+		}
+	}
+
+	if route != nil {
+		if t, err := route.GetPathTemplate(); err == nil {
+			return t
+		}
+	}
+
+	return UnmatchedRoute
+}
+
+// clientIP returns the IP address of the client making a request
+func clientIP(req *http.Request, c config.RateLimit) string {
+	if c.TrustProxy {
+		// Each trusted proxy appends the address it received the request from, so the client is as many entries
+		// from the end as there are proxies, anything before that was sent by the client itself
+		// NOTE: The header is joined across lines, as proxies may append lines rather than entries
+		if f := strings.Join(req.Header[ForwardedForHeader], ","); f != "" {
+			addrs := strings.Split(f, ",")
+			n := len(addrs) - c.TrustedProxies
+			if n < 0 {
+				n = 0
+			}
+
+			return strings.TrimSpace(addrs[n])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
+
+// ceilSeconds formats a duration as a whole number of seconds, rounded up
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,172 @@
+// Tests the rate-limit.go file
+package middleware
+
+import (
+	// Standard lib
+	"net/http"
+	"net/http/httptest"
+
+	// Internal
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/ratelimit"
+
+	// Third-party
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("rate-limit.go", func() {
+	var (
+		// Handler to test
+		h http.Handler
+		// Original configuration
+		original config.RateLimit
+	)
+
+	// serve makes a request from an IP, with an optional principal, returning the recorded response
+	serve := func(path, ip string, p *auth.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if p != nil {
+			req = req.WithContext(auth.NewContext(req.Context(), p))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		return w
+	}
+
+	BeforeEach(func() {
+		c := config.GetInstance()
+		original = c.RateLimit
+		c.RateLimit.Enabled = true
+		c.RateLimit.KeyBy = "key"
+		c.RateLimit.Rate = 60
+		c.RateLimit.Burst = 2
+		c.RateLimit.DailyQuota = 0
+
+		h = NewRateLimit(ratelimit.NewLimiter(ratelimit.NewMemoryStore())).Handler(
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}),
+		)
+	})
+
+	AfterEach(func() {
+		config.GetInstance().RateLimit = original
+	})
+
+	It("Adds rate limit headers, rejecting requests once the burst is used", func() {
+		w := serve("/sessions", "10.0.0.1", nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get(RateLimitLimitHeader)).To(Equal("2"))
+		Expect(w.Header().Get(RateLimitRemainingHeader)).To(Equal("1"))
+		Expect(w.Header().Get(RateLimitResetHeader)).To(Equal("1"))
+
+		serve("/sessions", "10.0.0.1", nil)
+		w = serve("/sessions", "10.0.0.1", nil)
+		Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(w.Header().Get(RetryAfterHeader)).To(Equal("1"))
+		Expect(w.Body.String()).To(Equal(`{"meta":{},"data":null}`))
+
+		// Other clients and exempt paths are unaffected
+		Expect(serve("/sessions", "10.0.0.2", nil).Code).To(Equal(http.StatusOK))
+		Expect(serve("/health", "10.0.0.1", nil).Code).To(Equal(http.StatusOK))
+	})
+
+	It("Limits authenticated requests by API key", func() {
+		p := &auth.Principal{ID: "key-1"}
+		serve("/sessions", "10.0.0.1", p)
+		serve("/sessions", "10.0.0.2", p)
+		Expect(serve("/sessions", "10.0.0.3", p).Code).To(Equal(http.StatusTooManyRequests))
+		Expect(serve("/sessions", "10.0.0.3", nil).Code).To(Equal(http.StatusOK))
+	})
+
+	It("Limits requests by route", func() {
+		config.GetInstance().RateLimit.KeyBy = "ip+route"
+		r := mux.NewRouter()
+		r.HandleFunc("/sessions", func(w http.ResponseWriter, req *http.Request) {})
+		r.HandleFunc("/alerts/{id}", func(w http.ResponseWriter, req *http.Request) {})
+		h = NewRateLimit(ratelimit.NewLimiter(ratelimit.NewMemoryStore())).Handler(r)
+
+		serve("/sessions", "10.0.0.1", nil)
+		serve("/sessions", "10.0.0.1", nil)
+		Expect(serve("/sessions", "10.0.0.1", nil).Code).To(Equal(http.StatusTooManyRequests))
+
+		// Paths of the same route share a bucket
+		Expect(serve("/alerts/a1", "10.0.0.1", nil).Code).To(Equal(http.StatusOK))
+		Expect(serve("/alerts/a2", "10.0.0.1", nil).Code).To(Equal(http.StatusOK))
+		Expect(serve("/alerts/a3", "10.0.0.1", nil).Code).To(Equal(http.StatusTooManyRequests))
+
+		// Unknown paths share a single bucket
+		Expect(serve("/unknown-1", "10.0.0.1", nil).Code).To(Equal(http.StatusNotFound))
+		Expect(serve("/unknown-2", "10.0.0.1", nil).Code).To(Equal(http.StatusNotFound))
+		Expect(serve("/unknown-3", "10.0.0.1", nil).Code).To(Equal(http.StatusTooManyRequests))
+	})
+
+	Describe("`clientIP` method", func() {
+		var c config.RateLimit
+
+		// request returns a request from a proxy, forwarded for a list of addresses
+		request := func(forwarded ...string) *http.Request {
+			req := httptest.NewRequest("GET", "/sessions", nil)
+			req.RemoteAddr = "10.0.0.9:1234"
+			for _, f := range forwarded {
+				req.Header.Add(ForwardedForHeader, f)
+			}
+
+			return req
+		}
+
+		BeforeEach(func() {
+			c = config.RateLimit{TrustProxy: true, TrustedProxies: 1}
+		})
+
+		It("Reads the address appended by the trusted proxy", func() {
+			// Verify output
+			Expect(clientIP(request("203.0.113.7"), c)).To(Equal("203.0.113.7"))
+			// NOTE: Clients can send their own header, which the proxy appends to
+			Expect(clientIP(request("1.2.3.4, 203.0.113.7"), c)).To(Equal("203.0.113.7"))
+			Expect(clientIP(request("1.2.3.4", "203.0.113.7"), c)).To(Equal("203.0.113.7"))
+		})
+
+		It("Reads the address as many entries from the end as there are trusted proxies", func() {
+			c.TrustedProxies = 2
+
+			// Verify output
+			Expect(clientIP(request("1.2.3.4, 203.0.113.7, 10.0.0.8"), c)).To(Equal("203.0.113.7"))
+			Expect(clientIP(request("203.0.113.7"), c)).To(Equal("203.0.113.7"))
+		})
+
+		It("Reads the remote address when proxies aren't trusted or the header isn't set", func() {
+			// Verify output
+			Expect(clientIP(request(), c)).To(Equal("10.0.0.9"))
+
+			c.TrustProxy = false
+			Expect(clientIP(request("203.0.113.7"), c)).To(Equal("10.0.0.9"))
+		})
+	})
+
+	It("Reports and enforces daily quotas", func() {
+		c := config.GetInstance()
+		c.RateLimit.Burst = 10
+		c.RateLimit.DailyQuota = 1
+
+		p := &auth.Principal{ID: "key-1"}
+		w := serve("/sessions", "10.0.0.1", p)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get(QuotaLimitHeader)).To(Equal("1"))
+		Expect(w.Header().Get(QuotaRemainingHeader)).To(Equal("0"))
+
+		w = serve("/sessions", "10.0.0.1", p)
+		Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(w.Header().Get(RetryAfterHeader)).To(Not(BeEmpty()))
+	})
+
+	It("Passes all requests when disabled", func() {
+		config.GetInstance().RateLimit.Enabled = false
+		for i := 0; i < 5; i++ {
+			Expect(serve("/sessions", "10.0.0.1", nil).Code).To(Equal(http.StatusOK))
+		}
+	})
+})
@@ -4,10 +4,13 @@ package server
 import (
 	// Standard lib
 	"fmt"
+	"net/http"
 	"time"
 
 	// Internal
+	"github.com/deezone/forex-clock/auth"
 	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
 
 	// Third-party
 	goutils "github.com/marksost/go-utils"
@@ -59,6 +62,9 @@ var _ = Describe("routes.go", func() {
 		)
 
 		BeforeEach(func() {
+			// Disable rate limiting, as every route is requested by the same client
+			config.GetInstance().RateLimit.Enabled = false
+
 			// Set input
 			input = []*RoutesTestData{
 				// Non-valid route
@@ -74,9 +80,146 @@ var _ = Describe("routes.go", func() {
 				&RoutesTestData{Method: "GET", Route: "/health", ResponseCode: 200},
 				// Version with valid method
 				&RoutesTestData{Method: "GET", Route: "/version", ResponseCode: 200},
+
+				/* Session Routes */
+
+				// Sessions with invalid method
+				&RoutesTestData{Method: "POST", Route: "/sessions", ResponseCode: 405},
+				// Sessions with valid method
+				&RoutesTestData{Method: "GET", Route: "/sessions", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/sessions/next?count=3", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/sessions/calendar?from=2024-06-10&days=5&session=london", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/sessions/calendar?format=ics", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/sessions/calendar.ics?sessions=london,tokyo&overlaps=false", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/sessions/dst?from=2024-01-01&days=731&session=london", ResponseCode: 200},
+				// Sessions with invalid parameters
+				&RoutesTestData{Method: "GET", Route: "/sessions?at=yesterday", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/sessions/calendar?days=1000", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/sessions/calendar?session=mars", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/sessions/calendar?format=xml", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/sessions/calendar.ics?sessions=mars", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/sessions/calendar.ics?holidays=maybe", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/sessions/dst?days=1000", ResponseCode: 400},
+
+				/* Time Routes */
+
+				// Time with invalid method
+				&RoutesTestData{Method: "POST", Route: "/time", ResponseCode: 405},
+				// Time with valid method
+				&RoutesTestData{Method: "GET", Route: "/time", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/time/convert?at=2024-03-10T02:30&from=Europe/London&to=America/New_York,Asia/Tokyo", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/time/zones?q=america", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/time/zones/America/Argentina/Buenos_Aires", ResponseCode: 200},
+				// Time with invalid parameters, or unknown zones
+				&RoutesTestData{Method: "GET", Route: "/time/convert?to=Mars/Olympus_Mons", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/time/convert?at=2024-03-10T02:30&from=America/New_York&to=UTC", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/time/zones/Mars/Olympus_Mons", ResponseCode: 404},
+
+				/* Pair Routes */
+
+				// Pairs with invalid method
+				&RoutesTestData{Method: "POST", Route: "/pairs", ResponseCode: 405},
+				&RoutesTestData{Method: "DELETE", Route: "/pairs/EURUSD", ResponseCode: 405},
+				// Pairs with invalid parameters
+				&RoutesTestData{Method: "GET", Route: "/pairs?currency=XYZ", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/pairs?class=common", ResponseCode: 400},
+
+				/* Quote Routes */
+
+				// Quotes with invalid method
+				&RoutesTestData{Method: "POST", Route: "/quotes", ResponseCode: 405},
+				// Quotes with valid method, no quotes are received while market data is disabled
+				&RoutesTestData{Method: "GET", Route: "/quotes?pairs=EURUSD,USDJPY", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/quotes/EURUSD", ResponseCode: 404},
+				&RoutesTestData{Method: "GET", Route: "/quotes/EURJPY", ResponseCode: 404},
+
+				/* Conversion Routes */
+
+				// Conversions with invalid method
+				&RoutesTestData{Method: "POST", Route: "/convert?from=EUR&to=USD", ResponseCode: 405},
+				// Conversions with valid method, converting into the same currency needs no quote
+				&RoutesTestData{Method: "GET", Route: "/convert?from=EUR&to=eur&amount=12.5", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/convert?from=EUR&to=JPY&amount=100", ResponseCode: 404},
+				// Conversions with invalid parameters
+				&RoutesTestData{Method: "GET", Route: "/convert?from=EUR", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/convert?from=EUR&to=XYZ", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/convert?from=EUR&to=USD&amount=-1", ResponseCode: 400},
+
+				/* Currency Strength Routes */
+
+				// Currency strength with invalid method
+				&RoutesTestData{Method: "POST", Route: "/strength", ResponseCode: 405},
+				&RoutesTestData{Method: "DELETE", Route: "/strength/daily", ResponseCode: 405},
+				// Currency strength with valid method, currencies are ranked without quotes
+				&RoutesTestData{Method: "GET", Route: "/strength", ResponseCode: 200},
+				&RoutesTestData{Method: "GET", Route: "/strength/session", ResponseCode: 200},
+				// Currency strength of unknown windows
+				&RoutesTestData{Method: "GET", Route: "/strength/weekly", ResponseCode: 404},
+
+				/* Candle Routes */
+
+				// Candles with invalid method
+				&RoutesTestData{Method: "POST", Route: "/candles/EURUSD", ResponseCode: 405},
+				// Candles with invalid parameters
+				&RoutesTestData{Method: "GET", Route: "/candles/EURUSD?interval=2h", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/candles/EURUSD?limit=0", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/candles/EURUSD?from=2024-03-02T00:00:00Z&to=2024-03-01T00:00:00Z", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/candles/EURXYZ", ResponseCode: 400},
+
+				/* Indicator Routes */
+
+				// Indicators with invalid method
+				&RoutesTestData{Method: "POST", Route: "/indicators/EURUSD?name=rsi", ResponseCode: 405},
+				// Indicators with invalid parameters
+				&RoutesTestData{Method: "GET", Route: "/indicators/EURUSD", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/indicators/EURUSD?name=stochastic", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/indicators/EURUSD?name=macd&params=12,x", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/indicators/EURUSD?name=bollinger&params=20,2,1", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/indicators/EURUSD?name=rsi&interval=2h", ResponseCode: 400},
+
+				/* Statistics Routes */
+
+				// Statistics with invalid method
+				&RoutesTestData{Method: "POST", Route: "/stats/sessions", ResponseCode: 405},
+				&RoutesTestData{Method: "DELETE", Route: "/stats/sessions/EURUSD", ResponseCode: 405},
+				// Statistics with invalid parameters
+				&RoutesTestData{Method: "GET", Route: "/stats/sessions?pairs=EURUSD,EURXYZ", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/stats/sessions/EURUSD?lookback=0", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/stats/sessions/EURXYZ", ResponseCode: 400},
+
+				/* Stream Routes */
+
+				// WebSocket stream without a handshake, or with invalid parameters
+				&RoutesTestData{Method: "GET", Route: "/stream/ws", ResponseCode: 400},
+				&RoutesTestData{Method: "GET", Route: "/stream/ws?sessions=mars", ResponseCode: 400},
+
+				/* Alert Routes */
+
+				// Alerts without credentials
+				&RoutesTestData{Method: "GET", Route: "/alerts", ResponseCode: 401},
+				&RoutesTestData{Method: "POST", Route: "/alerts", ResponseCode: 401},
+				&RoutesTestData{Method: "PUT", Route: "/alerts/abc", ResponseCode: 401},
+				&RoutesTestData{Method: "GET", Route: "/alert-history", ResponseCode: 401},
+
+				/* Admin Routes */
+
+				// API keys without credentials
+				&RoutesTestData{Method: "GET", Route: "/admin/api-keys", ResponseCode: 401},
+				&RoutesTestData{Method: "POST", Route: "/admin/api-keys/abc/rotate", ResponseCode: 401},
+				&RoutesTestData{Method: "DELETE", Route: "/admin/api-keys/abc", ResponseCode: 401},
+
+				// Webhooks without credentials
+				&RoutesTestData{Method: "GET", Route: "/admin/webhooks", ResponseCode: 401},
+				&RoutesTestData{Method: "PUT", Route: "/admin/webhooks/abc", ResponseCode: 401},
+				&RoutesTestData{Method: "GET", Route: "/admin/webhook-deliveries?status=dead", ResponseCode: 401},
+				&RoutesTestData{Method: "POST", Route: "/admin/webhook-deliveries/abc/retry", ResponseCode: 401},
 			}
 		})
 
+		AfterEach(func() {
+			config.GetInstance().RateLimit.Enabled = true
+		})
+
 		It("Resolves requests properly", func() {
 			// Loop through test data
 			for _, conf := range input {
@@ -96,4 +239,40 @@ var _ = Describe("routes.go", func() {
 			}
 		})
 	})
+
+	Describe("Caching of authenticated requests", func() {
+		BeforeEach(func() {
+			// Restart the server requiring authentication, which is read at start up
+			s.Stop()
+			c := config.GetInstance()
+			c.Auth.Required, c.Auth.AdminKey = true, "integration-admin-key"
+			c.RateLimit.Enabled = false
+
+			s = NewServer()
+			Expect(s.Start()).To(Succeed())
+			time.Sleep(500 * time.Millisecond)
+		})
+
+		AfterEach(func() {
+			c := config.GetInstance()
+			c.Auth.Required, c.Auth.AdminKey = false, ""
+			c.RateLimit.Enabled = true
+		})
+
+		It("Keeps responses out of shared caches", func() {
+			req, _ := http.NewRequest("GET", serverAddress+"/sessions?at=2024-06-05T10:00:00Z", nil)
+			req.Header.Set(auth.APIKeyHeader, "integration-admin-key")
+
+			// Make request
+			res, err := http.DefaultClient.Do(req)
+			Expect(err).To(Not(HaveOccurred()))
+			res.Body.Close()
+
+			// Verify response
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(res.Header.Get(helpers.CacheControlHeader)).To(HavePrefix("private, "))
+			Expect(res.Header[helpers.VaryHeader]).To(ContainElement(auth.AuthorizationHeader))
+			Expect(res.Header[helpers.VaryHeader]).To(ContainElement(auth.APIKeyHeader))
+		})
+	})
 })
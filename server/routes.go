@@ -3,8 +3,14 @@
 package server
 
 import (
+	// Standard lib
+	"net/http"
+
 	// Internal
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/config"
 	"github.com/deezone/forex-clock/handlers"
+	"github.com/deezone/forex-clock/server/middleware"
 
 	// Third Party
 	"github.com/gorilla/mux"
@@ -16,13 +22,90 @@ func (s *Server) SetRoutes() {
 	mux := mux.NewRouter()
 
 	// Create handlers
-	hh := handlers.NewHealthHandler(s.resources.DB)
+	hh := handlers.NewHealthHandler(s.resources.DB, s.resources.Provider)
+	sh := handlers.NewSessionsHandler()
+	ah := handlers.NewAPIKeysHandler(s.resources.APIKeys, s.resources.Limiter)
+	ch := handlers.NewCacheHandler()
+	th := handlers.NewStreamHandler(s.resources.Hub)
+	zh := handlers.NewTimeHandler()
+	wh := handlers.NewWebhooksHandler(s.resources.Webhooks, s.resources.Dispatcher)
+	ph := handlers.NewPairsHandler(s.resources.Pairs)
+	qh := handlers.NewQuotesHandler(s.resources.Quotes)
+	kh := handlers.NewCandlesHandler(s.resources.Candles)
+	xh := handlers.NewStatsHandler(s.resources.Stats)
+	ih := handlers.NewIndicatorsHandler(s.resources.Candles)
+	rh := handlers.NewStrengthHandler(s.resources.Strength)
+	lh := handlers.NewAlertsHandler(s.resources.Alerts)
+
+	// Data routes only require a scope when authentication is required
+	// NOTE: Read at start up, changing `auth.required` requires a restart
+	scoped := func(scope string, fn http.HandlerFunc) http.HandlerFunc {
+		if !config.GetInstance().Auth.Required {
+			return fn
+		}
+		return middleware.RequireScope(scope, fn)
+	}
 
 	// Set up health/readiness/version routes
 	mux.HandleFunc(handlers.HealthRoute, hh.Health)
 	mux.HandleFunc(handlers.ReadyRoute, hh.Ready)
 	mux.HandleFunc(handlers.VersionRoute, hh.Version)
 
+	// Set up trading session routes
+	mux.HandleFunc(handlers.SessionsRoute, scoped(auth.ScopeSessionsRead, sh.Sessions))
+	mux.HandleFunc(handlers.SessionsNextRoute, scoped(auth.ScopeSessionsRead, sh.Next))
+	mux.HandleFunc(handlers.SessionsCalendarRoute, scoped(auth.ScopeSessionsRead, sh.Calendar))
+	mux.HandleFunc(handlers.SessionsICSRoute, scoped(auth.ScopeSessionsRead, sh.ICS))
+	mux.HandleFunc(handlers.SessionsDSTRoute, scoped(auth.ScopeSessionsRead, sh.DST))
+
+	// Set up time zone routes
+	mux.HandleFunc(handlers.TimeRoute, scoped(auth.ScopeSessionsRead, zh.Time))
+	mux.HandleFunc(handlers.TimeConvertRoute, scoped(auth.ScopeSessionsRead, zh.Convert))
+	mux.HandleFunc(handlers.TimeZonesRoute, scoped(auth.ScopeSessionsRead, zh.Zones))
+	mux.HandleFunc(handlers.TimeZoneRoute, scoped(auth.ScopeSessionsRead, zh.Zone))
+
+	// Set up currency pair routes
+	mux.HandleFunc(handlers.PairsRoute, scoped(auth.ScopeQuotesRead, ph.Pairs))
+	mux.HandleFunc(handlers.PairRoute, scoped(auth.ScopeQuotesRead, ph.Pair))
+
+	// Set up FX quote routes
+	mux.HandleFunc(handlers.QuotesRoute, scoped(auth.ScopeQuotesRead, qh.Quotes))
+	mux.HandleFunc(handlers.QuoteRoute, scoped(auth.ScopeQuotesRead, qh.Quote))
+	mux.HandleFunc(handlers.ConvertRoute, scoped(auth.ScopeQuotesRead, qh.Convert))
+
+	// Set up candle and technical indicator routes
+	mux.HandleFunc(handlers.CandlesRoute, scoped(auth.ScopeQuotesRead, kh.Candles))
+	mux.HandleFunc(handlers.IndicatorsRoute, scoped(auth.ScopeQuotesRead, ih.Indicators))
+
+	// Set up currency strength routes
+	mux.HandleFunc(handlers.StrengthRoute, scoped(auth.ScopeQuotesRead, rh.Strength))
+	mux.HandleFunc(handlers.StrengthWindowRoute, scoped(auth.ScopeQuotesRead, rh.Window))
+
+	// Set up statistics routes
+	mux.HandleFunc(handlers.SessionStatsRoute, scoped(auth.ScopeQuotesRead, xh.Sessions))
+	mux.HandleFunc(handlers.SessionPairStatsRoute, scoped(auth.ScopeQuotesRead, xh.Pair))
+
+	// Set up market event stream routes
+	mux.HandleFunc(handlers.StreamWebSocketRoute, scoped(auth.ScopeSessionsRead, th.WebSocket))
+	mux.HandleFunc(handlers.StreamEventsRoute, scoped(auth.ScopeSessionsRead, th.Events))
+
+	// Set up alert routes, which always require the alerts scope, as alerts notify external targets
+	mux.HandleFunc(handlers.AlertsRoute, middleware.RequireScope(auth.ScopeAlerts, lh.Alerts))
+	mux.HandleFunc(handlers.AlertRoute, middleware.RequireScope(auth.ScopeAlerts, lh.Alert))
+	mux.HandleFunc(handlers.AlertHistoryRoute, middleware.RequireScope(auth.ScopeAlerts, lh.History))
+
+	// Set up admin routes, which always require the admin scope
+	mux.HandleFunc(handlers.APIKeysRoute, middleware.RequireScope(auth.ScopeAdmin, ah.APIKeys))
+	mux.HandleFunc(handlers.APIKeyRotateRoute, middleware.RequireScope(auth.ScopeAdmin, ah.Rotate))
+	mux.HandleFunc(handlers.APIKeyUsageRoute, middleware.RequireScope(auth.ScopeAdmin, ah.Usage))
+	mux.HandleFunc(handlers.APIKeyRoute, middleware.RequireScope(auth.ScopeAdmin, ah.APIKey))
+	mux.HandleFunc(handlers.CacheStatsRoute, middleware.RequireScope(auth.ScopeAdmin, ch.Stats))
+	mux.HandleFunc(handlers.WebhooksRoute, middleware.RequireScope(auth.ScopeAdmin, wh.Webhooks))
+	mux.HandleFunc(handlers.WebhookRoute, middleware.RequireScope(auth.ScopeAdmin, wh.Webhook))
+	mux.HandleFunc(handlers.WebhookDeliveriesRoute, middleware.RequireScope(auth.ScopeAdmin, wh.Deliveries))
+	mux.HandleFunc(handlers.WebhookDeliveryRetryRoute, middleware.RequireScope(auth.ScopeAdmin, wh.Retry))
+	mux.HandleFunc(handlers.WebhookDeliveryRoute, middleware.RequireScope(auth.ScopeAdmin, wh.Delivery))
+
 	// Set the server's routing handler to be the mux
 	s.GetInstance().Handler = mux
 }
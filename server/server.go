@@ -10,9 +10,21 @@ import (
 	"time"
 
 	// Internal
+	"github.com/deezone/forex-clock/alerts"
+	"github.com/deezone/forex-clock/auth"
+	"github.com/deezone/forex-clock/cache"
+	"github.com/deezone/forex-clock/candles"
 	"github.com/deezone/forex-clock/config"
 	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/provider"
+	"github.com/deezone/forex-clock/quotes"
+	"github.com/deezone/forex-clock/ratelimit"
 	"github.com/deezone/forex-clock/server/middleware"
+	"github.com/deezone/forex-clock/stats"
+	"github.com/deezone/forex-clock/stream"
+	"github.com/deezone/forex-clock/strength"
+	"github.com/deezone/forex-clock/webhooks"
 
 	// Third-party
 	"github.com/labstack/gommon/log"
@@ -21,7 +33,23 @@ import (
 type (
 	// Struct representing the various internal resources request handlers may need to access
 	Resources struct {
-		DB  db.DB   // The database instance to use
+		DB         db.DB                // The database instance to use
+		APIKeys    db.APIKeyStore       // Storage of API keys
+		Limiter    *ratelimit.Limiter   // Limiter of request rates and daily quotas
+		Cache      cache.Backend        // Backend of cached provider and database reads
+		Hub        *stream.Hub          // Hub publishing market events to streaming clients
+		Webhooks   db.WebhookStore      // Storage of webhooks and their deliveries
+		Pairs      db.PairStore         // Storage of currency pair reference data
+		Dispatcher *webhooks.Dispatcher // Dispatcher delivering market events to webhooks
+		Provider   provider.Provider    // Source of FX quotes, nil when market data is disabled
+		Quotes     *quotes.Store        // Snapshot of the latest FX quote of each pair
+		Candles    db.CandleStore       // Storage of candles aggregated from ticks
+		Recorder   *candles.Recorder    // Recorder saving ingested ticks, nil when disabled
+		Aggregator *candles.Aggregator  // Aggregator building candles from saved ticks, nil when disabled
+		Stats      *stats.Service       // Computation of per-session statistics from stored candles
+		Strength   *strength.Service    // Measurement of currency strength from the latest quotes
+		Alerts     db.AlertStore        // Storage of price alerts and their triggers
+		Evaluator  *alerts.Evaluator    // Evaluator triggering alerts against the latest quotes and market events
 	}
 	// Struct representing the actual http.Server and helper data
 	Server struct {
@@ -35,6 +63,52 @@ type (
 func NewServer() *Server {
 	c := config.GetInstance()
 
+	fcdb := db.NewFCDB()
+
+	// NOTE: Falls back to the in-memory store, as an invalid store is rejected when validating configuration
+	store, err := ratelimit.NewStore(c.RateLimit, fcdb)
+	if err != nil {
+		log.Error("Error creating rate limit store: " + err.Error())
+		store = ratelimit.NewMemoryStore()
+	}
+
+	// NOTE: Falls back to the in-memory backend, as an invalid backend is rejected when validating configuration
+	backend, err := cache.NewBackend(c.Cache)
+	if err != nil {
+		log.Error("Error creating cache backend: " + err.Error())
+		backend = cache.NewMemoryBackend(c.Cache.MaxEntries)
+	}
+
+	// NOTE: Disables market data, as an invalid type is rejected when validating configuration
+	quoteProvider, err := provider.New(c.Provider)
+	if err != nil {
+		log.Error("Error creating FX quote provider: " + err.Error())
+	}
+
+	// Save the latest quotes within the database when persistence is enabled
+	// NOTE: Read at start up, changing `quotes.persist` requires a restart
+	quoteHooks := []quotes.Hook{}
+	if c.Quotes.Persist {
+		quoteHooks = append(quoteHooks, quotes.NewDBHook(db.NewQuoteStore(fcdb)))
+	}
+
+	// Save ingested ticks, building candles from them, when enabled
+	// NOTE: Read at start up, changing `candles.enabled` requires a restart
+	candleStore := db.NewCandleStore(fcdb)
+	var recorder *candles.Recorder
+	var aggregator *candles.Aggregator
+	if c.Candles.Enabled {
+		ticks := db.NewTickStore(fcdb)
+		aggregator = candles.NewAggregator(ticks, candleStore)
+		recorder = candles.NewRecorder(ticks, aggregator)
+		quoteHooks = append(quoteHooks, recorder)
+	}
+
+	hub := stream.NewHub()
+	hooks := db.NewWebhookStore(fcdb)
+	quoteStore := quotes.NewStore(quoteProvider, quoteHooks...)
+	alertStore := db.NewAlertStore(fcdb)
+
 	return &Server {
 		instance: &http.Server{
 			Addr:         fmt.Sprintf(":%d", c.Server.Port),
@@ -42,7 +116,24 @@ func NewServer() *Server {
 			WriteTimeout: time.Duration(c.Server.Timeouts.Write) * time.Second,
 		},
 		resources: &Resources{
-			DB:  db.NewFCDB(),
+			DB:         fcdb,
+			APIKeys:    db.NewCachedAPIKeyStore(db.NewAPIKeyStore(fcdb), cache.New("api-keys", backend)),
+			Limiter:    ratelimit.NewLimiter(store),
+			Cache:      backend,
+			Hub:        hub,
+			Webhooks:   hooks,
+			Dispatcher: webhooks.NewDispatcher(hooks, hub),
+			Pairs:      db.NewPairStore(fcdb),
+			Provider:   quoteProvider,
+			Quotes:     quoteStore,
+			Candles:    candleStore,
+			Recorder:   recorder,
+			Aggregator: aggregator,
+			Stats:      stats.NewService(candleStore, cache.New("session-stats", backend)),
+			Strength:   strength.NewService(quoteStore, hub),
+			Alerts:     alertStore,
+			Evaluator: alerts.NewEvaluator(alertStore, quoteStore,
+				alerts.NewLogChannel(), alerts.NewWebhookChannel(), alerts.NewEmailChannel()),
 		},
 		running: false,
 	}
@@ -57,7 +148,55 @@ func (s *Server) Start() error {
 
 	// Set routes and middleware
 	s.SetRoutes()
-	s.GetInstance().Handler = middleware.NewMiddleware().Then(s.GetInstance().Handler)
+	s.GetInstance().Handler = middleware.NewMiddleware(middleware.Options{
+		Authenticators: s.authenticators(),
+		Limiter:        s.resources.Limiter,
+	}).Then(s.GetInstance().Handler)
+
+	// Publish market events to streaming clients
+	s.resources.Hub.Start()
+
+	// Deliver market events to webhooks
+	// NOTE: Read at start up, changing `webhooks.enabled` requires a restart
+	if config.GetInstance().Webhooks.Enabled {
+		s.resources.Dispatcher.Start()
+	}
+
+	// Add missing currency pairs to the registry
+	// NOTE: Failures, such as the pairs table not having been migrated, don't stop the server
+	added, err := pairs.Seed(s.resources.Pairs, config.SplitList(config.GetInstance().Pairs.Seed))
+	if err != nil {
+		log.Error("Error seeding currency pairs: " + err.Error())
+	}
+	if len(added) > 0 {
+		log.Info(fmt.Sprintf("Added %d currency pairs", len(added)))
+	}
+
+	// Save ingested ticks, building candles from them
+	if s.resources.Recorder != nil {
+		s.resources.Aggregator.Start()
+		s.resources.Recorder.Start()
+	}
+
+	// Receive FX quotes, ingesting them into the snapshot of the latest quotes
+	// NOTE: Failures are reported by the readiness check rather than stopping the server
+	if err := s.resources.Quotes.Start(); err != nil {
+		log.Error("Error ingesting FX quotes: " + err.Error())
+	}
+	if s.resources.Provider != nil {
+		if err := s.resources.Provider.Start(); err != nil {
+			log.Error("Error starting FX quote provider: " + err.Error())
+		}
+	}
+
+	// Measure currency strength from the latest quotes, broadcasting changes to streaming clients
+	s.resources.Strength.Start()
+
+	// Evaluate price alerts against the latest quotes and market events
+	// NOTE: Read at start up, changing `alerts.enabled` requires a restart
+	if config.GetInstance().Alerts.Enabled {
+		s.resources.Evaluator.Start()
+	}
 
 	m := "Listening for requests..."
 	log.Info(m)
@@ -77,7 +216,39 @@ func (s *Server) Stop() error {
 	}
 
 	// Shut down server gracefully, but wait no longer than a configured amount of seconds before halting
-	ctx, _ := context.WithTimeout(context.Background(), time.Duration(config.GetInstance().Server.Timeouts.ShutDown)*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.GetInstance().Server.Timeouts.ShutDown)*time.Second)
+	defer cancel()
+
+	// Stop webhook deliveries first, as the dispatcher subscribes to the hub
+	if err := s.resources.Dispatcher.Close(ctx); err != nil {
+		log.Error("Error stopping webhook deliveries: " + err.Error())
+	}
+
+	// Stop measuring currency strength, as changes are broadcast to the hub
+	s.resources.Strength.Close()
+
+	// Close streams next, as connections taken over from the server aren't closed by shutting it down
+	if err := s.resources.Hub.Close(ctx); err != nil {
+		log.Error("Error closing streams: " + err.Error())
+	}
+
+	// Stop evaluating alerts, waiting for notifications in progress, before the quotes they're evaluated against stop
+	s.resources.Evaluator.Close()
+
+	// Stop receiving FX quotes
+	s.resources.Quotes.Close()
+	if s.resources.Provider != nil {
+		if err := s.resources.Provider.Close(); err != nil {
+			log.Error("Error closing FX quote provider: " + err.Error())
+		}
+	}
+
+	// Save the last ticks received, then build their candles
+	if s.resources.Recorder != nil {
+		s.resources.Recorder.Close()
+		s.resources.Aggregator.Close()
+	}
+
 	if err := s.instance.Shutdown(ctx); err != nil {
 		return err
 	}
@@ -87,6 +258,17 @@ func (s *Server) Stop() error {
 	return nil
 }
 
+// authenticators returns the request authenticators enabled within configuration
+func (s *Server) authenticators() []auth.Authenticator {
+	authenticators := []auth.Authenticator{auth.NewAPIKeyAuthenticator(s.resources.APIKeys)}
+
+	if c := config.GetInstance().Auth.JWT; auth.JWTEnabled(c) {
+		authenticators = append(authenticators, auth.NewJWTAuthenticator(c))
+	}
+
+	return authenticators
+}
+
 // GetInstance returns the internal http.Server instance of the server
 func (s *Server) GetInstance() *http.Server { return s.instance }
 
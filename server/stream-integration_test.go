@@ -0,0 +1,250 @@
+// Tests the market event stream routes
+package server
+
+import (
+	// Standard lib
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/sessions"
+	"github.com/deezone/forex-clock/stream"
+
+	// Third-party
+	"github.com/gorilla/websocket"
+	goutils "github.com/marksost/go-utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stream integration tests", func() {
+	var (
+		// Mock server to test
+		s *Server
+		// The WebSocket stream address of the server
+		streamAddress string
+	)
+
+	BeforeEach(func() {
+		// Get empty port
+		port, err := goutils.GetEmptyPort()
+		if err != nil {
+			panic("Error getting an empty port. Testing cannot continue. Error was: " + err.Error())
+		}
+
+		// Set server port and stream address
+		config.GetInstance().Server.Port = port
+		streamAddress = "ws://localhost:" + goutils.Int2String(port) + "/stream/ws"
+
+		// Create and start server
+		s = NewServer()
+		if err := s.Start(); err != nil {
+			panic("Error starting server. Testing cannot continue. Error was: " + err.Error())
+		}
+
+		// Sleep so the server can start
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	AfterEach(func() {
+		// Stop Server, unless stopped by a test
+		if s.IsRunning() {
+			s.Stop()
+		}
+	})
+
+	// dial connects to the stream, returning the connection and its subscription acknowledgement
+	dial := func(query string) (*websocket.Conn, *stream.Message) {
+		conn, _, err := websocket.DefaultDialer.Dial(streamAddress+query, nil)
+		Expect(err).To(Not(HaveOccurred()))
+
+		m := &stream.Message{}
+		Expect(conn.ReadJSON(m)).To(Succeed())
+
+		return conn, m
+	}
+
+	It("Acknowledges subscriptions and sends matching events", func() {
+		conn, ack := dial("?sessions=london")
+		defer conn.Close()
+
+		// Verify acknowledgement
+		Expect(ack.Type).To(Equal(stream.MessageSubscribed))
+		Expect(ack.Data).To(Equal(map[string]interface{}{"sessions": []interface{}{"london"}}))
+
+		// Verify only matching events are sent
+		t := time.Now().UTC().Truncate(time.Second)
+		s.resources.Hub.Publish(sessions.NewEvent(sessions.EventSessionOpen, t, "tokyo", nil))
+		s.resources.Hub.Publish(sessions.NewEvent(sessions.EventSessionOpen, t, "london", nil))
+
+		m := &stream.Message{}
+		Expect(conn.ReadJSON(m)).To(Succeed())
+		Expect(m.Type).To(Equal(sessions.EventSessionOpen))
+		Expect(m.ID).To(HaveSuffix("london"))
+		Expect(m.Time.Equal(t)).To(BeTrue())
+	})
+
+	It("Changes subscriptions requested by clients", func() {
+		conn, _ := dial("")
+		defer conn.Close()
+
+		// Verify a valid change
+		Expect(conn.WriteJSON(map[string]interface{}{"action": "subscribe", "sessions": []string{"tokyo"}})).To(Succeed())
+		m := &stream.Message{}
+		Expect(conn.ReadJSON(m)).To(Succeed())
+		Expect(m.Type).To(Equal(stream.MessageSubscribed))
+		Expect(m.Data).To(Equal(map[string]interface{}{"sessions": []interface{}{"tokyo"}}))
+
+		// Verify an invalid change
+		Expect(conn.WriteJSON(map[string]interface{}{"action": "subscribe", "sessions": []string{"mars"}})).To(Succeed())
+		m = &stream.Message{}
+		Expect(conn.ReadJSON(m)).To(Succeed())
+		Expect(m.Type).To(Equal(stream.MessageError))
+	})
+
+	It("Sends heartbeats", func() {
+		config.GetInstance().Stream.HeartbeatInterval = 1
+		defer func() { config.GetInstance().Stream.HeartbeatInterval = 30 }()
+
+		conn, _ := dial("")
+		defer conn.Close()
+
+		// Verify heartbeat
+		m := &stream.Message{}
+		Expect(conn.ReadJSON(m)).To(Succeed())
+		Expect(m.Type).To(Equal(stream.MessageHeartbeat))
+	})
+
+	It("Closes streams when the server stops", func() {
+		conn, _ := dial("")
+		defer conn.Close()
+
+		Expect(s.Stop()).To(Succeed())
+
+		// Verify close message
+		_, _, err := conn.ReadMessage()
+		Expect(websocket.IsCloseError(err, websocket.CloseGoingAway)).To(BeTrue())
+		Expect(strings.Contains(err.Error(), stream.ErrHubClosed.Error())).To(BeTrue())
+		Expect(s.resources.Hub.Len()).To(BeZero())
+	})
+})
+
+var _ = Describe("Server-sent event integration tests", func() {
+	var (
+		// Mock server to test
+		s *Server
+		// The server-sent event stream address of the server
+		eventsAddress string
+	)
+
+	BeforeEach(func() {
+		// Get empty port
+		port, err := goutils.GetEmptyPort()
+		if err != nil {
+			panic("Error getting an empty port. Testing cannot continue. Error was: " + err.Error())
+		}
+
+		// Set server port, a write timeout shorter than tests, and frequent heartbeats
+		config.GetInstance().Server.Port = port
+		config.GetInstance().Server.Timeouts.Write = 1
+		config.GetInstance().Stream.HeartbeatInterval = 1
+		eventsAddress = "http://localhost:" + goutils.Int2String(port) + "/stream/events"
+
+		// Create and start server
+		s = NewServer()
+		if err := s.Start(); err != nil {
+			panic("Error starting server. Testing cannot continue. Error was: " + err.Error())
+		}
+
+		// Sleep so the server can start
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Server.Timeouts.Write = 30
+		config.GetInstance().Stream.HeartbeatInterval = 30
+
+		// Stop Server, unless stopped by a test
+		if s.IsRunning() {
+			s.Stop()
+		}
+	})
+
+	// connect opens the stream, returning the response and a reader of its lines
+	connect := func(lastEventID string) (*http.Response, *bufio.Reader) {
+		req, err := http.NewRequest(http.MethodGet, eventsAddress, nil)
+		Expect(err).To(Not(HaveOccurred()))
+		req.Header.Set("Accept-Encoding", "gzip")
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).To(Not(HaveOccurred()))
+
+		return resp, bufio.NewReader(resp.Body)
+	}
+
+	// readUntil reads lines until one starts with a prefix, returning it
+	readUntil := func(r *bufio.Reader, prefix string) string {
+		for {
+			line, err := r.ReadString('\n')
+			Expect(err).To(Not(HaveOccurred()))
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimSpace(line)
+			}
+		}
+	}
+
+	It("Sends uncompressed events and heartbeats beyond the server's write timeout", func() {
+		resp, r := connect("")
+		defer resp.Body.Close()
+
+		// Verify headers
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+		Expect(readUntil(r, "retry:")).To(Equal("retry: 5000"))
+
+		// Verify heartbeats continue past the write timeout
+		time.Sleep(1500 * time.Millisecond)
+		Expect(readUntil(r, ": heartbeat")).To(HavePrefix(": heartbeat"))
+
+		// Verify events
+		ev := sessions.NewEvent(sessions.EventSessionOpen, time.Now().UTC(), "london", nil)
+		s.resources.Hub.Publish(ev)
+		Expect(readUntil(r, "id:")).To(Equal("id: " + ev.ID))
+		Expect(readUntil(r, "event:")).To(Equal("event: " + sessions.EventSessionOpen))
+		Expect(readUntil(r, "data:")).To(ContainSubstring(`"type":"session-open"`))
+	})
+
+	It("Resumes streams from the last event received", func() {
+		t := time.Now().UTC()
+		first := sessions.NewEvent(sessions.EventSessionOpen, t, "london", nil)
+		second := sessions.NewEvent(sessions.EventSessionClose, t, "tokyo", nil)
+		s.resources.Hub.Publish(first)
+		s.resources.Hub.Publish(second)
+
+		resp, r := connect(first.ID)
+		defer resp.Body.Close()
+
+		// Verify missed events are sent
+		Expect(readUntil(r, "id:")).To(Equal("id: " + second.ID))
+	})
+
+	It("Ends streams when the server stops", func() {
+		resp, r := connect("")
+		defer resp.Body.Close()
+		readUntil(r, "retry:")
+
+		Expect(s.Stop()).To(Succeed())
+
+		// Verify the stream ends
+		_, err := ioutil.ReadAll(r)
+		Expect(err).To(Not(HaveOccurred()))
+	})
+})
@@ -0,0 +1,214 @@
+// sessions package contains the trading session engine
+// events contains the market event model, describing every session and market transition
+package sessions
+
+import (
+	// Standard lib
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Event types
+	EventSessionOpen   = "session-open"
+	EventSessionClose  = "session-close"
+	EventOverlapStart  = "overlap-start"
+	EventOverlapEnd    = "overlap-end"
+	EventMarketOpen    = "market-open"
+	EventMarketClose   = "market-close"
+	EventHolidayClosed = "holiday-closure"
+	EventShiftStart    = "session-shift-start"
+	EventShiftEnd      = "session-shift-end"
+)
+
+var (
+	// All event types
+	EventTypes = []string{
+		EventSessionOpen, EventSessionClose, EventOverlapStart, EventOverlapEnd,
+		EventMarketOpen, EventMarketClose, EventHolidayClosed, EventShiftStart, EventShiftEnd,
+	}
+)
+
+type (
+	// Event is a struct representing a single market transition
+	Event struct {
+		ID       string    `json:"id"`                 // Unique, stable identifier of the event
+		Type     string    `json:"type"`               // Type of event (ex: "session-open")
+		Time     time.Time `json:"time"`               // When the transition occurs
+		Session  string    `json:"session,omitempty"`  // ID of the session, for session and holiday events
+		Sessions []string  `json:"sessions,omitempty"` // IDs of the overlapping or shifted sessions, for overlap and shift events
+	}
+	// Overlap is a struct representing a period during which two or more sessions are open
+	Overlap struct {
+		Sessions []string  `json:"sessions"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+	}
+)
+
+// NewEvent creates and returns a new event with a stable identifier
+func NewEvent(eventType string, t time.Time, session string, sessions []string) *Event {
+	subject := session
+	if len(sessions) > 0 {
+		subject = strings.Join(sessions, "+")
+	}
+
+	return &Event{
+		ID:       fmt.Sprintf("%d-%s-%s", t.Unix(), eventType, subject),
+		Type:     eventType,
+		Time:     t.UTC(),
+		Session:  session,
+		Sessions: sessions,
+	}
+}
+
+// Events returns all market events occurring within a time range (inclusive of `from`,
+// exclusive of `to`), sorted by time
+func (e *Engine) Events(from, to time.Time) []*Event {
+	events := make([]*Event, 0)
+	add := func(ev *Event) {
+		if !ev.Time.Before(from) && ev.Time.Before(to) {
+			events = append(events, ev)
+		}
+	}
+
+	// Weekly market open and close
+	for _, w := range e.marketWindows(from, to) {
+		add(NewEvent(EventMarketOpen, w.Start, "", nil))
+		add(NewEvent(EventMarketClose, w.End, "", nil))
+	}
+
+	// Session open, close, and holiday closures
+	// NOTE: Look back a day so overlaps already in progress at `from` are known
+	intervals := e.Intervals(from.Add(-24*time.Hour), to)
+	open := make([]*Interval, 0)
+	for _, i := range intervals {
+		if i.Holiday {
+			add(NewEvent(EventHolidayClosed, i.Start, i.Session.ID, nil))
+			continue
+		}
+		add(NewEvent(EventSessionOpen, i.Start, i.Session.ID, nil))
+		add(NewEvent(EventSessionClose, i.End, i.Session.ID, nil))
+		open = append(open, i)
+	}
+
+	// Overlaps
+	for _, o := range e.overlaps(open) {
+		add(NewEvent(EventOverlapStart, o.Start, "", o.Sessions))
+		add(NewEvent(EventOverlapEnd, o.End, "", o.Sessions))
+	}
+
+	// Session shifts, involving every city observing daylight saving time
+	for _, sh := range e.Shifts(from, to) {
+		set := map[string]int{}
+		for _, id := range sh.Changed {
+			set[id]++
+		}
+		for _, id := range sh.Pending {
+			set[id]++
+		}
+		ids := e.orderedIDs(set)
+		add(NewEvent(EventShiftStart, sh.Start, "", ids))
+		add(NewEvent(EventShiftEnd, sh.End, "", ids))
+	}
+
+	sortEvents(events)
+
+	return events
+}
+
+// Next returns the next events occurring strictly after a point in time, up to a maximum number of events
+func (e *Engine) Next(t time.Time, max int) []*Event {
+	events := e.Events(t.Add(time.Nanosecond), t.Add(lookahead))
+	if len(events) > max {
+		events = events[:max]
+	}
+
+	return events
+}
+
+// Overlaps returns all periods within a time range during which two or more sessions are open
+func (e *Engine) Overlaps(from, to time.Time) []*Overlap {
+	open := make([]*Interval, 0)
+	for _, i := range e.Intervals(from, to) {
+		if !i.Holiday {
+			open = append(open, i)
+		}
+	}
+
+	return e.overlaps(open)
+}
+
+// overlaps sweeps over session intervals and returns every period during which the same set of two or
+// more sessions are open
+func (e *Engine) overlaps(intervals []*Interval) []*Overlap {
+	type edge struct {
+		t    time.Time
+		id   string
+		open bool
+	}
+
+	// Collect interval edges, closing before opening at the same instant
+	edges := make([]edge, 0, len(intervals)*2)
+	for _, i := range intervals {
+		edges = append(edges, edge{i.Start, i.Session.ID, true}, edge{i.End, i.Session.ID, false})
+	}
+	sort.SliceStable(edges, func(a, b int) bool {
+		if edges[a].t.Equal(edges[b].t) {
+			return !edges[a].open && edges[b].open
+		}
+		return edges[a].t.Before(edges[b].t)
+	})
+
+	overlaps := make([]*Overlap, 0)
+	open := map[string]int{}
+	var current *Overlap
+	for n, ed := range edges {
+		if ed.open {
+			open[ed.id]++
+		} else if open[ed.id]--; open[ed.id] <= 0 {
+			delete(open, ed.id)
+		}
+
+		// Only evaluate once all edges at the same instant are processed
+		if n+1 < len(edges) && edges[n+1].t.Equal(ed.t) {
+			continue
+		}
+
+		ids := e.orderedIDs(open)
+		if current != nil && strings.Join(current.Sessions, ",") != strings.Join(ids, ",") {
+			current.End = ed.t
+			overlaps = append(overlaps, current)
+			current = nil
+		}
+		if current == nil && len(ids) > 1 {
+			current = &Overlap{Sessions: ids, Start: ed.t}
+		}
+	}
+
+	return overlaps
+}
+
+// orderedIDs returns the IDs of a set of sessions in definition order
+func (e *Engine) orderedIDs(set map[string]int) []string {
+	ids := make([]string, 0, len(set))
+	for _, s := range e.sessions {
+		if _, ok := set[s.ID]; ok {
+			ids = append(ids, s.ID)
+		}
+	}
+
+	return ids
+}
+
+// sortEvents sorts events by time, then ID for a stable order
+func sortEvents(events []*Event) {
+	sort.SliceStable(events, func(a, b int) bool {
+		if events[a].Time.Equal(events[b].Time) {
+			return events[a].ID < events[b].ID
+		}
+		return events[a].Time.Before(events[b].Time)
+	})
+}
@@ -0,0 +1,490 @@
+// sessions package contains the trading session engine, which determines when each of the world FOREX
+// market sessions (Sydney, Tokyo, London, New York) are open, when they overlap, and when the market as a
+// whole is open for the week
+package sessions
+
+import (
+	// Standard lib
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/tz"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Session IDs
+	SessionSydney  = "sydney"
+	SessionTokyo   = "tokyo"
+	SessionLondon  = "london"
+	SessionNewYork = "new-york"
+
+	// Format of session opening and closing times
+	ClockFormat = "15:04"
+
+	// How far ahead to look when searching for the next transitions
+	lookahead = 14 * 24 * time.Hour
+)
+
+type (
+	// Engine is a struct representing a set of trading sessions and the weekly market schedule
+	// NOTE: An engine is immutable and safe for concurrent use
+	Engine struct {
+		sessions  []*Session     // Trading sessions, in definition order
+		holidays  []*Holiday     // Market and session holidays
+		weekOpen  weekTime       // Weekly market open
+		weekClose weekTime       // Weekly market close
+		location  *time.Location // Time zone of the weekly market open and close
+		created   time.Time      // When the engine was built from configuration
+	}
+	// Session is a struct representing a single trading session
+	Session struct {
+		ID       string         `json:"id"`
+		Name     string         `json:"name"`
+		Location *time.Location `json:"-"`
+		Open     int            `json:"-"` // Local opening time, in minutes since midnight
+		Close    int            `json:"-"` // Local closing time, in minutes since midnight
+	}
+	// Holiday is a struct representing a day a session, or the whole market, is closed
+	Holiday struct {
+		Session string // ID of the closed session, empty for all sessions
+		Year    int    // Year of the holiday, 0 for holidays occurring every year
+		Month   time.Month
+		Day     int
+	}
+	// Interval is a struct representing a single occurrence of a session
+	Interval struct {
+		Session *Session
+		Start   time.Time
+		End     time.Time
+		Holiday bool // Whether the session is closed for a holiday
+	}
+	// SessionStatus is a struct representing the state of a session at a point in time
+	SessionStatus struct {
+		ID        string     `json:"id"`
+		Name      string     `json:"name"`
+		TimeZone  string     `json:"time-zone"`
+		LocalTime string     `json:"local-time"`
+		Open      bool       `json:"open"`
+		NextOpen  *time.Time `json:"next-open"`
+		NextClose *time.Time `json:"next-close"`
+	}
+	// MarketStatus is a struct representing the state of the whole market at a point in time
+	MarketStatus struct {
+		Time      time.Time        `json:"time"`
+		Open      bool             `json:"open"`
+		NextOpen  *time.Time       `json:"next-open"`
+		NextClose *time.Time       `json:"next-close"`
+		Sessions  []*SessionStatus `json:"sessions"`
+		Overlaps  [][]string       `json:"overlaps"`
+	}
+	// weekTime is a struct representing a weekday and time within a week
+	weekTime struct {
+		Weekday time.Weekday
+		Minutes int
+	}
+)
+
+var (
+	// Default trading sessions, used when none are configured
+	DefaultDefinitions = []config.SessionDefinition{
+		{ID: SessionSydney, Name: "Sydney", TimeZone: "Australia/Sydney", Open: "07:00", Close: "16:00"},
+		{ID: SessionTokyo, Name: "Tokyo", TimeZone: "Asia/Tokyo", Open: "09:00", Close: "18:00"},
+		{ID: SessionLondon, Name: "London", TimeZone: "Europe/London", Open: "08:00", Close: "17:00"},
+		{ID: SessionNewYork, Name: "New York", TimeZone: "America/New_York", Open: "08:00", Close: "17:00"},
+	}
+
+	// Engine built from the current configuration
+	engine      *Engine
+	engineMutex sync.Mutex
+)
+
+// NewEngine creates and returns a new session engine from session settings
+func NewEngine(c config.Sessions) (*Engine, error) {
+	defs := c.Definitions
+	if len(defs) == 0 {
+		defs = DefaultDefinitions
+	}
+
+	loc, err := tz.Load(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid market time zone %q: %s", c.TimeZone, err)
+	}
+
+	e := &Engine{location: loc, sessions: make([]*Session, 0, len(defs)), created: time.Now().UTC().Truncate(time.Second)}
+	if e.weekOpen, err = parseWeekTime(c.WeekOpen); err != nil {
+		return nil, err
+	}
+	if e.weekClose, err = parseWeekTime(c.WeekClose); err != nil {
+		return nil, err
+	}
+
+	// Parse session definitions
+	ids := map[string]bool{}
+	for _, d := range defs {
+		s, err := newSession(d)
+		if err != nil {
+			return nil, err
+		}
+		if ids[s.ID] {
+			return nil, errors.New("Duplicate session ID: " + s.ID)
+		}
+		ids[s.ID] = true
+		e.sessions = append(e.sessions, s)
+	}
+
+	// Parse holidays
+	for _, h := range config.SplitList(c.Holidays) {
+		holiday, err := parseHoliday(h)
+		if err != nil {
+			return nil, err
+		}
+		if holiday.Session != "" && !ids[holiday.Session] {
+			return nil, errors.New("Holiday references an unknown session: " + h)
+		}
+		e.holidays = append(e.holidays, holiday)
+	}
+
+	return e, nil
+}
+
+// GetInstance returns the session engine built from the current configuration,
+// which is rebuilt whenever session settings are reloaded
+func GetInstance() *Engine {
+	engineMutex.Lock()
+	defer engineMutex.Unlock()
+
+	if engine == nil {
+		e, err := NewEngine(config.GetInstance().Sessions)
+		if err != nil {
+			// NOTE: Falls back to defaults so the engine is always usable
+			log.WithError(err).Error("Invalid session configuration, using defaults")
+			e, _ = NewEngine(config.Sessions{Holidays: "01-01,12-25", WeekOpen: "Sun 17:00", WeekClose: "Fri 17:00", TimeZone: "America/New_York"})
+		}
+		engine = e
+	}
+
+	return engine
+}
+
+// Sessions returns the engine's trading sessions
+func (e *Engine) Sessions() []*Session { return e.sessions }
+
+// Session returns a trading session by its ID, or nil if it doesn't exist
+func (e *Engine) Session(id string) *Session {
+	for _, s := range e.sessions {
+		if s.ID == id {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// Modified returns when the engine was built, as schedules only change when configuration is reloaded
+func (e *Engine) Modified() time.Time { return e.created }
+
+// Location returns the time zone of the weekly market open and close
+func (e *Engine) Location() *time.Location { return e.location }
+
+// MarketOpen returns a boolean indicating if the market is open for the week at a point in time
+// NOTE: Doesn't take holidays into account, see `Status` for session-level detail
+func (e *Engine) MarketOpen(t time.Time) bool {
+	for _, w := range e.marketWindows(t.Add(-time.Nanosecond), t.Add(time.Nanosecond)) {
+		if !t.Before(w.Start) && t.Before(w.End) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Intervals returns all occurrences of sessions overlapping a time range, clipped to the weekly market
+// schedule and sorted by start time. Occurrences closed for a holiday are included and flagged.
+func (e *Engine) Intervals(from, to time.Time) []*Interval {
+	windows := e.marketWindows(from, to)
+	intervals := make([]*Interval, 0)
+
+	for _, s := range e.sessions {
+		for _, i := range s.occurrences(from, to) {
+			i.Holiday = e.isHoliday(s, i.Start.In(s.Location))
+
+			// Clip occurrence to market windows
+			for _, w := range windows {
+				start, end := maxTime(i.Start, w.Start), minTime(i.End, w.End)
+				if start.Before(end) {
+					intervals = append(intervals, &Interval{Session: s, Start: start, End: end, Holiday: i.Holiday})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(intervals, func(a, b int) bool { return intervals[a].Start.Before(intervals[b].Start) })
+
+	return intervals
+}
+
+// Status returns the state of the market and every session at a point in time
+func (e *Engine) Status(t time.Time) *MarketStatus {
+	status := &MarketStatus{
+		Time:     t,
+		Open:     e.MarketOpen(t),
+		Sessions: make([]*SessionStatus, 0, len(e.sessions)),
+		Overlaps: make([][]string, 0),
+	}
+
+	// Find next market transitions
+	for _, w := range e.marketWindows(t, t.Add(lookahead)) {
+		if status.NextOpen == nil && w.Start.After(t) {
+			start := w.Start
+			status.NextOpen = &start
+		}
+		if status.NextClose == nil && w.End.After(t) {
+			end := w.End
+			status.NextClose = &end
+		}
+	}
+
+	intervals := e.Intervals(t.Add(-24*time.Hour), t.Add(lookahead))
+	open := []string{}
+	for _, s := range e.sessions {
+		ss := &SessionStatus{
+			ID:        s.ID,
+			Name:      s.Name,
+			TimeZone:  s.Location.String(),
+			LocalTime: t.In(s.Location).Format(time.RFC3339),
+		}
+
+		for _, i := range intervals {
+			if i.Session != s || i.Holiday || !i.End.After(t) {
+				continue
+			}
+
+			if !i.Start.After(t) {
+				ss.Open = true
+			} else if ss.NextOpen == nil {
+				start := i.Start
+				ss.NextOpen = &start
+			}
+
+			if ss.NextClose == nil {
+				end := i.End
+				ss.NextClose = &end
+			}
+		}
+
+		if ss.Open {
+			open = append(open, s.ID)
+		}
+		status.Sessions = append(status.Sessions, ss)
+	}
+
+	if len(open) > 1 {
+		status.Overlaps = append(status.Overlaps, open)
+	}
+
+	return status
+}
+
+// newSession creates a session from its definition
+func newSession(d config.SessionDefinition) (*Session, error) {
+	if d.ID == "" {
+		return nil, errors.New("Session definitions require an ID")
+	}
+
+	loc, err := tz.Load(d.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid time zone %q for session %s: %s", d.TimeZone, d.ID, err)
+	}
+
+	open, err := parseClock(d.Open)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid open time for session %s: %s", d.ID, err)
+	}
+
+	close, err := parseClock(d.Close)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid close time for session %s: %s", d.ID, err)
+	}
+
+	name := d.Name
+	if name == "" {
+		name = d.ID
+	}
+
+	return &Session{ID: d.ID, Name: name, Location: loc, Open: open, Close: close}, nil
+}
+
+// occurrences returns the unclipped occurrences of a session, on local weekdays, overlapping a time range
+// NOTE: Sessions with a closing time before their opening time close on the following day
+func (s *Session) occurrences(from, to time.Time) []*Interval {
+	intervals := make([]*Interval, 0)
+
+	// Iterate over local dates, starting a day early to catch sessions spanning midnight
+	local := from.In(s.Location)
+	day := time.Date(local.Year(), local.Month(), local.Day()-1, 0, 0, 0, 0, s.Location)
+	for !day.After(to) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			start := atMinutes(day, s.Open)
+			end := atMinutes(day, s.Close)
+			if s.Close <= s.Open {
+				end = atMinutes(day.AddDate(0, 0, 1), s.Close)
+			}
+
+			if end.After(from) && start.Before(to) {
+				intervals = append(intervals, &Interval{Session: s, Start: start, End: end})
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return intervals
+}
+
+// marketWindows returns the periods the market is open for the week that overlap a time range
+func (e *Engine) marketWindows(from, to time.Time) []*Interval {
+	windows := make([]*Interval, 0)
+
+	// Iterate over local weeks, starting a week early to catch a window spanning the range start
+	local := from.In(e.location)
+	sunday := time.Date(local.Year(), local.Month(), local.Day()-int(local.Weekday())-7, 0, 0, 0, 0, e.location)
+	for !sunday.After(to) {
+		start := atMinutes(sunday.AddDate(0, 0, int(e.weekOpen.Weekday)), e.weekOpen.Minutes)
+		end := atMinutes(sunday.AddDate(0, 0, int(e.weekClose.Weekday)), e.weekClose.Minutes)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 7)
+		}
+
+		if end.After(from) && start.Before(to) {
+			windows = append(windows, &Interval{Start: start, End: end})
+		}
+		sunday = sunday.AddDate(0, 0, 7)
+	}
+
+	return windows
+}
+
+// isHoliday returns a boolean indicating if a session is closed on a local date
+func (e *Engine) isHoliday(s *Session, local time.Time) bool {
+	for _, h := range e.holidays {
+		if h.Session != "" && h.Session != s.ID {
+			continue
+		}
+		if (h.Year == 0 || h.Year == local.Year()) && h.Month == local.Month() && h.Day == local.Day() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseClock parses a local time (ex: "08:00") into minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse(ClockFormat, s)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseWeekTime parses a weekday and local time (ex: "Sun 17:00")
+func parseWeekTime(s string) (weekTime, error) {
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		return weekTime{}, fmt.Errorf("Invalid weekly time %q, expected a weekday and time (ex: \"Sun 17:00\")", s)
+	}
+
+	t, err := time.Parse("Mon "+ClockFormat, parts[0]+" "+parts[1])
+	if err != nil {
+		return weekTime{}, fmt.Errorf("Invalid weekly time %q: %s", s, err)
+	}
+
+	// NOTE: Parsed weekdays aren't retained by `time.Parse`, so match them by name
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String()[:3], parts[0][:3]) {
+			return weekTime{Weekday: d, Minutes: t.Hour()*60 + t.Minute()}, nil
+		}
+	}
+
+	return weekTime{}, fmt.Errorf("Invalid weekday in weekly time %q", s)
+}
+
+// parseHoliday parses a holiday (ex: "12-25", "2024-03-29" or "london:12-26")
+func parseHoliday(s string) (*Holiday, error) {
+	h := &Holiday{}
+	if i := strings.Index(s, ":"); i >= 0 {
+		h.Session, s = s[:i], s[i+1:]
+	}
+
+	layout := "01-02"
+	if strings.Count(s, "-") == 2 {
+		layout = "2006-01-02"
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid holiday %q: %s", s, err)
+	}
+
+	if layout != "01-02" {
+		h.Year = t.Year()
+	}
+	h.Month, h.Day = t.Month(), t.Day()
+
+	return h, nil
+}
+
+// atMinutes returns a local time on a date at a number of minutes since midnight
+func atMinutes(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location())
+}
+
+// maxTime returns the later of two times
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+// minTime returns the earlier of two times
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+func init() {
+	// Rebuild the engine when session settings are reloaded
+	config.Subscribe(func(prev, next *config.Config, changed []string) {
+		for _, key := range changed {
+			if !strings.HasPrefix(key, "sessions.") {
+				continue
+			}
+
+			e, err := NewEngine(next.Sessions)
+			if err != nil {
+				log.WithError(err).Error("Invalid reloaded session configuration, keeping current sessions")
+				return
+			}
+
+			engineMutex.Lock()
+			engine = e
+			engineMutex.Unlock()
+			log.Info("Session definitions reloaded")
+			return
+		}
+	})
+}
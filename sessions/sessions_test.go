@@ -0,0 +1,149 @@
+// Tests the sessions.go and events.go files
+package sessions
+
+import (
+	// Standard lib
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sessions.go", func() {
+	var (
+		// Engine to test
+		e *Engine
+		// Session settings to build the engine from
+		c config.Sessions
+	)
+
+	BeforeEach(func() {
+		// Set default settings
+		c = config.Sessions{Holidays: "01-01,12-25,london:12-26", WeekOpen: "Sun 17:00", WeekClose: "Fri 17:00", TimeZone: "America/New_York"}
+
+		// Create engine
+		var err error
+		e, err = NewEngine(c)
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	Describe("`NewEngine` method", func() {
+		Context("When settings are invalid", func() {
+			It("Returns an error", func() {
+				// Set invalid settings
+				c.Definitions = []config.SessionDefinition{{ID: "mars", TimeZone: "Mars/Olympus_Mons", Open: "08:00", Close: "17:00"}}
+
+				// Call method
+				_, err := NewEngine(c)
+
+				// Verify return value
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("When no definitions are configured", func() {
+			It("Uses the default sessions", func() {
+				// Verify sessions
+				Expect(e.Sessions()).To(HaveLen(len(DefaultDefinitions)))
+				Expect(e.Session(SessionLondon)).To(Not(BeNil()))
+			})
+		})
+	})
+
+	Describe("`MarketOpen` method", func() {
+		It("Returns whether the market is open for the week", func() {
+			// Friday 16:59 and 17:00 in New York (EDT)
+			Expect(e.MarketOpen(time.Date(2024, 6, 7, 20, 59, 0, 0, time.UTC))).To(BeTrue())
+			Expect(e.MarketOpen(time.Date(2024, 6, 7, 21, 0, 0, 0, time.UTC))).To(BeFalse())
+
+			// Saturday
+			Expect(e.MarketOpen(time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC))).To(BeFalse())
+
+			// Sunday 17:00 in New York (EDT)
+			Expect(e.MarketOpen(time.Date(2024, 6, 9, 21, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+	})
+
+	Describe("`Status` method", func() {
+		It("Returns the state of every session", func() {
+			// Wednesday 13:30 UTC, London and New York are both open
+			s := e.Status(time.Date(2024, 6, 5, 13, 30, 0, 0, time.UTC))
+
+			// Verify return value
+			Expect(s.Open).To(BeTrue())
+			open := map[string]bool{}
+			for _, ss := range s.Sessions {
+				open[ss.ID] = ss.Open
+			}
+			Expect(open).To(Equal(map[string]bool{SessionSydney: false, SessionTokyo: false, SessionLondon: true, SessionNewYork: true}))
+			Expect(s.Overlaps).To(Equal([][]string{{SessionLondon, SessionNewYork}}))
+
+			// London (BST) closes at 16:00 UTC
+			Expect(s.Sessions[2].NextClose.UTC()).To(Equal(time.Date(2024, 6, 5, 16, 0, 0, 0, time.UTC)))
+		})
+
+		It("Clips sessions to the weekly market open", func() {
+			// Sunday in January, Sydney (AEDT) opens at 20:00 UTC but the market opens at 22:00 UTC (EST)
+			s := e.Status(time.Date(2024, 1, 14, 12, 0, 0, 0, time.UTC))
+
+			// Verify return value
+			Expect(s.Sessions[0].NextOpen.UTC()).To(Equal(time.Date(2024, 1, 14, 22, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Describe("`Events` method", func() {
+		It("Returns holiday closures instead of session events", func() {
+			// Call method for Boxing Day 2024
+			events := e.Events(time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC))
+
+			// Verify London is closed
+			types := map[string]string{}
+			for _, ev := range events {
+				if ev.Session == SessionLondon {
+					types[ev.Type] = ev.Session
+				}
+			}
+			Expect(types).To(HaveKey(EventHolidayClosed))
+			Expect(types).To(Not(HaveKey(EventSessionOpen)))
+		})
+
+		It("Returns overlaps", func() {
+			// Call method for a Wednesday
+			events := e.Events(time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 6, 0, 0, 0, 0, time.UTC))
+
+			// Find the London / New York overlap
+			var start, end *Event
+			for _, ev := range events {
+				if len(ev.Sessions) == 2 && ev.Sessions[0] == SessionLondon && ev.Sessions[1] == SessionNewYork {
+					if ev.Type == EventOverlapStart {
+						start = ev
+					} else {
+						end = ev
+					}
+				}
+			}
+
+			// Verify overlap runs 12:00 to 16:00 UTC
+			Expect(start).To(Not(BeNil()))
+			Expect(end).To(Not(BeNil()))
+			Expect(start.Time).To(Equal(time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)))
+			Expect(end.Time).To(Equal(time.Date(2024, 6, 5, 16, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Describe("`Next` method", func() {
+		It("Returns the next events in order", func() {
+			// Call method on a Saturday
+			events := e.Next(time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC), 2)
+
+			// Verify return value
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].Type).To(Equal(EventMarketOpen))
+			Expect(events[0].Time).To(Equal(time.Date(2024, 6, 9, 21, 0, 0, 0, time.UTC)))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+// sessions package contains the trading session engine
+// shifts contains the daylight saving time transitions of session cities, and the "session shifts" they cause:
+// periods during which cities observing daylight saving time disagree about the season (ex: the weeks each year
+// when New York has changed its clocks but London hasn't), so sessions and their overlaps move relative to each
+// other by an hour
+package sessions
+
+import (
+	// Standard lib
+	"sort"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/tz"
+)
+
+const (
+	// Time before and after a range searched for the changes that start and end shifts within it
+	// NOTE: Shifts longer than this are cut short, which no pair of session cities has
+	shiftMargin = 62 * 24 * time.Hour
+)
+
+type (
+	// ClockChange is a struct representing a change to the UTC offset of a session's city
+	ClockChange struct {
+		Session  string `json:"session"`
+		TimeZone string `json:"time-zone"`
+		// Embedded field
+		*tz.Transition
+	}
+	// Shift is a struct representing a period during which session cities disagree about the season
+	Shift struct {
+		Start   time.Time         `json:"start"`
+		End     time.Time         `json:"end"`
+		Changed []string          `json:"changed"` // Sessions whose clocks have changed for the coming season
+		Pending []string          `json:"pending"` // Sessions whose clocks are yet to change
+		Offsets map[string]string `json:"offsets"` // UTC offset of every session's city during the shift
+	}
+)
+
+// ClockChanges returns every change to the UTC offset of a session's city after a point in time (`from`) and
+// before another (`to`), sorted by time, then session definition order
+func (e *Engine) ClockChanges(from, to time.Time) []*ClockChange {
+	changes := make([]*ClockChange, 0)
+	for _, s := range e.sessions {
+		for _, t := range tz.Transitions(s.Location, from, to)[1:] {
+			changes = append(changes, &ClockChange{Session: s.ID, TimeZone: s.Location.String(), Transition: t})
+		}
+	}
+
+	sort.SliceStable(changes, func(a, b int) bool { return changes[a].At.Before(changes[b].At) })
+
+	return changes
+}
+
+// Shifts returns every session shift occurring within a time range, including those in progress at its start.
+// Shifts end whenever a city's clocks change, so the sessions and offsets of each are constant throughout
+func (e *Engine) Shifts(from, to time.Time) []*Shift {
+	shifts := make([]*Shift, 0)
+
+	var (
+		current *Shift
+		coming  bool // Whether the coming season is northern hemisphere summer
+	)
+	changes := e.ClockChanges(from.Add(-shiftMargin), to.Add(shiftMargin))
+	for n, c := range changes {
+		// Only evaluate once all changes at the same instant are known
+		if n+1 < len(changes) && changes[n+1].At.Equal(c.At) {
+			continue
+		}
+
+		if current != nil {
+			current.End = c.At.UTC()
+			if current.End.After(from) {
+				shifts = append(shifts, current)
+			}
+			current = nil
+		}
+		if !c.At.Before(to) {
+			break
+		}
+
+		// Sessions agreeing with the latest city to change clocks for daylight saving time have changed for the
+		// coming season
+		seasons := e.seasons(c.At)
+		for m := n; m >= 0 && changes[m].At.Equal(c.At); m-- {
+			if summer, ok := seasons[changes[m].Session]; ok {
+				coming = summer
+			}
+		}
+
+		current = &Shift{Start: c.At.UTC(), Changed: []string{}, Pending: []string{}, Offsets: map[string]string{}}
+		for _, s := range e.sessions {
+			current.Offsets[s.ID] = c.At.In(s.Location).Format("-07:00")
+
+			summer, ok := seasons[s.ID]
+			switch {
+			case !ok:
+				// Cities not observing daylight saving time don't take part
+			case summer == coming:
+				current.Changed = append(current.Changed, s.ID)
+			default:
+				current.Pending = append(current.Pending, s.ID)
+			}
+		}
+
+		// Cities agreeing about the season aren't a shift
+		if len(current.Changed) == 0 || len(current.Pending) == 0 {
+			current = nil
+		}
+	}
+
+	return shifts
+}
+
+// seasons returns whether it's northern hemisphere summer according to the clocks of every session city
+// observing daylight saving time, by session ID
+func (e *Engine) seasons(t time.Time) map[string]bool {
+	seasons := map[string]bool{}
+	for _, s := range e.sessions {
+		local := t.In(s.Location)
+		january := time.Date(local.Year(), time.January, 1, 12, 0, 0, 0, s.Location)
+		july := time.Date(local.Year(), time.July, 1, 12, 0, 0, 0, s.Location)
+		if !january.IsDST() && !july.IsDST() {
+			continue
+		}
+
+		// Southern hemisphere cities observe daylight saving time during northern winter
+		seasons[s.ID] = local.IsDST() != january.IsDST()
+	}
+
+	return seasons
+}
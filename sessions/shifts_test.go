@@ -0,0 +1,119 @@
+// Tests the shifts.go file
+package sessions
+
+import (
+	// Standard lib
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("shifts.go", func() {
+	var (
+		// Engine to test
+		e *Engine
+		// Range of tests, the year 2024
+		from = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to   = from.AddDate(1, 0, 0)
+	)
+
+	BeforeEach(func() {
+		var err error
+		e, err = NewEngine(config.Sessions{WeekOpen: "Sun 17:00", WeekClose: "Fri 17:00", TimeZone: "America/New_York"})
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	Describe("`ClockChanges` method", func() {
+		It("Returns the clock changes of every session city in order", func() {
+			changes := e.ClockChanges(from, to)
+
+			// Verify return value
+			sessions := []string{}
+			for _, c := range changes {
+				sessions = append(sessions, c.Session)
+			}
+			Expect(sessions).To(Equal([]string{SessionNewYork, SessionLondon, SessionSydney, SessionSydney, SessionLondon, SessionNewYork}))
+
+			Expect(changes[0].TimeZone).To(Equal("America/New_York"))
+			Expect(changes[0].At).To(Equal(time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)))
+			Expect(changes[0].UTCOffset).To(Equal("-04:00"))
+			Expect(changes[0].Abbreviation).To(Equal("EDT"))
+			Expect(changes[0].DST).To(BeTrue())
+			Expect(changes[2].At).To(Equal(time.Date(2024, 4, 6, 16, 0, 0, 0, time.UTC)))
+			Expect(changes[2].DST).To(BeFalse())
+		})
+	})
+
+	Describe("`Shifts` method", func() {
+		It("Returns the periods session cities disagree about the season", func() {
+			shifts := e.Shifts(from, to)
+
+			// Verify return value
+			Expect(shifts).To(HaveLen(4))
+
+			// New York changes clocks three weeks before London, and Sydney a week after it
+			Expect(shifts[0].Start).To(Equal(time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)))
+			Expect(shifts[0].End).To(Equal(time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC)))
+			Expect(shifts[0].Changed).To(Equal([]string{SessionNewYork}))
+			Expect(shifts[0].Pending).To(Equal([]string{SessionSydney, SessionLondon}))
+			Expect(shifts[0].Offsets).To(Equal(map[string]string{
+				SessionSydney: "+11:00", SessionTokyo: "+09:00", SessionLondon: "+00:00", SessionNewYork: "-04:00",
+			}))
+			Expect(shifts[1].Start).To(Equal(shifts[0].End))
+			Expect(shifts[1].Changed).To(Equal([]string{SessionLondon, SessionNewYork}))
+			Expect(shifts[1].Pending).To(Equal([]string{SessionSydney}))
+
+			// Sydney changes clocks three weeks before London, and New York a week after it
+			Expect(shifts[2].Start).To(Equal(time.Date(2024, 10, 5, 16, 0, 0, 0, time.UTC)))
+			Expect(shifts[2].Changed).To(Equal([]string{SessionSydney}))
+			Expect(shifts[3].End).To(Equal(time.Date(2024, 11, 3, 6, 0, 0, 0, time.UTC)))
+			Expect(shifts[3].Changed).To(Equal([]string{SessionSydney, SessionLondon}))
+			Expect(shifts[3].Pending).To(Equal([]string{SessionNewYork}))
+		})
+
+		It("Returns shifts in progress at the start of the range", func() {
+			shifts := e.Shifts(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 21, 0, 0, 0, 0, time.UTC))
+
+			// Verify return value
+			Expect(shifts).To(HaveLen(1))
+			Expect(shifts[0].Start).To(Equal(time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)))
+		})
+
+		It("Returns no shifts when session cities agree", func() {
+			e, _ = NewEngine(config.Sessions{
+				WeekOpen: "Sun 17:00", WeekClose: "Fri 17:00", TimeZone: "America/New_York",
+				Definitions: []config.SessionDefinition{
+					{ID: "london", TimeZone: "Europe/London", Open: "08:00", Close: "17:00"},
+					{ID: "frankfurt", TimeZone: "Europe/Berlin", Open: "07:00", Close: "16:00"},
+					{ID: "tokyo", TimeZone: "Asia/Tokyo", Open: "09:00", Close: "18:00"},
+				},
+			})
+
+			// Verify return value
+			Expect(e.ClockChanges(from, to)).To(HaveLen(4))
+			Expect(e.Shifts(from, to)).To(BeEmpty())
+		})
+
+		It("Is sent as market events", func() {
+			events := e.Events(time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC))
+
+			// Find the shift
+			var shift *Event
+			for _, ev := range events {
+				if ev.Type == EventShiftStart {
+					shift = ev
+				}
+			}
+
+			// Verify event
+			Expect(shift).To(Not(BeNil()))
+			Expect(shift.Time).To(Equal(time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)))
+			Expect(shift.Sessions).To(Equal([]string{SessionSydney, SessionLondon, SessionNewYork}))
+		})
+	})
+})
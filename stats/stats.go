@@ -0,0 +1,240 @@
+// stats package contains per-session volatility and range statistics of currency pairs, answering when each pair's
+// market is moving. Statistics are computed from stored candles over a lookback period, within every occurrence of
+// each trading session and each overlap of sessions, and sessions are ranked by how far prices move per hour
+package stats
+
+import (
+	// Standard lib
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/cache"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/pairs"
+	"github.com/deezone/forex-clock/sessions"
+)
+
+const (
+	// Interval of the candles statistics are computed from
+	CandleInterval = "5m"
+
+	// Separator of the session IDs of overlaps (ex: "london+new-york")
+	overlapSeparator = "+"
+)
+
+type (
+	// PairStats is a struct representing the statistics of every session of a single currency pair
+	PairStats struct {
+		Pair       string          `json:"pair"`
+		From       time.Time       `json:"from"`        // Start of the lookback period
+		To         time.Time       `json:"to"`          // End of the lookback period
+		Lookback   int             `json:"lookback"`    // Number of days within the lookback period
+		MostActive string          `json:"most-active"` // Session ranked first, empty without candles
+		Sessions   []*SessionStats `json:"sessions"`    // Sessions and overlaps, ranked most active first
+	}
+	// SessionStats is a struct representing the statistics of a pair within a single session or overlap
+	SessionStats struct {
+		Session      string   `json:"session"`        // ID of the session, or IDs of overlapping sessions joined by "+"
+		Sessions     []string `json:"sessions"`       // IDs of the open sessions
+		Overlap      bool     `json:"overlap"`        // Whether two or more sessions are open
+		Rank         int      `json:"rank"`           // Position when ranked by range per hour, from 1
+		Occurrences  int      `json:"occurrences"`    // Number of occurrences with candles
+		Hours        float64  `json:"hours"`          // Average length of occurrences
+		AverageRange float64  `json:"average-range"`  // Average high to low range of occurrences, in pips
+		RangePerHour float64  `json:"range-per-hour"` // Average range divided by average length, in pips
+		ATR          float64  `json:"atr"`            // Average true range of occurrences, in pips
+		Ticks        int      `json:"ticks"`          // Number of ticks within every occurrence
+		AverageTicks float64  `json:"average-ticks"`  // Average number of ticks within an occurrence
+		Volatility   float64  `json:"volatility"`     // Average realized volatility of occurrences, in percent
+	}
+	// Service is a struct representing the computation of statistics from stored candles
+	Service struct {
+		store db.CandleStore
+		cache *cache.Cache
+	}
+	// Struct representing the open, high, low, and close of a single occurrence of a session
+	bar struct {
+		open, high, low, close float64
+		ticks                  int
+		variance               float64 // Sum of squared log returns between candles
+		hours                  float64
+	}
+)
+
+// NewService creates and returns a new instance of a service computing statistics from stored candles, caching them
+func NewService(store db.CandleStore, c *cache.Cache) *Service {
+	return &Service{store: store, cache: c}
+}
+
+// Pair returns the statistics of a pair over a number of days up to a point in time
+// NOTE: Statistics are cached, so the lookback period may end up to `stats.cache-ttl` seconds before `to`
+func (s *Service) Pair(pair string, lookback int, to time.Time) (*PairStats, error) {
+	p, err := pairs.New(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps *PairStats
+	policy := cache.Policy{TTL: time.Duration(config.GetInstance().Stats.CacheTTL) * time.Second}
+	err = s.cache.Fetch(p.Symbol+":"+strconv.Itoa(lookback), policy, &ps, func() (interface{}, error) {
+		from := to.AddDate(0, 0, -lookback)
+		list, err := s.store.List(db.CandleFilter{Pair: p.Symbol, Interval: CandleInterval, From: from, To: to})
+		if err != nil {
+			return nil, err
+		}
+
+		ps := Compute(sessions.GetInstance(), list, p.PipSize, from, to)
+		ps.Pair, ps.Lookback = p.Symbol, lookback
+
+		return ps, nil
+	})
+
+	return ps, err
+}
+
+// Compute returns the statistics of every session and overlap of an engine within a time range, from a pair's
+// candles and pip size
+// NOTE: Occurrences closed for a holiday, or without candles, aren't counted
+func Compute(e *sessions.Engine, candles []*db.Candle, pip float64, from, to time.Time) *PairStats {
+	candles = append([]*db.Candle{}, candles...)
+	sort.SliceStable(candles, func(a, b int) bool { return candles[a].OpenTime.Before(candles[b].OpenTime) })
+
+	// Collect occurrences of every session, then every overlap
+	windows, order := map[string][]*sessions.Interval{}, []string{}
+	add := func(id string, i *sessions.Interval) {
+		if _, ok := windows[id]; !ok {
+			windows[id], order = []*sessions.Interval{}, append(order, id)
+		}
+		if i != nil {
+			windows[id] = append(windows[id], i)
+		}
+	}
+	for _, s := range e.Sessions() {
+		add(s.ID, nil)
+	}
+	for _, i := range e.Intervals(from, to) {
+		if !i.Holiday {
+			add(i.Session.ID, i)
+		}
+	}
+	for _, o := range e.Overlaps(from, to) {
+		add(strings.Join(o.Sessions, overlapSeparator), &sessions.Interval{Start: o.Start, End: o.End})
+	}
+
+	ps := &PairStats{From: from, To: to, Sessions: make([]*SessionStats, 0, len(order))}
+	for _, id := range order {
+		ss := summarize(windows[id], candles, pip, from, to)
+		ss.Session, ss.Sessions = id, strings.Split(id, overlapSeparator)
+		ss.Overlap = len(ss.Sessions) > 1
+		ps.Sessions = append(ps.Sessions, ss)
+	}
+
+	// Rank sessions by range per hour, then by number of ticks
+	sort.SliceStable(ps.Sessions, func(a, b int) bool {
+		sa, sb := ps.Sessions[a], ps.Sessions[b]
+		if sa.RangePerHour != sb.RangePerHour {
+			return sa.RangePerHour > sb.RangePerHour
+		}
+		return sa.Ticks > sb.Ticks
+	})
+	for n, ss := range ps.Sessions {
+		ss.Rank = n + 1
+	}
+	if len(ps.Sessions) > 0 && ps.Sessions[0].Occurrences > 0 {
+		ps.MostActive = ps.Sessions[0].Session
+	}
+
+	return ps
+}
+
+// summarize returns the statistics of a pair's candles within occurrences of a session, clipped to a time range
+func summarize(windows []*sessions.Interval, candles []*db.Candle, pip float64, from, to time.Time) *SessionStats {
+	ss := &SessionStats{}
+
+	var prev *bar
+	var hours, ranges, trueRanges, volatility float64
+	for _, w := range windows {
+		start, end := w.Start, w.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+
+		b := newBar(candles, start, end)
+		if b == nil {
+			continue
+		}
+
+		// True range includes any gap from the close of the previous occurrence
+		tr := b.high - b.low
+		if prev != nil {
+			tr = math.Max(tr, math.Max(math.Abs(b.high-prev.close), math.Abs(b.low-prev.close)))
+		}
+
+		ss.Occurrences++
+		ss.Ticks += b.ticks
+		hours += b.hours
+		ranges += (b.high - b.low) / pip
+		trueRanges += tr / pip
+		volatility += math.Sqrt(b.variance) * 100
+		prev = b
+	}
+
+	if ss.Occurrences == 0 {
+		return ss
+	}
+
+	n := float64(ss.Occurrences)
+	ss.Hours = round(hours/n, 2)
+	ss.AverageRange = round(ranges/n, 1)
+	ss.ATR = round(trueRanges/n, 1)
+	ss.AverageTicks = round(float64(ss.Ticks)/n, 1)
+	ss.Volatility = round(volatility/n, 4)
+	if hours > 0 {
+		ss.RangePerHour = round(ranges/hours, 1)
+	}
+
+	return ss
+}
+
+// newBar returns the bar of the candles opening within a time range, or nil if there are none
+func newBar(candles []*db.Candle, start, end time.Time) *bar {
+	first := sort.Search(len(candles), func(n int) bool { return !candles[n].OpenTime.Before(start) })
+	if first == len(candles) || !candles[first].OpenTime.Before(end) {
+		return nil
+	}
+
+	c := candles[first]
+	b := &bar{open: c.Open, high: c.High, low: c.Low, hours: end.Sub(start).Hours()}
+	last := c.Open
+	for _, c := range candles[first:] {
+		if !c.OpenTime.Before(end) {
+			break
+		}
+
+		b.high, b.low = math.Max(b.high, c.High), math.Min(b.low, c.Low)
+		b.close = c.Close
+		b.ticks += c.Ticks
+		if last > 0 && c.Close > 0 {
+			r := math.Log(c.Close / last)
+			b.variance += r * r
+		}
+		last = c.Close
+	}
+
+	return b
+}
+
+// round rounds a number to a number of decimal places
+func round(n float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+
+	return math.Round(n*p) / p
+}
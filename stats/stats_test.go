@@ -0,0 +1,171 @@
+// Tests the stats.go file
+package stats
+
+import (
+	// Standard lib
+	"errors"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/cache"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/sessions"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing in-memory candle storage, used throughout tests
+	memoryCandleStore struct {
+		candles []*db.Candle
+		lists   int   // Number of times candles were listed
+		err     error // Error returned by every method, if set
+	}
+)
+
+func (s *memoryCandleStore) Save(candles []*db.Candle) error { return s.err }
+
+func (s *memoryCandleStore) List(f db.CandleFilter) ([]*db.Candle, error) {
+	s.lists++
+	return s.candles, s.err
+}
+
+// day returns five-minute candles of EURUSD over a day, ranging two pips, or twenty during the London and
+// New York overlap
+func day(start time.Time) []*db.Candle {
+	candles := []*db.Candle{}
+	for t := start; t.Before(start.Add(24 * time.Hour)); t = t.Add(5 * time.Minute) {
+		half := 0.0001
+		if h := t.Hour(); h >= 13 && h < 17 {
+			half = 0.001
+		}
+		candles = append(candles, &db.Candle{
+			Pair: "EURUSD", Interval: CandleInterval, OpenTime: t, CloseTime: t.Add(5 * time.Minute),
+			Open: 1.1, High: 1.1 + half, Low: 1.1 - half, Close: 1.1, Ticks: 10,
+		})
+	}
+	return candles
+}
+
+var _ = Describe("stats.go", func() {
+	var (
+		e    *sessions.Engine
+		from time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		e, err = sessions.NewEngine(config.Sessions{WeekOpen: "Sun 17:00", WeekClose: "Fri 17:00", TimeZone: "America/New_York"})
+		Expect(err).To(Not(HaveOccurred()))
+
+		// NOTE: A Wednesday during winter, so London opens at 08:00 UTC and New York at 13:00 UTC
+		from = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("Computes and ranks the statistics of every session and overlap", func() {
+		ps := Compute(e, day(from), 0.0001, from, from.Add(24*time.Hour))
+
+		// Verify output
+		Expect(ps.MostActive).To(Equal("london+new-york"))
+		ids := []string{}
+		for _, ss := range ps.Sessions {
+			ids = append(ids, ss.Session)
+		}
+		Expect(ids).To(ConsistOf("sydney", "tokyo", "london", "new-york", "sydney+tokyo", "tokyo+london", "london+new-york", "sydney+new-york"))
+
+		top := ps.Sessions[0]
+		Expect(top.Rank).To(Equal(1))
+		Expect(top.Overlap).To(BeTrue())
+		Expect(top.Sessions).To(Equal([]string{"london", "new-york"}))
+		Expect(top.Occurrences).To(Equal(1))
+		Expect(top.Hours).To(Equal(4.0))
+		Expect(top.AverageRange).To(Equal(20.0))
+		Expect(top.RangePerHour).To(Equal(5.0))
+		Expect(top.ATR).To(Equal(20.0))
+		Expect(top.Ticks).To(Equal(480))
+		Expect(top.Volatility).To(Equal(0.0))
+
+		// NOTE: Sydney opens twice within the day, each occurrence clipped to the range
+		for _, ss := range ps.Sessions {
+			switch ss.Session {
+			case "tokyo":
+				Expect(ss.AverageRange).To(Equal(2.0))
+				Expect(ss.Hours).To(Equal(9.0))
+				Expect(ss.Overlap).To(BeFalse())
+			case "sydney":
+				Expect(ss.Occurrences).To(Equal(2))
+				Expect(ss.Hours).To(Equal(4.5))
+				Expect(ss.AverageTicks).To(Equal(540.0))
+			}
+		}
+	})
+
+	It("Counts gaps between occurrences within the average true range", func() {
+		candles := day(from)
+		for _, c := range day(from.Add(24 * time.Hour)) {
+			c.Open, c.High, c.Low, c.Close = c.Open+0.005, c.High+0.005, c.Low+0.005, c.Close+0.005
+			candles = append(candles, c)
+		}
+
+		ps := Compute(e, candles, 0.0001, from, from.Add(48*time.Hour))
+
+		// Verify output
+		for _, ss := range ps.Sessions {
+			if ss.Session == "tokyo" {
+				Expect(ss.Occurrences).To(Equal(2))
+				Expect(ss.AverageRange).To(Equal(2.0))
+				Expect(ss.ATR).To(Equal(26.5))
+			}
+		}
+	})
+
+	It("Computes realized volatility from log returns between candles", func() {
+		candles := []*db.Candle{
+			{OpenTime: from.Add(10 * time.Hour), Open: 1.0, High: 1.01, Low: 1.0, Close: 1.01},
+			{OpenTime: from.Add(10*time.Hour + 5*time.Minute), Open: 1.01, High: 1.01, Low: 0.99, Close: 1.0},
+		}
+
+		ps := Compute(e, candles, 0.0001, from, from.Add(24*time.Hour))
+
+		// Verify output
+		Expect(ps.MostActive).To(Equal("london"))
+		Expect(ps.Sessions[0].Volatility).To(BeNumerically("~", 1.4072, 0.0001))
+	})
+
+	It("Leaves sessions without candles unranked as most active", func() {
+		ps := Compute(e, nil, 0.0001, from, from.Add(24*time.Hour))
+
+		// Verify output
+		Expect(ps.MostActive).To(BeEmpty())
+		Expect(ps.Sessions).To(HaveLen(8))
+		Expect(ps.Sessions[0].Occurrences).To(BeZero())
+	})
+
+	It("Computes statistics of a pair from stored candles, caching them", func() {
+		store := &memoryCandleStore{candles: day(from)}
+		s := NewService(store, cache.New("stats-test", cache.NewMemoryBackend(10)))
+
+		ps, err := s.Pair("eur/usd", 1, from.Add(24*time.Hour))
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Verify output
+		Expect(ps.Pair).To(Equal("EURUSD"))
+		Expect(ps.Lookback).To(Equal(1))
+		Expect(ps.From).To(Equal(from))
+		Expect(ps.MostActive).To(Equal("london+new-york"))
+
+		_, err = s.Pair("EURUSD", 1, from.Add(24*time.Hour))
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(store.lists).To(Equal(1))
+
+		// Verify errors
+		_, err = s.Pair("EURXYZ", 1, from)
+		Expect(err).To(HaveOccurred())
+		store.err = errors.New("Unreachable")
+		_, err = s.Pair("USDJPY", 1, from)
+		Expect(err).To(HaveOccurred())
+	})
+})
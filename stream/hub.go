@@ -0,0 +1,381 @@
+// stream package contains the hub publishing market events to streaming clients as they occur.
+// Clients subscribe to all events or to events of specific sessions, and are disconnected if they fall behind.
+// Market-wide messages, such as changes of currency strength, are broadcast to every client.
+// Recent events are kept so clients can resume from the last event they received
+package stream
+
+import (
+	// Standard lib
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/helpers"
+	"github.com/deezone/forex-clock/sessions"
+)
+
+const (
+	// Message types other than market event types
+	MessageHeartbeat  = "heartbeat"
+	MessageSubscribed = "subscribed"
+	MessageError      = "error"
+
+	// Longest time the hub waits before checking for events again, so reloaded sessions are picked up
+	maxWait = time.Minute
+)
+
+var (
+	// Errors ending subscriptions
+	ErrSlowConsumer = errors.New("Client fell too far behind")
+	ErrHubClosed    = errors.New("Server is shutting down")
+)
+
+type (
+	// Message is a struct representing a single message sent to streaming clients
+	Message struct {
+		ID   string      `json:"id,omitempty"`   // ID of the market event, empty for other messages
+		Type string      `json:"type"`           // Type of market event (ex: "session-open"), or other message
+		Time time.Time   `json:"time"`           // When the event occurred, or the message was sent
+		Data interface{} `json:"data,omitempty"` // Payload of the message
+	}
+	// SubscribedData is a struct representing the payload of messages acknowledging a subscription
+	SubscribedData struct {
+		Sessions []string `json:"sessions"` // Sessions subscribed to, empty for all
+	}
+	// ErrorData is a struct representing the payload of messages describing invalid client requests
+	ErrorData struct {
+		Errors []*helpers.Error `json:"errors"`
+	}
+	// Subscriber is a struct representing a single client receiving messages from the hub
+	Subscriber struct {
+		messages chan *Message
+		mutex    sync.Mutex
+		filter   map[string]bool // Sessions subscribed to, empty for all
+		err      error           // Why the subscription ended
+		closed   bool
+	}
+	// Hub is a struct representing the publisher of market events to subscribers
+	Hub struct {
+		mutex       sync.Mutex
+		subscribers map[*Subscriber]bool
+		stop        chan struct{} // Closed to stop publishing, nil when not running
+		done        chan struct{} // Closed once publishing stops
+		drained     chan struct{} // Closed once the last subscriber leaves while closing
+		closed      bool          // Whether new subscriptions end immediately
+		replay      []*Message    // Recently published messages, oldest first
+	}
+)
+
+// NewMessage creates and returns a new message describing a market event
+func NewMessage(ev *sessions.Event) *Message {
+	return &Message{ID: ev.ID, Type: ev.Type, Time: ev.Time, Data: ev}
+}
+
+// NewHub creates and returns a new instance of a hub
+func NewHub() *Hub {
+	return &Hub{subscribers: map[*Subscriber]bool{}}
+}
+
+// Start starts publishing market events as they occur
+func (h *Hub) Start() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.stop != nil {
+		return
+	}
+
+	h.stop, h.done = make(chan struct{}), make(chan struct{})
+	h.closed, h.drained = false, nil
+	go h.run(h.stop, h.done)
+}
+
+// Close stops publishing, ends every subscription, and waits for subscribers to leave or a context to be done
+func (h *Hub) Close(ctx context.Context) error {
+	h.mutex.Lock()
+	stop, done := h.stop, h.done
+	h.stop, h.closed = nil, true
+	h.mutex.Unlock()
+
+	// NOTE: Stopped without holding the mutex, as publishing requires it
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	h.mutex.Lock()
+	for s := range h.subscribers {
+		s.close(ErrHubClosed)
+	}
+
+	drained := make(chan struct{})
+	if len(h.subscribers) == 0 {
+		close(drained)
+	} else {
+		h.drained = drained
+	}
+	h.mutex.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe adds a subscriber receiving events of a set of sessions, or all events if none are given
+func (h *Hub) Subscribe(ids []string) *Subscriber { return h.SubscribeFrom(ids, "") }
+
+// SubscribeFrom adds a subscriber receiving events of a set of sessions, or all events if none are given,
+// first receiving recent events published after the event with an ID
+// NOTE: If the event is no longer kept, recent events occurring after its time are received
+func (h *Hub) SubscribeFrom(ids []string, lastEventID string) *Subscriber {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	missed := h.since(lastEventID)
+	s := &Subscriber{messages: make(chan *Message, config.GetInstance().Stream.BufferSize+len(missed))}
+	s.Subscribe(ids)
+	for _, m := range missed {
+		if s.accepts(m) {
+			s.send(m)
+		}
+	}
+
+	h.subscribers[s] = true
+	if h.closed {
+		s.close(ErrHubClosed)
+	}
+
+	return s
+}
+
+// Unsubscribe removes a subscriber
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.subscribers, s)
+	s.close(nil)
+
+	if h.drained != nil && len(h.subscribers) == 0 {
+		close(h.drained)
+		h.drained = nil
+	}
+}
+
+// Len returns the number of subscribers
+func (h *Hub) Len() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.subscribers)
+}
+
+// Publish sends a market event to every subscriber of its sessions
+// NOTE: Never blocks, subscribers whose buffers are full are disconnected
+func (h *Hub) Publish(ev *sessions.Event) { h.Broadcast(NewMessage(ev)) }
+
+// Broadcast sends a message to every subscriber, or to subscribers of its sessions if it describes a market event.
+// Messages require an ID starting with their Unix time, so clients can resume from them
+// NOTE: Never blocks, subscribers whose buffers are full are disconnected
+func (h *Hub) Broadcast(m *Message) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// Keep recent messages for resuming subscribers
+	h.replay = append(h.replay, m)
+	if size := config.GetInstance().Stream.ReplaySize; len(h.replay) > size {
+		h.replay = append([]*Message(nil), h.replay[len(h.replay)-size:]...)
+	}
+
+	for s := range h.subscribers {
+		if s.accepts(m) {
+			s.send(m)
+		}
+	}
+}
+
+// since returns the kept messages published after the message with an ID
+// NOTE: Must be called while holding the mutex
+func (h *Hub) since(id string) []*Message {
+	if id == "" {
+		return nil
+	}
+
+	for i := len(h.replay) - 1; i >= 0; i-- {
+		if h.replay[i].ID == id {
+			return h.replay[i+1:]
+		}
+	}
+
+	// Event IDs start with their Unix time, so messages after that time were missed
+	after, err := strconv.ParseInt(strings.SplitN(id, "-", 2)[0], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	for i, m := range h.replay {
+		if m.Time.Unix() > after {
+			return h.replay[i:]
+		}
+	}
+
+	return nil
+}
+
+// run publishes market events as they occur, until stopped
+func (h *Hub) run(stop, done chan struct{}) {
+	defer close(done)
+
+	last := time.Now()
+	for {
+		e := sessions.GetInstance()
+
+		wait := maxWait
+		if next := e.Next(last, 1); len(next) > 0 {
+			if d := time.Until(next[0].Time); d < wait {
+				wait = d
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Publish events since the last check, including those at its exact time
+		now := time.Now()
+		for _, ev := range e.Events(last.Add(time.Nanosecond), now.Add(time.Nanosecond)) {
+			h.Publish(ev)
+		}
+		last = now
+	}
+}
+
+// Messages returns the channel messages are received from, closed when the subscription ends
+func (s *Subscriber) Messages() <-chan *Message { return s.messages }
+
+// Err returns why the subscription ended, nil if the subscriber left or the subscription hasn't ended
+func (s *Subscriber) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.err
+}
+
+// Subscribe adds sessions to the subscription, clearing the filter (receiving all events) if none are given
+func (s *Subscriber) Subscribe(ids []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(ids) == 0 {
+		s.filter = map[string]bool{}
+		return
+	}
+
+	if s.filter == nil {
+		s.filter = map[string]bool{}
+	}
+	for _, id := range ids {
+		s.filter[id] = true
+	}
+}
+
+// Unsubscribe removes sessions from the subscription
+// NOTE: Removing every session leaves an empty filter, receiving all events
+func (s *Subscriber) Unsubscribe(ids []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, id := range ids {
+		delete(s.filter, id)
+	}
+}
+
+// Sessions returns the sessions subscribed to, empty for all
+func (s *Subscriber) Sessions() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ids := make([]string, 0, len(s.filter))
+	for _, session := range sessions.GetInstance().Sessions() {
+		if s.filter[session.ID] {
+			ids = append(ids, session.ID)
+		}
+	}
+
+	return ids
+}
+
+// Matches returns a boolean indicating if an event is within the subscription.
+// Market-wide events (ex: weekly market open) are always included
+func (s *Subscriber) Matches(ev *sessions.Event) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.filter) == 0 || (ev.Session == "" && len(ev.Sessions) == 0) || s.filter[ev.Session] {
+		return true
+	}
+
+	for _, id := range ev.Sessions {
+		if s.filter[id] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// accepts returns a boolean indicating if a message is within the subscription, which messages other than market
+// events always are
+func (s *Subscriber) accepts(m *Message) bool {
+	ev, ok := m.Data.(*sessions.Event)
+
+	return !ok || s.Matches(ev)
+}
+
+// send sends a message without blocking, ending the subscription if its buffer is full
+// NOTE: Must be called while holding the hub's mutex
+func (s *Subscriber) send(m *Message) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.messages <- m:
+	default:
+		s.closeLocked(ErrSlowConsumer)
+	}
+}
+
+// close ends the subscription, recording why
+func (s *Subscriber) close(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closeLocked(err)
+}
+
+// closeLocked ends the subscription, recording why
+// NOTE: Must be called while holding the subscriber's mutex
+func (s *Subscriber) closeLocked(err error) {
+	if s.closed {
+		return
+	}
+
+	s.closed, s.err = true, err
+	close(s.messages)
+}
@@ -0,0 +1,175 @@
+// Tests the hub.go file
+package stream
+
+import (
+	// Standard lib
+	"context"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/sessions"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("hub.go", func() {
+	var (
+		// Hub to test
+		h *Hub
+		// Time of test events
+		t = time.Date(2020, time.March, 2, 12, 0, 0, 0, time.UTC)
+		// Test events
+		londonOpen  = sessions.NewEvent(sessions.EventSessionOpen, t, "london", nil)
+		tokyoClose  = sessions.NewEvent(sessions.EventSessionClose, t, "tokyo", nil)
+		overlap     = sessions.NewEvent(sessions.EventOverlapStart, t, "", []string{"london", "new-york"})
+		marketClose = sessions.NewEvent(sessions.EventMarketClose, t, "", nil)
+	)
+
+	BeforeEach(func() {
+		config.GetInstance().Stream.BufferSize = 4
+		h = NewHub()
+	})
+
+	// receive returns the IDs of messages waiting for a subscriber
+	receive := func(s *Subscriber) []string {
+		ids := []string{}
+		for {
+			select {
+			case m, ok := <-s.Messages():
+				if !ok {
+					return ids
+				}
+				ids = append(ids, m.ID)
+			default:
+				return ids
+			}
+		}
+	}
+
+	It("Sends events as messages to every subscriber", func() {
+		a, b := h.Subscribe(nil), h.Subscribe(nil)
+		h.Publish(londonOpen)
+
+		// Verify messages
+		for _, s := range []*Subscriber{a, b} {
+			m := <-s.Messages()
+			Expect(m.ID).To(Equal(londonOpen.ID))
+			Expect(m.Type).To(Equal(sessions.EventSessionOpen))
+			Expect(m.Time).To(Equal(t))
+			Expect(m.Data).To(Equal(londonOpen))
+		}
+	})
+
+	It("Filters events by session, always sending market-wide events", func() {
+		s := h.Subscribe([]string{"london"})
+		for _, ev := range []*sessions.Event{londonOpen, tokyoClose, overlap, marketClose} {
+			h.Publish(ev)
+		}
+
+		// Verify messages
+		Expect(receive(s)).To(Equal([]string{londonOpen.ID, overlap.ID, marketClose.ID}))
+		Expect(s.Sessions()).To(Equal([]string{"london"}))
+
+		// Verify changed subscriptions
+		s.Subscribe([]string{"tokyo"})
+		s.Unsubscribe([]string{"london"})
+		h.Publish(londonOpen)
+		h.Publish(tokyoClose)
+		Expect(receive(s)).To(Equal([]string{tokyoClose.ID}))
+
+		// Verify removing every session receives all events
+		s.Unsubscribe([]string{"tokyo"})
+		h.Publish(londonOpen)
+		Expect(receive(s)).To(Equal([]string{londonOpen.ID}))
+	})
+
+	It("Broadcasts messages other than market events to every subscriber", func() {
+		s := h.Subscribe([]string{"london"})
+		m := &Message{ID: "1583150400-strength-change-daily", Type: "strength-change", Time: t, Data: map[string]string{}}
+		h.Publish(tokyoClose)
+		h.Broadcast(m)
+
+		// Verify messages, and that broadcasts are replayed
+		Expect(receive(s)).To(Equal([]string{m.ID}))
+		Expect(receive(h.SubscribeFrom([]string{"london"}, tokyoClose.ID))).To(Equal([]string{m.ID}))
+	})
+
+	It("Disconnects subscribers that fall behind", func() {
+		slow, fast := h.Subscribe(nil), h.Subscribe(nil)
+		for i := 0; i < 5; i++ {
+			h.Publish(londonOpen)
+			receive(fast)
+		}
+
+		// Verify the slow subscriber's buffered messages, then the end of its subscription
+		Expect(receive(slow)).To(HaveLen(4))
+		_, ok := <-slow.Messages()
+		Expect(ok).To(BeFalse())
+		Expect(slow.Err()).To(Equal(ErrSlowConsumer))
+
+		// Verify the fast subscriber is unaffected
+		h.Publish(londonOpen)
+		Expect(receive(fast)).To(HaveLen(1))
+		Expect(fast.Err()).To(BeNil())
+	})
+
+	It("Replays recent events missed by resuming subscribers", func() {
+		config.GetInstance().Stream.ReplaySize = 3
+		defer func() { config.GetInstance().Stream.ReplaySize = 100 }()
+
+		later := sessions.NewEvent(sessions.EventSessionClose, t.Add(time.Hour), "london", nil)
+		for _, ev := range []*sessions.Event{tokyoClose, londonOpen, overlap, marketClose, later} {
+			h.Publish(ev)
+		}
+
+		// Verify events after a kept event are replayed, filtered by session
+		Expect(receive(h.SubscribeFrom(nil, overlap.ID))).To(Equal([]string{marketClose.ID, later.ID}))
+		Expect(receive(h.SubscribeFrom([]string{"tokyo"}, overlap.ID))).To(Equal([]string{marketClose.ID}))
+
+		// Verify events after the time of an event no longer kept are replayed
+		Expect(receive(h.SubscribeFrom(nil, tokyoClose.ID))).To(Equal([]string{later.ID}))
+
+		// Verify nothing is replayed for new or unknown subscribers
+		Expect(receive(h.Subscribe(nil))).To(BeEmpty())
+		Expect(receive(h.SubscribeFrom(nil, "unknown"))).To(BeEmpty())
+	})
+
+	It("Ends subscriptions when closed, waiting for subscribers to leave", func() {
+		h.Start()
+		s := h.Subscribe(nil)
+
+		go func() {
+			defer GinkgoRecover()
+
+			// Leave once the subscription ends
+			_, ok := <-s.Messages()
+			Expect(ok).To(BeFalse())
+			Expect(s.Err()).To(Equal(ErrHubClosed))
+			h.Unsubscribe(s)
+		}()
+
+		// Verify the hub closes once the subscriber leaves
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		Expect(h.Close(ctx)).To(Succeed())
+		Expect(h.Len()).To(BeZero())
+
+		// Verify new subscriptions end immediately
+		late := h.Subscribe(nil)
+		_, ok := <-late.Messages()
+		Expect(ok).To(BeFalse())
+		Expect(late.Err()).To(Equal(ErrHubClosed))
+	})
+
+	It("Stops waiting for subscribers that don't leave", func() {
+		h.Subscribe(nil)
+
+		// Verify the context's error is returned
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		Expect(h.Close(ctx)).To(Equal(context.DeadlineExceeded))
+	})
+})
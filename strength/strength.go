@@ -0,0 +1,374 @@
+// strength package contains the currency strength meter, measuring which of the eight major currencies is strongest.
+// The latest quotes of the 28 crosses of majors are sampled periodically, and each currency's strength index is its
+// average percent change against the other majors over a window: a rolling intraday window, since the current
+// session opened, or since the trading day started. Changes of ranking are broadcast to streaming clients
+package strength
+
+import (
+	// Standard lib
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/candles"
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/quotes"
+	"github.com/deezone/forex-clock/sessions"
+	"github.com/deezone/forex-clock/stream"
+
+	// Third-party
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Windows strength is measured over
+	WindowIntraday = "intraday" // Rolling window of `strength.intraday` minutes
+	WindowSession  = "session"  // Since the most recently opened session opened
+	WindowDaily    = "daily"    // Since the trading day started, at the daily close
+
+	// Type of stream messages sent when the ranking of a window changes
+	MessageStrengthChange = "strength-change"
+
+	// How often prices are kept to measure changes from
+	sampleInterval = time.Minute
+
+	// How far back to look for the most recently opened session, covering weekends and holidays
+	sessionLookback = 5 * 24 * time.Hour
+
+	// Number of decimal places of strength indexes
+	indexPlaces = 4
+)
+
+var (
+	// Major currencies, in the order of priority deciding which is the base currency of their crosses
+	Currencies = []string{"EUR", "GBP", "AUD", "NZD", "USD", "CAD", "CHF", "JPY"}
+
+	// Windows, in order
+	Windows = []string{WindowIntraday, WindowSession, WindowDaily}
+
+	// Crosses of major currencies (ex: EURUSD, GBPJPY, AUDNZD), in order of priority
+	Pairs = crosses(Currencies)
+
+	// Error returned for windows that aren't known
+	ErrUnknownWindow = errors.New("Unknown window, expected one of: " + strings.Join(Windows, ", "))
+)
+
+type (
+	// Meter is a struct representing the strength of every major currency over a single window
+	Meter struct {
+		Window     string      `json:"window"`
+		Session    string      `json:"session,omitempty"` // ID of the session the window started with, for session windows
+		From       time.Time   `json:"from"`              // Start of the window
+		To         time.Time   `json:"to"`                // When strength was measured
+		Partial    bool        `json:"partial"`           // Whether prices aren't known since the start of the window
+		Currencies []*Strength `json:"currencies"`        // Currencies, ranked strongest first
+	}
+	// Strength is a struct representing the strength of a single currency
+	Strength struct {
+		Currency string  `json:"currency"`
+		Rank     int     `json:"rank"`  // Position when ranked by strength index, from 1
+		Index    float64 `json:"index"` // Average percent change against the other majors
+		Pairs    int     `json:"pairs"` // Number of crosses measured, currencies without any are ranked last
+	}
+	// Service is a struct representing the measurement of currency strength from the latest quotes
+	Service struct {
+		quotes  *quotes.Store
+		hub     *stream.Hub
+		mutex   sync.Mutex
+		current map[string]*sample   // Latest price of each cross
+		history map[string][]*sample // Prices kept every sample interval, oldest first, by cross
+		ranking map[string]string    // Last ranking of each window, as currencies joined by ","
+		stop    chan struct{}        // Closed to stop the worker, nil when not running
+		done    chan struct{}        // Closed once the worker stops
+		reset   chan time.Duration   // Receives the measuring interval when it's reloaded
+	}
+	// Struct representing the mid price of a cross at a point in time
+	sample struct {
+		time time.Time
+		mid  float64
+	}
+)
+
+// NewService creates and returns a new instance of a service measuring currency strength from a store of quotes,
+// broadcasting changes of ranking to a hub
+func NewService(store *quotes.Store, hub *stream.Hub) *Service {
+	return &Service{
+		quotes:  store,
+		hub:     hub,
+		current: map[string]*sample{},
+		history: map[string][]*sample{},
+		ranking: map[string]string{},
+		reset:   make(chan time.Duration, 1),
+	}
+}
+
+// Start starts measuring strength periodically
+func (s *Service) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	go s.run(s.stop, s.done)
+}
+
+// Close stops measuring strength, keeping the prices sampled
+func (s *Service) Close() {
+	s.mutex.Lock()
+	stop, done := s.stop, s.done
+	s.stop = nil
+	s.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Sample records the latest price of every cross at a point in time, deriving crosses that aren't quoted, and
+// discards prices no longer needed by any window
+func (s *Service) Sample(now time.Time) {
+	list := s.quotes.CrossList(Pairs)
+	earliest := now
+	for _, w := range Windows {
+		if from, _, err := start(w, now); err == nil && from.Before(earliest) {
+			earliest = from
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, q := range list {
+		smp := &sample{time: now, mid: q.Mid}
+		s.current[q.Pair] = smp
+
+		h := s.history[q.Pair]
+		if len(h) == 0 || now.Sub(h[len(h)-1].time) >= sampleInterval {
+			h = append(h, smp)
+		}
+
+		// Keep the last price before the earliest window, which changes are measured from
+		if i := after(h, earliest); i > 1 {
+			h = append([]*sample(nil), h[i-1:]...)
+		}
+		s.history[q.Pair] = h
+	}
+}
+
+// Meter returns the strength of every major currency over a window ending at a point in time
+func (s *Service) Meter(window string, now time.Time) (*Meter, error) {
+	from, session, err := start(window, now)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Meter{Window: window, Session: session, From: from, To: now.UTC()}
+	changes := map[string]float64{}
+
+	s.mutex.Lock()
+	for _, pair := range Pairs {
+		cur, h := s.current[pair], s.history[pair]
+		if cur == nil || len(h) == 0 {
+			continue
+		}
+
+		// Measure from the last price at or before the start, or the first price kept when there's none
+		ref := h[0]
+		if i := after(h, from); i > 0 {
+			ref = h[i-1]
+		} else {
+			m.Partial = true
+		}
+		changes[pair] = (cur.mid/ref.mid - 1) * 100
+	}
+	s.mutex.Unlock()
+
+	m.Currencies = Rank(changes)
+
+	return m, nil
+}
+
+// Meters returns the strength of every major currency over every window ending at a point in time
+func (s *Service) Meters(now time.Time) []*Meter {
+	list := make([]*Meter, 0, len(Windows))
+	for _, w := range Windows {
+		if m, err := s.Meter(w, now); err == nil {
+			list = append(list, m)
+		}
+	}
+
+	return list
+}
+
+// Update measures strength at a point in time, broadcasting the strength of every window whose ranking changed
+// NOTE: Windows measured for the first time, or without any cross measured, aren't broadcast
+func (s *Service) Update(now time.Time) {
+	for _, m := range s.Meters(now) {
+		ranked := []string{}
+		for _, c := range m.Currencies {
+			if c.Pairs > 0 {
+				ranked = append(ranked, c.Currency)
+			}
+		}
+		if len(ranked) == 0 {
+			continue
+		}
+
+		ranking := strings.Join(ranked, ",")
+		s.mutex.Lock()
+		prev, ok := s.ranking[m.Window]
+		s.ranking[m.Window] = ranking
+		s.mutex.Unlock()
+
+		if !ok || prev == ranking {
+			continue
+		}
+
+		log.WithFields(log.Fields{"window": m.Window, "ranking": ranking}).Debug("Currency strength ranking changed")
+		s.hub.Broadcast(&stream.Message{
+			ID:   fmt.Sprintf("%d-%s-%s", now.Unix(), MessageStrengthChange, m.Window),
+			Type: MessageStrengthChange,
+			Time: now.UTC(),
+			Data: m,
+		})
+	}
+}
+
+// Rank returns the strength of every major currency from the percent change of crosses, ranked strongest first
+// NOTE: Ties keep the order of priority of currencies
+func Rank(changes map[string]float64) []*Strength {
+	list := make([]*Strength, 0, len(Currencies))
+	for _, c := range Currencies {
+		st, sum := &Strength{Currency: c}, 0.0
+		for pair, change := range changes {
+			switch c {
+			case pair[:3]:
+				sum += change
+			case pair[3:]:
+				sum -= change
+			default:
+				continue
+			}
+			st.Pairs++
+		}
+		if st.Pairs > 0 {
+			st.Index = round(sum/float64(st.Pairs), indexPlaces)
+		}
+		list = append(list, st)
+	}
+
+	sort.SliceStable(list, func(a, b int) bool {
+		if (list[a].Pairs > 0) != (list[b].Pairs > 0) {
+			return list[a].Pairs > 0
+		}
+		return list[a].Index > list[b].Index
+	})
+	for i, st := range list {
+		st.Rank = i + 1
+	}
+
+	return list
+}
+
+// run samples prices and measures strength periodically, until stopped
+func (s *Service) run(stop, done chan struct{}) {
+	defer close(done)
+
+	s.Sample(time.Now())
+
+	t := time.NewTicker(interval(config.GetInstance()))
+	defer t.Stop()
+
+	unsubscribe := config.Subscribe(s.reload)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case d := <-s.reset:
+			t.Reset(d)
+		case now := <-t.C:
+			s.Sample(now)
+			s.Update(now)
+		}
+	}
+}
+
+// reload passes the measuring interval to the worker when it changes during a configuration reload
+func (s *Service) reload(prev, next *config.Config, changed []string) {
+	if prev.Strength.Interval == next.Strength.Interval {
+		return
+	}
+
+	// NOTE: Replaces an interval the worker hasn't applied yet, so it always applies the latest
+	select {
+	case <-s.reset:
+	default:
+	}
+	s.reset <- interval(next)
+}
+
+// interval returns how often strength is measured
+func interval(c *config.Config) time.Duration {
+	return time.Duration(c.Strength.Interval) * time.Second
+}
+
+// start returns the start of a window ending at a point in time, and the ID of the session it started with
+// NOTE: Session windows start with the most recently opened session, still open or not, or with the trading day
+// when no session opened recently
+func start(window string, now time.Time) (time.Time, string, error) {
+	switch window {
+	case WindowIntraday:
+		return now.Add(-time.Duration(config.GetInstance().Strength.Intraday) * time.Minute).UTC(), "", nil
+	case WindowSession:
+		var latest *sessions.Interval
+		for _, i := range sessions.GetInstance().Intervals(now.Add(-sessionLookback), now) {
+			if !i.Holiday && (latest == nil || !i.Start.Before(latest.Start)) {
+				latest = i
+			}
+		}
+		if latest != nil {
+			return latest.Start.UTC(), latest.Session.ID, nil
+		}
+		return candles.DayStart(now), "", nil
+	case WindowDaily:
+		return candles.DayStart(now), "", nil
+	}
+
+	return time.Time{}, "", ErrUnknownWindow
+}
+
+// after returns the index of the first sample after a point in time, or the number of samples if there's none
+func after(h []*sample, t time.Time) int {
+	return sort.Search(len(h), func(i int) bool { return h[i].time.After(t) })
+}
+
+// crosses returns every pair of currencies, quoted in the currency with the lower priority
+func crosses(currencies []string) []string {
+	list := []string{}
+	for i, base := range currencies {
+		for _, quote := range currencies[i+1:] {
+			list = append(list, base+quote)
+		}
+	}
+
+	return list
+}
+
+// round rounds a number to a number of decimal places
+func round(n float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+
+	return math.Round(n*p) / p
+}
@@ -0,0 +1,155 @@
+// Tests the strength.go file
+package strength
+
+import (
+	// Standard lib
+	"strings"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/provider"
+	"github.com/deezone/forex-clock/quotes"
+	"github.com/deezone/forex-clock/stream"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("strength.go", func() {
+	var (
+		store *quotes.Store
+		hub   *stream.Hub
+		s     *Service
+		base  time.Time
+	)
+
+	// put stores a quote of each pair at a price
+	put := func(prices map[string]float64) {
+		now := time.Now().UTC()
+		for pair, price := range prices {
+			store.Put(&provider.Tick{Pair: pair, Bid: price, Ask: price, Time: now, Source: "replay"})
+		}
+	}
+
+	BeforeEach(func() {
+		config.GetInstance().Strength.Intraday = 5
+		store, hub = quotes.NewStore(nil), stream.NewHub()
+		s = NewService(store, hub)
+		base = time.Now().UTC()
+
+		// Quote every major against the US dollar, deriving the other crosses
+		put(map[string]float64{
+			"EURUSD": 1.08, "GBPUSD": 1.27, "AUDUSD": 0.66, "NZDUSD": 0.61,
+			"USDCAD": 1.36, "USDCHF": 0.88, "USDJPY": 150,
+		})
+		s.Sample(base)
+	})
+
+	AfterEach(func() {
+		config.GetInstance().Strength.Intraday = 240
+	})
+
+	It("Lists the crosses of major currencies", func() {
+		Expect(Pairs).To(HaveLen(28))
+		Expect(Pairs).To(ContainElement("EURUSD"))
+		Expect(Pairs).To(ContainElement("GBPJPY"))
+		Expect(Pairs).To(ContainElement("AUDNZD"))
+		Expect(Pairs).To(ContainElement("USDCAD"))
+		Expect(Pairs).To(Not(ContainElement("USDEUR")))
+	})
+
+	It("Ranks currencies by their average change against other majors", func() {
+		list := Rank(map[string]float64{"EURUSD": 1, "USDJPY": 0.5})
+
+		// Verify output
+		Expect(list).To(HaveLen(8))
+		Expect(*list[0]).To(Equal(Strength{Currency: "EUR", Rank: 1, Index: 1, Pairs: 1}))
+		Expect(*list[1]).To(Equal(Strength{Currency: "USD", Rank: 2, Index: -0.25, Pairs: 2}))
+		Expect(*list[2]).To(Equal(Strength{Currency: "JPY", Rank: 3, Index: -0.5, Pairs: 1}))
+
+		// Verify currencies without crosses are ranked last, in order of priority
+		Expect(*list[3]).To(Equal(Strength{Currency: "GBP", Rank: 4}))
+		Expect(list[7].Currency).To(Equal("CHF"))
+	})
+
+	It("Measures strength over windows from sampled prices", func() {
+		put(map[string]float64{"EURUSD": 1.0908})
+		now := base.Add(10 * time.Minute)
+		s.Sample(now)
+
+		// Verify output
+		m, err := s.Meter(WindowIntraday, now)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(m.From).To(Equal(now.Add(-5 * time.Minute)))
+		Expect(m.To).To(Equal(now))
+		Expect(m.Partial).To(BeFalse())
+		Expect(m.Currencies[0].Currency).To(Equal("EUR"))
+		Expect(m.Currencies[0].Index).To(BeNumerically("~", 1, 0.01))
+		for _, c := range m.Currencies {
+			Expect(c.Pairs).To(Equal(7))
+			if c.Currency != "EUR" {
+				Expect(c.Index).To(BeNumerically("~", -1.0/7, 0.01))
+			}
+		}
+
+		// Verify windows starting before the first sample are partial
+		m, err = s.Meter(WindowDaily, now)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(m.Partial).To(BeTrue())
+		Expect(s.Meters(now)).To(HaveLen(3))
+
+		// Verify errors
+		_, err = s.Meter("weekly", now)
+		Expect(err).To(Equal(ErrUnknownWindow))
+	})
+
+	It("Broadcasts the strength of windows whose ranking changed", func() {
+		sub := hub.Subscribe(nil)
+		s.Update(base)
+
+		put(map[string]float64{"EURUSD": 1.0692})
+		now := base.Add(10 * time.Minute)
+		s.Sample(now)
+		s.Update(now)
+		s.Update(now)
+
+		// Verify messages, sent once for every window
+		messages := []*stream.Message{}
+		for len(sub.Messages()) > 0 {
+			messages = append(messages, <-sub.Messages())
+		}
+		Expect(messages).To(HaveLen(3))
+
+		m := messages[0]
+		Expect(m.Type).To(Equal(MessageStrengthChange))
+		Expect(strings.HasSuffix(m.ID, "-"+WindowIntraday)).To(BeTrue())
+		Expect(m.Time).To(Equal(now))
+		Expect(m.Data.(*Meter).Currencies[7].Currency).To(Equal("EUR"))
+	})
+
+	It("Measures at the reloaded interval", func() {
+		config.GetInstance().Strength.Interval = 300
+		defer func() { config.GetInstance().Strength.Interval = 10 }()
+		s.Start()
+		defer s.Close()
+
+		// sampled returns when the latest price was sampled
+		sampled := func() time.Time {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			return s.current["EURUSD"].time
+		}
+		Eventually(sampled).Should(BeTemporally(">", base))
+		first := sampled()
+
+		// Call method
+		prev, next := *config.GetInstance(), *config.GetInstance()
+		next.Strength.Interval = 1
+		s.reload(&prev, &next, []string{"strength.interval"})
+
+		// Verify output
+		Eventually(sampled, 3*time.Second).Should(BeTemporally(">", first))
+	})
+})
@@ -0,0 +1,92 @@
+// tz package contains the time zone database used throughout the application
+// transitions contains the search for changes to a time zone's offset, such as daylight saving time starting
+// or ending
+package tz
+
+import (
+	// Standard lib
+	"time"
+)
+
+const (
+	// Time stepped between when searching for transitions
+	// NOTE: Transitions closer together than this may be missed, which no IANA zone has
+	Step = 6 * time.Hour
+	// How far ahead to look when searching for the next transition
+	Lookahead = 366 * 24 * time.Hour
+)
+
+type (
+	// Transition is a struct representing a change to the offset of a time zone
+	Transition struct {
+		At           time.Time `json:"at"`           // When the transition occurs (UTC)
+		From         int       `json:"-"`            // Offset (in seconds east of UTC) before the transition
+		To           int       `json:"offset"`       // Offset after the transition
+		UTCOffset    string    `json:"utc-offset"`   // Offset after the transition (ex: "+01:00")
+		Abbreviation string    `json:"abbreviation"` // Abbreviation after the transition (ex: "BST")
+		DST          bool      `json:"dst"`          // Whether the offset is daylight saving time
+	}
+)
+
+// Transitions returns the offset in effect at the start of a time range, followed by every change to it
+// within the range
+func Transitions(loc *time.Location, from, to time.Time) []*Transition {
+	start := from.In(loc)
+	_, off := start.Zone()
+	list := []*Transition{transition(start, off)}
+
+	for t := start; t.Before(to); {
+		next := find(t, to)
+		if next == nil {
+			break
+		}
+
+		list = append(list, next)
+		t = next.At.In(loc)
+	}
+
+	return list
+}
+
+// Next returns the first transition after a point in time, within a duration of it, or nil if there is none
+func Next(loc *time.Location, t time.Time, within time.Duration) *Transition {
+	return find(t.In(loc), t.Add(within))
+}
+
+// find returns the first transition after a local time and before an end time, or nil if there is none
+func find(start, end time.Time) *Transition {
+	name, off := start.Zone()
+	for t := start; t.Before(end); t = t.Add(Step) {
+		next := t.Add(Step)
+		if n, o := next.Zone(); n == name && o == off {
+			continue
+		}
+
+		// Search for the second the offset changes
+		// NOTE: Offsets only change on whole seconds, so the search is bounded by them, otherwise truncating a
+		// time between them could fall back to the lower bound and never narrow the range
+		lo, hi := t.Truncate(time.Second), next.Truncate(time.Second)
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+			if n, o := mid.Zone(); n == name && o == off {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+
+		if !hi.Before(end) {
+			return nil
+		}
+
+		return transition(hi, off)
+	}
+
+	return nil
+}
+
+// transition returns the transition to the offset in effect at a local time, from a previous offset
+func transition(t time.Time, from int) *Transition {
+	name, off := t.Zone()
+	return &Transition{At: t.UTC(), From: from, To: off, UTCOffset: t.Format("-07:00"), Abbreviation: name, DST: t.IsDST()}
+}
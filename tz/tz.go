@@ -0,0 +1,169 @@
+// tz package contains the time zone database used throughout the application. Zones are loaded from a copy of
+// the Go toolchain's IANA database embedded within the binary, rather than the host's zoneinfo, so conversions
+// are the same on every host. Update the copy with `make tzdata`
+package tz
+
+import (
+	// Standard lib
+	"archive/zip"
+	"bytes"
+	_ "embed"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Release of the embedded IANA time zone database
+	// NOTE: Update alongside zoneinfo.zip
+	Version = "2026c"
+)
+
+type (
+	// Zone is a struct representing the state of a time zone at a point in time
+	Zone struct {
+		Name         string      `json:"name"`
+		Time         string      `json:"time"`         // Local time (RFC 3339)
+		Abbreviation string      `json:"abbreviation"` // Abbreviation of the offset in effect (ex: "BST")
+		Offset       int         `json:"offset"`       // Offset in effect, in seconds east of UTC
+		UTCOffset    string      `json:"utc-offset"`   // Offset in effect (ex: "+01:00")
+		DST          bool        `json:"dst"`          // Whether daylight saving time is in effect
+		Next         *Transition `json:"next-transition"`
+	}
+)
+
+var (
+	// Errors returned when loading zones and converting local times
+	ErrUnknownZone     = errors.New("Unknown time zone")
+	ErrNonexistentTime = errors.New("Local time does not exist, it is skipped by a transition")
+
+	// Embedded time zone database, a zip archive of zoneinfo files
+	//go:embed zoneinfo.zip
+	zoneinfo []byte
+
+	// Zoneinfo files by zone name, read on first use
+	files     map[string]*zip.File
+	filesOnce sync.Once
+
+	// Zones already loaded
+	locations      = map[string]*time.Location{}
+	locationsMutex sync.RWMutex
+)
+
+// Load returns the time zone with an IANA name (ex: "Europe/London")
+// NOTE: "Local" is not supported, as it depends on the host
+func Load(name string) (*time.Location, error) {
+	if name == "" || name == "UTC" {
+		return time.UTC, nil
+	}
+
+	locationsMutex.RLock()
+	loc, ok := locations[name]
+	locationsMutex.RUnlock()
+	if ok {
+		return loc, nil
+	}
+
+	f, ok := database()[name]
+	if !ok {
+		return nil, ErrUnknownZone
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if loc, err = time.LoadLocationFromTZData(name, data); err != nil {
+		return nil, err
+	}
+
+	locationsMutex.Lock()
+	locations[name] = loc
+	locationsMutex.Unlock()
+
+	return loc, nil
+}
+
+// Names returns the names of every zone within the database, sorted
+func Names() []string {
+	names := make([]string, 0, len(database()))
+	for name := range database() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// At returns the state of a time zone at a point in time, including its next transition within a year
+func At(loc *time.Location, t time.Time) *Zone {
+	local := t.In(loc)
+	name, off := local.Zone()
+
+	return &Zone{
+		Name:         loc.String(),
+		Time:         local.Format(time.RFC3339),
+		Abbreviation: name,
+		Offset:       off,
+		UTCOffset:    local.Format("-07:00"),
+		DST:          local.IsDST(),
+		Next:         Next(loc, t, Lookahead),
+	}
+}
+
+// Date returns the instant a local date and time occurs within a time zone. Times repeated by a transition
+// (ex: when clocks go back) return the earlier instant, times skipped by one return `ErrNonexistentTime`
+func Date(year int, month time.Month, day, hour, min, sec int, loc *time.Location) (time.Time, error) {
+	wall := time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+
+	// Try each offset in effect around the time, as a transition may occur within a day of it
+	var found time.Time
+	for _, d := range []time.Duration{-24 * time.Hour, 0, 24 * time.Hour} {
+		_, off := wall.Add(d).In(loc).Zone()
+		t := wall.Add(-time.Duration(off) * time.Second).In(loc)
+
+		y, m, dd := t.Date()
+		if y == year && m == month && dd == day && t.Hour() == hour && t.Minute() == min && t.Second() == sec {
+			if found.IsZero() || t.Before(found) {
+				found = t
+			}
+		}
+	}
+
+	if found.IsZero() {
+		return time.Time{}, ErrNonexistentTime
+	}
+
+	return found, nil
+}
+
+// database returns the zoneinfo files of the embedded database by zone name
+func database() map[string]*zip.File {
+	filesOnce.Do(func() {
+		files = map[string]*zip.File{}
+
+		r, err := zip.NewReader(bytes.NewReader(zoneinfo), int64(len(zoneinfo)))
+		if err != nil {
+			// NOTE: The database is embedded at build time, so this can't happen at run time
+			panic("Invalid embedded time zone database: " + err.Error())
+		}
+
+		for _, f := range r.File {
+			if !strings.HasSuffix(f.Name, "/") {
+				files[f.Name] = f
+			}
+		}
+	})
+
+	return files
+}
@@ -0,0 +1,153 @@
+// Tests the tz.go and transitions.go files
+package tz
+
+import (
+	// Standard lib
+	"encoding/json"
+	"time"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tz.go", func() {
+	Describe("`Load` method", func() {
+		It("Loads zones from the embedded database", func() {
+			loc, err := Load("Europe/London")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(loc.String()).To(Equal("Europe/London"))
+
+			// Verify zones are only loaded once
+			again, _ := Load("Europe/London")
+			Expect(again).To(BeIdenticalTo(loc))
+
+			// Verify UTC
+			loc, err = Load("")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(loc).To(Equal(time.UTC))
+		})
+
+		It("Returns an error for unknown zones", func() {
+			for _, name := range []string{"Mars/Olympus_Mons", "Local", "europe/london", "../etc/passwd"} {
+				_, err := Load(name)
+				Expect(err).To(Equal(ErrUnknownZone))
+			}
+		})
+	})
+
+	It("Lists every zone within the database", func() {
+		names := Names()
+
+		// Verify output
+		Expect(len(names)).To(BeNumerically(">", 400))
+		Expect(names).To(ContainElement("America/New_York"))
+		Expect(names).To(ContainElement("Australia/Sydney"))
+		Expect(names).To(ContainElement("America/Argentina/Buenos_Aires"))
+		Expect(names[0] < names[len(names)-1]).To(BeTrue())
+		for _, name := range names {
+			_, err := Load(name)
+			Expect(err).To(Not(HaveOccurred()))
+		}
+	})
+
+	Describe("`At` method", func() {
+		It("Describes zones at a point in time", func() {
+			loc, _ := Load("Europe/London")
+			z := At(loc, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+
+			// Verify output
+			Expect(z.Name).To(Equal("Europe/London"))
+			Expect(z.Time).To(Equal("2024-06-01T13:00:00+01:00"))
+			Expect(z.Abbreviation).To(Equal("BST"))
+			Expect(z.Offset).To(Equal(3600))
+			Expect(z.UTCOffset).To(Equal("+01:00"))
+			Expect(z.DST).To(BeTrue())
+			Expect(z.Next.At).To(BeTemporally("==", time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC)))
+			Expect(z.Next.Abbreviation).To(Equal("GMT"))
+			Expect(z.Next.UTCOffset).To(Equal("+00:00"))
+			Expect(z.Next.DST).To(BeFalse())
+
+			// Verify JSON
+			b, _ := json.Marshal(z)
+			Expect(string(b)).To(ContainSubstring(`"next-transition":{"at":"2024-10-27T01:00:00Z","offset":0,"utc-offset":"+00:00","abbreviation":"GMT","dst":false}`))
+		})
+
+		It("Describes zones without transitions", func() {
+			loc, _ := Load("Asia/Tokyo")
+			z := At(loc, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+
+			// Verify output
+			Expect(z.UTCOffset).To(Equal("+09:00"))
+			Expect(z.DST).To(BeFalse())
+			Expect(z.Next).To(BeNil())
+		})
+	})
+
+	Describe("`Date` method", func() {
+		var newYork *time.Location
+
+		BeforeEach(func() {
+			newYork, _ = Load("America/New_York")
+		})
+
+		It("Returns the instant of local times", func() {
+			t, err := Date(2024, 6, 3, 8, 0, 0, newYork)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(t).To(BeTemporally("==", time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)))
+		})
+
+		It("Returns the earlier instant of repeated local times", func() {
+			// Clocks go back from 02:00 EDT to 01:00 EST
+			t, err := Date(2024, 11, 3, 1, 30, 0, newYork)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(t).To(BeTemporally("==", time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC)))
+		})
+
+		It("Returns an error for skipped local times", func() {
+			// Clocks go forward from 02:00 EST to 03:00 EDT
+			_, err := Date(2024, 3, 10, 2, 30, 0, newYork)
+			Expect(err).To(Equal(ErrNonexistentTime))
+		})
+	})
+})
+
+var _ = Describe("transitions.go", func() {
+	It("Lists transitions within a time range", func() {
+		loc, _ := Load("Australia/Sydney")
+		list := Transitions(loc, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		// Verify output
+		Expect(list).To(HaveLen(3))
+		Expect(list[0].Abbreviation).To(Equal("AEDT"))
+		Expect(list[1].At).To(BeTemporally("==", time.Date(2024, 4, 6, 16, 0, 0, 0, time.UTC)))
+		Expect(list[1].From).To(Equal(11 * 3600))
+		Expect(list[1].To).To(Equal(10 * 3600))
+		Expect(list[1].DST).To(BeFalse())
+		Expect(list[2].At).To(BeTemporally("==", time.Date(2024, 10, 5, 16, 0, 0, 0, time.UTC)))
+		Expect(list[2].DST).To(BeTrue())
+	})
+
+	It("Finds the next transition within a duration", func() {
+		loc, _ := Load("America/New_York")
+		t := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		// Verify output
+		Expect(Next(loc, t, Lookahead).At).To(BeTemporally("==", time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)))
+		Expect(Next(loc, t, 7*24*time.Hour)).To(BeNil())
+	})
+
+	It("Finds transitions from times between whole seconds", func() {
+		loc, _ := Load("Europe/London")
+
+		// NOTE: A transition a little over a second after the last step is only found by whole-second bounds
+		t := time.Date(2024, 3, 31, 1, 0, 1, 310000000, time.UTC).Add(-Step - time.Second)
+		for _, from := range []time.Time{t, t.Add(time.Nanosecond), t.Add(-700 * time.Millisecond)} {
+			next := Next(loc, from, Lookahead)
+
+			// Verify output
+			Expect(next).NotTo(BeNil())
+			Expect(next.At).To(BeTemporally("==", time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC)))
+		}
+	})
+})
@@ -0,0 +1,103 @@
+// webhooks package contains the delivery of market events to webhook subscriptions
+// addresses contains the guard keeping webhook requests (deliveries and alert notifications) from reaching private,
+// loopback, and link-local addresses, so webhooks can't be used to reach internal services
+package webhooks
+
+import (
+	// Standard lib
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+)
+
+var (
+	// Errors of webhooks that can't be sent
+	ErrPrivateAddress = errors.New("Webhooks can't be sent to private, loopback, or link-local addresses")
+
+	// Networks webhooks aren't sent to, unless `webhooks.private-addresses` is set
+	// NOTE: Includes the unspecified, "this network", shared (carrier-grade NAT), benchmarking, multicast, and reserved
+	// ranges, none of which are public hosts
+	privateNetworks = parseNetworks(
+		"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+		"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+		"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+	)
+)
+
+// ValidHost returns an error if webhooks can't be sent to a host, as it's a private, loopback, or link-local address
+// NOTE: Hostnames are resolved when webhooks are sent, so only addresses and "localhost" are checked here
+func ValidHost(host string) error {
+	if config.GetInstance().Webhooks.PrivateAddresses {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); strings.EqualFold(host, "localhost") || (ip != nil && privateIP(ip)) {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
+
+// NewClient creates and returns a new HTTP client for sending webhooks
+// NOTE: Connections to private, loopback, and link-local addresses are refused once hostnames are resolved (including
+// those of redirects). Proxies aren't used, as they'd resolve hostnames
+func NewClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: dialControl}
+
+	return &http.Client{Transport: &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}}
+}
+
+// dialControl refuses connections to private, loopback, and link-local addresses, unless
+// `webhooks.private-addresses` is set
+func dialControl(network, address string, c syscall.RawConn) error {
+	if config.GetInstance().Webhooks.PrivateAddresses {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || privateIP(ip) {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
+
+// privateIP returns a boolean indicating if an IP address is within a network webhooks aren't sent to
+// NOTE: IPv4-mapped IPv6 addresses (ex: "::ffff:127.0.0.1") match IPv4 networks
+func privateIP(ip net.IP) bool {
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseNetworks parses a list of CIDR networks, panicking if any is invalid
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, n)
+	}
+
+	return networks
+}
@@ -0,0 +1,52 @@
+// Tests the addresses.go file
+package webhooks
+
+import (
+	// Standard lib
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("addresses.go", func() {
+	BeforeEach(func() {
+		config.GetInstance().Webhooks.PrivateAddresses = false
+	})
+
+	It("Validates webhook hosts", func() {
+		for _, host := range []string{"127.0.0.1", "localhost", "LocalHost", "10.1.2.3", "172.20.0.1", "192.168.1.1",
+			"169.254.169.254", "0.0.0.0", "100.64.0.1", "::1", "::", "fd00::1", "fe80::1", "::ffff:127.0.0.1"} {
+			Expect(ValidHost(host)).To(Equal(ErrPrivateAddress), host)
+		}
+		for _, host := range []string{"example.com", "93.184.216.34", "2606:2800:220:1::1", "172.32.0.1"} {
+			Expect(ValidHost(host)).To(Succeed(), host)
+		}
+
+		config.GetInstance().Webhooks.PrivateAddresses = true
+		Expect(ValidHost("127.0.0.1")).To(Succeed())
+	})
+
+	It("Refuses to connect to private addresses once hostnames are resolved", func() {
+		received := false
+		internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			received = true
+		}))
+		defer internal.Close()
+
+		// NOTE: Hostnames are checked once resolved
+		for _, target := range []string{internal.URL, strings.Replace(internal.URL, "127.0.0.1", "localhost", 1)} {
+			_, err := NewClient().Get(target)
+
+			// Verify output
+			Expect(err).To(MatchError(ContainSubstring(ErrPrivateAddress.Error())), target)
+		}
+		Expect(received).To(BeFalse())
+	})
+})
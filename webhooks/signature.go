@@ -0,0 +1,94 @@
+// webhooks package contains the delivery of market events to webhook subscriptions
+// signature contains the signing of payloads, letting receivers verify deliveries and reject replays
+package webhooks
+
+import (
+	// Standard lib
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Prefix of webhook secrets
+	SecretPrefix = "whsec_"
+
+	// Version of the signature scheme
+	signatureVersion = "v1"
+)
+
+var (
+	// Signature verification errors
+	ErrInvalidSignature = errors.New("Invalid webhook signature")
+	ErrExpiredSignature = errors.New("Webhook signature is too old")
+)
+
+// GenerateSecret returns a new random webhook secret
+func GenerateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return SecretPrefix + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value of a payload sent at a point in time, in the form `t=<unix time>,v1=<hex>`,
+// where the hex value is the HMAC-SHA256 of `<unix time>.<payload>` keyed by the webhook's secret
+func Sign(secret string, t time.Time, payload []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+
+	return "t=" + ts + "," + signatureVersion + "=" + hex.EncodeToString(mac(secret, ts, payload))
+}
+
+// Verify checks a signature header value against a payload, rejecting signatures older than a tolerance
+func Verify(secret, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var ts string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch kv[0] {
+		case "t":
+			ts = kv[1]
+		case signatureVersion:
+			if sig, err := hex.DecodeString(kv[1]); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	sent, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected := mac(secret, ts, payload)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			if now.Sub(time.Unix(sent, 0)) > tolerance {
+				return ErrExpiredSignature
+			}
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// mac returns the HMAC-SHA256 of a timestamped payload
+func mac(secret, ts string, payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts + "."))
+	h.Write(payload)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,66 @@
+// Tests the signature.go file
+package webhooks
+
+import (
+	// Standard lib
+	"strings"
+	"time"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("signature.go", func() {
+	var (
+		// Test secret, time and payload
+		secret  = "whsec_test"
+		t       = time.Date(2020, time.March, 2, 12, 0, 0, 0, time.UTC)
+		payload = []byte(`{"id":"1583150400-session-open-london"}`)
+	)
+
+	It("Generates prefixed, unique secrets", func() {
+		a, err := GenerateSecret()
+		Expect(err).To(Not(HaveOccurred()))
+		b, _ := GenerateSecret()
+
+		// Verify secrets
+		Expect(a).To(HavePrefix(SecretPrefix))
+		Expect(a).To(HaveLen(len(SecretPrefix) + 48))
+		Expect(a).To(Not(Equal(b)))
+	})
+
+	It("Signs payloads with their time", func() {
+		header := Sign(secret, t, payload)
+
+		// Verify header
+		Expect(header).To(HavePrefix("t=1583150400,v1="))
+		Expect(Sign(secret, t, payload)).To(Equal(header))
+		Expect(Sign("whsec_other", t, payload)).To(Not(Equal(header)))
+		Expect(Sign(secret, t.Add(time.Second), payload)).To(Not(Equal(header)))
+	})
+
+	It("Verifies signatures", func() {
+		header := Sign(secret, t, payload)
+
+		Expect(Verify(secret, header, payload, time.Minute, t.Add(30*time.Second))).To(Succeed())
+		// NOTE: Receivers may be sent several signatures while secrets are changed
+		Expect(Verify(secret, "t=1583150400,v1=00ff,"+strings.SplitN(header, ",", 2)[1], payload, time.Minute, t)).To(Succeed())
+	})
+
+	It("Rejects invalid signatures", func() {
+		header := Sign(secret, t, payload)
+
+		Expect(Verify("whsec_other", header, payload, time.Minute, t)).To(Equal(ErrInvalidSignature))
+		Expect(Verify(secret, header, []byte(`{}`), time.Minute, t)).To(Equal(ErrInvalidSignature))
+		Expect(Verify(secret, strings.Replace(header, "t=1583150400", "t=1583150401", 1), payload, time.Minute, t)).To(Equal(ErrInvalidSignature))
+		Expect(Verify(secret, "", payload, time.Minute, t)).To(Equal(ErrInvalidSignature))
+		Expect(Verify(secret, "t=abc,v1=zz", payload, time.Minute, t)).To(Equal(ErrInvalidSignature))
+	})
+
+	It("Rejects old signatures", func() {
+		header := Sign(secret, t, payload)
+
+		Expect(Verify(secret, header, payload, time.Minute, t.Add(2*time.Minute))).To(Equal(ErrExpiredSignature))
+	})
+})
@@ -0,0 +1,413 @@
+// webhooks package contains the delivery of market events to webhook subscriptions. Events are queued as
+// deliveries within the database, then attempted by a worker that signs payloads and retries failures with
+// exponential backoff, moving deliveries that fail every attempt to a dead-letter queue
+package webhooks
+
+import (
+	// Standard lib
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/sessions"
+	"github.com/deezone/forex-clock/stream"
+
+	// Third-party
+	goutils "github.com/marksost/go-utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Delivery headers
+	SignatureHeader = "X-Forex-Clock-Signature"
+	EventHeader     = "X-Forex-Clock-Event"
+	DeliveryHeader  = "X-Forex-Clock-Delivery"
+
+	// Longest error message kept for a delivery
+	maxErrorLength = 1024
+	// Largest response body read from receivers
+	maxResponseBody = 64 * 1024
+	// Time added to the timeout when claiming a delivery, so it isn't attempted again while in progress
+	claimMargin = 30 * time.Second
+)
+
+var (
+	// Errors of deliveries that can't be retried
+	ErrNotDead = errors.New("Only dead deliveries can be retried")
+)
+
+type (
+	// Dispatcher is a struct representing the queueing and delivery of market events to webhooks
+	Dispatcher struct {
+		store       db.WebhookStore
+		hub         *stream.Hub
+		client      *http.Client
+		mutex       sync.Mutex
+		stop        chan struct{} // Closed to stop the worker, nil when not running
+		done        chan struct{} // Closed once the worker stops
+		wake        chan struct{} // Signals queued deliveries are due
+		lastEventID string        // ID of the last event queued, to resume after falling behind
+	}
+)
+
+// NewDispatcher creates and returns a new instance of a dispatcher queueing events published by a hub
+// NOTE: Deliveries are never sent to private, loopback, or link-local addresses (see `NewClient`)
+func NewDispatcher(store db.WebhookStore, hub *stream.Hub) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		hub:    hub,
+		client: NewClient(),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Start starts queueing published events and attempting due deliveries
+func (d *Dispatcher) Start() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.stop != nil {
+		return
+	}
+
+	d.stop, d.done = make(chan struct{}), make(chan struct{})
+	go d.run(d.stop, d.done)
+}
+
+// Close stops the worker, waiting for attempts in progress to finish or a context to be done
+// NOTE: Must be called before closing the hub, which waits for its subscribers to leave
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mutex.Lock()
+	stop, done := d.stop, d.done
+	d.stop = nil
+	d.mutex.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enqueue queues deliveries of a market event to every active webhook subscribed to it
+// NOTE: Webhooks whose deliveries can't be checked for are skipped, returning the first error once the others
+// are queued
+func (d *Dispatcher) Enqueue(ev *sessions.Event) error {
+	webhooks, err := d.store.List()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(stream.NewMessage(ev))
+	if err != nil {
+		return err
+	}
+
+	var failed error
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, w := range webhooks {
+		if !w.Active || !Matches(w, ev) {
+			continue
+		}
+
+		// NOTE: IDs are derived from the webhook and event, so replicas queueing the same event don't duplicate it
+		id := deliveryID(w.ID, ev.ID)
+		existing, err := d.store.GetDelivery(id)
+		if err != nil && failed == nil {
+			failed = fmt.Errorf("Error checking for a delivery to webhook %s: %v", w.ID, err)
+		}
+		if err != nil || existing != nil {
+			continue
+		}
+
+		del := &db.Delivery{
+			ID:            id,
+			WebhookID:     w.ID,
+			EventID:       ev.ID,
+			EventType:     ev.Type,
+			Payload:       string(payload),
+			Status:        db.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := d.store.CreateDelivery(del); err != nil {
+			log.WithError(err).WithField("webhook", w.ID).Error("Error queueing webhook delivery")
+		}
+	}
+
+	d.notify()
+
+	return failed
+}
+
+// Retry moves a dead delivery back to the queue, to be attempted once more
+// NOTE: Attempts keep counting, so a delivery failing again returns to the dead-letter queue
+func (d *Dispatcher) Retry(id string) (*db.Delivery, error) {
+	del, err := d.store.GetDelivery(id)
+	if err != nil || del == nil {
+		return nil, err
+	}
+	if del.Status != db.DeliveryDead {
+		return nil, ErrNotDead
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	del.Status, del.NextAttemptAt, del.UpdatedAt = db.DeliveryPending, now, now
+	if err := d.store.UpdateDelivery(del); err != nil {
+		return nil, err
+	}
+
+	d.notify()
+
+	return del, nil
+}
+
+// Matches returns a boolean indicating if an event is subscribed to by a webhook.
+// Market-wide events (ex: weekly market open) match any sessions
+func Matches(w *db.Webhook, ev *sessions.Event) bool {
+	if len(w.EventTypes) > 0 && !goutils.SliceContains(ev.Type, []string(w.EventTypes)) {
+		return false
+	}
+	if len(w.Sessions) == 0 || (ev.Session == "" && len(ev.Sessions) == 0) {
+		return true
+	}
+
+	for _, id := range append([]string{ev.Session}, ev.Sessions...) {
+		if goutils.SliceContains(id, []string(w.Sessions)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Backoff returns the delay before retrying a delivery after a number of failed attempts
+func Backoff(attempts int, c config.Webhooks) time.Duration {
+	delay, max := time.Duration(c.BackoffBase)*time.Second, time.Duration(c.BackoffMax)*time.Second
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	return delay
+}
+
+// run queues published events and attempts due deliveries, until stopped
+func (d *Dispatcher) run(stop, done chan struct{}) {
+	defer close(done)
+
+	sub := d.hub.SubscribeFrom(nil, d.lastEventID)
+	defer func() { d.hub.Unsubscribe(sub) }()
+	messages := sub.Messages()
+
+	poll := time.NewTicker(time.Duration(config.GetInstance().Webhooks.PollInterval) * time.Second)
+	defer poll.Stop()
+
+	// Attempt deliveries left from before starting
+	d.process(time.Now())
+
+	for {
+		select {
+		case <-stop:
+			return
+		case m, ok := <-messages:
+			if !ok {
+				// NOTE: Resubscribes after falling behind, receiving the events missed
+				if sub.Err() == stream.ErrSlowConsumer {
+					d.hub.Unsubscribe(sub)
+					sub = d.hub.SubscribeFrom(nil, d.lastEventID)
+					messages = sub.Messages()
+				} else {
+					messages = nil
+				}
+				continue
+			}
+
+			// NOTE: Only market events are delivered, other messages (ex: strength changes) are skipped
+			d.lastEventID = m.ID
+			ev, ok := m.Data.(*sessions.Event)
+			if !ok {
+				continue
+			}
+			if err := d.Enqueue(ev); err != nil {
+				log.WithError(err).WithField("event", m.ID).Error("Error queueing webhook deliveries")
+			}
+		case <-poll.C:
+			d.process(time.Now())
+		case <-d.wake:
+			d.process(time.Now())
+		}
+	}
+}
+
+// process attempts every due delivery, in batches, concurrently within a batch
+func (d *Dispatcher) process(now time.Time) {
+	c := config.GetInstance().Webhooks
+
+	due, err := d.store.DueDeliveries(now, c.BatchSize)
+	if err != nil {
+		log.WithError(err).Error("Error reading due webhook deliveries")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, del := range due {
+		// NOTE: Deliveries claimed by another replica are skipped
+		until := now.Add(time.Duration(c.Timeout)*time.Second + claimMargin).UTC().Truncate(time.Second)
+		if err := d.store.ClaimDelivery(del, until); err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(del *db.Delivery) {
+			defer wg.Done()
+			d.attempt(del, c)
+		}(del)
+	}
+	wg.Wait()
+
+	// Continue with the next batch if this one was full
+	if len(due) == c.BatchSize {
+		d.notify()
+	}
+}
+
+// attempt sends a delivery to its webhook, logging the attempt and scheduling a retry if it fails
+func (d *Dispatcher) attempt(del *db.Delivery, c config.Webhooks) {
+	w, err := d.store.Get(del.WebhookID)
+	if err != nil {
+		log.WithError(err).WithField("delivery", del.ID).Error("Error getting webhook")
+		return
+	}
+
+	start := time.Now()
+	code := 0
+	switch {
+	case w == nil:
+		err = errors.New("Webhook was deleted")
+	case !w.Active:
+		err = errors.New("Webhook is inactive")
+	default:
+		code, err = d.send(w, del, start, c)
+	}
+	duration := time.Since(start)
+
+	del.Attempts++
+	del.LastStatusCode, del.LastError = code, ""
+	if err != nil {
+		del.LastError = truncate(err.Error(), maxErrorLength)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	switch {
+	case err == nil:
+		del.Status = db.DeliverySucceeded
+	case del.Attempts >= c.MaxAttempts || w == nil || !w.Active:
+		del.Status = db.DeliveryDead
+	default:
+		del.NextAttemptAt = now.Add(Backoff(del.Attempts, c))
+	}
+	del.UpdatedAt = now
+
+	if err := d.store.CreateAttempt(&db.DeliveryAttempt{
+		ID:         db.NewID(),
+		DeliveryID: del.ID,
+		Attempt:    del.Attempts,
+		StatusCode: del.LastStatusCode,
+		Error:      del.LastError,
+		DurationMS: int64(duration / time.Millisecond),
+		CreatedAt:  now,
+	}); err != nil {
+		log.WithError(err).WithField("delivery", del.ID).Error("Error logging webhook delivery attempt")
+	}
+
+	if err := d.store.UpdateDelivery(del); err != nil {
+		log.WithError(err).WithField("delivery", del.ID).Error("Error updating webhook delivery")
+	}
+
+	log.WithFields(log.Fields{
+		"delivery": del.ID,
+		"webhook":  del.WebhookID,
+		"attempt":  del.Attempts,
+		"status":   del.Status,
+	}).Debug("Webhook delivery attempted")
+}
+
+// send posts a delivery's signed payload to a webhook, returning the response's status code
+// NOTE: Responses other than 2xx are errors
+func (d *Dispatcher) send(w *db.Webhook, del *db.Delivery, now time.Time, c config.Webhooks) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout)*time.Second)
+	defer cancel()
+
+	payload := []byte(del.Payload)
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "forex-clock-webhooks")
+	req.Header.Set(EventHeader, del.EventType)
+	req.Header.Set(DeliveryHeader, del.ID)
+	req.Header.Set(SignatureHeader, Sign(w.Secret, now, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Read (a limited amount of) the body, so the connection can be reused
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, errors.New("Receiver responded with " + resp.Status + ": " + truncate(string(body), 256))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// notify signals that deliveries may be due, without blocking
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliveryID returns the ID of the delivery of an event to a webhook
+func deliveryID(webhookID, eventID string) string {
+	sum := sha256.Sum256([]byte(webhookID + "|" + eventID))
+
+	return hex.EncodeToString(sum[:16])
+}
+
+// truncate shortens a string to a maximum length
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+
+	return s
+}
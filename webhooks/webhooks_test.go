@@ -0,0 +1,376 @@
+// Tests the webhooks.go file
+package webhooks
+
+import (
+	// Standard lib
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/deezone/forex-clock/config"
+	"github.com/deezone/forex-clock/db"
+	"github.com/deezone/forex-clock/db/dbtest"
+	"github.com/deezone/forex-clock/sessions"
+	"github.com/deezone/forex-clock/stream"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	// Struct representing a webhook store that can't read a delivery
+	unavailableStore struct {
+		db.WebhookStore
+		deliveryID string
+	}
+)
+
+func (s *unavailableStore) GetDelivery(id string) (*db.Delivery, error) {
+	if id == s.deliveryID {
+		return nil, errors.New("Database is unavailable")
+	}
+
+	return s.WebhookStore.GetDelivery(id)
+}
+
+var _ = Describe("webhooks.go", func() {
+	var (
+		// Dispatcher to test, and its storage and hub
+		d     *Dispatcher
+		conn  db.DB
+		store db.WebhookStore
+		hub   *stream.Hub
+		// Local receiver of deliveries
+		receiver *httptest.Server
+		// Status code the receiver responds with
+		code int
+		// Requests received, and their verification
+		mutex    sync.Mutex
+		received []*http.Request
+		verified []error
+		// Time of test events
+		t = time.Date(2020, time.March, 2, 12, 0, 0, 0, time.UTC)
+		// Test events
+		londonOpen  = sessions.NewEvent(sessions.EventSessionOpen, t, "london", nil)
+		tokyoClose  = sessions.NewEvent(sessions.EventSessionClose, t, "tokyo", nil)
+		overlap     = sessions.NewEvent(sessions.EventOverlapStart, t, "", []string{"london", "new-york"})
+		marketClose = sessions.NewEvent(sessions.EventMarketClose, t, "", nil)
+	)
+
+	// subscribe stores a webhook delivering to the receiver
+	subscribe := func(id string, eventTypes, ids []string) *db.Webhook {
+		w := &db.Webhook{
+			ID:         id,
+			URL:        receiver.URL,
+			Secret:     "whsec_" + id,
+			EventTypes: eventTypes,
+			Sessions:   ids,
+			Active:     true,
+		}
+		store.Create(w)
+
+		return w
+	}
+
+	// deliveries returns the queued deliveries with a status
+	deliveries := func(status string) []*db.Delivery {
+		ds, _ := store.ListDeliveries(db.DeliveryFilter{Status: status, Limit: 100})
+		return ds
+	}
+
+	// requests returns the number of requests received
+	requests := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(received)
+	}
+
+	BeforeEach(func() {
+		c := config.GetInstance()
+		c.Stream.BufferSize, c.Stream.ReplaySize = 4, 10
+		c.Webhooks = config.Webhooks{
+			Enabled:      true,
+			MaxAttempts:  3,
+			BackoffBase:  10,
+			BackoffMax:   15,
+			Timeout:      2,
+			PollInterval: 1,
+			BatchSize:    10,
+			// NOTE: The receiver listens on a loopback address
+			PrivateAddresses: true,
+		}
+
+		code, received, verified = http.StatusOK, nil, nil
+		receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, _ := ioutil.ReadAll(req.Body)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			// Verify against the secret of the webhook named by the path
+			received = append(received, req)
+			verified = append(verified, Verify("whsec_"+req.URL.Query().Get("id"), req.Header.Get(SignatureHeader), body, time.Minute, time.Now()))
+			w.WriteHeader(code)
+		}))
+
+		conn = dbtest.New()
+		store, hub = db.NewWebhookStore(conn), stream.NewHub()
+		d = NewDispatcher(store, hub)
+	})
+
+	AfterEach(func() {
+		d.Close(context.Background())
+		hub.Close(context.Background())
+		receiver.Close()
+		conn.Close()
+	})
+
+	Describe("Queueing", func() {
+		It("Queues events for subscribed webhooks", func() {
+			subscribe("all", nil, nil)
+			subscribe("opens", []string{sessions.EventSessionOpen}, nil)
+			subscribe("tokyo", nil, []string{"tokyo"})
+			w := subscribe("inactive", nil, nil)
+			w.Active = false
+			store.Update(w)
+
+			Expect(d.Enqueue(londonOpen)).To(Succeed())
+			Expect(d.Enqueue(tokyoClose)).To(Succeed())
+
+			// Verify deliveries
+			queued := map[string][]string{}
+			for _, del := range deliveries(db.DeliveryPending) {
+				queued[del.WebhookID] = append(queued[del.WebhookID], del.EventID)
+			}
+			Expect(queued).To(HaveLen(3))
+			Expect(queued["all"]).To(ConsistOf(londonOpen.ID, tokyoClose.ID))
+			Expect(queued["opens"]).To(ConsistOf(londonOpen.ID))
+			Expect(queued["tokyo"]).To(ConsistOf(tokyoClose.ID))
+		})
+
+		It("Queues events as stream messages", func() {
+			subscribe("all", nil, nil)
+			d.Enqueue(londonOpen)
+
+			// Verify payload
+			m := &stream.Message{Data: &sessions.Event{}}
+			Expect(json.Unmarshal([]byte(deliveries("")[0].Payload), m)).To(Succeed())
+			Expect(m.ID).To(Equal(londonOpen.ID))
+			Expect(m.Type).To(Equal(sessions.EventSessionOpen))
+			Expect(m.Data.(*sessions.Event).Session).To(Equal("london"))
+		})
+
+		It("Doesn't queue an event twice", func() {
+			subscribe("all", nil, nil)
+			d.Enqueue(londonOpen)
+			d.Enqueue(londonOpen)
+
+			Expect(deliveries("")).To(HaveLen(1))
+		})
+
+		It("Returns errors checking for deliveries, queueing for the other webhooks", func() {
+			subscribe("all", nil, nil)
+			subscribe("opens", []string{sessions.EventSessionOpen}, nil)
+			d.store = &unavailableStore{WebhookStore: store, deliveryID: deliveryID("all", londonOpen.ID)}
+
+			// Call method
+			err := d.Enqueue(londonOpen)
+
+			// Verify output
+			Expect(err).To(MatchError(ContainSubstring("webhook all: Database is unavailable")))
+			del := deliveries("")
+			Expect(del).To(HaveLen(1))
+			Expect(del[0].WebhookID).To(Equal("opens"))
+		})
+
+		It("Matches events by type and session", func() {
+			w := &db.Webhook{Sessions: []string{"london"}}
+			Expect(Matches(w, londonOpen)).To(BeTrue())
+			Expect(Matches(w, tokyoClose)).To(BeFalse())
+			Expect(Matches(w, overlap)).To(BeTrue())
+			// NOTE: Market-wide events match any sessions
+			Expect(Matches(w, marketClose)).To(BeTrue())
+
+			w.EventTypes = []string{sessions.EventMarketClose}
+			Expect(Matches(w, londonOpen)).To(BeFalse())
+			Expect(Matches(w, marketClose)).To(BeTrue())
+		})
+	})
+
+	Describe("Delivering", func() {
+		var (
+			// Webhook subscribed to all events
+			w *db.Webhook
+		)
+
+		BeforeEach(func() {
+			w = subscribe("all", nil, nil)
+			w.URL += "?id=all"
+			store.Update(w)
+		})
+
+		It("Delivers signed payloads", func() {
+			d.Enqueue(londonOpen)
+			d.process(time.Now())
+
+			// Verify request
+			Expect(requests()).To(Equal(1))
+			Expect(verified[0]).To(Not(HaveOccurred()))
+			Expect(received[0].Method).To(Equal(http.MethodPost))
+			Expect(received[0].Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(received[0].Header.Get(EventHeader)).To(Equal(sessions.EventSessionOpen))
+
+			// Verify delivery and its log
+			del := deliveries(db.DeliverySucceeded)
+			Expect(del).To(HaveLen(1))
+			Expect(received[0].Header.Get(DeliveryHeader)).To(Equal(del[0].ID))
+			Expect(del[0].Attempts).To(Equal(1))
+			Expect(del[0].LastStatusCode).To(Equal(http.StatusOK))
+
+			attempts, _ := store.ListAttempts(del[0].ID)
+			Expect(attempts).To(HaveLen(1))
+			Expect(attempts[0].Attempt).To(Equal(1))
+			Expect(attempts[0].StatusCode).To(Equal(http.StatusOK))
+			Expect(attempts[0].Error).To(BeEmpty())
+
+			// Succeeded deliveries aren't attempted again
+			d.process(time.Now().Add(time.Hour))
+			Expect(requests()).To(Equal(1))
+		})
+
+		It("Retries failed deliveries with exponential backoff", func() {
+			code = http.StatusServiceUnavailable
+			d.Enqueue(londonOpen)
+			d.process(time.Now())
+
+			// Verify the retry is scheduled after the base delay
+			del := deliveries(db.DeliveryPending)
+			Expect(del).To(HaveLen(1))
+			Expect(del[0].Attempts).To(Equal(1))
+			Expect(del[0].LastStatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(del[0].LastError).To(ContainSubstring("503"))
+			Expect(del[0].NextAttemptAt).To(BeTemporally("~", time.Now().Add(10*time.Second), 2*time.Second))
+
+			// Not attempted again before it's due
+			d.process(time.Now())
+			Expect(requests()).To(Equal(1))
+
+			// Succeeds once the receiver recovers
+			code = http.StatusNoContent
+			d.process(time.Now().Add(time.Minute))
+			Expect(requests()).To(Equal(2))
+			Expect(deliveries(db.DeliverySucceeded)).To(HaveLen(1))
+		})
+
+		It("Doubles the backoff up to the maximum", func() {
+			c := config.GetInstance().Webhooks
+			c.BackoffBase, c.BackoffMax = 10, 3600
+
+			Expect(Backoff(1, c)).To(Equal(10 * time.Second))
+			Expect(Backoff(2, c)).To(Equal(20 * time.Second))
+			Expect(Backoff(4, c)).To(Equal(80 * time.Second))
+			Expect(Backoff(9, c)).To(Equal(2560 * time.Second))
+			Expect(Backoff(10, c)).To(Equal(time.Hour))
+			Expect(Backoff(100, c)).To(Equal(time.Hour))
+		})
+
+		It("Moves deliveries failing every attempt to the dead-letter queue", func() {
+			code = http.StatusInternalServerError
+			d.Enqueue(londonOpen)
+			for i := 0; i < 5; i++ {
+				d.process(time.Now().Add(time.Duration(i) * time.Hour))
+			}
+
+			// Verify delivery and its log
+			Expect(requests()).To(Equal(3))
+			del := deliveries(db.DeliveryDead)
+			Expect(del).To(HaveLen(1))
+			Expect(del[0].Attempts).To(Equal(3))
+
+			attempts, _ := store.ListAttempts(del[0].ID)
+			Expect(attempts).To(HaveLen(3))
+			for i, a := range attempts {
+				Expect(a.Attempt).To(Equal(i + 1))
+				Expect(a.StatusCode).To(Equal(http.StatusInternalServerError))
+			}
+		})
+
+		It("Logs deliveries without a response", func() {
+			receiver.Close()
+			d.Enqueue(londonOpen)
+			d.process(time.Now())
+
+			// Verify delivery
+			del := deliveries(db.DeliveryPending)
+			Expect(del).To(HaveLen(1))
+			Expect(del[0].LastStatusCode).To(Equal(0))
+			Expect(del[0].LastError).To(Not(BeEmpty()))
+		})
+
+		It("Doesn't deliver to inactive webhooks", func() {
+			d.Enqueue(londonOpen)
+			w.Active = false
+			store.Update(w)
+			d.process(time.Now())
+
+			// Verify delivery
+			Expect(requests()).To(Equal(0))
+			del := deliveries(db.DeliveryDead)
+			Expect(del).To(HaveLen(1))
+			Expect(del[0].LastError).To(ContainSubstring("inactive"))
+		})
+
+		It("Retries dead deliveries once more", func() {
+			code = http.StatusInternalServerError
+			d.Enqueue(londonOpen)
+			for i := 0; i < 3; i++ {
+				d.process(time.Now().Add(time.Duration(i) * time.Hour))
+			}
+			id := deliveries(db.DeliveryDead)[0].ID
+
+			// Verify only dead deliveries can be retried
+			d.Enqueue(tokyoClose)
+			_, err := d.Retry(deliveries(db.DeliveryPending)[0].ID)
+			Expect(err).To(Equal(ErrNotDead))
+
+			// Verify missing deliveries
+			del, err := d.Retry("missing")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(del).To(BeNil())
+
+			// Verify retry
+			code = http.StatusOK
+			del, err = d.Retry(id)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(del.Status).To(Equal(db.DeliveryPending))
+
+			d.process(time.Now())
+			del, _ = store.GetDelivery(id)
+			Expect(del.Status).To(Equal(db.DeliverySucceeded))
+			Expect(del.Attempts).To(Equal(4))
+		})
+
+		It("Delivers events published by the hub", func() {
+			d.Start()
+			Eventually(hub.Len).Should(Equal(1))
+
+			hub.Publish(londonOpen)
+
+			// Verify delivery
+			Eventually(requests).Should(Equal(1))
+			Eventually(func() []*db.Delivery { return deliveries(db.DeliverySucceeded) }).Should(HaveLen(1))
+
+			// Verify the dispatcher leaves the hub when closed
+			Expect(d.Close(context.Background())).To(Succeed())
+			Expect(hub.Len()).To(Equal(0))
+		})
+	})
+})